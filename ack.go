@@ -0,0 +1,278 @@
+package eventdistributor
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrNotInFlight is returned by (*Reader[T]).Ack() and Nack() when the event is not in flight -
+// i.e., it was not delivered to the Reader, or was already acked or nacked
+var ErrNotInFlight = errors.New("event is not in flight")
+
+// readerAcks is the state of a Reader in ack mode. See SubscribeAcked().
+type readerAcks struct {
+	// inFlight is the sorted sequence numbers of the events that were delivered but not yet acked
+	inFlight []int64
+	// redeliver is the sorted sequence numbers of the events that were nacked, and will be
+	// delivered again
+	redeliver []int64
+	// holdSeq is the sequence number of the event holding the Reader's extra reference, which keeps
+	// its unacked events in the buffer, or -1 if there is none
+	holdSeq int64
+}
+
+// SubscribeAcked creates a new Reader in ack mode, which must acknowledge every event it receives.
+//
+// Consumed events are "in flight" until they are acked with Ack() or AckUpTo(), and are kept in the
+// buffer until then - so the channels returned by Submit are only closed once the events are acked.
+// An in-flight event can instead be returned with Nack(), after which it is delivered again before
+// any unseen events.
+//
+// Because Ack() and Nack() identify events by sequence number, Readers in ack mode should consume
+// with TryConsumeSeq().
+//
+// SubscribeAcked panics if the Distributor was created with WithOrderBy.
+//
+// SubscribeAcked is thread-safe.
+func (d *Distributor[T]) SubscribeAcked() Reader[T] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.orderBy != nil {
+		panic("eventdistributor: SubscribeAcked called on a Distributor with WithOrderBy")
+	}
+
+	r := d.subscribe()
+	r.acks = &readerAcks{inFlight: nil, redeliver: nil, holdSeq: -1}
+	return r
+}
+
+// Ack acknowledges the in-flight event with the sequence number seq, allowing it to be removed
+// from the buffer. See SubscribeAcked().
+//
+// Ack returns ErrNotInFlight if the event is not in flight, which is always the case for Readers
+// that are not in ack mode, and ErrClosed if the Distributor is closed.
+//
+// Ack is thread-safe.
+func (r *Reader[T]) Ack(seq int64) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if r.d.closed {
+		return ErrClosed
+	} else if r.acks == nil || !containsSeq(r.acks.inFlight, seq) {
+		return ErrNotInFlight
+	}
+
+	r.acks.inFlight = removeSeq(r.acks.inFlight, seq)
+	r.moveAckHold()
+	r.d.cleanupOldEvents()
+	return nil
+}
+
+// AckUpTo acknowledges every in-flight event with a sequence number of at most seq, returning the
+// number of events acked. Later in-flight events remain outstanding. See SubscribeAcked().
+//
+// Nacked events that have not yet been delivered again are not in flight, so they are not acked.
+//
+// AckUpTo returns zero for Readers that are not in ack mode, and if the Distributor is closed.
+//
+// AckUpTo is thread-safe.
+func (r *Reader[T]) AckUpTo(seq int64) int {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if r.d.closed || r.acks == nil {
+		return 0
+	}
+
+	inFlight := r.acks.inFlight
+	n := sort.Search(len(inFlight), func(i int) bool { return inFlight[i] > seq })
+	if n == 0 {
+		return 0
+	}
+
+	r.acks.inFlight = append(inFlight[:0], inFlight[n:]...)
+	r.moveAckHold()
+	r.d.cleanupOldEvents()
+	return n
+}
+
+// Nack returns the in-flight event with the sequence number seq, so that it is delivered to the
+// Reader again, before any events it has not yet seen. See SubscribeAcked().
+//
+// Nack returns ErrNotInFlight if the event is not in flight, which is always the case for Readers
+// that are not in ack mode, and ErrClosed if the Distributor is closed.
+//
+// Nack is thread-safe.
+func (r *Reader[T]) Nack(seq int64) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if r.d.closed {
+		return ErrClosed
+	} else if r.acks == nil || !containsSeq(r.acks.inFlight, seq) {
+		return ErrNotInFlight
+	}
+
+	r.acks.inFlight = removeSeq(r.acks.inFlight, seq)
+	r.acks.redeliver = insertSeq(r.acks.redeliver, seq)
+	r.wakeOwn()
+	return nil
+}
+
+// InFlight returns the number of events that have been delivered to the Reader but not yet acked
+// or nacked. It is always zero for Readers that are not in ack mode. See SubscribeAcked().
+//
+// InFlight is thread-safe.
+func (r *Reader[T]) InFlight() int {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if r.acks == nil {
+		return 0
+	}
+	return len(r.acks.inFlight)
+}
+
+// hasQueued returns whether the Reader has events to receive that aren't at its position in the
+// buffer: nacked events waiting to be delivered again, or archived events. See SubscribeFrom().
+//
+// r.d.mu must be held.
+func (r *Reader[T]) hasQueued() bool {
+	return r.hasBackfill() || (r.acks != nil && len(r.acks.redeliver) != 0)
+}
+
+// consumeAcked implements Consume for Readers in ack mode, additionally returning the sequence
+// number of the consumed event. Nacked events are delivered first, and the event is then in flight.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) consumeAcked() (T, int64) {
+	if len(r.acks.redeliver) != 0 {
+		seq := r.acks.redeliver[0]
+		r.acks.redeliver = r.acks.redeliver[1:]
+		r.acks.inFlight = insertSeq(r.acks.inFlight, seq)
+		idx, _ := r.d.findSeq(seq)
+		return r.d.buf[idx].value, seq
+	}
+
+	// The event must be held before the Reader moves past it, so that it isn't removed from the
+	// buffer. After a Rewind(), it may also be waiting for redelivery.
+	seq := r.d.buf[r.position-r.d.basePosition].seq
+	r.acks.redeliver = removeSeq(r.acks.redeliver, seq)
+	r.acks.inFlight = insertSeq(r.acks.inFlight, seq)
+	r.moveAckHold()
+	return r.consume()
+}
+
+// moveAckHold moves the Reader's extra reference to its oldest unacked event, or drops it if there
+// are none. Callers should then call cleanupOldEvents.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) moveAckHold() {
+	oldest := int64(-1)
+	if len(r.acks.inFlight) != 0 {
+		oldest = r.acks.inFlight[0]
+	}
+	if len(r.acks.redeliver) != 0 && (oldest == -1 || r.acks.redeliver[0] < oldest) {
+		oldest = r.acks.redeliver[0]
+	}
+
+	if oldest == r.acks.holdSeq {
+		return
+	}
+
+	if oldest != -1 {
+		idx, _ := r.d.findSeq(oldest)
+		r.d.buf[idx].refcount += 1
+	}
+	if r.acks.holdSeq != -1 {
+		idx, _ := r.d.findSeq(r.acks.holdSeq)
+		r.d.buf[idx].refcount -= 1
+	}
+	r.acks.holdSeq = oldest
+}
+
+// releaseAcks drops all of the unacked events of a Reader in ack mode, as part of releasing it.
+// Callers should then call cleanupOldEvents.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) releaseAcks() {
+	r.acks.inFlight = nil
+	r.acks.redeliver = nil
+	r.moveAckHold()
+}
+
+// voidAckHolds drops the holds of Readers in ack mode on the event with the sequence number seq,
+// because it is being removed from the buffer, returning the number of references they held.
+//
+// d.mu must be held.
+func (d *Distributor[T]) voidAckHolds(seq int64) int64 {
+	var count int64
+	for _, r := range d.readers {
+		if r.acks != nil && r.acks.holdSeq == seq {
+			r.acks.holdSeq = -1
+			count += 1
+		}
+	}
+	return count
+}
+
+// pruneAcks removes events that are no longer buffered from the Readers' unacked events, after
+// events were removed by FilterInPlace(), and moves their holds to the remaining ones.
+//
+// d.mu must be held.
+func (d *Distributor[T]) pruneAcks() {
+	for _, r := range d.readers {
+		if r.acks == nil {
+			continue
+		}
+
+		isBuffered := func(seq int64) bool {
+			_, ok := d.findSeq(seq)
+			return ok
+		}
+		r.acks.inFlight = filterSeqs(r.acks.inFlight, isBuffered)
+		r.acks.redeliver = filterSeqs(r.acks.redeliver, isBuffered)
+		r.reader().moveAckHold()
+	}
+}
+
+// containsSeq returns whether the sorted seqs contains seq
+func containsSeq(seqs []int64, seq int64) bool {
+	i := sort.Search(len(seqs), func(i int) bool { return seqs[i] >= seq })
+	return i < len(seqs) && seqs[i] == seq
+}
+
+// insertSeq adds seq to the sorted seqs, if it's not already present
+func insertSeq(seqs []int64, seq int64) []int64 {
+	i := sort.Search(len(seqs), func(i int) bool { return seqs[i] >= seq })
+	if i < len(seqs) && seqs[i] == seq {
+		return seqs
+	}
+
+	seqs = append(seqs, 0)
+	copy(seqs[i+1:], seqs[i:])
+	seqs[i] = seq
+	return seqs
+}
+
+// removeSeq removes seq from the sorted seqs, if it's present
+func removeSeq(seqs []int64, seq int64) []int64 {
+	i := sort.Search(len(seqs), func(i int) bool { return seqs[i] >= seq })
+	if i == len(seqs) || seqs[i] != seq {
+		return seqs
+	}
+	return append(seqs[:i], seqs[i+1:]...)
+}
+
+// filterSeqs removes every element of seqs for which keep returns false
+func filterSeqs(seqs []int64, keep func(int64) bool) []int64 {
+	kept := seqs[:0]
+	for _, seq := range seqs {
+		if keep(seq) {
+			kept = append(kept, seq)
+		}
+	}
+	return kept
+}
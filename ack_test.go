@@ -0,0 +1,127 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestAck(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	r := d.SubscribeAcked()
+
+	s0 := d.Submit(MyEvent{id: 0})
+	s1 := d.Submit(MyEvent{id: 1})
+
+	t.Log("consumed events are kept until they're acked")
+	e, seq, err := r.TryConsumeSeq()
+	require.NoError(t, err)
+	require.Equal(t, 0, e.id)
+	require.Equal(t, int64(0), seq)
+	require.Equal(t, 1, r.InFlight())
+	nowNotReady(t, s0)
+
+	require.NoError(t, r.Ack(0))
+	nowReady(t, s0)
+	require.ErrorIs(t, r.Ack(0), eventdistributor.ErrNotInFlight)
+
+	t.Log("nacked events are delivered again")
+	e, seq, err = r.TryConsumeSeq()
+	require.NoError(t, err)
+	require.Equal(t, 1, e.id)
+	notReady(t, r)
+
+	require.NoError(t, r.Nack(seq))
+	require.Equal(t, 0, r.InFlight())
+	ready(t, r)
+	d.Submit(MyEvent{id: 2})
+	require.Equal(t, []int{1, 2}, drainIDs(&r))
+	nowNotReady(t, s1)
+
+	t.Log("unsubscribing releases unacked events")
+	r.Unsubscribe()
+	nowReady(t, s1)
+}
+
+func TestAckUpTo(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var consumed []int
+	options.OnFullyConsumed(func(e MyEvent) {
+		consumed = append(consumed, e.id)
+	})
+	d := eventdistributor.New(options)
+
+	r := d.SubscribeAcked()
+	defer r.Unsubscribe()
+
+	for i := 0; i < 5; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+	require.Equal(t, []int{0, 1, 2, 3, 4}, drainIDs(&r))
+
+	t.Log("nacked events aren't acked until they're delivered again")
+	require.NoError(t, r.Nack(1))
+	require.Equal(t, 3, r.AckUpTo(3))
+	require.Equal(t, []int{0}, consumed)
+	require.Equal(t, 1, r.InFlight())
+
+	require.Equal(t, []int{1}, drainIDs(&r))
+	require.Equal(t, 1, r.AckUpTo(3))
+	require.Equal(t, []int{0, 1, 2, 3}, consumed)
+
+	t.Log("later events remain in flight")
+	require.Equal(t, 0, r.AckUpTo(3))
+	require.Equal(t, 1, r.InFlight())
+	require.Equal(t, 1, r.AckUpTo(10))
+	require.Equal(t, []int{0, 1, 2, 3, 4}, consumed)
+
+	t.Log("readers not in ack mode have nothing in flight")
+	plain := d.Subscribe()
+	defer plain.Unsubscribe()
+	d.Submit(MyEvent{id: 5})
+	require.Equal(t, []int{5}, drainIDs(&plain))
+	require.Equal(t, 0, plain.AckUpTo(5))
+	require.ErrorIs(t, plain.Ack(5), eventdistributor.ErrNotInFlight)
+}
+
+func TestAckFilterInPlace(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var consumed []int
+	options.OnFullyConsumed(func(e MyEvent) {
+		consumed = append(consumed, e.id)
+	})
+	d := eventdistributor.New(options)
+
+	r := d.SubscribeAcked()
+	defer r.Unsubscribe()
+
+	for i := 0; i < 4; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+	require.Equal(t, []int{0, 1}, drainN(t, &r, 2))
+
+	t.Log("removed events are no longer in flight")
+	require.Equal(t, 1, d.FilterInPlace(func(e MyEvent) bool { return e.id == 0 }))
+	require.Equal(t, 1, r.InFlight())
+	require.ErrorIs(t, r.Ack(0), eventdistributor.ErrNotInFlight)
+
+	require.NoError(t, r.Ack(1))
+	require.Equal(t, []int{1}, consumed)
+	require.Equal(t, []int{2, 3}, drainIDs(&r))
+	require.Equal(t, 2, r.AckUpTo(3))
+	require.Equal(t, []int{1, 2, 3}, consumed)
+}
+
+// drainN consumes n events from the Reader, returning their IDs
+func drainN(t *testing.T, r *eventdistributor.Reader[MyEvent], n int) []int {
+	var ids []int
+	for i := 0; i < n; i++ {
+		e, err := r.TryConsume()
+		require.NoError(t, err)
+		ids = append(ids, e.id)
+	}
+	return ids
+}
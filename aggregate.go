@@ -0,0 +1,110 @@
+package eventdistributor
+
+import (
+	"context"
+	"sync"
+)
+
+// Aggregator folds every event from a Distributor into an aggregate value, in order, allowing
+// other code to read the current aggregate at any time. It is created by NewAggregator.
+type Aggregator[A any] struct {
+	mu  sync.Mutex
+	agg A
+	seq int64
+
+	done chan struct{}
+}
+
+// NewAggregator creates an Aggregator that folds every event submitted to d from now on into an
+// aggregate, starting with init.
+//
+// Events are folded by a background goroutine, which stops - unsubscribing from d - once ctx is
+// canceled or d is closed. See Done().
+//
+// Snapshot() returns the aggregate by value, while holding the same lock that's held while fold is
+// applied, so it never observes a partial update. If A contains references (like maps or slices),
+// fold must not modify the aggregate it's given in place, because earlier snapshots share it;
+// instead, it should return an updated copy.
+func NewAggregator[T, A any](
+	ctx context.Context,
+	d *Distributor[T],
+	init A,
+	fold func(A, T) A,
+) *Aggregator[A] {
+	a := &Aggregator[A]{
+		mu:   sync.Mutex{},
+		agg:  init,
+		seq:  -1,
+		done: make(chan struct{}),
+	}
+
+	// Subscribe before returning, so that no event submitted after NewAggregator returns is
+	// missed.
+	r := d.Subscribe()
+
+	go func() {
+		defer close(a.done)
+		defer r.Unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-r.WaitChan():
+			}
+
+			value, seq, err := r.TryConsumeSeq()
+			if err == ErrNoEvent {
+				continue
+			} else if err != nil {
+				// The Distributor was closed, so there's nothing left to fold.
+				return
+			}
+
+			a.mu.Lock()
+			a.agg = fold(a.agg, value)
+			a.seq = seq
+			a.mu.Unlock()
+		}
+	}()
+
+	return a
+}
+
+// Snapshot returns the current aggregate.
+//
+// Snapshot is thread-safe.
+func (a *Aggregator[A]) Snapshot() A {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.agg
+}
+
+// Seq returns the sequence number of the last event folded into the aggregate, or -1 if no events
+// have been folded yet.
+//
+// Seq is thread-safe.
+func (a *Aggregator[A]) Seq() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.seq
+}
+
+// SnapshotSeq returns the current aggregate together with the sequence number of the last event
+// folded into it, as a consistent pair. See Snapshot() and Seq().
+//
+// SnapshotSeq is thread-safe.
+func (a *Aggregator[A]) SnapshotSeq() (A, int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.agg, a.seq
+}
+
+// Done returns a channel that is closed once the Aggregator has stopped and unsubscribed, after its
+// context was canceled or its Distributor was closed.
+func (a *Aggregator[A]) Done() <-chan struct{} {
+	return a.done
+}
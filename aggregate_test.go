@@ -0,0 +1,75 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestAggregator(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sum := eventdistributor.NewAggregator(ctx, d, 0, func(total int, e MyEvent) int {
+		return total + e.id
+	})
+	require.Equal(t, 0, sum.Snapshot())
+	require.Equal(t, int64(-1), sum.Seq())
+
+	for id := 1; id <= 4; id++ {
+		d.Submit(MyEvent{id: id})
+	}
+	awaitSeq(t, sum, 3)
+
+	total, seq := sum.SnapshotSeq()
+	require.Equal(t, 10, total)
+	require.Equal(t, int64(3), seq)
+
+	t.Log("canceling the context unsubscribes")
+	cancel()
+	<-sum.Done()
+	nowReady(t, d.Submit(MyEvent{id: 5}))
+	require.Equal(t, 10, sum.Snapshot())
+}
+
+func TestAggregatorClose(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	latest := eventdistributor.NewAggregator(context.Background(), d, map[int]bool{},
+		func(seen map[int]bool, e MyEvent) map[int]bool {
+			updated := make(map[int]bool, len(seen)+1)
+			for id := range seen {
+				updated[id] = true
+			}
+			updated[e.id] = true
+			return updated
+		})
+
+	d.Submit(MyEvent{id: 1})
+	awaitSeq(t, latest, 0)
+	snapshot := latest.Snapshot()
+	d.Submit(MyEvent{id: 2})
+	awaitSeq(t, latest, 1)
+
+	t.Log("earlier snapshots are unaffected by later events")
+	require.Equal(t, map[int]bool{1: true}, snapshot)
+	require.Equal(t, map[int]bool{1: true, 2: true}, latest.Snapshot())
+
+	d.Close()
+	select {
+	case <-latest.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Aggregator didn't stop after Close")
+	}
+}
+
+// awaitSeq waits for the Aggregator to have folded the event with the sequence number seq
+func awaitSeq[A any](t *testing.T, a *eventdistributor.Aggregator[A], seq int64) {
+	require.Eventually(t, func() bool { return a.Seq() >= seq }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,230 @@
+package eventdistributor
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Archiver stores events after they leave a Distributor's buffer, so that they can still be
+// retrieved later. See (*Options[T]).WithArchiver().
+type Archiver[T any] interface {
+	// Store stores the event with the sequence number seq, submitted at the time ts. Events are
+	// stored in order of sequence number.
+	Store(seq int64, ts time.Time, v T) error
+	// Load returns every stored event with a sequence number from "from" (inclusive) to "to"
+	// (exclusive), in order
+	Load(from, to int64) ([]ArchivedEvent[T], error)
+	// Trim discards every stored event with a sequence number before the given one. It is not
+	// called by the Distributor; it is intended for limiting the size of the archive.
+	Trim(before int64) error
+}
+
+// ArchivedEvent is an event returned by (Archiver[T]).Load()
+type ArchivedEvent[T any] struct {
+	// Seq is the sequence number of the event
+	Seq int64
+	// Timestamp is the time at which the event was submitted
+	Timestamp time.Time
+	// Value is the submitted value
+	Value T
+}
+
+// WithArchiver sets an Archiver that every event is stored in once it leaves the buffer - whether
+// it was fully consumed, immediately discarded because there were no Readers, expired by
+// WithEventTTL, dropped to make room in a buffer created by NewFixed(), or dropped by Close().
+// Events removed by FilterInPlace() or dropped before they were added to the buffer are not stored.
+// Archived events are available to (*Distributor[T]).SubscribeFrom().
+//
+// Events are stored by a background goroutine, so that a slow Archiver never blocks producers or
+// Readers. Errors from Store are passed to any OnArchiveError callbacks.
+//
+// WithArchiver also enables timestamps, so that they can be stored. See WithTimestamps().
+func (o *Options[T]) WithArchiver(a Archiver[T]) {
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.timestamps = true
+		if d.archive == nil {
+			d.archive = &archiveQueue[T]{archiver: a}
+		} else {
+			d.archive.archiver = a
+		}
+	})
+}
+
+// OnArchiveError adds a callback to the options that will be called whenever the Archiver fails to
+// store an event. See WithArchiver().
+//
+// Unlike other callbacks, it is called from the Distributor's archiving goroutine, without holding
+// the Distributor's lock.
+func (o *Options[T]) OnArchiveError(callback func(seq int64, err error)) {
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		if d.archive == nil {
+			d.archive = &archiveQueue[T]{}
+		}
+		d.archive.onError = append(d.archive.onError, callback)
+	})
+}
+
+// archiveQueue passes events to an Archiver in the background. See WithArchiver.
+type archiveQueue[T any] struct {
+	archiver Archiver[T]
+	onError  []func(seq int64, err error)
+
+	mu      sync.Mutex
+	pending []ArchivedEvent[T]
+	// idle, if not nil, is closed once the background goroutine has stored every pending event and
+	// exited. It is nil if the goroutine is not running.
+	idle chan struct{}
+}
+
+// enqueue adds the event to the queue, starting the background goroutine if it isn't running
+func (q *archiveQueue[T]) enqueue(e ArchivedEvent[T]) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pending = append(q.pending, e)
+	if q.idle == nil {
+		q.idle = make(chan struct{})
+		go q.run()
+	}
+}
+
+// run stores the pending events until there are none left
+func (q *archiveQueue[T]) run() {
+	for {
+		q.mu.Lock()
+		batch := q.pending
+		q.pending = nil
+		if len(batch) == 0 {
+			close(q.idle)
+			q.idle = nil
+			q.mu.Unlock()
+			return
+		}
+		q.mu.Unlock()
+
+		for _, e := range batch {
+			if err := q.archiver.Store(e.Seq, e.Timestamp, e.Value); err != nil {
+				// The Distributor's lock isn't held here, so the callbacks can run directly.
+				for _, f := range q.onError {
+					f(e.Seq, err)
+				}
+			}
+		}
+	}
+}
+
+// flush waits until every event enqueued so far has been stored
+func (q *archiveQueue[T]) flush() {
+	q.mu.Lock()
+	idle := q.idle
+	q.mu.Unlock()
+
+	if idle != nil {
+		<-idle
+	}
+}
+
+// archiveEvent passes the event to the Archiver, if there is one.
+//
+// d.mu must be held.
+func (d *Distributor[T]) archiveEvent(seq int64, timestamp time.Time, value T) {
+	if d.activeArchive() == nil {
+		return
+	}
+	d.archive.enqueue(ArchivedEvent[T]{Seq: seq, Timestamp: timestamp, Value: value})
+}
+
+// activeArchive returns the Distributor's archiveQueue, or nil if it doesn't have an Archiver
+func (d *Distributor[T]) activeArchive() *archiveQueue[T] {
+	if d.archive == nil || d.archive.archiver == nil {
+		return nil
+	}
+	return d.archive
+}
+
+// SubscribeFrom creates a new Reader that starts at the event with the sequence number seq,
+// receiving every event from there on - including events that have already left the buffer, if
+// they were stored by the Distributor's Archiver. See (*Options[T]).WithArchiver().
+//
+// Archived events are delivered first, followed by the buffered events, and then live events, with
+// no gaps or duplicates between them. Archived events are loaded before SubscribeFrom returns. The
+// archived and buffered events are treated as a replay. See ReplayDone().
+//
+// Without an Archiver, SubscribeFrom returns ErrSeqEvicted if seq is before the oldest buffered
+// event. It returns ErrSeqInFuture if seq is after the live edge, ErrClosed if the Distributor is
+// closed, and any error from (Archiver[T]).Load().
+//
+// SubscribeFrom is thread-safe.
+func (d *Distributor[T]) SubscribeFrom(seq int64) (Reader[T], error) {
+	d.mu.Lock()
+
+	if d.closed {
+		d.mu.Unlock()
+		return Reader[T]{}, ErrClosed
+	} else if seq > d.nextSeq {
+		d.mu.Unlock()
+		return Reader[T]{}, ErrSeqInFuture
+	}
+
+	// Events from seq up to the first buffered event must come from the archive.
+	buf := d.buf
+	idx := sort.Search(len(buf), func(i int) bool { return buf[i].seq >= seq })
+	archivedEnd := d.nextSeq
+	if idx < len(buf) {
+		archivedEnd = buf[idx].seq
+	}
+
+	q := d.activeArchive()
+	if seq < archivedEnd && q == nil {
+		d.mu.Unlock()
+		return Reader[T]{}, ErrSeqEvicted
+	}
+
+	r := d.subscribe()
+	r.firstSeq = seq
+	r.moveTo(d.basePosition + int64(idx))
+	d.mu.Unlock()
+
+	// The Reader holds everything from archivedEnd onwards, so the rest of the events are either
+	// archived already or waiting in the queue.
+	if seq < archivedEnd {
+		q.flush()
+		events, err := q.archiver.Load(seq, archivedEnd)
+		if err != nil {
+			r.Unsubscribe()
+			return Reader[T]{}, err
+		}
+
+		d.mu.Lock()
+		r.backfill = events
+		if len(events) != 0 && r.replay == nil {
+			r.replay = &readerReplay{end: r.position, done: nil}
+		}
+		d.mu.Unlock()
+	}
+
+	return r, nil
+}
+
+// hasBackfill returns whether the Reader has archived events left to deliver. See SubscribeFrom().
+//
+// r.d.mu must be held.
+func (r *Reader[T]) hasBackfill() bool {
+	return len(r.backfill) != 0
+}
+
+// consumeBackfill consumes the next archived event for a Reader created by SubscribeFrom(),
+// additionally returning its sequence number.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) consumeBackfill() (T, int64) {
+	e := r.backfill[0]
+	r.backfill[0] = ArchivedEvent[T]{}
+	r.backfill = r.backfill[1:]
+	if len(r.backfill) == 0 {
+		r.backfill = nil
+		r.checkReplayDone()
+	}
+	return e.Value, e.Seq
+}
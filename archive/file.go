@@ -0,0 +1,225 @@
+// Package archive provides a file-backed implementation of eventdistributor.Archiver, for use with
+// (*eventdistributor.Options[T]).WithArchiver().
+package archive
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+// recordHeaderSize is the size of the sequence number, timestamp, and length that precede each
+// encoded event in the file
+const recordHeaderSize = 20
+
+// This is checked here so that any change to eventdistributor.Archiver breaks the build.
+var _ eventdistributor.Archiver[int] = (*File[int])(nil)
+
+// File is an eventdistributor.Archiver that appends events to a single file.
+//
+// Each event is stored as a record: its sequence number (big-endian int64), its timestamp in
+// nanoseconds since the Unix epoch (big-endian int64), the length of the encoded event (big-endian
+// uint32), and the encoded event itself. An index of the records is kept in memory.
+//
+// Writes are not synced to disk, so events may be lost if the machine crashes. If the file ends
+// with an incomplete record - for example, because the process crashed while writing it - the
+// record is discarded when the file is opened.
+type File[T any] struct {
+	mu sync.Mutex
+
+	path   string
+	file   *os.File
+	encode func(T) ([]byte, error)
+	decode func([]byte) (T, error)
+
+	// index is the location of each record in the file, in order
+	index []record
+	// size is the total size of the records in the file
+	size int64
+}
+
+// record is the location of a single record in the file
+type record struct {
+	seq    int64
+	offset int64
+}
+
+// OpenFile opens the archive at path, creating it if it doesn't exist. Events are encoded with
+// encode when they are stored, and decoded with decode when they are loaded.
+func OpenFile[T any](
+	path string,
+	encode func(T) ([]byte, error),
+	decode func([]byte) (T, error),
+) (*File[T], error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &File[T]{
+		mu:     sync.Mutex{},
+		path:   path,
+		file:   file,
+		encode: encode,
+		decode: decode,
+		index:  nil,
+		size:   0,
+	}
+	if err := a.buildIndex(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read archive %q: %w", path, err)
+	}
+	return a, nil
+}
+
+// buildIndex reads the records in the file to build the index, discarding any incomplete record at
+// the end.
+//
+// a.mu must be held, unless the File is still being created.
+func (a *File[T]) buildIndex() error {
+	a.index = nil
+	a.size = 0
+
+	info, err := a.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	var header [recordHeaderSize]byte
+	for {
+		if _, err := a.file.ReadAt(header[:], a.size); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+
+		seq := int64(binary.BigEndian.Uint64(header[0:8]))
+		length := int64(binary.BigEndian.Uint32(header[16:20]))
+		if a.size+recordHeaderSize+length > info.Size() {
+			break
+		}
+
+		a.index = append(a.index, record{seq: seq, offset: a.size})
+		a.size += recordHeaderSize + length
+	}
+
+	if a.size != info.Size() {
+		return a.file.Truncate(a.size)
+	}
+	return nil
+}
+
+// Store implements eventdistributor.Archiver
+func (a *File[T]) Store(seq int64, ts time.Time, v T) error {
+	payload, err := a.encode(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %d: %w", seq, err)
+	}
+
+	buf := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint64(buf[0:8], uint64(seq))
+	binary.BigEndian.PutUint64(buf[8:16], uint64(ts.UnixNano()))
+	binary.BigEndian.PutUint32(buf[16:20], uint32(len(payload)))
+	copy(buf[recordHeaderSize:], payload)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, err := a.file.WriteAt(buf, a.size); err != nil {
+		return err
+	}
+	a.index = append(a.index, record{seq: seq, offset: a.size})
+	a.size += int64(len(buf))
+	return nil
+}
+
+// Load implements eventdistributor.Archiver
+func (a *File[T]) Load(from, to int64) ([]eventdistributor.ArchivedEvent[T], error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	start := sort.Search(len(a.index), func(i int) bool { return a.index[i].seq >= from })
+	end := sort.Search(len(a.index), func(i int) bool { return a.index[i].seq >= to })
+
+	var events []eventdistributor.ArchivedEvent[T]
+	for _, rec := range a.index[start:end] {
+		var header [recordHeaderSize]byte
+		if _, err := a.file.ReadAt(header[:], rec.offset); err != nil {
+			return nil, err
+		}
+		payload := make([]byte, binary.BigEndian.Uint32(header[16:20]))
+		if _, err := a.file.ReadAt(payload, rec.offset+recordHeaderSize); err != nil {
+			return nil, err
+		}
+
+		value, err := a.decode(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode event %d: %w", rec.seq, err)
+		}
+		events = append(events, eventdistributor.ArchivedEvent[T]{
+			Seq:       rec.seq,
+			Timestamp: time.Unix(0, int64(binary.BigEndian.Uint64(header[8:16]))),
+			Value:     value,
+		})
+	}
+	return events, nil
+}
+
+// Trim implements eventdistributor.Archiver. The remaining records are copied into a new file,
+// which replaces the old one.
+func (a *File[T]) Trim(before int64) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	n := sort.Search(len(a.index), func(i int) bool { return a.index[i].seq >= before })
+	if n == 0 {
+		return nil
+	}
+
+	start := a.size
+	if n < len(a.index) {
+		start = a.index[n].offset
+	}
+
+	tmpPath := a.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, io.NewSectionReader(a.file, start, a.size-start)); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, a.path); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	a.file.Close()
+	a.file = tmp
+
+	a.index = append(a.index[:0], a.index[n:]...)
+	for i := range a.index {
+		a.index[i].offset -= start
+	}
+	a.size -= start
+	return nil
+}
+
+// Close closes the file. The File must not be used afterwards.
+func (a *File[T]) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.file.Close()
+}
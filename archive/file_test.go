@@ -0,0 +1,103 @@
+package archive_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+	"github.com/sharnoff/eventdistributor/archive"
+)
+
+func encode(v int) ([]byte, error) {
+	return []byte(strconv.Itoa(v)), nil
+}
+
+func decode(b []byte) (int, error) {
+	return strconv.Atoi(string(b))
+}
+
+func values(events []eventdistributor.ArchivedEvent[int]) []int {
+	var vs []int
+	for _, e := range events {
+		vs = append(vs, e.Value)
+	}
+	return vs
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive")
+	a, err := archive.OpenFile(path, encode, decode)
+	require.NoError(t, err)
+
+	ts := time.Unix(100, 5)
+	for seq := int64(0); seq < 5; seq++ {
+		require.NoError(t, a.Store(seq, ts, int(seq)*10))
+	}
+
+	t.Log("events are loaded by sequence number, with their timestamps")
+	events, err := a.Load(1, 3)
+	require.NoError(t, err)
+	require.Equal(t, []int{10, 20}, values(events))
+	require.Equal(t, int64(1), events[0].Seq)
+	require.True(t, ts.Equal(events[0].Timestamp))
+
+	t.Log("trimmed events are no longer available")
+	require.NoError(t, a.Trim(2))
+	events, err = a.Load(0, 10)
+	require.NoError(t, err)
+	require.Equal(t, []int{20, 30, 40}, values(events))
+
+	require.NoError(t, a.Store(5, ts, 50))
+	require.NoError(t, a.Close())
+
+	t.Log("reopening the file restores the index, discarding incomplete records")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 0})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	a, err = archive.OpenFile(path, encode, decode)
+	require.NoError(t, err)
+	defer a.Close()
+
+	require.NoError(t, a.Store(6, ts, 60))
+	events, err = a.Load(0, 10)
+	require.NoError(t, err)
+	require.Equal(t, []int{20, 30, 40, 50, 60}, values(events))
+}
+
+func TestFileWithDistributor(t *testing.T) {
+	a, err := archive.OpenFile(filepath.Join(t.TempDir(), "archive"), encode, decode)
+	require.NoError(t, err)
+	defer a.Close()
+
+	var options eventdistributor.Options[int]
+	options.WithArchiver(a)
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	for i := 0; i < 4; i++ {
+		d.Submit(i)
+		r.Consume()
+	}
+	d.Submit(4)
+
+	backfill, err := d.SubscribeFrom(1)
+	require.NoError(t, err)
+	defer backfill.Unsubscribe()
+
+	var received []int
+	for i := 0; i < 4; i++ {
+		v, err := backfill.TryConsume()
+		require.NoError(t, err)
+		received = append(received, v)
+	}
+	require.Equal(t, []int{1, 2, 3, 4}, received)
+}
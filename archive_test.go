@@ -0,0 +1,162 @@
+package eventdistributor_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+// memArchiver is an eventdistributor.Archiver that keeps events in memory
+type memArchiver struct {
+	mu       sync.Mutex
+	events   []eventdistributor.ArchivedEvent[MyEvent]
+	storeErr error
+}
+
+func (a *memArchiver) Store(seq int64, ts time.Time, v MyEvent) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.storeErr != nil {
+		return a.storeErr
+	}
+	a.events = append(a.events, eventdistributor.ArchivedEvent[MyEvent]{
+		Seq:       seq,
+		Timestamp: ts,
+		Value:     v,
+	})
+	return nil
+}
+
+func (a *memArchiver) Load(from, to int64) ([]eventdistributor.ArchivedEvent[MyEvent], error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var events []eventdistributor.ArchivedEvent[MyEvent]
+	for _, e := range a.events {
+		if e.Seq >= from && e.Seq < to {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (a *memArchiver) Trim(before int64) error {
+	return nil
+}
+
+func TestSubscribeFrom(t *testing.T) {
+	a := &memArchiver{}
+	var options eventdistributor.Options[MyEvent]
+	options.WithArchiver(a)
+	d := eventdistributor.New(options)
+
+	t.Log("events discarded without Readers are archived")
+	d.Submit(MyEvent{id: 0})
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	for i := 1; i < 4; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+	require.Equal(t, []int{1, 2}, drainN(t, &r, 2))
+	d.Submit(MyEvent{id: 4})
+
+	t.Log("archived events are stitched together with buffered and live events")
+	from, err := d.SubscribeFrom(0)
+	require.NoError(t, err)
+	defer from.Unsubscribe()
+
+	replayDone := from.ReplayDone()
+	require.Equal(t, []int{0, 1, 2}, drainN(t, &from, 3))
+	nowNotReady(t, replayDone)
+	require.Equal(t, []int{3, 4}, drainIDs(&from))
+	nowReady(t, replayDone)
+
+	d.Submit(MyEvent{id: 5})
+	require.Equal(t, []int{5}, drainIDs(&from))
+	require.Equal(t, []int{3, 4, 5}, drainIDs(&r))
+
+	t.Log("sequence numbers are preserved")
+	from2, err := d.SubscribeFrom(4)
+	require.NoError(t, err)
+	defer from2.Unsubscribe()
+	_, seq, err := from2.TryConsumeSeq()
+	require.NoError(t, err)
+	require.Equal(t, int64(4), seq)
+
+	_, err = d.SubscribeFrom(100)
+	require.ErrorIs(t, err, eventdistributor.ErrSeqInFuture)
+}
+
+func TestSubscribeFromWithoutArchiver(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 1})
+	require.Equal(t, []int{0}, drainN(t, &r, 1))
+
+	_, err := d.SubscribeFrom(0)
+	require.ErrorIs(t, err, eventdistributor.ErrSeqEvicted)
+
+	from, err := d.SubscribeFrom(1)
+	require.NoError(t, err)
+	defer from.Unsubscribe()
+	require.Equal(t, []int{1}, drainIDs(&from))
+}
+
+func TestArchiveError(t *testing.T) {
+	errStore := errors.New("store failed")
+	a := &memArchiver{storeErr: errStore}
+
+	var options eventdistributor.Options[MyEvent]
+	options.WithArchiver(a)
+	failed := make(chan int64, 1)
+	options.OnArchiveError(func(seq int64, err error) {
+		require.ErrorIs(t, err, errStore)
+		failed <- seq
+	})
+	d := eventdistributor.New(options)
+
+	d.Submit(MyEvent{id: 0})
+	select {
+	case seq := <-failed:
+		require.Equal(t, int64(0), seq)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for OnArchiveError")
+	}
+}
+
+func TestArchiveExpired(t *testing.T) {
+	clock := newFakeClock()
+	a := &memArchiver{}
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	options.WithEventTTL(time.Second)
+	options.WithArchiver(a)
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	for i := 0; i < 3; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+
+	t.Log("events that expire before they're consumed are still archived")
+	clock.Advance(time.Second)
+	require.EqualValues(t, 3, r.Skipped())
+	d.Submit(MyEvent{id: 3})
+
+	from, err := d.SubscribeFrom(0)
+	require.NoError(t, err)
+	defer from.Unsubscribe()
+	require.Equal(t, []int{0, 1, 2, 3}, drainIDs(&from))
+	require.Equal(t, []int{3}, drainIDs(&r))
+}
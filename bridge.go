@@ -0,0 +1,237 @@
+package eventdistributor
+
+import (
+	"fmt"
+	"sync"
+)
+
+// AsAny returns a type-erased view of d, as a Distributor[any].
+//
+// The view is a live bridge, not a copy: every event submitted to d is also submitted to the view,
+// and every event of type T submitted to the view is also submitted to d. Events submitted to the
+// view that are not of type T remain only in the view. Each event is forwarded at most once, so an
+// event that was forwarded from one side is never sent back to the side it came from.
+//
+// Forwarding is done by a pair of background goroutines, each holding a Reader on one side of the
+// bridge. Because of this, events submitted to either side are buffered until they have been
+// forwarded, even if the side they were submitted to has no other readers. Forwarded events are
+// submitted like any others, so each side's WithSubmitLimiter() and WithMaxReaderLag() apply to
+// them as well - while a pump waits to submit an event, it doesn't forward any others.
+//
+// The returned stop function stops forwarding, unsubscribes the bridge's readers, and waits for the
+// background goroutines to exit, including for any event they're waiting to submit. It is safe to
+// call stop more than once.
+func AsAny[T any](d *Distributor[T]) (view *Distributor[any], stop func()) {
+	view = New[any]()
+
+	b := &bridge{
+		echo:     nil,
+		stopOnce: sync.Once{},
+		done:     make(chan struct{}),
+		wg:       sync.WaitGroup{},
+	}
+	b.echo = &eventToken{forwardedBy: b}
+
+	// Subscribe both sides before starting either pump, so that no event submitted after AsAny
+	// returns is missed by either side.
+	typedReader := d.Subscribe()
+	viewReader := view.Subscribe()
+
+	b.wg.Add(2)
+	go runPump(b, typedReader, view, func(v T) (any, bool) {
+		return v, true
+	})
+	go runPump(b, viewReader, d, func(v any) (T, bool) {
+		t, ok := v.(T)
+		return t, ok
+	})
+
+	return view, b.stop
+}
+
+// bridge is the shared state for the pair of pumps started by AsAny
+type bridge struct {
+	// echo is the token attached to every event that the bridge forwards, on either side. The pump
+	// reading from that side recognizes it, instead of forwarding the event back. Because the token
+	// is attached as the event is submitted, it stays with the event even if it's held by the sort
+	// window or gates first, and disappears along with it if it's merged or deduplicated.
+	echo *eventToken
+
+	stopOnce sync.Once
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func (b *bridge) stop() {
+	b.stopOnce.Do(func() {
+		close(b.done)
+	})
+	b.wg.Wait()
+}
+
+// runPump forwards events from src into dst until the bridge is stopped, skipping events that
+// were themselves forwarded into src.
+func runPump[S, D any](b *bridge, src Reader[S], dst *Distributor[D], convert func(S) (D, bool)) {
+	defer b.wg.Done()
+	defer src.Unsubscribe()
+
+	for {
+		// The pump's Reader is its own, so it can reuse the same channel for every wait.
+		select {
+		case <-b.done:
+			return
+		case <-src.waitSignal():
+		}
+
+		src.d.mu.Lock()
+		// Events delivered before the buffer, like one retained by WithRetainLast, were submitted
+		// before the bridge started, so they have no token and are never echoes.
+		var token *eventToken
+		err := src.prepare()
+		if err == nil && !src.hasBackfill() && src.hasPending() {
+			token = src.nextToken()
+		}
+		value, _, err := src.tryConsume()
+		src.d.mu.Unlock()
+
+		if err == ErrNoEvent {
+			continue
+		} else if err != nil {
+			// The source was closed, so there's nothing left to forward.
+			return
+		}
+
+		if token == b.echo {
+			continue
+		} else if v, ok := convert(value); ok {
+			dst.submitBlocking(v, nil, false, nil, b.echo, nil)
+		}
+	}
+}
+
+// nextToken returns the token attached to the event that the Reader would receive next, which must
+// be buffered rather than come from the backfill. The Reader must not be in ack mode - as with the
+// Readers used by AsAny().
+//
+// r.d.mu must be held.
+func (r *Reader[T]) nextToken() *eventToken {
+	idx := int(r.position - r.d.basePosition)
+	if r.lifo != nil && r.d.orderBy != nil {
+		idx = r.firstByPriority()
+	} else if r.lifo != nil {
+		idx = r.newestUnseen()
+	}
+	return r.d.buf[idx].token
+}
+
+// TypedReaderFactory creates TypedReaders from a Distributor[any]. It is created by TypedView.
+type TypedReaderFactory[T any] struct {
+	d *Distributor[any]
+}
+
+// TypedView returns a TypedReaderFactory that subscribes to d, delivering only the values of type
+// T.
+func TypedView[T any](d *Distributor[any]) TypedReaderFactory[T] {
+	return TypedReaderFactory[T]{d: d}
+}
+
+// Subscribe creates a new TypedReader to receive future events of type T from the Distributor.
+//
+// Like (*Distributor[T]).Subscribe(), it is STRONGLY recommended to defer
+// (*TypedReader[T]).Unsubscribe() immediately after subscribing.
+//
+// Subscribe is thread-safe.
+func (f TypedReaderFactory[T]) Subscribe() TypedReader[T] {
+	return TypedReader[T]{
+		r:          f.d.Subscribe(),
+		mismatches: 0,
+	}
+}
+
+// TypedReader is a Reader on a Distributor[any] that only delivers values of type T. Values of
+// any other type are consumed and discarded automatically, and counted as mismatches.
+type TypedReader[T any] struct {
+	r          Reader[any]
+	mismatches int64
+}
+
+// WaitChan returns a channel that will be closed once there is an event of type T that this
+// TypedReader has not yet seen.
+//
+// WaitChan is thread-safe.
+func (r *TypedReader[T]) WaitChan() <-chan struct{} {
+	r.r.d.mu.Lock()
+	defer r.r.d.mu.Unlock()
+
+	if r.r.prepare() != nil {
+		return closedChannel
+	}
+	r.skipMismatches()
+	return r.r.waitChan()
+}
+
+// Consume returns the first event of type T that has not yet been seen by this TypedReader,
+// marking it and any preceding events of other types as "seen".
+//
+// Like (*Reader[T]).Consume(), Consume must only be called when there is an event available, and
+// panics otherwise.
+//
+// Consume is thread-safe.
+func (r *TypedReader[T]) Consume() T {
+	r.r.d.mu.Lock()
+	defer r.r.d.mu.Unlock()
+
+	if err := r.r.prepare(); err != nil {
+		panic(fmt.Errorf("eventdistributor: Consume called on unusable TypedReader: %w", err))
+	}
+	r.skipMismatches()
+	r.r.mustHaveEvent("Consume")
+	value, _ := r.r.deliver()
+	return value.(T)
+}
+
+// Mismatches returns the number of events that this TypedReader has discarded because they were
+// not of type T.
+//
+// Mismatches is thread-safe.
+func (r *TypedReader[T]) Mismatches() int64 {
+	r.r.d.mu.Lock()
+	defer r.r.d.mu.Unlock()
+
+	return r.mismatches
+}
+
+// Unsubscribe de-registers the TypedReader, returning whether this call was the one that
+// unsubscribed it. For more information, see (*Reader[T]).Unsubscribe().
+//
+// Unsubscribe is thread-safe.
+func (r *TypedReader[T]) Unsubscribe() bool {
+	return r.r.Unsubscribe()
+}
+
+// skipMismatches consumes every pending event at the front of the reader's unseen events that is
+// not of type T, including events delivered before the buffer, like one retained by WithRetainLast.
+//
+// r.r.d.mu must be held.
+func (r *TypedReader[T]) skipMismatches() {
+	for {
+		var next any
+		if r.r.hasBackfill() {
+			next = r.r.backfill[0].Value
+		} else if r.r.hasPending() {
+			next = r.r.d.buf[r.r.position-r.r.d.basePosition].value
+		} else {
+			return
+		}
+		if _, ok := next.(T); ok {
+			return
+		}
+
+		if r.r.hasBackfill() {
+			r.r.consumeBackfill()
+		} else {
+			r.r.consume()
+		}
+		r.mismatches += 1
+	}
+}
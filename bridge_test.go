@@ -0,0 +1,222 @@
+package eventdistributor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestAsAny(t *testing.T) {
+	typed := eventdistributor.New[MyEvent]()
+	view, stop := eventdistributor.AsAny(typed)
+	defer stop()
+
+	typedReader := typed.Subscribe()
+	defer typedReader.Unsubscribe()
+	viewReader := view.Subscribe()
+	defer viewReader.Unsubscribe()
+
+	t.Log("submissions on the typed side appear on the view")
+	typed.Submit(MyEvent{id: 1})
+	require.Equal(t, MyEvent{id: 1}, typedReader.Consume())
+	require.Equal(t, MyEvent{id: 1}, awaitAny(t, &viewReader))
+
+	t.Log("submissions on the view appear on the typed side")
+	view.Submit(MyEvent{id: 2})
+	require.Equal(t, MyEvent{id: 2}, viewReader.Consume())
+	require.Equal(t, MyEvent{id: 2}, await(t, &typedReader))
+
+	t.Log("values of other types remain only on the view")
+	view.Submit("not a MyEvent")
+	view.Submit(MyEvent{id: 3})
+	require.Equal(t, "not a MyEvent", viewReader.Consume())
+	require.Equal(t, MyEvent{id: 3}, viewReader.Consume())
+	require.Equal(t, MyEvent{id: 3}, await(t, &typedReader))
+
+	t.Log("forwarded events are not sent back")
+	// Each side's events are forwarded in order, so any echo would arrive before these.
+	typed.Submit(MyEvent{id: 4})
+	require.Equal(t, MyEvent{id: 4}, typedReader.Consume())
+	require.Equal(t, MyEvent{id: 4}, awaitAny(t, &viewReader))
+	view.Submit(MyEvent{id: 5})
+	require.Equal(t, MyEvent{id: 5}, viewReader.Consume())
+	require.Equal(t, MyEvent{id: 5}, await(t, &typedReader))
+}
+
+func TestAsAnyStop(t *testing.T) {
+	typed := eventdistributor.New[MyEvent]()
+	view, stop := eventdistributor.AsAny(typed)
+
+	viewReader := view.Subscribe()
+	defer viewReader.Unsubscribe()
+
+	stop()
+	stop() // calling stop twice is ok
+
+	t.Log("after stop, typed submissions are immediately discarded")
+	nowReady(t, typed.Submit(MyEvent{id: 1}))
+	nowNotReady(t, viewReader.WaitChan())
+}
+
+func TestAsAnyLimited(t *testing.T) {
+	limiter := newTokenLimiter()
+	var options eventdistributor.Options[MyEvent]
+	options.WithSubmitLimiter(limiter, eventdistributor.ThrottleDrop)
+	dropped := make(chan MyEvent, 1)
+	options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+		require.Equal(t, eventdistributor.DropReasonThrottled, reason)
+		dropped <- e
+	})
+	typed := eventdistributor.New(options)
+	view, stop := eventdistributor.AsAny(typed)
+	defer stop()
+
+	typedReader := typed.Subscribe()
+	defer typedReader.Unsubscribe()
+
+	t.Log("forwarded events are subject to the submit limiter, like any others")
+	view.Submit(MyEvent{id: 1})
+	select {
+	case e := <-dropped:
+		require.Equal(t, MyEvent{id: 1}, e)
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for the forwarded event to be throttled")
+	}
+	limiter.give(1)
+	view.Submit(MyEvent{id: 2})
+	require.Equal(t, MyEvent{id: 2}, await(t, &typedReader))
+}
+
+func TestTypedView(t *testing.T) {
+	d := eventdistributor.New[any]()
+	factory := eventdistributor.TypedView[MyEvent](d)
+
+	r := factory.Subscribe()
+	defer r.Unsubscribe()
+	nowNotReady(t, r.WaitChan())
+
+	t.Log("values of other types are skipped")
+	d.Submit("foo")
+	d.Submit(3)
+	nowNotReady(t, r.WaitChan())
+	require.Equal(t, int64(2), r.Mismatches())
+
+	t.Log("values of the right type are delivered")
+	d.Submit(MyEvent{id: 1})
+	d.Submit("bar")
+	d.Submit(MyEvent{id: 2})
+	nowReady(t, r.WaitChan())
+	require.Equal(t, MyEvent{id: 1}, r.Consume())
+	require.Equal(t, MyEvent{id: 2}, r.Consume())
+	require.Equal(t, int64(3), r.Mismatches())
+	nowNotReady(t, r.WaitChan())
+}
+
+func TestTypedViewConsume(t *testing.T) {
+	var options eventdistributor.Options[any]
+	options.WithRetainLast()
+	var consumed []any
+	options.OnConsume(func(v any, _ uint64) {
+		consumed = append(consumed, v)
+	})
+	d := eventdistributor.New(options)
+	factory := eventdistributor.TypedView[MyEvent](d)
+
+	t.Log("the retained event is delivered like any other")
+	d.Submit(MyEvent{id: 0})
+	r := factory.Subscribe()
+	defer r.Unsubscribe()
+	nowReady(t, r.WaitChan())
+	require.Equal(t, MyEvent{id: 0}, r.Consume())
+
+	t.Log("a retained event of another type is skipped")
+	d.Submit("foo")
+	r2 := factory.Subscribe()
+	defer r2.Unsubscribe()
+	nowNotReady(t, r2.WaitChan())
+	require.Equal(t, int64(1), r2.Mismatches())
+
+	t.Log("OnConsume callbacks are called for delivered events")
+	require.Equal(t, []any{MyEvent{id: 0}}, consumed)
+
+	t.Log("consuming with nothing available panics with a description of the Reader")
+	require.PanicsWithValue(t,
+		"eventdistributor: Consume called with no event available for Reader 2 (position 1, buffer 0 to 0)",
+		func() { r2.Consume() },
+	)
+}
+
+func TestAsAnyRetained(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithRetainLast()
+	typed := eventdistributor.New(options)
+	typed.Submit(MyEvent{id: 0})
+
+	t.Log("the retained event is forwarded, even if an echo is buffered behind it")
+	view, stop := eventdistributor.AsAny(typed)
+	defer stop()
+	view.Submit(MyEvent{id: 1})
+
+	require.Eventually(t, func() bool {
+		return view.Stats().Submitted == 2
+	}, time.Second, time.Millisecond)
+	require.Equal(t, int64(2), typed.Stats().Submitted)
+}
+
+func TestAsAnyStaged(t *testing.T) {
+	clock := newFakeClock()
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	options.WithSortWindow(100*time.Millisecond, func(a, b MyEvent) bool { return a.id < b.id })
+	typed := eventdistributor.New(options)
+	view, stop := eventdistributor.AsAny(typed)
+	defer stop()
+
+	typedReader := typed.Subscribe()
+	defer typedReader.Unsubscribe()
+	viewReader := view.Subscribe()
+	defer viewReader.Unsubscribe()
+
+	t.Log("events held by the sort window once they're forwarded are still not sent back")
+	view.Submit(MyEvent{id: 7})
+	require.Equal(t, MyEvent{id: 7}, viewReader.Consume())
+	require.Eventually(t, func() bool {
+		clock.Advance(100 * time.Millisecond)
+		select {
+		case <-typedReader.WaitChan():
+			return true
+		default:
+			return false
+		}
+	}, time.Second, time.Millisecond)
+	require.Equal(t, []int{7}, drainIDs(&typedReader))
+
+	t.Log("events submitted to the typed side are still forwarded, without the echo before them")
+	typed.Submit(MyEvent{id: 8})
+	clock.Advance(100 * time.Millisecond)
+	require.Equal(t, MyEvent{id: 8}, awaitAny(t, &viewReader))
+	require.Equal(t, []int{8}, drainIDs(&typedReader))
+}
+
+func await(t *testing.T, r *eventdistributor.Reader[MyEvent]) MyEvent {
+	select {
+	case <-r.WaitChan():
+		return r.Consume()
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for event")
+		panic("unreachable")
+	}
+}
+
+func awaitAny(t *testing.T, r *eventdistributor.Reader[any]) any {
+	select {
+	case <-r.WaitChan():
+		return r.Consume()
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for event")
+		panic("unreachable")
+	}
+}
@@ -0,0 +1,124 @@
+package eventdistributor
+
+// minStorage is the smallest backing array allocated for the buffer of a Distributor created by
+// New(), unless it's raised by WithBufferCapacity
+const minStorage = 8
+
+// WithBufferCapacity preallocates storage for n buffered events, so that the buffer can hold up
+// to n events without allocating. The buffer's storage still grows if more events are buffered,
+// but it is never shrunk below n, and is kept while the buffer is empty.
+//
+// WithBufferCapacity has no effect on a Distributor created by NewFixed(), whose storage is
+// determined by its capacity. If it is set more than once, the last call takes precedence.
+//
+// WithBufferCapacity panics if n is not positive.
+func (o *Options[T]) WithBufferCapacity(n int) {
+	if n <= 0 {
+		panic("eventdistributor: WithBufferCapacity requires n > 0")
+	}
+
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.bufCapacity = n
+		if d.fixed == nil && len(d.storage) < n {
+			d.moveStorage(n)
+		}
+	})
+}
+
+// Compact releases the memory that the buffer's storage isn't using, by moving the buffered
+// events to storage that fits them exactly - or to storage for as many events as set by
+// WithBufferCapacity, if that's larger. It's intended to be called after a burst of events has
+// drained, for example when the program is under memory pressure.
+//
+// The storage is also shrunk gradually as events are submitted, once it's mostly empty, so Compact
+// is only needed to release memory right away, or while no events are being submitted.
+//
+// Compact doesn't change which events are buffered, so no callbacks are called, and Readers are
+// unaffected. It has no effect on a Distributor created by NewFixed().
+//
+// Compact is thread-safe.
+func (d *Distributor[T]) Compact() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.fixed != nil {
+		return
+	}
+
+	size := len(d.buf)
+	if size < d.bufCapacity {
+		size = d.bufCapacity
+	}
+	if size == len(d.storage) {
+		return
+	} else if size == 0 {
+		d.buf = nil
+		d.storage = nil
+		return
+	}
+	d.moveStorage(size)
+}
+
+// emptyBuf returns an empty buffer to replace d.buf once every event has left it, reusing its
+// storage.
+//
+// d.mu must be held.
+func (d *Distributor[T]) emptyBuf() []eventInfo[T] {
+	return d.storage[:0]
+}
+
+// compactBuf makes room to append an event to the buffer, if it has reached the end of its
+// storage, so that appending to it won't allocate.
+//
+// The buffer is moved back to the start of its storage if at least half of the storage would then
+// be free. Otherwise, the storage is replaced by one twice as large. Either way, the buffer only
+// needs to be moved once per len(d.storage)/2 events, on average. Conversely, storage that would
+// be mostly empty is replaced by one half as large - but no smaller than WithBufferCapacity - so
+// that a burst of events doesn't keep a large array alive forever. The storage of a Distributor
+// created by NewFixed() is never replaced, because its buffer never holds more than half of it.
+//
+// d.mu must be held.
+func (d *Distributor[T]) compactBuf() {
+	if len(d.buf) < cap(d.buf) {
+		return
+	}
+
+	if d.fixed == nil {
+		size := len(d.storage)
+		if 2*len(d.buf) > size {
+			size *= 2
+		} else if 8*len(d.buf) < size {
+			size /= 2
+		}
+		if size < minStorage {
+			size = minStorage
+		}
+		if size < d.bufCapacity {
+			size = d.bufCapacity
+		}
+
+		if size != len(d.storage) {
+			d.moveStorage(size)
+			return
+		}
+	}
+
+	n := copy(d.storage, d.buf)
+	// Clear the rest of the storage, so that the moved events' values can be garbage collected once
+	// they leave the buffer.
+	for i := n; i < len(d.storage); i++ {
+		d.storage[i] = eventInfo[T]{}
+	}
+	d.buf = d.storage[:n]
+}
+
+// moveStorage moves the buffer to new storage for size events, which must be at least
+// len(d.buf). The old storage is dropped, along with the values of any events that have left the
+// buffer.
+//
+// d.mu must be held, unless the Distributor is still being created.
+func (d *Distributor[T]) moveStorage(size int) {
+	storage := make([]eventInfo[T], size)
+	d.buf = storage[:copy(storage, d.buf)]
+	d.storage = storage
+}
@@ -0,0 +1,214 @@
+package eventdistributor_test
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSteadyStateAllocationFree(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	// Keep a few events buffered, so that the buffer moves through its storage.
+	for i := -3; i < 0; i++ {
+		d.SubmitQuiet(MyEvent{id: i})
+	}
+
+	// Checks are made without require, which may allocate.
+	i := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		d.SubmitQuiet(MyEvent{id: i})
+		if id := r.Consume().id; id != i-3 {
+			t.Fatalf("consumed %d, expected %d", id, i-3)
+		}
+		i += 1
+	})
+	require.Zero(t, allocs)
+	require.Equal(t, []int{i - 3, i - 2, i - 1}, drainIDs(&r))
+}
+
+func TestBufferGrowsAndShrinks(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	fast := d.Subscribe()
+	defer fast.Unsubscribe()
+	slow := d.Subscribe()
+	defer slow.Unsubscribe()
+
+	// Alternate between bursts that grow the buffer and trickles that let it shrink again, checking
+	// that no events are lost or reordered as it moves between storage.
+	next, fastNext, slowNext := 0, 0, 0
+	for _, depth := range []int{1, 100, 3, 1000, 2, 50, 0, 10} {
+		for d.Stats().Bufsize < depth {
+			d.SubmitQuiet(MyEvent{id: next})
+			next += 1
+		}
+		for i := 0; i < 2*depth+10; i++ {
+			d.SubmitQuiet(MyEvent{id: next})
+			next += 1
+			require.Equal(t, fastNext, fast.Consume().id)
+			fastNext += 1
+			if i%2 == 0 {
+				require.Equal(t, slowNext, slow.Consume().id)
+				slowNext += 1
+			}
+		}
+		for _, id := range drainIDs(&fast) {
+			require.Equal(t, fastNext, id)
+			fastNext += 1
+		}
+		for _, id := range drainIDs(&slow) {
+			require.Equal(t, slowNext, id)
+			slowNext += 1
+		}
+		require.Equal(t, next, slowNext)
+		require.Zero(t, d.Stats().Bufsize)
+	}
+}
+
+// BenchmarkSteadyState submits and consumes events one at a time, with depth events kept buffered
+// throughout
+func BenchmarkSteadyState(b *testing.B) {
+	for _, depth := range []int{0, 1, 16, 1024} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			d := eventdistributor.New[MyEvent]()
+			r := d.Subscribe()
+			defer r.Unsubscribe()
+			for i := 0; i < depth; i++ {
+				d.SubmitQuiet(MyEvent{id: i})
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				d.SubmitQuiet(MyEvent{id: i})
+				r.Consume()
+			}
+		})
+	}
+}
+
+func TestWithBufferCapacity(t *testing.T) {
+	const capacity = 1000
+	d := eventdistributor.New(eventdistributor.WithBufferCapacity[MyEvent](capacity))
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	// Checks are made without require, which may allocate.
+	allocs := testing.AllocsPerRun(10, func() {
+		for i := 0; i < capacity; i++ {
+			d.SubmitQuiet(MyEvent{id: i})
+		}
+		for i := 0; i < capacity; i++ {
+			if id := r.Consume().id; id != i {
+				t.Fatalf("consumed %d, expected %d", id, i)
+			}
+		}
+
+		// Move through the storage with only one event buffered, which would shrink it without
+		// WithBufferCapacity.
+		d.SubmitQuiet(MyEvent{id: 0})
+		for i := 1; i < 4*capacity; i++ {
+			d.SubmitQuiet(MyEvent{id: i})
+			if id := r.Consume().id; id != i-1 {
+				t.Fatalf("consumed %d, expected %d", id, i-1)
+			}
+		}
+		r.Consume()
+	})
+	require.Zero(t, allocs)
+
+	t.Log("the storage still grows past the capacity")
+	for i := 0; i < 3*capacity; i++ {
+		d.SubmitQuiet(MyEvent{id: i})
+	}
+	require.Equal(t, 3*capacity, d.Stats().Bufsize)
+	require.Len(t, drainIDs(&r), 3*capacity)
+}
+
+// BenchmarkBurst submits a burst of events to a new Distributor, and then consumes them
+func BenchmarkBurst(b *testing.B) {
+	const burst = 4096
+	cases := []struct {
+		name    string
+		options []eventdistributor.Options[MyEvent]
+	}{
+		{"default", nil},
+		{"WithBufferCapacity", []eventdistributor.Options[MyEvent]{
+			eventdistributor.WithBufferCapacity[MyEvent](burst),
+		}},
+	}
+
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				d := eventdistributor.New(c.options...)
+				r := d.Subscribe()
+				for j := 0; j < burst; j++ {
+					d.SubmitQuiet(MyEvent{id: j})
+				}
+				for j := 0; j < burst; j++ {
+					r.Consume()
+				}
+				r.Unsubscribe()
+			}
+		})
+	}
+}
+
+func TestCompact(t *testing.T) {
+	var sizes []int
+	var options eventdistributor.Options[MyEvent]
+	options.OnBufsizeChange(func(size int) { sizes = append(sizes, size) })
+	d := eventdistributor.New(options)
+	r1 := d.Subscribe()
+	defer r1.Unsubscribe()
+	r2 := d.Subscribe()
+	defer r2.Unsubscribe()
+
+	// heapAfter returns the memory in use once f has been called
+	heapAfter := func(f func()) uint64 {
+		var stats runtime.MemStats
+		f()
+		runtime.GC()
+		runtime.ReadMemStats(&stats)
+		return stats.HeapAlloc
+	}
+
+	const burst = 100_000
+	for i := 0; i < burst; i++ {
+		d.SubmitQuiet(MyEvent{id: i})
+	}
+	require.Len(t, drainIDs(&r1), burst)
+	for i := 0; i < burst-2; i++ {
+		r2.Consume()
+	}
+	require.Equal(t, 2, d.Stats().Bufsize)
+
+	t.Log("the storage left over from the burst is released")
+	sizes = nil
+	before := heapAfter(func() {})
+	after := heapAfter(d.Compact)
+	require.Less(t, after, before-burst*8)
+	require.Empty(t, sizes)
+
+	t.Log("Readers are unaffected")
+	notReady(t, r1)
+	d.SubmitQuiet(MyEvent{id: burst})
+	require.Equal(t, []int{burst}, drainIDs(&r1))
+	require.Equal(t, []int{burst - 2, burst - 1, burst}, drainIDs(&r2))
+	require.Zero(t, d.Stats().Bufsize)
+
+	t.Log("an empty buffer's storage is released entirely")
+	d.Compact()
+	d.Compact()
+	d.SubmitQuiet(MyEvent{id: burst + 1})
+	require.Equal(t, []int{burst + 1}, drainIDs(&r1))
+	require.Equal(t, []int{burst + 1}, drainIDs(&r2))
+}
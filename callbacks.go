@@ -0,0 +1,102 @@
+package eventdistributor
+
+import (
+	"sync/atomic"
+)
+
+// OnSubmit adds a callback that is called whenever an event is submitted, like
+// (*Options[T]).OnSubmit(), returning a function that removes it.
+//
+// Callbacks can be added and removed at any time, without affecting the order in which other
+// callbacks are called. Once remove returns, the callback is not called again, unless it was
+// already running. Calling remove more than once does nothing.
+//
+// OnSubmit is thread-safe.
+func (d *Distributor[T]) OnSubmit(callback func(item T)) (remove func()) {
+	watched := watchCallback(d.watchdog, "OnSubmit", captureRegistrationSite(), callback)
+	return addCallback(&d.mu, &d.onSubmit, watched)
+}
+
+// OnFullyConsumed adds a callback that is called whenever an event is dropped from the buffer, like
+// (*Options[T]).OnFullyConsumed(), returning a function that removes it. See OnSubmit().
+//
+// OnFullyConsumed is thread-safe.
+func (d *Distributor[T]) OnFullyConsumed(callback func(item T)) (remove func()) {
+	watched := watchCallback(d.watchdog, "OnFullyConsumed", captureRegistrationSite(), callback)
+	return addCallback(&d.mu, &d.onFullyConsumed, watched)
+}
+
+// OnBufsizeChange adds a callback that is called whenever the number of events in the buffer
+// changes, like (*Options[T]).OnBufsizeChange(), returning a function that removes it. See
+// OnSubmit().
+//
+// OnBufsizeChange is thread-safe.
+func (d *Distributor[T]) OnBufsizeChange(callback func(size int)) (remove func()) {
+	watched := watchCallback(d.watchdog, "OnBufsizeChange", captureRegistrationSite(), callback)
+	return addCallback(&d.mu, &d.onBufsizeChange, watched)
+}
+
+// callbackList is a list of callbacks that can be added to and removed from at any time. See
+// addCallback.
+//
+// fs is copied whenever it's changed, instead of being modified in place, because callbacks that
+// were already queued keep using the slice as it was when they were queued.
+type callbackList[A any] struct {
+	fs []func(A)
+	// tokens has the token identifying each callback in fs, at the same index. Callbacks set by
+	// Options can't be removed, so their tokens are nil.
+	tokens []*callbackToken
+}
+
+// callbackToken identifies a callback added by addCallback, so that it can be removed
+type callbackToken struct {
+	// removed is set once the callback has been removed, so that calls that were already queued
+	// are skipped
+	removed atomic.Bool
+}
+
+// add adds f to the end of the list, identified by token
+func (l *callbackList[A]) add(f func(A), token *callbackToken) {
+	l.fs = append(l.fs[:len(l.fs):len(l.fs)], f)
+	l.tokens = append(l.tokens[:len(l.tokens):len(l.tokens)], token)
+}
+
+// remove removes the callback identified by token from the list, if it's there
+func (l *callbackList[A]) remove(token *callbackToken) {
+	fs := make([]func(A), 0, len(l.fs))
+	tokens := make([]*callbackToken, 0, len(l.tokens))
+	for i, t := range l.tokens {
+		if t != token {
+			fs = append(fs, l.fs[i])
+			tokens = append(tokens, t)
+		}
+	}
+	l.fs = fs
+	l.tokens = tokens
+}
+
+// addCallback adds f to the callbacks in l, returning a function that removes it.
+//
+// m must not be locked.
+func addCallback[A any](m *callbackMutex, l *callbackList[A], f func(A)) (remove func()) {
+	token := new(callbackToken)
+	wrapped := func(v A) {
+		if !token.removed.Load() {
+			f(v)
+		}
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	l.add(wrapped, token)
+	return func() {
+		m.Lock()
+		defer m.Unlock()
+
+		if token.removed.Swap(true) {
+			return
+		}
+		l.remove(token)
+	}
+}
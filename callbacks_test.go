@@ -0,0 +1,98 @@
+package eventdistributor_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestRuntimeCallbacks(t *testing.T) {
+	var calls []string
+	var options eventdistributor.Options[MyEvent]
+	options.OnSubmit(func(MyEvent) { calls = append(calls, "options") })
+	d := eventdistributor.New(options)
+
+	removeA := d.OnSubmit(func(MyEvent) { calls = append(calls, "a") })
+	removeB := d.OnSubmit(func(MyEvent) { calls = append(calls, "b") })
+	var sizes []int
+	removeSize := d.OnBufsizeChange(func(size int) { sizes = append(sizes, size) })
+	var fullyConsumed []int
+	removeConsumed := d.OnFullyConsumed(func(e MyEvent) {
+		fullyConsumed = append(fullyConsumed, e.id)
+	})
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	t.Log("callbacks are called in the order they were added")
+	d.Submit(MyEvent{id: 1})
+	require.Equal(t, []string{"options", "a", "b"}, calls)
+	require.Equal(t, []int{1}, drainIDs(&r))
+	require.Equal(t, []int{1, 0}, sizes)
+	require.Equal(t, []int{1}, fullyConsumed)
+
+	t.Log("removing a callback doesn't affect the others")
+	calls = nil
+	removeA()
+	removeA()
+	d.Submit(MyEvent{id: 2})
+	require.Equal(t, []string{"options", "b"}, calls)
+
+	t.Log("a new callback is added after the remaining ones")
+	calls = nil
+	removeC := d.OnSubmit(func(MyEvent) { calls = append(calls, "c") })
+	d.Submit(MyEvent{id: 3})
+	require.Equal(t, []string{"options", "b", "c"}, calls)
+
+	removeB()
+	removeC()
+	removeSize()
+	removeConsumed()
+	calls = nil
+	require.Equal(t, []int{2, 3}, drainIDs(&r))
+	d.Submit(MyEvent{id: 4})
+	require.Equal(t, []string{"options"}, calls)
+	require.Equal(t, []int{1, 0, 1, 2}, sizes)
+	require.Equal(t, []int{1}, fullyConsumed)
+}
+
+func TestRuntimeCallbacksConcurrent(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			d.Submit(MyEvent{id: i})
+			r.TryConsume()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			remove := d.OnSubmit(func(MyEvent) {})
+			removeSize := d.OnBufsizeChange(func(int) {})
+			remove()
+			removeSize()
+		}
+	}()
+	wg.Wait()
+
+	t.Log("callbacks on the Distributor returned by Events() can call this one")
+	done := false
+	d.Events().OnSubmit(func(e eventdistributor.MetaEvent) {
+		if !done {
+			done = true
+			d.Stats()
+		}
+	})
+	r2 := d.Subscribe()
+	r2.Unsubscribe()
+	require.True(t, done)
+}
@@ -0,0 +1,95 @@
+package eventdistributor
+
+import (
+	"runtime"
+	"time"
+)
+
+// WithCallerCapture records the call stack that submitted each buffered event, up to depth frames,
+// starting with the function that called Submit. The stack is available from
+// (*Distributor[T]).ForEachBuffered(), and is released along with the event.
+//
+// Capturing the stack has a cost for every submitted event, so it is intended for debugging.
+//
+// WithCallerCapture panics if depth is not positive.
+func (o *Options[T]) WithCallerCapture(depth int) {
+	if depth <= 0 {
+		panic("eventdistributor: WithCallerCapture requires depth > 0")
+	}
+
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.callerDepth = depth
+	})
+}
+
+// BufferedEvent is an event in a Distributor's buffer, as provided by ForEachBuffered()
+type BufferedEvent[T any] struct {
+	// Seq is the sequence number of the event
+	Seq int64
+	// Value is the submitted value
+	Value T
+	// Timestamp is the time the event was submitted, if the Distributor records timestamps. See
+	// (*Options[T]).WithTimestamps().
+	Timestamp time.Time
+	// Caller is the call stack that submitted the event, if the Distributor captures callers. See
+	// (*Options[T]).WithCallerCapture().
+	Caller []runtime.Frame
+}
+
+// ForEachBuffered calls f with each event in the buffer, in order, stopping early if f returns
+// false.
+//
+// f is called while the Distributor's lock is held, so it must not call any methods on the
+// Distributor or its Readers.
+//
+// ForEachBuffered is thread-safe.
+func (d *Distributor[T]) ForEachBuffered(f func(BufferedEvent[T]) bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, e := range d.buf {
+		event := BufferedEvent[T]{
+			Seq:       e.seq,
+			Value:     e.value,
+			Timestamp: e.timestamp,
+			Caller:    callerFrames(e.caller),
+		}
+
+		if !f(event) {
+			return
+		}
+	}
+}
+
+// captureCaller returns the call stack of the caller of the exported function that called it, if
+// the Distributor captures callers. Otherwise, it returns nil.
+//
+// d.mu must NOT be held, because the capture is done outside of it.
+func (d *Distributor[T]) captureCaller() []uintptr {
+	if d.callerDepth == 0 {
+		return nil
+	}
+
+	pcs := make([]uintptr, d.callerDepth)
+	// skip runtime.Callers, captureCaller, and the exported function
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
+
+// callerFrames returns the frames of the call stack recorded by runtime.Callers, or nil if there
+// are none
+func callerFrames(pcs []uintptr) []runtime.Frame {
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	var frames []runtime.Frame
+	iter := runtime.CallersFrames(pcs)
+	for {
+		frame, more := iter.Next()
+		frames = append(frames, frame)
+		if !more {
+			return frames
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package eventdistributor_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestWithCallerCapture(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithCallerCapture(2)
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	d.Submit(MyEvent{id: 1})
+	_, file, line, _ := runtime.Caller(0)
+	_, err := d.SubmitChecked(MyEvent{id: 2})
+	require.NoError(t, err)
+
+	var events []eventdistributor.BufferedEvent[MyEvent]
+	d.ForEachBuffered(func(e eventdistributor.BufferedEvent[MyEvent]) bool {
+		events = append(events, e)
+		return true
+	})
+	require.Len(t, events, 2)
+
+	t.Log("the first frame is the call to Submit, and the depth is bounded")
+	require.Len(t, events[0].Caller, 2)
+	const testFunc = "github.com/sharnoff/eventdistributor_test.TestWithCallerCapture"
+	require.Equal(t, testFunc, events[0].Caller[0].Function)
+	require.Equal(t, file, events[0].Caller[0].File)
+	require.Equal(t, line-1, events[0].Caller[0].Line)
+	require.Equal(t, line+1, events[1].Caller[0].Line)
+
+	t.Log("ForEachBuffered stops early")
+	count := 0
+	d.ForEachBuffered(func(eventdistributor.BufferedEvent[MyEvent]) bool {
+		count += 1
+		return false
+	})
+	require.Equal(t, 1, count)
+}
+
+func TestForEachBufferedWithoutCapture(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(MyEvent{id: 1})
+
+	var events []eventdistributor.BufferedEvent[MyEvent]
+	d.ForEachBuffered(func(e eventdistributor.BufferedEvent[MyEvent]) bool {
+		events = append(events, e)
+		return true
+	})
+	require.Equal(t, []eventdistributor.BufferedEvent[MyEvent]{{Seq: 0, Value: MyEvent{id: 1}}}, events)
+}
@@ -0,0 +1,110 @@
+package eventdistributor
+
+import (
+	"sync/atomic"
+)
+
+// OnCancelled adds a callback that is called with each event that is withdrawn by
+// (Submission[T]).Cancel(). Cancelled events are also passed to any OnDrop callbacks, with
+// DropReasonCancelled, but never to OnFullyConsumed callbacks.
+func (o *Options[T]) OnCancelled(callback func(item T)) {
+	site := captureRegistrationSite()
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.onCancelled = append(d.onCancelled, watchCallback(d.watchdog, "OnCancelled", site, callback))
+	})
+}
+
+// Submission is an event submitted by (*Distributor[T]).SubmitCancellable(), which can be withdrawn
+// until a Reader receives it.
+//
+// The zero value is a Submission that was never buffered: Cancel() returns false, and Done()
+// returns a closed channel.
+type Submission[T any] struct {
+	d *Distributor[T]
+	// token identifies the event in the buffer. It's unique to this Submission, so the event is
+	// still found after compaction or removal of other events, and isn't mistaken for an event
+	// that replaced it - for example, with WithConflateBy.
+	token *eventToken
+	// done is set once the event has left the buffer, so that Cancel can return early
+	done        *atomic.Bool
+	allConsumed <-chan struct{}
+}
+
+// eventToken identifies a buffered event by its address, for as long as it's in the buffer. Each
+// one is allocated separately, and is never attached to more than one event - except by AsAny(),
+// which attaches the same token to every event it forwards.
+type eventToken struct {
+	// forwardedBy is the bridge that forwards the events with this token, if any. See AsAny().
+	//
+	// It also gives eventToken a non-zero size, which is what keeps separately allocated tokens at
+	// distinct addresses: pointers to distinct zero-size values may be equal.
+	forwardedBy *bridge
+}
+
+// SubmitCancellable is like Submit, but returns a Submission that can withdraw the event with
+// Cancel(), as long as no Reader has received it yet.
+//
+// SubmitCancellable is thread-safe.
+func (d *Distributor[T]) SubmitCancellable(value T) Submission[T] {
+	done := new(atomic.Bool)
+	token := new(eventToken)
+	onDone := func(struct{}) { done.Store(true) }
+	allConsumed, _ := d.submitBlocking(value, d.captureCaller(), true, onDone, token, nil)
+	return Submission[T]{d: d, token: token, done: done, allConsumed: allConsumed}
+}
+
+// Done returns the channel that would have been returned by Submit for the event, which is closed
+// once no remaining Readers are able to consume it - including because it was cancelled.
+func (s Submission[T]) Done() <-chan struct{} {
+	if s.d == nil {
+		return closedChannel
+	}
+	return s.allConsumed
+}
+
+// Cancel withdraws the event from the buffer if no Reader has received it yet, returning whether it
+// did so. Once Cancel returns true, no Reader will receive the event.
+//
+// As with WithConflateBy, an event counts as received once any Reader has moved past it - even a
+// filtered Reader that skipped it - and while it's pinned, in flight for a Reader in ack mode, or
+// while the Distributor has Readers created by SubscribeLIFO(). Cancel also returns false if the
+// event was already dropped from the buffer, or is still held by the sort window or gates.
+//
+// A cancelled event is passed to any OnCancelled callbacks, and to OnDrop callbacks with
+// DropReasonCancelled, instead of to OnFullyConsumed callbacks. Like FilterInPlace(), the event is
+// removed in place, so each Reader's remaining unseen events are exactly the ones it had before,
+// minus the cancelled one. This takes time proportional to the size of the buffer.
+//
+// Cancel is thread-safe.
+func (s Submission[T]) Cancel() bool {
+	if s.d == nil || s.done.Load() {
+		return false
+	}
+
+	d := s.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Events before any Reader's position may have been received.
+	from := 0
+	for _, r := range d.readers {
+		if i := int(r.position - d.basePosition); i > from {
+			from = i
+		}
+	}
+
+	for i := from; i < len(d.buf); i++ {
+		e := &d.buf[i]
+		if e.token != s.token {
+			continue
+		} else if d.heldSeparately(e.seq) {
+			return false
+		}
+
+		seq := e.seq
+		runCallbacks(&d.mu, d.onCancelled, e.value)
+		d.removeEvents(func(e *eventInfo[T]) bool { return e.seq == seq }, DropReasonCancelled)
+		return true
+	}
+	return false
+}
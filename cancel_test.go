@@ -0,0 +1,91 @@
+package eventdistributor_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSubmitCancellable(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var calls []string
+	options.OnCancelled(func(e MyEvent) {
+		calls = append(calls, fmt.Sprintf("cancelled %d", e.id))
+	})
+	options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+		calls = append(calls, fmt.Sprintf("dropped %d: %v", e.id, reason))
+	})
+	options.OnFullyConsumed(func(e MyEvent) {
+		calls = append(calls, fmt.Sprintf("fully consumed %d", e.id))
+	})
+	d := eventdistributor.New(options)
+
+	t.Log("an event discarded immediately can't be cancelled")
+	s := d.SubmitCancellable(MyEvent{id: 0})
+	nowReady(t, s.Done())
+	require.False(t, s.Cancel())
+	require.Equal(t, []string{"fully consumed 0"}, calls)
+
+	r1 := d.Subscribe()
+	defer r1.Unsubscribe()
+	r2 := d.Subscribe()
+	defer r2.Unsubscribe()
+
+	t.Log("an event in the middle of the buffer is skipped by every Reader once cancelled")
+	calls = nil
+	d.Submit(MyEvent{id: 1})
+	s = d.SubmitCancellable(MyEvent{id: 2})
+	d.Submit(MyEvent{id: 3})
+	nowNotReady(t, s.Done())
+	require.True(t, s.Cancel())
+	require.Equal(t, []string{"cancelled 2", "dropped 2: Cancelled"}, calls)
+	nowReady(t, s.Done())
+	require.False(t, s.Cancel())
+	require.Equal(t, []int{1, 3}, drainIDs(&r1))
+	require.Equal(t, []int{1, 3}, drainIDs(&r2))
+
+	t.Log("an event can't be cancelled once any Reader has received it")
+	calls = nil
+	s = d.SubmitCancellable(MyEvent{id: 4})
+	require.Equal(t, 4, r1.Consume().id)
+	require.False(t, s.Cancel())
+	require.Equal(t, 4, r2.Consume().id)
+	require.Equal(t, []string{"fully consumed 4"}, calls)
+
+	t.Log("cancelling the event at a Reader's position leaves the Reader waiting")
+	s = d.SubmitCancellable(MyEvent{id: 5})
+	wait := r1.WaitChan()
+	nowReady(t, wait)
+	require.True(t, s.Cancel())
+	nowNotReady(t, r1.WaitChan())
+	_, err := r1.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrNoEvent)
+
+	t.Log("the zero Submission can't be cancelled")
+	var zero eventdistributor.Submission[MyEvent]
+	require.False(t, zero.Cancel())
+	nowReady(t, zero.Done())
+}
+
+func TestSubmitCancellableConflated(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	eventdistributor.WithConflateBy(&options, func(e MyEvent) int { return e.id % 2 })
+	d := eventdistributor.New(options)
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	t.Log("a Submission whose event was replaced doesn't cancel the event that replaced it")
+	old := d.SubmitCancellable(MyEvent{id: 0})
+	d.SubmitCancellable(MyEvent{id: 2})
+	nowReady(t, old.Done())
+	require.False(t, old.Cancel())
+	require.Equal(t, []int{2}, drainIDs(&r))
+
+	d.SubmitCancellable(MyEvent{id: 4})
+	replacement := d.SubmitCancellable(MyEvent{id: 6})
+	require.True(t, replacement.Cancel())
+	require.Empty(t, drainIDs(&r))
+}
@@ -0,0 +1,82 @@
+package eventdistributor
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnsubscribed is returned by operations on a Reader that was unsubscribed
+var ErrUnsubscribed = errors.New("reader unsubscribed")
+
+// CaughtUp returns whether the Reader has consumed every event currently in the Distributor - i.e.,
+// there is no event that it has not yet seen.
+//
+// CaughtUp is thread-safe.
+func (r *Reader[T]) CaughtUp() bool {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if r.prepare() != nil {
+		return false
+	}
+	return !r.hasPending()
+}
+
+// WaitCaughtUp blocks until the Reader is caught up (see CaughtUp()), or ctx is canceled, in which
+// case it returns ctx.Err().
+//
+// WaitCaughtUp waits for a moving target: if new events are submitted while waiting, the Reader
+// must consume those as well before it's considered caught up. This is the opposite of WaitChan(),
+// which waits for there to be an event available. Typically, WaitCaughtUp is called from a
+// different goroutine than the one consuming events.
+//
+// WaitCaughtUp returns ErrUnsubscribed if the Reader is unsubscribed while waiting, and ErrClosed
+// or ErrExpired if the Reader can no longer be used for those reasons.
+//
+// WaitCaughtUp is thread-safe.
+func (r *Reader[T]) WaitCaughtUp(ctx context.Context) error {
+	d := r.d
+
+	for {
+		d.mu.Lock()
+		if err := r.prepare(); err != nil {
+			d.mu.Unlock()
+			return err
+		} else if !r.hasPending() {
+			d.mu.Unlock()
+			return nil
+		}
+
+		if r.caughtUp == nil {
+			r.caughtUp = make(chan struct{})
+		}
+		caughtUp := r.caughtUp
+		d.mu.Unlock()
+
+		select {
+		case <-caughtUp:
+			// Check again, in case we were woken for some other reason.
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// checkCaughtUp notifies anything waiting in WaitCaughtUp() if the Reader is now caught up.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) checkCaughtUp() {
+	if r.caughtUp != nil && !r.hasPending() {
+		r.wakeCaughtUp()
+	}
+}
+
+// wakeCaughtUp wakes anything waiting in WaitCaughtUp(), so that it can check the Reader's state.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) wakeCaughtUp() {
+	if r.caughtUp != nil {
+		close(r.caughtUp)
+		r.caughtUp = nil
+	}
+}
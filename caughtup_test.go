@@ -0,0 +1,78 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestCaughtUp(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	require.True(t, r.CaughtUp())
+	require.NoError(t, r.WaitCaughtUp(context.Background()))
+
+	d.Submit(MyEvent{id: 1})
+	require.False(t, r.CaughtUp())
+	r.Consume()
+	require.True(t, r.CaughtUp())
+}
+
+func TestWaitCaughtUpMovingTarget(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	d.Submit(MyEvent{id: 1})
+
+	done := make(chan error)
+	go func() {
+		done <- r.WaitCaughtUp(context.Background())
+	}()
+
+	t.Log("new events arriving while waiting must also be consumed")
+	d.Submit(MyEvent{id: 2})
+	r.Consume()
+	select {
+	case <-done:
+		require.FailNow(t, "WaitCaughtUp returned before the reader was caught up")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	r.Consume()
+	require.NoError(t, <-done)
+}
+
+func TestWaitCaughtUpCanceled(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	d.Submit(MyEvent{id: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, r.WaitCaughtUp(ctx), context.DeadlineExceeded)
+}
+
+func TestWaitCaughtUpUnsubscribed(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+
+	d.Submit(MyEvent{id: 1})
+
+	done := make(chan error)
+	go func() {
+		done <- r.WaitCaughtUp(context.Background())
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	r.Unsubscribe()
+	require.ErrorIs(t, <-done, eventdistributor.ErrUnsubscribed)
+}
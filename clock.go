@@ -0,0 +1,66 @@
+package eventdistributor
+
+import (
+	"time"
+)
+
+// Clock is the source of time used by a Distributor, for any features that depend on it.
+//
+// The default Clock uses the time package directly. A custom Clock can be provided with
+// (*Options[T]).WithClock(), typically for testing.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// AfterFunc waits for the duration to elapse and then calls f in its own goroutine, returning
+	// a Timer that can be used to cancel the call.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending call created by (Clock).AfterFunc()
+type Timer interface {
+	// Stop prevents the Timer from firing, returning false if it already fired or was stopped.
+	Stop() bool
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// WithClock sets the Clock used by the Distributor. By default, the time package is used directly.
+func (o *Options[T]) WithClock(clock Clock) {
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.clock = clock
+	})
+}
+
+// WithTimestamps enables recording the time at which each event was submitted, according to the
+// Distributor's Clock.
+//
+// Timestamps are required by some features, like (*Distributor[T]).SubscribeLossyByAge().
+func (o *Options[T]) WithTimestamps() {
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.timestamps = true
+	})
+}
+
+// Clock returns the Clock used by the Distributor, so that durations can be measured against
+// the timestamps it records. See (*Options[T]).WithClock().
+//
+// Clock is thread-safe.
+func (d *Distributor[T]) Clock() Clock {
+	return d.getClock()
+}
+
+// getClock returns the Clock used by the Distributor
+func (d *Distributor[T]) getClock() Clock {
+	if d.clock == nil {
+		return realClock{}
+	}
+	return d.clock
+}
@@ -0,0 +1,174 @@
+package eventdistributor
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClosed is returned by operations on a Reader after its Distributor has been closed
+var ErrClosed = errors.New("distributor closed")
+
+// Seal stops the Distributor from accepting any new events. Events that are already buffered
+// remain available to Readers, and any events held by the sort window are added to the buffer
+// immediately. See (*Options[T]).WithSortWindow().
+//
+// After Seal, every call to Submit immediately drops the event, passing it to any OnDrop callbacks
+// with DropReasonSealed, and returns a closed channel.
+//
+// Seal is typically followed by WaitForDrain() and Close(). It is safe to call Seal more than
+// once.
+//
+// Seal is thread-safe.
+func (d *Distributor[T]) Seal() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.sealed {
+		d.emitMeta(MetaEvent{Kind: MetaSeal})
+	}
+	d.sealed = true
+	// Producers waiting for lagging Readers should now drop their events instead.
+	d.wakeProducers()
+	// Events held by the sort window were submitted before sealing, so they're still delivered.
+	d.flushSorted()
+}
+
+// WaitForDrain blocks until the buffer is empty - i.e., every buffered event has been fully
+// consumed - and no events are held by gates, or until ctx is canceled, in which case it returns
+// ctx.Err(). See SubscribeGate().
+//
+// If new events are submitted while waiting, WaitForDrain continues to wait until those are
+// consumed as well. To ensure that it eventually returns, call Seal() first. If the Distributor is
+// already drained, WaitForDrain returns immediately, without allocating.
+//
+// WaitForDrain is thread-safe.
+func (d *Distributor[T]) WaitForDrain(ctx context.Context) error {
+	d.mu.Lock()
+	if d.isDrained() {
+		d.mu.Unlock()
+		return nil
+	}
+
+	if d.drained == nil {
+		d.drained = make(chan struct{})
+	}
+	drained := d.drained
+	d.mu.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isDrained returns whether the buffer is empty, with no events held by gates waiting to be added
+// to it.
+//
+// d.mu must be held.
+func (d *Distributor[T]) isDrained() bool {
+	return len(d.buf) == 0 && (d.gate == nil || len(d.gate.events) == 0)
+}
+
+// checkDrained notifies anything waiting in WaitForDrain if the Distributor is now drained, and
+// its DistributorSet if it's also idle. See checkSetIdle.
+//
+// d.mu must be held.
+func (d *Distributor[T]) checkDrained() {
+	if d.drained != nil && d.isDrained() {
+		close(d.drained)
+		d.drained = nil
+	}
+	d.checkSetIdle()
+}
+
+// Close seals the Distributor, drops all buffered events, and releases all Readers.
+//
+// Buffered events are passed to any OnDrop callbacks with DropReasonClosed. Every Reader's
+// WaitChan() returns a closed channel and its TryConsume() returns ErrClosed. Calling Unsubscribe()
+// on a Reader after Close is not necessary, but is allowed. Any values tracked by WithKeyFunc are
+// cleared, the channels of any observers are closed, and all pins are released.
+//
+// It is safe to call Close more than once.
+//
+// Close is thread-safe.
+func (d *Distributor[T]) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return
+	}
+
+	d.sealed = true
+	d.closed = true
+
+	for _, r := range d.readers {
+		if r.expiry != nil {
+			r.expiry.timer.Stop()
+		}
+		r.reader().finishReplay()
+		r.reader().wakeCaughtUp()
+		r.reader().endWait()
+		r.reader().endDeferral()
+		d.forgetLag(r)
+	}
+	hadReaders := d.numReaders() != 0
+	d.readers = nil
+	d.seed = nil
+	d.numFiltered = 0
+	d.nextRefcount = 0
+
+	// Wake every waiting Reader, so that they can observe that the Distributor is closed.
+	d.wakeWaiters()
+	d.wakeProducers()
+	if hadReaders {
+		runCallbacks(&d.mu, d.onLastUnsub, struct{}{})
+	}
+
+	for _, p := range d.pins {
+		p.voided = true
+	}
+	d.pins = nil
+	d.numPins = 0
+
+	if len(d.buf) != 0 {
+		buf := d.buf
+		d.basePosition += int64(len(buf))
+
+		for _, e := range buf {
+			d.dropped(e.value, e.seq, DropReasonClosed)
+			d.archiveEvent(e.seq, e.timestamp, e.value)
+			d.finish(e.allConsumed, e.onDone)
+		}
+		runCallbacks(&d.mu, d.onBufsizeChange.fs, 0)
+	}
+	// Nothing can be added to the buffer anymore, so its storage can be released.
+	d.buf = nil
+	d.storage = nil
+	d.dropSorted()
+	d.stopTTLTimer()
+	for _, w := range d.idleWatches {
+		w.stop()
+	}
+	d.dropGated()
+	d.checkDrained()
+
+	if d.keyIndex != nil {
+		d.keyIndex.clear()
+	}
+	if d.orderCheck != nil {
+		d.orderCheck.clear()
+	}
+
+	for _, o := range d.observers {
+		o.remove()
+	}
+	d.observers = nil
+
+	if d.meta != nil {
+		d.emitMeta(MetaEvent{Kind: MetaClose})
+		d.meta.Seal()
+	}
+}
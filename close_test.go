@@ -0,0 +1,163 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSeal(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var dropped []eventdistributor.DropReason
+	options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+		dropped = append(dropped, reason)
+	})
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	d.Submit(MyEvent{id: 1})
+	d.Seal()
+
+	t.Log("submissions after seal are dropped")
+	nowReady(t, d.Submit(MyEvent{id: 2}))
+	require.Equal(t, []eventdistributor.DropReason{eventdistributor.DropReasonSealed}, dropped)
+
+	t.Log("buffered events are still available")
+	require.Equal(t, []int{1}, drainIDs(&r))
+}
+
+func TestWaitForDrain(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	t.Log("empty buffer returns immediately")
+	require.NoError(t, d.WaitForDrain(context.Background()))
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(MyEvent{id: 1})
+
+	t.Log("returns ctx.Err() if not drained")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, d.WaitForDrain(ctx), context.DeadlineExceeded)
+
+	t.Log("waits for events submitted while waiting")
+	done := make(chan error)
+	go func() {
+		done <- d.WaitForDrain(context.Background())
+	}()
+	d.Submit(MyEvent{id: 2})
+	r.Consume()
+	select {
+	case <-done:
+		require.FailNow(t, "WaitForDrain returned before the buffer was empty")
+	case <-time.After(10 * time.Millisecond):
+	}
+	r.Consume()
+	require.NoError(t, <-done)
+}
+
+func TestWaitForDrainGated(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	gate := d.SubscribeGate()
+	defer gate.Unsubscribe()
+
+	d.Submit(MyEvent{id: 1})
+	d.Submit(MyEvent{id: 2})
+	done := make(chan error)
+	go func() {
+		done <- d.WaitForDrain(context.Background())
+	}()
+
+	t.Log("events held by the gate aren't drained yet")
+	e1 := gate.Consume()
+	e2 := gate.Consume()
+	require.NoError(t, e1.Approve())
+	require.Equal(t, []int{1}, drainIDs(&r))
+	select {
+	case <-done:
+		require.FailNow(t, "WaitForDrain returned while the gate was holding events")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	t.Log("rejecting the last held event drains the Distributor")
+	require.NoError(t, e2.Reject(errors.New("invalid")))
+	require.NoError(t, <-done)
+}
+
+func TestWaitForDrainAllocations(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	ctx := context.Background()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		require.NoError(t, d.WaitForDrain(ctx))
+	})
+	require.Zero(t, allocs, "waiting on an empty buffer allocated")
+
+	t.Log("the buffer is empty again once every event is consumed")
+	d.Submit(MyEvent{id: 1})
+	r.Consume()
+	allocs = testing.AllocsPerRun(100, func() {
+		require.NoError(t, d.WaitForDrain(ctx))
+	})
+	require.Zero(t, allocs, "waiting on a drained buffer allocated")
+}
+
+func TestClose(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var dropped []eventdistributor.DropReason
+	options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+		dropped = append(dropped, reason)
+	})
+	d := eventdistributor.New(options)
+
+	r1 := d.Subscribe()
+	r2 := d.Subscribe()
+	waitChan := r2.WaitChan()
+
+	s1 := d.Submit(MyEvent{id: 1})
+	d.Submit(MyEvent{id: 2})
+	r1.Consume()
+	r1.Consume()
+
+	d.Close()
+	d.Close() // ok to call twice
+
+	t.Log("buffered events are dropped")
+	nowReady(t, s1)
+	require.Equal(t, []eventdistributor.DropReason{
+		eventdistributor.DropReasonClosed,
+		eventdistributor.DropReasonClosed,
+	}, dropped)
+
+	t.Log("readers are released")
+	nowReady(t, waitChan)
+	ready(t, r1)
+	_, err := r2.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrClosed)
+	require.Panics(t, func() { r2.Consume() })
+
+	t.Log("new readers are immediately closed")
+	r3 := d.Subscribe()
+	_, err = r3.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrClosed)
+
+	t.Log("submissions are dropped")
+	nowReady(t, d.Submit(MyEvent{id: 3}))
+	require.Equal(t, eventdistributor.DropReasonSealed, dropped[len(dropped)-1])
+
+	r1.Unsubscribe()
+	r2.Unsubscribe()
+	r3.Unsubscribe()
+}
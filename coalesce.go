@@ -0,0 +1,82 @@
+package eventdistributor
+
+// WithCoalesce sets a function to merge buffered events, so that Readers that have fallen behind
+// receive fewer, merged events instead of a long backlog - for example, if events are incremental
+// updates to some state.
+//
+// When an event is submitted, merge is called with it and the newest buffered event, if every
+// Reader that has yet to receive one of them has yet to receive both. If merge returns ok = true,
+// the newest buffered event is replaced by the merged event, which takes the sequence number of the
+// submitted one. The replaced event is passed to OnDrop callbacks with DropReasonCoalesced, and the
+// channel returned by Submit for it is closed. Readers that have already received the newest
+// buffered event are unaffected, because they only receive the submitted one.
+//
+// Events are never merged while the newest buffered event is pinned or in flight for a Reader in
+// ack mode, or while the Distributor has Readers created by SubscribeLIFO() or with filters, which
+// may need to receive the events separately.
+//
+// merge is called while the Distributor's lock is held, so it must not call any methods on the
+// Distributor or its Readers.
+func (o *Options[T]) WithCoalesce(merge func(older, newer T) (merged T, ok bool)) {
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.coalesce = merge
+	})
+}
+
+// coalesceLast merges the submitted event e, as it would be added to the buffer, into the newest
+// buffered event, if possible, returning whether it did. See WithCoalesce.
+//
+// d.mu must be held.
+func (d *Distributor[T]) coalesceLast(e eventInfo[T]) bool {
+	// Any Reader that has received the newest buffered event is at the end of the buffer, and the
+	// refcount of the submitted event would be zero only if there are none.
+	if d.coalesce == nil || len(d.buf) == 0 || d.nextRefcount != 0 || d.numFiltered != 0 {
+		return false
+	}
+
+	last := &d.buf[len(d.buf)-1]
+	if d.heldSeparately(last.seq) {
+		return false
+	}
+
+	merged, ok := d.coalesce(last.value, e.value)
+	if !ok {
+		return false
+	}
+
+	d.dropped(last.value, last.seq, DropReasonCoalesced)
+	d.finish(last.allConsumed, last.onDone)
+
+	// The merged event is only an echo of an event forwarded by AsAny() if both events were, so
+	// that the rest of it is still forwarded.
+	if e.token != nil && e.token.forwardedBy != nil && e.token != last.token {
+		e.token = nil
+	}
+
+	e.value = merged
+	e.refcount = last.refcount
+	*last = e
+	return true
+}
+
+// heldSeparately returns whether the buffered event with the sequence number seq may be received
+// by some Reader apart from its position in the buffer - because it's pinned or in flight for a
+// Reader in ack mode, or because there are Readers created by SubscribeLIFO() - so that it can't be
+// replaced by WithCoalesce or WithConflateBy.
+//
+// d.mu must be held.
+func (d *Distributor[T]) heldSeparately(seq int64) bool {
+	if _, pinned := d.pins[seq]; pinned {
+		return true
+	}
+	for _, r := range d.readers {
+		if r.lifo != nil {
+			return true
+		} else if a := r.acks; a != nil {
+			if containsSeq(a.inFlight, seq) || containsSeq(a.redeliver, seq) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestWithCoalesce(t *testing.T) {
+	var sizes []int
+	var coalesced []int
+	var options eventdistributor.Options[MyEvent]
+	options.WithCoalesce(func(older, newer MyEvent) (MyEvent, bool) {
+		return MyEvent{id: older.id*10 + newer.id}, newer.id != 0
+	})
+	options.OnBufsizeChange(func(size int) { sizes = append(sizes, size) })
+	options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+		require.Equal(t, eventdistributor.DropReasonCoalesced, reason)
+		coalesced = append(coalesced, e.id)
+	})
+	d := eventdistributor.New(options)
+
+	fast := d.Subscribe()
+	defer fast.Unsubscribe()
+	slow := d.Subscribe()
+	defer slow.Unsubscribe()
+
+	t.Log("events aren't merged while a Reader has received the newest one")
+	d.Submit(MyEvent{id: 1})
+	require.Equal(t, []int{1}, drainIDs(&fast))
+	d.Submit(MyEvent{id: 2})
+	require.Empty(t, coalesced)
+
+	t.Log("events are merged once every Reader is behind")
+	allConsumed := d.Submit(MyEvent{id: 3})
+	d.Submit(MyEvent{id: 4})
+	require.Equal(t, []int{2, 23}, coalesced)
+	nowReady(t, allConsumed)
+	require.Equal(t, []int{1, 2}, sizes)
+	require.Equal(t, []int{234}, drainIDs(&fast))
+	require.Equal(t, []int{1, 234}, drainIDs(&slow))
+
+	t.Log("merge can decline")
+	d.Submit(MyEvent{id: 5})
+	d.Submit(MyEvent{id: 0})
+	require.Equal(t, []int{5, 0}, drainIDs(&fast))
+	require.Equal(t, []int{5, 0}, drainIDs(&slow))
+}
@@ -0,0 +1,95 @@
+package eventdistributor
+
+import (
+	"context"
+	"sync"
+)
+
+// CondAdapter provides the broadcast behavior of a sync.Cond, built on a Distributor, to help with
+// migrating code that uses sync.Cond. Each Broadcast() is submitted as an event, which is received
+// by every call to Wait() that started before it, exactly once. Waiting can also be canceled with a
+// context, which sync.Cond doesn't allow.
+//
+// Like sync.Cond, L is held while checking the condition, and is released by Wait() while waiting.
+// Because Wait() starts listening for broadcasts before releasing L, a Broadcast() made while
+// holding L can't be missed. L may be nil, in which case Wait() only receives broadcasts made after
+// it was called.
+//
+// sync.Cond's Signal is not provided, because every event is delivered to every Reader.
+//
+// The zero value of a CondAdapter is valid, with a nil L.
+type CondAdapter struct {
+	// L is held while checking the condition. See Wait().
+	L sync.Locker
+
+	d Distributor[struct{}]
+
+	// mu protects tail, a Reader that is always caught up after each broadcast, so that its
+	// WaitChan() is closed by the next one. See NotifyChan().
+	mu   sync.Mutex
+	tail *Reader[struct{}]
+}
+
+// NewCondAdapter creates a new CondAdapter with the Locker l, like sync.NewCond.
+func NewCondAdapter(l sync.Locker) *CondAdapter {
+	return &CondAdapter{
+		L:    l,
+		d:    Distributor[struct{}]{},
+		mu:   sync.Mutex{},
+		tail: nil,
+	}
+}
+
+// Broadcast wakes every current call to Wait(), and closes the channels returned by NotifyChan().
+// It is not required to hold L while calling Broadcast.
+//
+// Broadcast is thread-safe.
+func (c *CondAdapter) Broadcast() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.d.SubmitQuiet(struct{}{})
+	if c.tail != nil {
+		c.tail.Consume()
+	}
+}
+
+// Wait blocks until the next call to Broadcast(), returning nil, or until ctx is canceled,
+// returning ctx.Err(). If L is not nil, it must be held when Wait is called: Wait releases it once
+// it has started waiting, and acquires it again before returning, even if ctx was canceled.
+//
+// As with sync.Cond, the condition being waited for should be checked again after Wait returns.
+//
+// Wait is thread-safe.
+func (c *CondAdapter) Wait(ctx context.Context) error {
+	r := c.d.Subscribe()
+	defer r.Unsubscribe()
+
+	if c.L != nil {
+		c.L.Unlock()
+		defer c.L.Lock()
+	}
+
+	select {
+	case <-r.WaitChan():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// NotifyChan returns a channel that is closed by the next call to Broadcast(). Like
+// (*Reader[T]).WaitChan(), it should be called again for each wait: once closed, the channel stays
+// closed.
+//
+// NotifyChan is thread-safe.
+func (c *CondAdapter) NotifyChan() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tail == nil {
+		r := c.d.Subscribe()
+		c.tail = &r
+	}
+	return c.tail.WaitChan()
+}
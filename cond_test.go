@@ -0,0 +1,76 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestCondAdapter(t *testing.T) {
+	var mu sync.Mutex
+	c := eventdistributor.NewCondAdapter(&mu)
+	ready := false
+
+	t.Log("a broadcast made while holding L after Wait started can't be missed")
+	done := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		defer close(done)
+		mu.Lock()
+		defer mu.Unlock()
+		close(started)
+		for !ready {
+			require.NoError(t, c.Wait(context.Background()))
+		}
+	}()
+
+	<-started
+	mu.Lock()
+	ready = true
+	c.Broadcast()
+	mu.Unlock()
+	<-done
+
+	t.Log("earlier broadcasts are not observed")
+	c.Broadcast()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	mu.Lock()
+	require.ErrorIs(t, c.Wait(ctx), context.DeadlineExceeded)
+	mu.Unlock()
+}
+
+func TestCondAdapterNotifyChan(t *testing.T) {
+	var c eventdistributor.CondAdapter
+
+	notify := c.NotifyChan()
+	nowNotReady(t, notify)
+	c.Broadcast()
+	nowReady(t, notify)
+
+	t.Log("each channel is only closed by broadcasts after it was returned")
+	notify = c.NotifyChan()
+	nowNotReady(t, notify)
+	c.Broadcast()
+	c.Broadcast()
+	nowReady(t, notify)
+	nowNotReady(t, c.NotifyChan())
+
+	t.Log("without L, Wait receives broadcasts made after it's called")
+	waited := make(chan error)
+	go func() { waited <- c.Wait(context.Background()) }()
+	for {
+		c.Broadcast()
+		select {
+		case err := <-waited:
+			require.NoError(t, err)
+			return
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package eventdistributor
+
+// WithConflateBy enables keyed conflation: when an event is submitted with the same key as a
+// buffered event that no Reader has begun consuming, the buffered event is replaced in place by the
+// submitted one, so that Readers receive only the latest event for each key they haven't yet
+// received. This bounds the buffer by the number of distinct keys, rather than the number of
+// submitted events.
+//
+// The submitted event takes the place of the buffered one, including its sequence number and the
+// time it was buffered, so events with different keys stay in the order they were first submitted,
+// and WithEventTTL applies from the time the replaced event was buffered. The replaced event is
+// passed to OnDrop callbacks with DropReasonConflated, and the channel returned by Submit for it is
+// closed.
+//
+// Events that some Reader has already received are never replaced - the submitted event is added
+// to the buffer as usual. As with WithCoalesce, events are also never replaced while they're pinned
+// or in flight for a Reader in ack mode, or while the Distributor has Readers created by
+// SubscribeLIFO() or with filters.
+//
+// key is called while the Distributor's lock is held, so it must not call any methods on the
+// Distributor or its Readers.
+func WithConflateBy[T any, K comparable](o *Options[T], key func(T) K) {
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.conflate = &keyConflater[K, T]{key: key}
+	})
+}
+
+// conflater is the type-erased interface to a *keyConflater, so that it can be stored in the
+// Distributor without an extra type parameter
+type conflater[T any] interface {
+	// find returns the index of the newest event in buf[from:] with the same key as value, if
+	// there is one
+	find(buf []eventInfo[T], from int, value T) (int, bool)
+}
+
+type keyConflater[K comparable, T any] struct {
+	key func(T) K
+}
+
+func (c *keyConflater[K, T]) find(buf []eventInfo[T], from int, value T) (int, bool) {
+	k := c.key(value)
+	for i := len(buf) - 1; i >= from; i-- {
+		if c.key(buf[i].value) == k {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// conflateBuffered replaces the buffered event with the same key as the submitted event e, as it
+// would be added to the buffer, if possible, returning the sequence number of the replaced event
+// and whether it did. See WithConflateBy.
+//
+// d.mu must be held.
+func (d *Distributor[T]) conflateBuffered(e eventInfo[T]) (int64, bool) {
+	// Any Reader at the end of the buffer has begun consuming every buffered event.
+	if d.conflate == nil || len(d.buf) == 0 || d.nextRefcount != 0 || d.numFiltered != 0 {
+		return 0, false
+	}
+
+	// Only events that no Reader has moved past can be replaced.
+	from := 0
+	for _, r := range d.readers {
+		if i := int(r.position - d.basePosition); i > from {
+			from = i
+		}
+	}
+
+	i, ok := d.conflate.find(d.buf, from, e.value)
+	if !ok {
+		return 0, false
+	}
+
+	old := &d.buf[i]
+	if d.heldSeparately(old.seq) {
+		return 0, false
+	}
+
+	d.dropped(old.value, old.seq, DropReasonConflated)
+	d.finish(old.allConsumed, old.onDone)
+
+	e.seq = old.seq
+	e.refcount = old.refcount
+	e.timestamp = old.timestamp
+	*old = e
+	return e.seq, true
+}
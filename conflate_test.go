@@ -0,0 +1,53 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestWithConflateBy(t *testing.T) {
+	var conflated []int
+	var options eventdistributor.Options[MyEvent]
+	// Events with ids in the same multiple of 10 share a key
+	eventdistributor.WithConflateBy(&options, func(e MyEvent) int { return e.id / 10 })
+	options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+		require.Equal(t, eventdistributor.DropReasonConflated, reason)
+		conflated = append(conflated, e.id)
+	})
+	d := eventdistributor.New(options)
+
+	fast := d.Subscribe()
+	defer fast.Unsubscribe()
+	slow := d.Subscribe()
+	defer slow.Unsubscribe()
+
+	t.Log("pending events are replaced in place, keeping distinct keys in order")
+	allConsumed := d.Submit(MyEvent{id: 10})
+	d.Submit(MyEvent{id: 20})
+	d.Submit(MyEvent{id: 11})
+	d.Submit(MyEvent{id: 12})
+	require.Equal(t, []int{10, 11}, conflated)
+	nowReady(t, allConsumed)
+	require.Equal(t, 2, d.Stats().Bufsize)
+
+	t.Log("events that a Reader has received aren't replaced")
+	require.Equal(t, []int{12, 20}, drainIDs(&fast))
+	d.Submit(MyEvent{id: 13})
+	d.Submit(MyEvent{id: 21})
+	require.Equal(t, []int{10, 11}, conflated)
+	require.Equal(t, []int{13, 21}, drainIDs(&fast))
+	require.Equal(t, []int{12, 20, 13, 21}, drainIDs(&slow))
+
+	t.Log("events after the furthest Reader can still be replaced")
+	d.Submit(MyEvent{id: 30})
+	require.Equal(t, []int{30}, drainIDs(&fast))
+	d.Submit(MyEvent{id: 40})
+	d.Submit(MyEvent{id: 41})
+	d.Submit(MyEvent{id: 31})
+	require.Equal(t, []int{10, 11, 40}, conflated)
+	require.Equal(t, []int{41, 31}, drainIDs(&fast))
+	require.Equal(t, []int{30, 41, 31}, drainIDs(&slow))
+}
@@ -0,0 +1,238 @@
+// Package debughttp provides an http.Handler that shows the live state of a set of Distributors,
+// for debugging. It is typically mounted at "/debug/eventdistributor/".
+//
+// For each registered Distributor, the handler shows its name, its state, a table of its Readers,
+// and the oldest and newest buffered events. The state is rendered as HTML, or as JSON if the
+// request has the query parameter "format=json" or accepts "application/json".
+//
+// Event values are only shown if a formatter was provided when the Distributor was registered, so
+// that the contents of events are never exposed by accident.
+package debughttp
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+// defaultEvents is the number of oldest and newest buffered events shown for each Distributor, if
+// the request doesn't specify it with the query parameter "events"
+const defaultEvents = 5
+
+// Registry is a set of named Distributors to show with Handler.
+//
+// The zero value of a Registry is valid, and has no Distributors.
+type Registry struct {
+	mu sync.Mutex
+
+	// snapshots has a function to take a snapshot of each registered Distributor, by name
+	snapshots map[string]func(events int) distributorView
+}
+
+// Register adds the Distributor to the registry under the name, replacing any Distributor already
+// registered with the same name.
+//
+// If format is not nil, it is used to show the values of buffered events. Otherwise, only their
+// sequence numbers and timestamps are shown. format is called without holding the Distributor's
+// lock, so it may be slow.
+//
+// Register is thread-safe.
+func Register[T any](
+	reg *Registry,
+	name string,
+	d *eventdistributor.Distributor[T],
+	format func(T) string,
+) {
+	snapshot := func(events int) distributorView {
+		return newDistributorView(name, d.Snapshot(events), format)
+	}
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if reg.snapshots == nil {
+		reg.snapshots = make(map[string]func(events int) distributorView)
+	}
+	reg.snapshots[name] = snapshot
+}
+
+// Unregister removes the Distributor registered under the name, if there is one.
+//
+// Unregister is thread-safe.
+func (reg *Registry) Unregister(name string) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	delete(reg.snapshots, name)
+}
+
+// snapshot takes a snapshot of each registered Distributor, sorted by name
+func (reg *Registry) snapshot(events int) []distributorView {
+	reg.mu.Lock()
+	funcs := make([]func(int) distributorView, 0, len(reg.snapshots))
+	for _, f := range reg.snapshots {
+		funcs = append(funcs, f)
+	}
+	reg.mu.Unlock()
+
+	// Each Distributor's lock is only held while its snapshot is taken, and the registry's lock
+	// isn't held at all, so that rendering never blocks anything else.
+	views := make([]distributorView, 0, len(funcs))
+	for _, f := range funcs {
+		views = append(views, f(events))
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].Name < views[j].Name })
+	return views
+}
+
+// Handler returns an http.Handler that shows the state of each Distributor in the registry.
+//
+// The number of oldest and newest buffered events shown for each Distributor can be set with the
+// query parameter "events", which defaults to 5.
+func Handler(reg *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events := defaultEvents
+		if s := r.URL.Query().Get("events"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				http.Error(w, "invalid value for 'events'", http.StatusBadRequest)
+				return
+			}
+			events = n
+		}
+
+		views := reg.snapshot(events)
+
+		if wantsJSON(r) {
+			w.Header().Set("Content-Type", "application/json")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			_ = enc.Encode(views)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = page.Execute(w, views)
+	})
+}
+
+// wantsJSON returns whether the request should be answered with JSON instead of HTML
+func wantsJSON(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == "json"
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// distributorView is the rendered state of a single Distributor
+type distributorView struct {
+	Name     string       `json:"name"`
+	NextSeq  int64        `json:"nextSeq"`
+	Buffered int          `json:"buffered"`
+	Sealed   bool         `json:"sealed"`
+	Closed   bool         `json:"closed"`
+	Readers  []readerView `json:"readers"`
+	Oldest   []eventView  `json:"oldest"`
+	Newest   []eventView  `json:"newest"`
+}
+
+type readerView struct {
+	ID      uint64 `json:"id"`
+	NextSeq int64  `json:"nextSeq"`
+	Pending int    `json:"pending"`
+}
+
+type eventView struct {
+	Seq       int64      `json:"seq"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	// Value is the formatted value of the event, or nil if there's no formatter
+	Value *string `json:"value,omitempty"`
+}
+
+func newDistributorView[T any](
+	name string,
+	s eventdistributor.Snapshot[T],
+	format func(T) string,
+) distributorView {
+	readers := make([]readerView, 0, len(s.Readers))
+	for _, r := range s.Readers {
+		readers = append(readers, readerView{ID: r.ID, NextSeq: r.NextSeq, Pending: r.Pending})
+	}
+	sort.Slice(readers, func(i, j int) bool { return readers[i].ID < readers[j].ID })
+
+	return distributorView{
+		Name:     name,
+		NextSeq:  s.NextSeq,
+		Buffered: s.Buffered,
+		Sealed:   s.Sealed,
+		Closed:   s.Closed,
+		Readers:  readers,
+		Oldest:   newEventViews(s.Oldest, format),
+		Newest:   newEventViews(s.Newest, format),
+	}
+}
+
+func newEventViews[T any](
+	events []eventdistributor.SnapshotEvent[T],
+	format func(T) string,
+) []eventView {
+	views := make([]eventView, 0, len(events))
+	for _, e := range events {
+		v := eventView{Seq: e.Seq, Timestamp: nil, Value: nil}
+		if !e.Timestamp.IsZero() {
+			ts := e.Timestamp
+			v.Timestamp = &ts
+		}
+		if format != nil {
+			value := format(e.Value)
+			v.Value = &value
+		}
+		views = append(views, v)
+	}
+	return views
+}
+
+var page = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head><title>eventdistributor</title></head>
+<body>
+{{- range .}}
+<h2>{{.Name}}</h2>
+<p>
+next seq: {{.NextSeq}}, buffered: {{.Buffered}}
+{{- if .Closed}}, closed{{else if .Sealed}}, sealed{{end}}
+</p>
+<h3>Readers</h3>
+<table border="1">
+<tr><th>ID</th><th>Next seq</th><th>Pending</th></tr>
+{{- range .Readers}}
+<tr><td>{{.ID}}</td><td>{{.NextSeq}}</td><td>{{.Pending}}</td></tr>
+{{- end}}
+</table>
+<h3>Oldest events</h3>
+{{template "events" .Oldest}}
+<h3>Newest events</h3>
+{{template "events" .Newest}}
+{{- else}}
+<p>No distributors registered.</p>
+{{- end}}
+</body>
+</html>
+{{define "events"}}<table border="1">
+<tr><th>Seq</th><th>Timestamp</th><th>Value</th></tr>
+{{- range .}}
+<tr>
+<td>{{.Seq}}</td>
+<td>{{with .Timestamp}}{{.}}{{end}}</td>
+<td>{{with .Value}}{{.}}{{else}}<i>hidden</i>{{end}}</td>
+</tr>
+{{- end}}
+</table>{{end}}
+`))
@@ -0,0 +1,111 @@
+package debughttp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+	"github.com/sharnoff/eventdistributor/debughttp"
+)
+
+func get(t *testing.T, h http.Handler, target string, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	var reg debughttp.Registry
+	h := debughttp.Handler(&reg)
+
+	secrets := eventdistributor.New[string]()
+	counts := eventdistributor.New[int]()
+	debughttp.Register(&reg, "secrets", secrets, nil)
+	debughttp.Register(&reg, "counts", counts, strconv.Itoa)
+
+	r := secrets.Subscribe()
+	defer r.Unsubscribe()
+	secrets.Submit("hunter2")
+	c := counts.Subscribe()
+	defer c.Unsubscribe()
+	for i := 0; i < 10; i++ {
+		counts.Submit(i)
+	}
+	_, err := c.TryConsume()
+	require.NoError(t, err)
+
+	t.Log("JSON shows each Distributor, sorted by name")
+	rec := get(t, h, "/debug/eventdistributor/?format=json&events=2", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var views []struct {
+		Name     string
+		NextSeq  int64
+		Buffered int
+		Readers  []struct {
+			ID      uint64
+			NextSeq int64
+			Pending int
+		}
+		Oldest []struct {
+			Seq   int64
+			Value *string
+		}
+		Newest []struct {
+			Seq   int64
+			Value *string
+		}
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &views))
+	require.Len(t, views, 2)
+
+	require.Equal(t, "counts", views[0].Name)
+	require.Equal(t, int64(10), views[0].NextSeq)
+	require.Equal(t, 9, views[0].Buffered)
+	require.Len(t, views[0].Readers, 1)
+	require.Equal(t, c.ID(), views[0].Readers[0].ID)
+	require.Equal(t, int64(1), views[0].Readers[0].NextSeq)
+	require.Equal(t, 9, views[0].Readers[0].Pending)
+	require.Len(t, views[0].Oldest, 2)
+	require.Equal(t, "1", *views[0].Oldest[0].Value)
+	require.Len(t, views[0].Newest, 2)
+	require.Equal(t, int64(9), views[0].Newest[1].Seq)
+	require.Equal(t, "9", *views[0].Newest[1].Value)
+
+	t.Log("values are hidden without a formatter")
+	require.Equal(t, "secrets", views[1].Name)
+	require.Len(t, views[1].Oldest, 1)
+	require.Empty(t, views[1].Newest)
+	require.Nil(t, views[1].Oldest[0].Value)
+	require.NotContains(t, rec.Body.String(), "hunter2")
+
+	t.Log("JSON can also be requested with the Accept header")
+	rec = get(t, h, "/debug/eventdistributor/", "application/json")
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	t.Log("HTML is shown by default")
+	rec = get(t, h, "/debug/eventdistributor/", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+	require.Contains(t, rec.Body.String(), "<h2>counts</h2>")
+	require.NotContains(t, rec.Body.String(), "hunter2")
+
+	t.Log("unregistered Distributors are no longer shown")
+	reg.Unregister("secrets")
+	rec = get(t, h, "/debug/eventdistributor/?format=json", "")
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &views))
+	require.Len(t, views, 1)
+
+	rec = get(t, h, "/debug/eventdistributor/?events=-1", "")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
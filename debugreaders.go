@@ -0,0 +1,89 @@
+package eventdistributor
+
+import (
+	"runtime"
+	"sort"
+	"time"
+)
+
+// WithSubscribeCapture records the call stack that created each Reader, up to depth frames,
+// starting with the Subscribe method - or one of its variants - that created it. The stack is
+// available from (*Distributor[T]).DebugReaders(), and is released along with the Reader.
+//
+// Capturing the stack has a cost for every new Reader, so it is intended for debugging - for
+// example, to find the Readers that are keeping events in a buffer that grows unexpectedly.
+//
+// WithSubscribeCapture panics if depth is not positive.
+func (o *Options[T]) WithSubscribeCapture(depth int) {
+	if depth <= 0 {
+		panic("eventdistributor: WithSubscribeCapture requires depth > 0")
+	}
+
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.subscribeDepth = depth
+	})
+}
+
+// ReaderDebugInfo describes a single Reader, as returned by (*Distributor[T]).DebugReaders()
+type ReaderDebugInfo struct {
+	// ID is the Reader's ID, as returned by (*Reader[T]).ID()
+	ID uint64
+	// NextSeq is the sequence number of the next buffered event the Reader will receive, or the
+	// sequence number of the next submitted event if it has received every buffered event
+	NextSeq int64
+	// Pending is the number of buffered events that the Reader has not yet seen
+	Pending int
+	// LastConsumed is the time at which an event was last delivered to the Reader, according to
+	// the Distributor's Clock, or the zero value if none have been
+	LastConsumed time.Time
+	// Subscribed is the call stack that created the Reader, if the Distributor captures it. See
+	// (*Options[T]).WithSubscribeCapture().
+	Subscribed []runtime.Frame
+}
+
+// DebugReaders returns a description of every active Reader of the Distributor, ordered by ID.
+// Readers that were unsubscribed, expired, evicted, or released by Close() are not
+// included.
+//
+// The returned ReaderDebugInfo are copies, so they can be used without any synchronization - for
+// example, logged or served from an admin endpoint.
+//
+// DebugReaders is thread-safe.
+func (d *Distributor[T]) DebugReaders() []ReaderDebugInfo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	infos := make([]ReaderDebugInfo, 0, len(d.readers))
+	tail := d.basePosition + int64(len(d.buf))
+	for _, r := range d.readers {
+		nextSeq := d.nextSeq
+		if idx := int(r.position - d.basePosition); idx < len(d.buf) {
+			nextSeq = d.buf[idx].seq
+		}
+		infos = append(infos, ReaderDebugInfo{
+			ID:           r.id,
+			NextSeq:      nextSeq,
+			Pending:      int(tail - r.position),
+			LastConsumed: r.lastConsumed,
+			Subscribed:   callerFrames(r.subscribed),
+		})
+	}
+	// Readers are removed from the registry by swapping with the last one, so it isn't in order.
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// captureSubscriber returns the call stack that's creating a Reader, if the Distributor captures
+// it. Otherwise, it returns nil.
+//
+// d.mu must be held.
+func (d *Distributor[T]) captureSubscriber() []uintptr {
+	if d.subscribeDepth == 0 {
+		return nil
+	}
+
+	pcs := make([]uintptr, d.subscribeDepth)
+	// skip runtime.Callers, captureSubscriber, and subscribe
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
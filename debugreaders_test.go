@@ -0,0 +1,58 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestDebugReaders(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithSubscribeCapture(4)
+	clock := newFakeClock()
+	options.WithClock(clock)
+	d := eventdistributor.New(options)
+
+	r1 := d.Subscribe()
+	defer r1.Unsubscribe()
+	r2 := d.SubscribeFiltered(func(MyEvent) bool { return true })
+	r3 := d.Subscribe()
+	d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 1})
+	require.Equal(t, MyEvent{id: 0}, r1.Consume())
+	r2.Unsubscribe()
+
+	infos := d.DebugReaders()
+	require.Len(t, infos, 2)
+	require.Equal(t, []uint64{r1.ID(), r3.ID()}, []uint64{infos[0].ID, infos[1].ID})
+
+	t.Log("position and last consumption are recorded")
+	require.Equal(t, int64(1), infos[0].NextSeq)
+	require.Equal(t, 1, infos[0].Pending)
+	require.Equal(t, clock.Now(), infos[0].LastConsumed)
+	require.Equal(t, int64(0), infos[1].NextSeq)
+	require.Equal(t, 2, infos[1].Pending)
+	require.True(t, infos[1].LastConsumed.IsZero())
+
+	t.Log("the stack starts with the Subscribe method, followed by its caller")
+	const testFunc = "github.com/sharnoff/eventdistributor_test.TestDebugReaders"
+	require.Len(t, infos[0].Subscribed, 4)
+	require.Contains(t, infos[0].Subscribed[0].Function, "Subscribe")
+	require.Equal(t, testFunc, infos[0].Subscribed[1].Function)
+
+	t.Log("after Unsubscribe, the Reader is no longer listed")
+	r3.Unsubscribe()
+	require.Len(t, d.DebugReaders(), 1)
+}
+
+func TestDebugReadersWithoutCapture(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	infos := d.DebugReaders()
+	require.Len(t, infos, 1)
+	require.Nil(t, infos[0].Subscribed)
+}
@@ -0,0 +1,47 @@
+package eventdistributor
+
+// WithDedupPending enables deduplicating submitted events: when an event is submitted while an
+// equal event, according to eq, is still buffered, the submitted event isn't added to the buffer.
+// If wholeBuffer is false, only the newest buffered event is compared with it - which is enough to
+// collapse bursts of identical events, and costs a single call to eq. Otherwise, every buffered
+// event is compared, newest first.
+//
+// Deduplicated events are still passed to OnSubmit callbacks, and counted by Stats().Deduped
+// instead of being reported as dropped. Readers that have already received the buffered copy don't
+// receive anything new, and the channel returned by Submit is the one for the buffered copy, if it
+// has one, or else a closed channel.
+//
+// eq is called while the Distributor's lock is held, so it must not call any methods on the
+// Distributor or its Readers.
+func (o *Options[T]) WithDedupPending(eq func(a, b T) bool, wholeBuffer bool) {
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.dedup = &dedupPending[T]{eq: eq, wholeBuffer: wholeBuffer}
+	})
+}
+
+// dedupPending is the configuration set by WithDedupPending
+type dedupPending[T any] struct {
+	eq          func(a, b T) bool
+	wholeBuffer bool
+}
+
+// findDuplicate returns the index of a buffered event equal to value, if there is one. See
+// WithDedupPending.
+//
+// d.mu must be held.
+func (d *Distributor[T]) findDuplicate(value T) (int, bool) {
+	if d.dedup == nil {
+		return 0, false
+	}
+
+	stop := 0
+	if !d.dedup.wholeBuffer {
+		stop = len(d.buf) - 1
+	}
+	for i := len(d.buf) - 1; i >= stop && i >= 0; i-- {
+		if d.dedup.eq(d.buf[i].value, value) {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,51 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestWithDedupPending(t *testing.T) {
+	for _, wholeBuffer := range []bool{false, true} {
+		var submitted []int
+		var options eventdistributor.Options[MyEvent]
+		options.WithDedupPending(func(a, b MyEvent) bool { return a == b }, wholeBuffer)
+		options.OnSubmit(func(e MyEvent) { submitted = append(submitted, e.id) })
+		d := eventdistributor.New(options)
+
+		fast := d.Subscribe()
+		defer fast.Unsubscribe()
+		slow := d.Subscribe()
+		defer slow.Unsubscribe()
+
+		t.Logf("wholeBuffer = %v: duplicates of the newest event are skipped", wholeBuffer)
+		allConsumed := d.Submit(MyEvent{id: 1})
+		require.Equal(t, allConsumed, d.Submit(MyEvent{id: 1}))
+		require.Equal(t, []int{1}, drainIDs(&fast))
+		d.Submit(MyEvent{id: 1})
+		require.Empty(t, drainIDs(&fast))
+		require.Equal(t, []int{1, 1, 1}, submitted)
+
+		t.Logf("wholeBuffer = %v: older events are only compared with wholeBuffer", wholeBuffer)
+		d.Submit(MyEvent{id: 2})
+		d.Submit(MyEvent{id: 1})
+		if wholeBuffer {
+			require.Equal(t, []int{2}, drainIDs(&fast))
+			require.Equal(t, []int{1, 2}, drainIDs(&slow))
+			require.Equal(t, int64(3), d.Stats().Deduped)
+		} else {
+			require.Equal(t, []int{2, 1}, drainIDs(&fast))
+			require.Equal(t, []int{1, 2, 1}, drainIDs(&slow))
+			require.Equal(t, int64(2), d.Stats().Deduped)
+		}
+		nowReady(t, allConsumed)
+
+		t.Logf("wholeBuffer = %v: events are compared only while buffered", wholeBuffer)
+		d.Submit(MyEvent{id: 2})
+		require.Equal(t, []int{2}, drainIDs(&fast))
+		require.Equal(t, []int{2}, drainIDs(&slow))
+	}
+}
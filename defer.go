@@ -0,0 +1,83 @@
+package eventdistributor
+
+import (
+	"time"
+)
+
+// Defer postpones the events currently available to the Reader, without consuming them: until
+// duration has elapsed or a newer event is submitted, the Reader's WaitChan() and Signal report
+// that there is nothing to receive. This allows waiting for a condition that the next event
+// depends on, without repeatedly consuming and re-checking it.
+//
+// The deferred events are still held for the Reader, and can still be consumed explicitly.
+// Consuming an event ends the deferral, as does calling the returned function, which can be used to
+// cancel the deferral early - for example, while shutting down. Calling Defer again replaces any
+// earlier deferral.
+//
+// If the Reader can no longer be used, Defer does nothing.
+//
+// Defer is thread-safe.
+func (r *Reader[T]) Defer(duration time.Duration) (cancel func()) {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if err := r.prepare(); err != nil {
+		return func() {}
+	}
+
+	r.endDeferral()
+	def := &readerDeferral{
+		nextSeq: r.d.nextSeq,
+		timer:   nil,
+	}
+	r.deferral = def
+	r.publishPosition()
+
+	d := r.d
+	state := r.readerState
+	end := func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		// The Reader may have already been unsubscribed.
+		if !state.unsubscribed && state.deferral == def {
+			state.reader().endDeferral()
+			state.reader().wakeOwn()
+		}
+	}
+	def.timer = d.getClock().AfterFunc(duration, end)
+	return end
+}
+
+// readerDeferral is the state of a Reader's call to Defer()
+type readerDeferral struct {
+	// nextSeq is the sequence number of the first event that would end the deferral
+	nextSeq int64
+	timer   Timer
+}
+
+// deferred returns whether the Reader's available events are currently deferred. See Defer().
+//
+// r.d.mu must be held.
+func (r *Reader[T]) deferred() bool {
+	return r.deferral != nil && r.d.nextSeq <= r.deferral.nextSeq
+}
+
+// available returns whether the Reader has an event available that it isn't deferring - i.e.,
+// whether it should be woken.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) available() bool {
+	return (r.hasPending() || r.hasQueued()) && !r.deferred()
+}
+
+// endDeferral ends the Reader's deferral, if it has one.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) endDeferral() {
+	if r.deferral != nil {
+		r.deferral.timer.Stop()
+		r.deferral = nil
+		r.publishPosition()
+	}
+}
@@ -0,0 +1,67 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestDefer(t *testing.T) {
+	clock := newFakeClock()
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	other := d.Subscribe()
+	defer other.Unsubscribe()
+
+	allConsumed := d.Submit(MyEvent{id: 1})
+	other.Consume()
+
+	r.Defer(time.Second)
+	wait := r.WaitChan()
+	nowNotReady(t, wait)
+	require.False(t, r.Signal().Ready())
+
+	t.Log("the deferred event is still held")
+	nowNotReady(t, allConsumed)
+
+	t.Log("the deferral ends once the duration has elapsed")
+	clock.Advance(time.Second)
+	nowReady(t, wait)
+	require.Equal(t, []int{1}, drainIDs(&r))
+	nowReady(t, allConsumed)
+
+	t.Log("the deferral ends when a newer event is submitted")
+	d.Submit(MyEvent{id: 2})
+	r.Defer(time.Second)
+	wait = r.WaitChan()
+	nowNotReady(t, wait)
+	d.Submit(MyEvent{id: 3})
+	nowReady(t, wait)
+	require.Equal(t, []int{2, 3}, drainIDs(&r))
+
+	t.Log("the deferral can be canceled")
+	d.Submit(MyEvent{id: 4})
+	cancel := r.Defer(time.Second)
+	waited := make(chan error)
+	go func() { waited <- r.Signal().Wait(context.Background()) }()
+	cancel()
+	require.NoError(t, <-waited)
+	require.Equal(t, []int{4}, drainIDs(&r))
+
+	t.Log("consuming ends the deferral")
+	d.Submit(MyEvent{id: 5})
+	d.Submit(MyEvent{id: 6})
+	r.Defer(time.Second)
+	notReady(t, r)
+	require.Equal(t, 5, r.Consume().id)
+	ready(t, r)
+	require.Equal(t, []int{6}, drainIDs(&r))
+}
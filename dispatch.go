@@ -0,0 +1,178 @@
+package eventdistributor
+
+import (
+	"sync"
+)
+
+// callbackMutex is the Distributor's lock. It's a sync.Mutex that also runs the user callbacks
+// queued while it was held, once it's released, so that callbacks can safely call methods on the
+// Distributor - for example, to submit a follow-up event from OnFullyConsumed.
+//
+// Queued callbacks run one at a time, in the order they were queued, no matter how many goroutines
+// release the lock: whichever releases it first runs the queued callbacks, including those queued
+// by other goroutines in the meantime, until there are none left. So, the callbacks for an event
+// are never reordered with those of events that were processed after it.
+//
+// The zero value is an unlocked callbackMutex with no queued callbacks.
+type callbackMutex struct {
+	mu sync.Mutex
+
+	// queued is the callbacks waiting to run, in order. It is protected by mu.
+	queued []func()
+	// dispatching is whether some goroutine is running queued callbacks. It is protected by mu.
+	dispatching bool
+	// onPanic, if not nil, is called with the value recovered from any callback that panics. It is
+	// set once, when the Distributor is created. See OnCallbackPanic.
+	onPanic func(recovered any)
+	// publisher, if not nil, has events that were published without the lock, which are added to
+	// the buffer once it's released. It is set once, when the Distributor is created. See
+	// WithSingleProducer.
+	publisher lockFreePublisher
+}
+
+// Lock locks the mutex, like (*sync.Mutex).Lock()
+func (m *callbackMutex) Lock() {
+	m.mu.Lock()
+}
+
+// Unlock unlocks the mutex, and then runs any queued callbacks, unless another goroutine is
+// already running them.
+func (m *callbackMutex) Unlock() {
+	if m.publisher != nil {
+		m.unlockPublisher()
+		return
+	}
+	m.unlockAndDispatch()
+}
+
+// unlockAndDispatch implements Unlock
+func (m *callbackMutex) unlockAndDispatch() {
+	if m.dispatching || len(m.queued) == 0 {
+		m.mu.Unlock()
+		return
+	}
+
+	m.dispatching = true
+	for len(m.queued) != 0 {
+		calls := m.queued
+		m.queued = nil
+		m.mu.Unlock()
+		m.run(calls)
+		m.mu.Lock()
+	}
+	m.dispatching = false
+	m.mu.Unlock()
+}
+
+// run runs the callbacks, with m.mu released. If a callback panics, the callbacks after it are
+// queued again, to run the next time the mutex is unlocked, before the panic continues.
+func (m *callbackMutex) run(calls []func()) {
+	i := 0
+	defer func() {
+		if i != len(calls) {
+			m.mu.Lock()
+			m.queued = append(calls[i+1:], m.queued...)
+			m.dispatching = false
+			m.mu.Unlock()
+		}
+	}()
+
+	for ; i < len(calls); i++ {
+		calls[i]()
+	}
+}
+
+// queue adds a callback to run once the mutex is unlocked.
+//
+// m must be locked.
+func (m *callbackMutex) queue(f func()) {
+	m.queued = append(m.queued, f)
+}
+
+// unlockQuietly unlocks the mutex without running the queued callbacks, so that they can be run
+// later by flush() - for example, once some other lock is released.
+func (m *callbackMutex) unlockQuietly() {
+	m.mu.Unlock()
+}
+
+// flush runs any queued callbacks that weren't run by unlockQuietly().
+//
+// m must not be locked.
+func (m *callbackMutex) flush() {
+	m.Lock()
+	m.Unlock()
+}
+
+// OnCallbackPanic sets a function to handle panics in callbacks (OnSubmit, OnFullyConsumed, etc.):
+// each callback is called with a deferred recover, and if it panics, handler is called with the
+// recovered value, before the remaining callbacks are called as usual.
+//
+// Without OnCallbackPanic, a panicking callback panics in whichever call to the Distributor or its
+// Readers ends up calling it. Either way, the change that the callback reports has already been
+// made - callbacks are only called after the Distributor's lock is released - so a panicking
+// callback never leaves the Distributor in an inconsistent state.
+//
+// OnArchiveError callbacks are called by the Archiver's background goroutine, and aren't covered.
+// If OnCallbackPanic is set more than once, the last call takes precedence.
+func (o *Options[T]) OnCallbackPanic(handler func(recovered any)) {
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.mu.onPanic = handler
+	})
+}
+
+// runCallbacks queues each of the callbacks to be called with v, once m is unlocked.
+//
+// m must be locked.
+func runCallbacks[A any](m *callbackMutex, fs []func(A), v A) {
+	if len(fs) != 0 {
+		m.queue(func() {
+			for _, f := range fs {
+				callSafely(m.onPanic, f, v)
+			}
+		})
+	}
+}
+
+// runCallbacks2 is like runCallbacks, but for callbacks with two arguments
+func runCallbacks2[A, B any](m *callbackMutex, fs []func(A, B), a A, b B) {
+	if len(fs) != 0 {
+		m.queue(func() {
+			for _, f := range fs {
+				callSafely2(m.onPanic, f, a, b)
+			}
+		})
+	}
+}
+
+// queueCallback2 returns a function that queues a call to f, once m is unlocked. It's for
+// callbacks that are stored separately from the Distributor, and are called without access to m.
+func queueCallback2[A, B any](m *callbackMutex, f func(A, B)) func(A, B) {
+	return func(a A, b B) {
+		m.queue(func() { callSafely2(m.onPanic, f, a, b) })
+	}
+}
+
+// callSafely calls f with v, passing the value recovered from any panic to onPanic, if it's not
+// nil. See OnCallbackPanic.
+func callSafely[A any](onPanic func(any), f func(A), v A) {
+	if onPanic != nil {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				onPanic(recovered)
+			}
+		}()
+	}
+	f(v)
+}
+
+// callSafely2 is like callSafely, but for callbacks with two arguments
+func callSafely2[A, B any](onPanic func(any), f func(A, B), a A, b B) {
+	if onPanic != nil {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				onPanic(recovered)
+			}
+		}()
+	}
+	f(a, b)
+}
@@ -0,0 +1,178 @@
+package eventdistributor_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestCallbacksCanCallDistributor(t *testing.T) {
+	cases := []struct {
+		name     string
+		register func(o *eventdistributor.Options[MyEvent], followUp func())
+	}{
+		{"OnSubmit", func(o *eventdistributor.Options[MyEvent], followUp func()) {
+			o.OnSubmit(func(MyEvent) { followUp() })
+		}},
+		{"OnFullyConsumed", func(o *eventdistributor.Options[MyEvent], followUp func()) {
+			o.OnFullyConsumed(func(MyEvent) { followUp() })
+		}},
+		{"OnBufsizeChange", func(o *eventdistributor.Options[MyEvent], followUp func()) {
+			o.OnBufsizeChange(func(int) { followUp() })
+		}},
+		{"OnConsume", func(o *eventdistributor.Options[MyEvent], followUp func()) {
+			o.OnConsume(func(MyEvent, uint64) { followUp() })
+		}},
+		{"OnConsumedWithLatency", func(o *eventdistributor.Options[MyEvent], followUp func()) {
+			o.OnConsumedWithLatency(func(MyEvent, time.Duration) { followUp() })
+		}},
+		{"OnDrop", func(o *eventdistributor.Options[MyEvent], followUp func()) {
+			o.OnDrop(func(MyEvent, eventdistributor.DropReason) { followUp() })
+		}},
+		{"OnFirstSubscriber", func(o *eventdistributor.Options[MyEvent], followUp func()) {
+			o.OnFirstSubscriber(followUp)
+		}},
+		{"OnLastUnsubscribed", func(o *eventdistributor.Options[MyEvent], followUp func()) {
+			o.OnLastUnsubscribed(followUp)
+		}},
+		{"OnReaderLag", func(o *eventdistributor.Options[MyEvent], followUp func()) {
+			o.OnReaderLag(1, func(uint64, int) { followUp() })
+		}},
+		{"OnWaitStart", func(o *eventdistributor.Options[MyEvent], followUp func()) {
+			o.OnWaitStart(func(uint64) { followUp() })
+		}},
+		{"OnOrderViolation", func(o *eventdistributor.Options[MyEvent], followUp func()) {
+			less := func(a, b MyEvent) bool { return a.id < b.id }
+			o.WithOrderCheck(less, func(prev, next MyEvent) { followUp() })
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var d *eventdistributor.Distributor[MyEvent]
+			var submitted []int
+			var options eventdistributor.Options[MyEvent]
+			options.OnSubmit(func(e MyEvent) { submitted = append(submitted, e.id) })
+			followedUp := false
+			c.register(&options, func() {
+				// The follow-up is only submitted once, so that it doesn't trigger itself forever.
+				if !followedUp {
+					followedUp = true
+					d.Stats()
+					d.Submit(MyEvent{id: 100})
+				}
+			})
+			d = eventdistributor.New(options)
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+
+				r := d.Subscribe()
+				_ = r.WaitChan()
+				d.Submit(MyEvent{id: 2})
+				d.Submit(MyEvent{id: 1})
+				drainIDs(&r)
+				d.Submit(MyEvent{id: 3})
+				d.FilterInPlace(func(MyEvent) bool { return true })
+				r.Unsubscribe()
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("deadlocked")
+			}
+			require.True(t, followedUp)
+			require.Contains(t, submitted, 100)
+		})
+	}
+}
+
+func TestCallbackOrder(t *testing.T) {
+	var running atomic.Int32
+	var submitted, fullyConsumed []int
+	var options eventdistributor.Options[MyEvent]
+	options.OnSubmit(func(e MyEvent) {
+		require.Equal(t, int32(1), running.Add(1), "callbacks ran concurrently")
+		submitted = append(submitted, e.id)
+		running.Add(-1)
+	})
+	options.OnFullyConsumed(func(e MyEvent) {
+		require.Equal(t, int32(1), running.Add(1), "callbacks ran concurrently")
+		fullyConsumed = append(fullyConsumed, e.id)
+		running.Add(-1)
+	})
+	d := eventdistributor.New(options)
+
+	const producers, perProducer = 4, 250
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				d.Submit(MyEvent{id: p*perProducer + i})
+			}
+		}(p)
+	}
+	wg.Wait()
+	// Callbacks queued by one producer may be called by another, but they've all been called once
+	// every producer has returned.
+	require.Len(t, submitted, producers*perProducer)
+
+	t.Log("without Readers, each event's callbacks are in order")
+	require.Equal(t, submitted, fullyConsumed)
+}
+
+func TestOnCallbackPanic(t *testing.T) {
+	var recovered []any
+	var sizes []int
+	var options eventdistributor.Options[MyEvent]
+	options.OnBufsizeChange(func(size int) { panic(size) })
+	options.OnBufsizeChange(func(size int) { sizes = append(sizes, size) })
+	options.OnCallbackPanic(func(r any) { recovered = append(recovered, r) })
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	waitChan := r.WaitChan()
+
+	t.Log("a panicking OnBufsizeChange doesn't stop waiters from being woken")
+	require.NotPanics(t, func() { d.Submit(MyEvent{id: 1}) })
+	nowReady(t, waitChan)
+	require.Equal(t, []any{1}, recovered)
+
+	t.Log("callbacks after the panicking one are still called")
+	require.Equal(t, []int{1}, sizes)
+	require.Equal(t, []int{1}, drainIDs(&r))
+	require.Equal(t, []any{1, 0}, recovered)
+	require.Equal(t, []int{1, 0}, sizes)
+}
+
+func TestCallbackPanicPropagates(t *testing.T) {
+	var sizes []int
+	var options eventdistributor.Options[MyEvent]
+	options.OnBufsizeChange(func(size int) {
+		sizes = append(sizes, size)
+		if size == 1 {
+			panic("bufsize 1")
+		}
+	})
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	waitChan := r.WaitChan()
+
+	t.Log("without OnCallbackPanic, the panic propagates, but the event is still submitted")
+	require.PanicsWithValue(t, "bufsize 1", func() { d.Submit(MyEvent{id: 1}) })
+	nowReady(t, waitChan)
+	require.Equal(t, []int{1}, drainIDs(&r))
+	require.Equal(t, []int{1, 0}, sizes)
+}
@@ -1,27 +1,215 @@
 package eventdistributor
 
 import (
-	"sync"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
 )
 
 type Distributor[T any] struct {
-	mu sync.Mutex
+	mu callbackMutex
 
 	basePosition int64
 	buf          []eventInfo[T]
+	// storage is the backing array of buf, which is reused as events enter and leave the buffer.
+	// See compactBuf.
+	storage []eventInfo[T]
+	// bufCapacity is the minimum size of storage, if set by WithBufferCapacity
+	bufCapacity int
+	// fixed, if not nil, is the pre-allocated storage for buf, and its overflow policy. See
+	// NewFixed().
+	fixed *fixedStorage[T]
 
 	nextRefcount int64
 	waiters      chan struct{}
+	// ownWaiters is the set of Readers that are waiting with their own channel instead of waiters,
+	// because they may need to be woken individually. See (*Reader[T]).waitChan().
+	ownWaiters []*readerState[T]
+	// fastTail is the end of the buffer, and fastFilter is odd while FilterInPlace() is removing
+	// events from it, so that WaitChan() can check for an available event without the lock. See
+	// (*Reader[T]).availableFast().
+	fastTail   atomic.Int64
+	fastFilter atomic.Uint64
 
-	onBufsizeChange []func(size int)
-	onSubmit        []func(item T)
-	onFullyConsumed []func(item T)
+	// nextSeq is the sequence number that will be assigned to the next submitted event
+	nextSeq int64
+	// readers is the set of all active Readers
+	readers []*readerState[T]
+	// numFiltered is the number of Readers in readers that have a filter. See SubscribeFiltered().
+	numFiltered int
+	// handlers is the set of handlers that may receive events directly from producers, and
+	// directCalls is the list of handlers claimed by the event being submitted. See
+	// SubscribeHandler().
+	handlers    []*handlerSub[T]
+	directCalls *handlerSub[T]
+	// managed is the set of running handlers created by AddHandler() or AddHandlerFunc(). See
+	// StopHandlers().
+	managed []*managedHandler[T]
+
+	onBufsizeChange callbackList[int]
+	onSubmit        callbackList[T]
+	onFullyConsumed callbackList[T]
+	onConsume       []func(item T, readerID uint64)
+	onLatency       callbackList[consumedLatency[T]]
+	onDrop          []func(item T, reason DropReason)
+	onLate          []func(item T)
+	onReject        []func(item T, reason error)
+	onCancelled     []func(item T)
+	onDiscarded     []func(item T)
+	onWaitStart     []func(readerID uint64)
+	onWaitEnd       []func(readerID uint64, waited time.Duration)
+	// onFirstSub and onLastUnsub take struct{} so that they can share the helpers used by other
+	// callbacks. See OnFirstSubscriber.
+	onFirstSub  []func(struct{})
+	onLastUnsub []func(struct{})
+
+	watchdog *callbackWatchdog
+
+	clock      Clock
+	timestamps bool
+	// lastReleased is the latest timestamp of any event that has left the buffer, if timestamps
+	// are recorded. See SubscribeSince().
+	lastReleased time.Time
+
+	// keyIndex, if not nil, tracks the latest value for each key. See WithKeyFunc.
+	keyIndex keyIndexer[T]
+	// orderCheck, if not nil, checks the ordering of submitted events. See WithOrderCheck.
+	orderCheck orderChecker[T]
+	// sorter, if not nil, holds submitted events so that they're added to the buffer in order. See
+	// WithSortWindow.
+	sorter *sortWindow[T]
+	// orderBy, if not nil, makes every Reader receive its highest-priority unseen event first. See
+	// WithOrderBy.
+	orderBy func(a, b T) bool
+	// coalesce, if not nil, merges submitted events into the newest buffered event. See
+	// WithCoalesce.
+	coalesce func(older, newer T) (merged T, ok bool)
+	// conflate, if not nil, replaces buffered events with newer ones with the same key. See
+	// WithConflateBy.
+	conflate conflater[T]
+	// dedup, if not nil, drops submitted events that are equal to buffered ones. See
+	// WithDedupPending.
+	dedup *dedupPending[T]
+	// retainLast is whether the most recently submitted event is retained, in retained, for new
+	// Readers. See WithRetainLast.
+	retainLast bool
+	retained   *ArchivedEvent[T]
+	// history, if not nil, keeps the most recent events. See WithHistory.
+	history *eventHistory[T]
+	// ttl, if not nil, drops events that have been buffered for too long. See WithEventTTL.
+	ttl *eventTTL
+	// gate, if not nil, holds the gates and the events waiting for them. See SubscribeGate().
+	gate *gateStage[T]
+
+	// limiter, if not nil, limits the rate of submitted events, according to throttlePolicy. See
+	// WithSubmitLimiter.
+	limiter        Limiter
+	throttlePolicy ThrottlePolicy
+
+	// observers are the taps registered with Observe()
+	observers []*observer[T]
+
+	// pins tracks the pinned events by sequence number, and numPins is the total number of pins.
+	// See Pin().
+	pins    map[int64]*pinnedEvent
+	numPins int
+	// maxPins is the maximum value of numPins, or zero to use defaultMaxPins
+	maxPins int
+
+	// maxReaderLag, if non-zero, is the maximum number of events any Reader can be behind. See
+	// WithMaxReaderLag.
+	maxReaderLag int
+	// lagWatches are the callbacks registered with OnReaderLag
+	lagWatches []*lagWatch
+	// idleWatches are the callbacks registered with OnIdle
+	idleWatches []*idleWatch
+	// setIdle, if not nil, is queued whenever the Distributor has no Readers and nothing buffered,
+	// so that the DistributorSet it belongs to can remove it. See checkSetIdle.
+	setIdle func()
+	// skipOverLag, if non-zero, is the maximum number of events any Reader can be behind before it
+	// skips ahead. See WithSkipOverLag.
+	skipOverLag int
+	// evictOverLag, if non-zero, is the maximum number of events any Reader can be behind before
+	// it's evicted. See WithEvictOverLag.
+	evictOverLag int
+	// producerWait, if not nil, is closed when a Reader moves forward, to wake producers waiting
+	// for lagging Readers
+	producerWait chan struct{}
+	// nextReaderID is the ID of the most recently created Reader. See (*Reader[T]).ID().
+	nextReaderID uint64
+
+	// singleProducer is true if events must only be submitted by one goroutine at a time, and
+	// producing is true while an event is being submitted, in builds with the race detector.
+	// publisher holds the events published by the producer without the lock, and fastPublish is
+	// true while it can be used. See WithSingleProducer.
+	singleProducer bool
+	producing      atomic.Bool
+	publisher      *publishRing[T]
+	fastPublish    atomic.Bool
+
+	// callerDepth is the maximum number of stack frames captured for each submitted event, or zero
+	// if callers are not captured. See WithCallerCapture.
+	callerDepth int
+	// subscribeDepth is the maximum number of stack frames captured for each new Reader, or zero if
+	// they're not captured. See WithSubscribeCapture.
+	subscribeDepth int
+
+	// archive, if not nil, stores events that leave the buffer. See WithArchiver.
+	archive *archiveQueue[T]
+	// owned, if not nil, collects the values of fully consumed events while a Reader is being
+	// unsubscribed. See UnsubscribeOwned().
+	owned *[]T
+	// holdCleanup is true while an event is being delivered to a Reader, so that it isn't removed
+	// from the buffer until OnConsume callbacks have been called. See (*Reader[T]).deliver().
+	holdCleanup bool
+
+	// leakCheck, if not nil, is called with each Reader that's garbage collected without being
+	// unsubscribed. See WithLeakCheck.
+	leakCheck func(readerID uint64, stack []byte)
+
+	// meta, if not nil, is the Distributor returned by Events()
+	meta *Distributor[MetaEvent]
+
+	// seed, if not nil, is a registered Reader holding the events copied into a fork, until it's
+	// taken by the first call to Subscribe(). See ForkAt().
+	seed *readerState[T]
+
+	// sealed is true if the Distributor no longer accepts new events. See Seal().
+	sealed bool
+	// closed is true if the Distributor has been closed. See Close(). If closed is true, so is
+	// sealed.
+	closed bool
+	// drained, if not nil, is closed once the buffer is empty. See WaitForDrain().
+	drained chan struct{}
+
+	// stats holds the Distributor's counters. Its Bufsize and Readers are only set in the copy
+	// returned by Stats().
+	stats Stats
 }
 
 type eventInfo[T any] struct {
-	refcount    int64
-	value       T
+	// seq is the sequence number of the event. Unlike positions, which may shift as events are
+	// removed from the buffer, sequence numbers are fixed for the lifetime of the event.
+	seq      int64
+	refcount int64
+	value    T
+	// allConsumed is closed once the event is fully consumed. It is nil if the event was submitted
+	// with SubmitQuiet().
 	allConsumed chan struct{}
+	// timestamp is the time at which the event was submitted, if the Distributor records
+	// timestamps. Otherwise, it is the zero value.
+	timestamp time.Time
+	// caller is the call stack that submitted the event, if the Distributor captures callers. See
+	// WithCallerCapture.
+	caller []uintptr
+	// onDone, if not nil, is called once the event is dropped from the buffer. See
+	// SubmitWithCallback().
+	onDone func(struct{})
+	// token, if not nil, identifies the event for as long as it's in the buffer. See eventToken.
+	token *eventToken
 }
 
 // New creates a new Distributor with the provided options.
@@ -29,14 +217,99 @@ type eventInfo[T any] struct {
 // If you don't have any options to set, the zero value of an Distributor is also valid.
 func New[T any](options ...Options[T]) *Distributor[T] {
 	d := &Distributor[T]{
-		mu:              sync.Mutex{},
+		mu:              callbackMutex{},
 		basePosition:    0,
 		buf:             nil,
+		storage:         nil,
+		bufCapacity:     0,
+		fixed:           nil,
 		nextRefcount:    0,
 		waiters:         nil,
-		onBufsizeChange: nil,
-		onSubmit:        nil,
-		onFullyConsumed: nil,
+		ownWaiters:      nil,
+		fastTail:        atomic.Int64{},
+		fastFilter:      atomic.Uint64{},
+		nextSeq:         0,
+		readers:         nil,
+		numFiltered:     0,
+		handlers:        nil,
+		directCalls:     nil,
+		managed:         nil,
+		onBufsizeChange: callbackList[int]{},
+		onSubmit:        callbackList[T]{},
+		onFullyConsumed: callbackList[T]{},
+		onConsume:       nil,
+		onLatency:       callbackList[consumedLatency[T]]{},
+		onDrop:          nil,
+		onLate:          nil,
+		onReject:        nil,
+		onCancelled:     nil,
+		onDiscarded:     nil,
+		onWaitStart:     nil,
+		onWaitEnd:       nil,
+		onFirstSub:      nil,
+		onLastUnsub:     nil,
+		watchdog:        nil,
+		clock:           nil,
+		timestamps:      false,
+		lastReleased:    time.Time{},
+		keyIndex:        nil,
+		orderCheck:      nil,
+		sorter:          nil,
+		orderBy:         nil,
+		coalesce:        nil,
+		conflate:        nil,
+		dedup:           nil,
+		retainLast:      false,
+		retained:        nil,
+		history:         nil,
+		ttl:             nil,
+		gate:            nil,
+		limiter:         nil,
+		throttlePolicy:  0,
+		observers:       nil,
+		pins:            nil,
+		numPins:         0,
+		maxPins:         0,
+		maxReaderLag:    0,
+		lagWatches:      nil,
+		idleWatches:     nil,
+		setIdle:         nil,
+		skipOverLag:     0,
+		evictOverLag:    0,
+		producerWait:    nil,
+		nextReaderID:    0,
+		singleProducer:  false,
+		producing:       atomic.Bool{},
+		publisher:       nil,
+		fastPublish:     atomic.Bool{},
+		callerDepth:     0,
+		subscribeDepth:  0,
+		archive:         nil,
+		owned:           nil,
+		holdCleanup:     false,
+		leakCheck:       nil,
+		meta:            nil,
+		seed:            nil,
+		sealed:          false,
+		closed:          false,
+		drained:         nil,
+		stats:           Stats{},
+	}
+
+	d.configure(options)
+	return d
+}
+
+// configure applies the options to the Distributor. Options applied to an existing Distributor
+// only affect what happens afterwards.
+//
+// d.mu must be held, unless the Distributor is still being created.
+func (d *Distributor[T]) configure(options []Options[T]) {
+	// The watchdog must be set before any callbacks are added, so that they can be wrapped.
+	for _, os := range options {
+		if os.watchdog != nil {
+			d.watchdog = os.watchdog
+		}
 	}
 
 	for _, os := range options {
@@ -44,50 +317,351 @@ func New[T any](options ...Options[T]) *Distributor[T] {
 			f(d)
 		}
 	}
-
-	return d
-}
-
-func runCallbacks[T any](fs []func(T), v T) {
-	for _, f := range fs {
-		f(v)
+	// Some options, like WithEventTTL, stop Readers from using WaitChan's lock-free check, and
+	// others stop the producer from publishing without the lock.
+	for _, r := range d.readers {
+		r.reader().publishPosition()
 	}
+	d.updateFastPublish()
+	// The buffer may already be empty, and any new OnIdle callbacks should count from now.
+	d.startIdle()
 }
 
 // Submit adds an event to the queue, notifying any waiting Readers.
 //
 // The returned channel is closed when no remaining Readers are able
-// to consume the value - either by Consume() or Unsubscribe().
+// to consume the value - either by Consume() or Unsubscribe(). For Distributors created by
+// NewFixed(), consumption isn't tracked, and the returned channel is nil if the event was buffered.
+//
+// If the event isn't buffered - for example, because there are no Readers - the returned channel
+// is already closed, and no channel is allocated. Otherwise, each event gets its own channel: each
+// one is closed at a different time, and a closed channel can't be reopened, so they can't be
+// shared or reused. Producers that ignore the returned channel should use SubmitQuiet() instead,
+// which doesn't allocate.
+//
+// If the Distributor has a submit limiter, Submit may block or drop the event when it is
+// throttled. See (*Options[T]).WithSubmitLimiter(). Submit may also block while a Reader is too far
+// behind. See (*Options[T]).WithMaxReaderLag().
 //
 // Submit is thread-safe.
 func (d *Distributor[T]) Submit(value T) <-chan struct{} {
+	allConsumed, _ := d.submitBlocking(value, d.captureCaller(), true, nil, nil, nil)
+	return allConsumed
+}
+
+// SubmitQuiet is like Submit, but doesn't track when the event has been fully consumed. It is
+// cheaper than Submit - avoiding an allocation for each buffered event - for callers that would
+// ignore the returned channel anyway.
+//
+// Callbacks like OnFullyConsumed and OnDrop are still called as usual.
+//
+// SubmitQuiet is thread-safe.
+func (d *Distributor[T]) SubmitQuiet(value T) {
+	d.submitBlocking(value, d.captureCaller(), false, nil, nil, nil)
+}
+
+// SubmitWithCallback is like SubmitQuiet, but calls onDone once the event is dropped from the
+// buffer - for example, to release resources that are only needed while the event is buffered.
+//
+// onDone is called exactly once, for whatever reason the event leaves the buffer: because it was
+// fully consumed, or dropped for one of the reasons passed to OnDrop callbacks. This includes
+// events that are never buffered at all, like those discarded immediately because there are no
+// Readers, or dropped because the Distributor was sealed. Like other callbacks, onDone is called
+// once the Distributor's lock is released, and after the OnFullyConsumed or OnDrop callbacks for
+// the event.
+//
+// If onDone is nil, SubmitWithCallback is the same as SubmitQuiet.
+//
+// SubmitWithCallback is thread-safe.
+func (d *Distributor[T]) SubmitWithCallback(value T, onDone func()) {
+	var done func(struct{})
+	if onDone != nil {
+		done = func(struct{}) { onDone() }
+	}
+	d.submitBlocking(value, d.captureCaller(), false, done, nil, nil)
+}
+
+// submitBlocking implements Submit and SubmitQuiet, for an event submitted from caller. If track is
+// false, the returned channel is nil. If onDone is not nil, it's called once the event is dropped
+// from the buffer, and token is attached to the event if it's buffered. See submit.
+//
+// If skip is not nil, it is called with d.mu held just before the event would be added, and if it
+// returns true, the event is not submitted. submitBlocking returns whether the event was submitted
+// - i.e., it wasn't skipped, throttled, or dropped because the Distributor was sealed or its buffer
+// was full.
+func (d *Distributor[T]) submitBlocking(
+	value T,
+	caller []uintptr,
+	track bool,
+	onDone func(struct{}),
+	token *eventToken,
+	skip func() bool,
+) (<-chan struct{}, bool) {
+	d.enterProducer()
+	defer d.exitProducer()
+
+	if skip == nil {
+		if allConsumed, ok := d.publishFast(value, caller, track, onDone, token); ok {
+			return allConsumed, true
+		}
+	}
+	return d.submitLocked(value, caller, track, onDone, token, skip)
+}
+
+// submitLocked implements submitBlocking, for events that are submitted while holding the lock,
+// instead of being published without it. See WithSingleProducer.
+//
+// d.mu must NOT be held, and the call must be between enterProducer and exitProducer.
+func (d *Distributor[T]) submitLocked(
+	value T,
+	caller []uintptr,
+	track bool,
+	onDone func(struct{}),
+	token *eventToken,
+	skip func() bool,
+) (<-chan struct{}, bool) {
+	if err := d.throttle(context.Background()); err != nil {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		d.absorbPublished()
+		d.noteSubmitted(value)
+		d.dropped(value, -1, DropReasonThrottled)
+		d.finish(nil, onDone)
+		return closedChannel, false
+	}
+
+	// Direct calls are made once the lock is released. See SubscribeHandler().
+	var directCalls *handlerSub[T]
+	defer func() { runDirectCalls(directCalls) }()
+
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	runCallbacks(d.onSubmit, value)
+	d.absorbPublished()
+	// Without a deadline, this can't fail.
+	_ = d.waitForLaggingReaders(context.Background(), true)
+
+	if skip != nil && !d.sealed && skip() {
+		d.finish(nil, onDone)
+		return closedChannel, false
+	}
+
+	submitted := !d.sealed && d.hasRoom()
+	allConsumed, _ := d.submit(value, caller, nil, onDone, token, track, true)
+	directCalls = d.takeDirectCalls()
+	return allConsumed, submitted
+}
+
+// submit implements Submit, additionally returning the sequence number of the new event, or -1 if
+// it was immediately discarded or held by the sort window or gates. caller is the captured call
+// site of the submission, if any. See WithCallerCapture.
+//
+// If allConsumed is not nil, it is used for the event instead of allocating a new channel, and
+// closed if the event is discarded. Otherwise, if track is false, or the Distributor was created by
+// NewFixed(), no channel is allocated to signal that the event was fully consumed, and the returned
+// channel is nil if the event was buffered. See SubmitQuiet().
+//
+// If onDone is not nil, it's called once the event is dropped from the buffer. See
+// SubmitWithCallback(). If token is not nil, it identifies the event once it's buffered - including
+// if it was held by the sort window or gates first. See eventToken.
+//
+// If direct is true, the event may be claimed by handlers for direct delivery, which the caller
+// must then make with runDirectCalls, after releasing d.mu. See SubscribeHandler().
+//
+// d.mu must be held.
+func (d *Distributor[T]) submit(
+	value T,
+	caller []uintptr,
+	allConsumed chan struct{},
+	onDone func(struct{}),
+	token *eventToken,
+	track bool,
+	direct bool,
+) (<-chan struct{}, int64) {
+	d.noteSubmitted(value)
+
+	if d.sealed {
+		d.dropped(value, -1, DropReasonSealed)
+		d.finish(allConsumed, onDone)
+		return closedChannel, -1
+	}
+
+	if s := d.sorter; s != nil {
+		if !s.isLate(value) {
+			return d.stage(value, caller, allConsumed, onDone, token, track), -1
+		}
+		runCallbacks(&d.mu, d.onLate, value)
+	}
+	return d.admit(value, caller, allConsumed, onDone, token, track, direct)
+}
+
+// add implements submit, once the event is ready to be added to the buffer. If allConsumed is not
+// nil, it is used for the event instead of allocating a new channel, and closed if the event is
+// discarded, along with calling onDone. See stage and admit.
+//
+// d.mu must be held.
+func (d *Distributor[T]) add(
+	value T,
+	caller []uintptr,
+	allConsumed chan struct{},
+	onDone func(struct{}),
+	token *eventToken,
+	track bool,
+	direct bool,
+) (<-chan struct{}, int64) {
+	d.endIdle()
+	// If the event doesn't stay in the buffer, a new idle period starts right away.
+	defer d.startIdle()
+
+	d.expireEvents()
+	if i, ok := d.findDuplicate(value); ok {
+		d.stats.Deduped += 1
+		d.finish(allConsumed, onDone)
+		if dup := d.buf[i]; dup.allConsumed != nil {
+			return dup.allConsumed, dup.seq
+		}
+		return closedChannel, d.buf[i].seq
+	}
+	if !d.makeRoom() {
+		d.dropped(value, -1, DropReasonOverflow)
+		d.finish(allConsumed, onDone)
+		return closedChannel, -1
+	}
+
+	if d.orderCheck != nil {
+		d.orderCheck.check(value)
+	}
+	if d.keyIndex != nil {
+		d.keyIndex.update(value, d.nextSeq)
+	}
+	d.notifyObservers(value)
+
+	seq := d.nextSeq
+	d.nextSeq += 1
+
+	// Filtered Readers that don't match the event skip it immediately, so that they don't hold it
+	// in the buffer.
+	rejected := d.filterAtSubmit(value)
+	if direct {
+		rejected += d.claimAtSubmit(value)
+	}
+
+	var timestamp time.Time
+	if d.timestamps {
+		timestamp = d.getClock().Now()
+	}
+	d.retain(seq, timestamp, value)
+	if d.history != nil {
+		d.history.add(seq, timestamp, value)
+	}
 
 	// If there's no readers waiting, then we should immediately discard the event.
-	if len(d.buf) == 0 && d.nextRefcount == 0 {
-		runCallbacks(d.onFullyConsumed, value)
-		return closedChannel
+	if len(d.buf) == 0 && d.nextRefcount == rejected {
+		if rejected != 0 {
+			d.unfilterAtSubmit()
+		}
+		runCallbacks(&d.mu, d.onFullyConsumed.fs, value)
+		runCallbacks(&d.mu, d.onLatency.fs, consumedLatency[T]{item: value, inBuffer: 0})
+		d.stats.FullyConsumed += 1
+		d.stats.Discarded += 1
+		d.archiveEvent(seq, timestamp, value)
+		d.noteReleased(timestamp)
+		d.finish(allConsumed, onDone)
+		return closedChannel, -1
 	}
 
-	allConsumed := make(chan struct{})
+	if allConsumed == nil && track && d.fixed == nil {
+		allConsumed = make(chan struct{})
+	}
 
-	d.buf = append(d.buf, eventInfo[T]{
-		refcount:    d.nextRefcount,
+	e := eventInfo[T]{
+		seq:         seq,
+		refcount:    d.nextRefcount - rejected,
 		value:       value,
 		allConsumed: allConsumed,
-	})
-	d.nextRefcount = 0
+		timestamp:   timestamp,
+		caller:      caller,
+		onDone:      onDone,
+		token:       token,
+	}
+	if rejected == 0 {
+		if d.coalesceLast(e) {
+			return allConsumed, seq
+		} else if replaced, ok := d.conflateBuffered(e); ok {
+			return allConsumed, replaced
+		}
+	}
+
+	d.compactBuf()
+	d.buf = append(d.buf, e)
+	d.publishTail()
+	d.nextRefcount = rejected
+	d.skipLaggingReaders()
+	d.evictLaggingReaders()
+	d.wakeWaiters()
+	d.checkLags()
+
+	if len(d.buf) > d.stats.MaxBufsize {
+		d.stats.MaxBufsize = len(d.buf)
+	}
+	runCallbacks(&d.mu, d.onBufsizeChange.fs, len(d.buf))
+	d.armTTLTimer(timestamp)
+
+	return allConsumed, seq
+}
+
+// finish closes the allConsumed channel of an event that was dropped from the buffer - or never
+// added to it - and calls its onDone callback, once d.mu is released. Either may be nil.
+//
+// d.mu must be held.
+func (d *Distributor[T]) finish(allConsumed chan struct{}, onDone func(struct{})) {
+	if allConsumed != nil {
+		close(allConsumed)
+	}
+	if onDone != nil {
+		d.mu.queue(func() { callSafely(d.mu.onPanic, onDone, struct{}{}) })
+	}
+}
+
+// wakeWaiters wakes all Readers waiting for a new event, or all waiting Readers if the Distributor
+// is closed.
+//
+// d.mu must be held.
+func (d *Distributor[T]) wakeWaiters() {
+	d.wakeShared()
+
+	// Readers with their own channel are only woken if they have something to receive, because a
+	// filtered Reader may have skipped the new event.
+	kept := d.ownWaiters[:0]
+	for _, r := range d.ownWaiters {
+		if !d.closed && !r.reader().hasPending() {
+			kept = append(kept, r)
+			continue
+		}
+		r.reader().wakeOwnWaiter()
+		// A Group's Reader keeps waiting while it has other members waiting. See
+		// (*groupState[T]).wake().
+		if r.reader().isOwnWaiter() {
+			kept = append(kept, r)
+		}
+	}
+	for i := len(kept); i < len(d.ownWaiters); i++ {
+		d.ownWaiters[i] = nil
+	}
+	d.ownWaiters = kept
+
+	d.endWaits()
+}
+
+// wakeShared wakes every Reader waiting on the shared d.waiters channel.
+//
+// d.mu must be held.
+func (d *Distributor[T]) wakeShared() {
 	if d.waiters != nil {
 		close(d.waiters)
 		d.waiters = nil
 	}
-
-	runCallbacks(d.onBufsizeChange, len(d.buf))
-
-	return allConsumed
 }
 
 // Subscribe creates a new Reader to receive future events from the Distributor.
@@ -95,21 +669,183 @@ func (d *Distributor[T]) Submit(value T) <-chan struct{} {
 // It is STRONGLY recommended to defer (*Reader[T]).Unsubscribe() immediately after
 // subscribing.
 //
+// If the Distributor retains the last event, the Reader receives it first. See WithRetainLast.
+//
 // Subscribe is thread-safe.
 func (d *Distributor[T]) Subscribe() Reader[T] {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	d.nextRefcount += 1
-	return Reader[T]{
-		d:        d,
-		position: d.basePosition + int64(len(d.buf)),
+	// The seed of a fork already has the events it should receive. See ForkAt().
+	seeded := d.seed != nil
+	r := d.subscribe()
+	// The retained event was already submitted, so it's received like an archived one.
+	if d.retained != nil && !d.closed && !seeded {
+		r.backfill = []ArchivedEvent[T]{*d.retained}
+	}
+	return r
+}
+
+// subscribe implements Subscribe.
+//
+// d.mu must be held.
+func (d *Distributor[T]) subscribe() Reader[T] {
+	if d.seed != nil {
+		// The first Reader takes over the seed, so that it receives the copied events. See
+		// ForkAt().
+		r := d.newReader(d.seed)
+		d.seed = nil
+		r.orderByPriority()
+		d.emitMeta(MetaEvent{Kind: MetaSubscribe, Reader: r.readerInfo()})
+		if d.numReaders() == 1 {
+			runCallbacks(&d.mu, d.onFirstSub, struct{}{})
+		}
+		return r
+	}
+
+	d.nextReaderID += 1
+	r := &readerState[T]{
+		id:            d.nextReaderID,
+		d:             d,
+		position:      d.basePosition + int64(len(d.buf)),
+		fastPos:       atomic.Int64{},
+		registryIdx:   len(d.readers),
+		firstSeq:      d.nextSeq,
+		maxAge:        0,
+		skipped:       0,
+		skippedTaken:  0,
+		consumed:      0,
+		lastConsumed:  time.Time{},
+		expiry:        nil,
+		evicted:       false,
+		unsubscribed:  false,
+		waitCh:        nil,
+		signal:        nil,
+		signalWaiting: false,
+		notify:        nil,
+		notifyWaiting: false,
+		waitStart:     time.Time{},
+		replay:        nil,
+		caughtUp:      nil,
+		filter:        nil,
+		acks:          nil,
+		backfill:      nil,
+		deferral:      nil,
+		lifo:          nil,
+		onRelease:     nil,
+		subscribed:    d.captureSubscriber(),
+	}
+	r.reader().orderByPriority()
+	r.reader().publishPosition()
+
+	// Readers of a closed Distributor are not registered, because there's nothing for them to
+	// hold on to.
+	if !d.closed {
+		d.nextRefcount += 1
+		d.readers = append(d.readers, r)
+		d.emitMeta(MetaEvent{Kind: MetaSubscribe, Reader: r.reader().readerInfo()})
+		if d.numReaders() == 1 {
+			runCallbacks(&d.mu, d.onFirstSub, struct{}{})
+		}
 	}
+	return d.newReader(r)
 }
 
+// Reader receives events from a Distributor. It is created by (*Distributor[T]).Subscribe() or one
+// of its variants.
+//
+// Copies of a Reader refer to the same subscription, so a Reader can be stored and passed around by
+// value: consuming from any copy advances all of them, and the subscription holds a single
+// reference to the buffered events no matter how many copies there are.
 type Reader[T any] struct {
+	*readerState[T]
+	// leak, if not nil, tracks whether the Reader is garbage collected without being unsubscribed.
+	// See WithLeakCheck.
+	leak *readerLeak[T]
+}
+
+// readerState is the state of a Reader, shared between all copies of it
+type readerState[T any] struct {
+	// id identifies the Reader. See ID().
+	id       uint64
 	d        *Distributor[T]
 	position int64
+	// fastPos is position as seen by availableFast(). See publishPosition().
+	fastPos atomic.Int64
+	// registryIdx is the index of the Reader in d.readers
+	registryIdx int
+	// firstSeq is the sequence number of the first event the Reader could receive. Events before
+	// it were submitted before the Reader subscribed.
+	firstSeq int64
+
+	// maxAge, if non-zero, is the maximum age of events delivered to the Reader. Older events are
+	// skipped. See (*Distributor[T]).SubscribeLossyByAge().
+	maxAge time.Duration
+	// skipped is the total number of events that were skipped by the Reader, and skippedTaken is
+	// its value at the last call to TakeSkipped()
+	skipped      int64
+	skippedTaken int64
+	// consumed is the total number of events delivered to the Reader, and lastConsumed is when the
+	// last one was. See Stats().
+	consumed     int64
+	lastConsumed time.Time
+
+	// expiry, if not nil, is the expiry state of a Reader created by SubscribeFor
+	expiry *readerExpiry
+	// evicted is true if the Reader was evicted for falling too far behind. See WithEvictOverLag.
+	evicted bool
+	// unsubscribed is true once Unsubscribe() has been called. The Reader keeps its pointer to the
+	// Distributor, so that later calls fail with ErrUnsubscribed instead of a nil dereference.
+	unsubscribed bool
+
+	// waitCh, if not nil, is the channel returned by WaitChan() for a Reader that must be woken
+	// individually. If waitCh is not nil, or signalWaiting or notifyWaiting is true, the Reader is
+	// in d.ownWaiters.
+	waitCh chan struct{}
+	// signal, if not nil, is the semaphore used by the Reader's Signal, and signalWaiting is true
+	// while the Signal is waiting on it. See (*Reader[T]).Signal().
+	signal        chan struct{}
+	signalWaiting bool
+	// notify, if not nil, is called when the Reader is woken while notifyWaiting is true. It's used
+	// by the ShardedReader or Group that the Reader belongs to.
+	notify        func()
+	notifyWaiting bool
+	// waitStart, if not zero, is the time at which the Reader started waiting. See OnWaitStart.
+	waitStart time.Time
+
+	// replay, if not nil, tracks the end of the events that the Reader is replaying. See
+	// ReplayDone().
+	replay *readerReplay
+
+	// caughtUp, if not nil, is closed when the Reader becomes caught up. See WaitCaughtUp().
+	caughtUp chan struct{}
+
+	// filter, if not nil, determines which events the Reader receives. See SubscribeFiltered().
+	filter func(T) bool
+
+	// acks, if not nil, tracks the unacked events of a Reader in ack mode. See SubscribeAcked().
+	acks *readerAcks
+	// backfill is the archived events that the Reader has yet to receive. See SubscribeFrom().
+	backfill []ArchivedEvent[T]
+
+	// deferral, if not nil, postpones the events available to the Reader. See Defer().
+	deferral *readerDeferral
+
+	// lifo, if not nil, tracks the events consumed out of order by a Reader that receives the newest
+	// events first, or the highest-priority ones. See SubscribeLIFO() and WithOrderBy.
+	lifo *readerLIFO
+
+	// onRelease, if not nil, is called once the Reader is released. See SubscribeMatching().
+	onRelease func()
+
+	// subscribed is the call stack that created the Reader, if the Distributor captures it. See
+	// WithSubscribeCapture.
+	subscribed []uintptr
+}
+
+// reader returns a Reader for the state, so that its methods can be used
+func (r *readerState[T]) reader() *Reader[T] {
+	return &Reader[T]{readerState: r}
 }
 
 var closedChannel <-chan struct{} = func() <-chan struct{} {
@@ -121,13 +857,74 @@ var closedChannel <-chan struct{} = func() <-chan struct{} {
 // WaitChan returns a channel that will be closed once there is an event that this Reader has
 // not yet seen.
 //
+// Readers that only receive some of the events - for example, those created by
+// SubscribeFiltered() - wait on their own channel, so they aren't woken by events they won't
+// receive. Other Readers share a channel that's closed by each new event, because every one of
+// them receives it.
+//
+// If there is already an event available, WaitChan returns a channel that's already closed, and
+// calls while the Reader is waiting return the same channel, so neither allocates. But a closed
+// channel can't be reused, so each wait that actually blocks allocates a new channel. To wait
+// without allocating, use Range() or the Reader's Signal() instead, which re-arm the same channel
+// for every wait. Handlers added with AddHandler() wait the same way.
+//
+// For Readers that receive every event, WaitChan doesn't take the Distributor's lock when there is
+// already an event available, so Readers that are keeping up with a busy producer don't contend
+// with it.
+//
 // WaitChan is thread-safe.
 func (r *Reader[T]) WaitChan() <-chan struct{} {
+	if r.availableFast() {
+		return closedChannel
+	}
+
 	r.d.mu.Lock()
 	defer r.d.mu.Unlock()
 
-	if r.position-r.d.basePosition < int64(len(r.d.buf)) {
+	if r.prepare() != nil {
+		return closedChannel
+	}
+	return r.waitChan()
+}
+
+// prepare updates the Reader's state before any operation on it, returning an error if the Reader
+// can no longer be used.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) prepare() error {
+	if r.unsubscribed {
+		return ErrUnsubscribed
+	} else if r.d.closed {
+		return ErrClosed
+	} else if err := r.checkReleased(); err != nil {
+		return err
+	}
+
+	r.d.expireEvents()
+	r.skipStale()
+	r.skipFiltered()
+	r.skipSeen()
+	return nil
+}
+
+// waitChan implements WaitChan.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) waitChan() <-chan struct{} {
+	if r.available() {
 		return closedChannel
+	}
+
+	r.startWait()
+	if r.needsOwnWaitChan() {
+		if r.waitCh == nil {
+			registered := r.isOwnWaiter()
+			r.waitCh = make(chan struct{})
+			if !registered {
+				r.d.ownWaiters = append(r.d.ownWaiters, r.readerState)
+			}
+		}
+		return r.waitCh
 	} else {
 		if r.d.waiters == nil {
 			r.d.waiters = make(chan struct{})
@@ -136,18 +933,172 @@ func (r *Reader[T]) WaitChan() <-chan struct{} {
 	}
 }
 
+// needsOwnWaitChan returns whether the Reader may need to be woken separately from other Readers,
+// in which case it can't use the shared d.waiters channel.
+func (r *Reader[T]) needsOwnWaitChan() bool {
+	return r.expiry != nil || r.filter != nil || r.acks != nil || r.deferral != nil
+}
+
+// wakeOwn wakes the Reader individually, if it's waiting with its own channel.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) wakeOwn() {
+	if !r.isOwnWaiter() {
+		return
+	}
+
+	r.wakeOwnWaiter()
+	if !r.isOwnWaiter() {
+		r.removeOwnWaiter()
+	}
+}
+
+// removeOwnWaiter removes the Reader from d.ownWaiters, if it's there.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) removeOwnWaiter() {
+	for i, other := range r.d.ownWaiters {
+		if other == r.readerState {
+			last := len(r.d.ownWaiters) - 1
+			r.d.ownWaiters[i] = r.d.ownWaiters[last]
+			r.d.ownWaiters[last] = nil
+			r.d.ownWaiters = r.d.ownWaiters[:last]
+			break
+		}
+	}
+}
+
+// hasPending returns whether there is an event that this Reader has not yet seen.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) hasPending() bool {
+	return r.position-r.d.basePosition < int64(len(r.d.buf))
+}
+
 // Consume returns the first event that has not yet been seen by this Reader, marking it as "seen"
 // so that the next call to WaitChan() will require a newer event.
 //
+// Consume must only be called when there is an event available - i.e., after WaitChan() has been
+// closed - and panics otherwise, with a message describing the Reader's position in the buffer.
+// For Readers that may skip events or expire (like those created by SubscribeLossyByAge or
+// SubscribeFor), or that are shared between goroutines, the available event may have been consumed
+// or skipped in the meantime, so TryConsume() should be used instead.
+//
 // Consume is thread-safe.
 func (r *Reader[T]) Consume() T {
 	r.d.mu.Lock()
 	defer r.d.mu.Unlock()
 
+	if err := r.prepare(); err != nil {
+		panic(fmt.Errorf("eventdistributor: Consume called on unusable Reader: %w", err))
+	}
+	r.mustHaveEvent("Consume")
+	value, _ := r.deliver()
+	return value
+}
+
+// mustHaveEvent panics if the Reader has no event available, naming the method that required one.
+// Without it, consuming would fail with an index out of range, which says nothing about the
+// Reader.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) mustHaveEvent(method string) {
+	if r.hasPending() || r.hasQueued() {
+		return
+	}
+
+	bounds := "buffer empty"
+	if len(r.d.buf) != 0 {
+		bounds = fmt.Sprintf("buffer %d to %d", r.d.basePosition, r.d.basePosition+int64(len(r.d.buf))-1)
+	}
+	panic(fmt.Sprintf(
+		"eventdistributor: %s called with no event available for Reader %d (position %d, %s)",
+		method, r.id, r.position, bounds,
+	))
+}
+
+// ErrNoEvent is returned by (*Reader[T]).TryConsume() when there is no event available
+var ErrNoEvent = errors.New("no event available")
+
+// TryConsume is like Consume, but returns ErrNoEvent if there is no event available, instead of
+// panicking. If the Reader has expired, was evicted, or was unsubscribed, TryConsume returns
+// ErrExpired, ErrEvicted, or ErrUnsubscribed.
+//
+// TryConsume is thread-safe.
+func (r *Reader[T]) TryConsume() (T, error) {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	value, _, err := r.tryConsume()
+	return value, err
+}
+
+// tryConsume implements TryConsume, additionally returning the sequence number of the consumed
+// event.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) tryConsume() (T, int64, error) {
+	if err := r.prepare(); err != nil {
+		var zero T
+		return zero, 0, err
+	}
+	if !r.hasPending() && !r.hasQueued() {
+		var zero T
+		return zero, 0, ErrNoEvent
+	}
+
+	value, seq := r.deliver()
+	return value, seq, nil
+}
+
+// deliver consumes the next event for the Reader, additionally returning its sequence number.
+// Unlike consume, it takes into account the Reader's mode - see SubscribeAcked() and
+// SubscribeLIFO().
+//
+// r.d.mu must be held.
+func (r *Reader[T]) deliver() (T, int64) {
+	// Waits usually end when an event is submitted, but there are other ways to have an event
+	// available, like Nack() and SeekTo().
+	r.endWait()
+	r.endDeferral()
+	r.consumed += 1
+	r.lastConsumed = r.d.getClock().Now()
+
+	// OnConsume callbacks must be called before the event is fully consumed, so cleanup is held
+	// until afterwards.
+	var value T
+	var seq int64
+	r.d.holdCleanup = true
+	if r.hasBackfill() {
+		value, seq = r.consumeBackfill()
+	} else if r.lifo != nil {
+		value, seq = r.consumeOutOfOrder()
+	} else if r.acks != nil {
+		value, seq = r.consumeAcked()
+	} else {
+		value, seq = r.consume()
+	}
+	r.d.holdCleanup = false
+
+	runCallbacks2(&r.d.mu, r.d.onConsume, value, r.id)
+	r.d.cleanupOldEvents()
+	return value, seq
+}
+
+// consume implements Consume, additionally returning the sequence number of the consumed event.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) consume() (T, int64) {
 	idx := int(r.position - r.d.basePosition)
 	value := r.d.buf[idx].value
+	seq := r.d.buf[idx].seq
 	r.d.buf[idx].refcount -= 1
 	r.position += 1
+	r.publishPosition()
+	r.checkLag()
+	r.checkReplayDone()
+	r.checkCaughtUp()
+	r.d.wakeProducers()
 
 	if idx+1 < len(r.d.buf) {
 		r.d.buf[idx+1].refcount += 1
@@ -156,7 +1107,7 @@ func (r *Reader[T]) Consume() T {
 	}
 
 	r.d.cleanupOldEvents()
-	return value
+	return value, seq
 }
 
 // Unsubscribe de-registers the Reader, freeing any buffered events that may have been kept for
@@ -165,11 +1116,76 @@ func (r *Reader[T]) Consume() T {
 // If you stop using an Reader and never call Unsubscribe, unread events will slowly
 // accumulate, increasing the memory usage of your program.
 //
+// After Unsubscribe, the Reader's WaitChan() returns a closed channel, and operations that would
+// use it fail with ErrUnsubscribed - or, for those without an error to return, like Consume(),
+// panic.
+//
+// It is safe to call Unsubscribe more than once, for example from both a defer and an explicit
+// shutdown path. Only the first call has any effect; Unsubscribe returns whether this call was the
+// one that unsubscribed the Reader.
+//
 // Unsubscribe is thread-safe.
-func (r *Reader[T]) Unsubscribe() {
+func (r *Reader[T]) Unsubscribe() bool {
+	d := r.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return r.unsubscribe()
+}
+
+// Subscribed returns whether the Reader has not yet been unsubscribed. Readers that were released
+// because they expired, were evicted, or because the Distributor was closed are still subscribed
+// until Unsubscribe() is called.
+//
+// Subscribed is thread-safe.
+func (r *Reader[T]) Subscribed() bool {
 	r.d.mu.Lock()
 	defer r.d.mu.Unlock()
 
+	return !r.unsubscribed
+}
+
+// unsubscribe implements Unsubscribe, returning false if the Reader was already unsubscribed.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) unsubscribe() bool {
+	// Keep the Reader reachable until it's marked unsubscribed, so that it isn't reported as leaked
+	// if this is its last use. See WithLeakCheck.
+	defer runtime.KeepAlive(r.leak)
+
+	// Releasing the Reader again would corrupt the buffer's refcounts.
+	if r.unsubscribed {
+		return false
+	}
+	r.unsubscribed = true
+
+	// If the Distributor is closed, all Readers have already been released.
+	if r.d.closed {
+		return true
+	}
+
+	if r.expiry != nil {
+		r.expiry.timer.Stop()
+		// If the Reader already expired, it has already been released.
+		if r.expiry.expired {
+			return true
+		}
+	}
+	// Likewise, evicted Readers have already been released.
+	if r.evicted {
+		return true
+	}
+
+	r.d.emitMeta(MetaEvent{Kind: MetaUnsubscribe, Reader: r.readerInfo()})
+	r.release()
+	return true
+}
+
+// release releases the Reader's hold on any buffered events, and removes it from the Distributor's
+// set of active Readers.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) release() {
 	idx := int(r.position - r.d.basePosition)
 	if idx < len(r.d.buf) {
 		r.d.buf[idx].refcount -= 1
@@ -179,14 +1195,49 @@ func (r *Reader[T]) Unsubscribe() {
 	} else {
 		r.d.nextRefcount -= 1
 	}
+	// Unacked events may be held anywhere in the buffer.
+	if r.acks != nil {
+		r.releaseAcks()
+		r.d.cleanupOldEvents()
+	}
+
+	r.endDeferral()
+	r.d.unregister(r.readerState)
+	// Wake anything waiting on the Reader, so that it can observe that it's no longer usable.
+	r.wakeOwn()
+	r.endWait()
+	r.d.wakeProducers()
+	r.finishReplay()
+	r.wakeCaughtUp()
 
-	// For safety, remove the Distributor pointer so that future calls to Unsubscribe() will
-	// panic, rather than silently corrupt the buffer.
-	r.d = nil
+	r.d.checkSetIdle()
+	if r.onRelease != nil {
+		r.onRelease()
+	}
+}
+
+// unregister removes the Reader from d.readers
+//
+// d.mu must be held.
+func (d *Distributor[T]) unregister(r *readerState[T]) {
+	if r.filter != nil {
+		d.numFiltered -= 1
+	}
+
+	last := len(d.readers) - 1
+	d.readers[r.registryIdx] = d.readers[last]
+	d.readers[r.registryIdx].registryIdx = r.registryIdx
+	d.readers[last] = nil
+	d.readers = d.readers[:last]
+	d.forgetLag(r)
+
+	if d.numReaders() == 0 {
+		runCallbacks(&d.mu, d.onLastUnsub, struct{}{})
+	}
 }
 
 func (d *Distributor[T]) cleanupOldEvents() {
-	if len(d.buf) == 0 {
+	if len(d.buf) == 0 || d.holdCleanup {
 		return
 	}
 
@@ -196,8 +1247,16 @@ func (d *Distributor[T]) cleanupOldEvents() {
 		if d.buf[firstNonEmpty].refcount != 0 {
 			break
 		} else {
-			runCallbacks(d.onFullyConsumed, d.buf[firstNonEmpty].value)
-			close(d.buf[firstNonEmpty].allConsumed)
+			e := &d.buf[firstNonEmpty]
+			runCallbacks(&d.mu, d.onFullyConsumed.fs, e.value)
+			d.reportLatency(e.value, e.timestamp)
+			d.stats.FullyConsumed += 1
+			d.archiveEvent(e.seq, e.timestamp, e.value)
+			d.noteReleased(e.timestamp)
+			if d.owned != nil {
+				*d.owned = append(*d.owned, e.value)
+			}
+			d.finish(e.allConsumed, e.onDone)
 		}
 	}
 
@@ -205,12 +1264,24 @@ func (d *Distributor[T]) cleanupOldEvents() {
 		return
 	}
 
+	// Clear the dropped events, so that their values and captured callers can be garbage collected
+	// even while the rest of the buffer's backing array is still in use.
+	for i := 0; i < firstNonEmpty; i++ {
+		d.buf[i] = eventInfo[T]{}
+	}
+
 	if firstNonEmpty == len(d.buf) {
-		d.buf = nil
+		d.buf = d.emptyBuf()
 	} else {
 		d.buf = d.buf[firstNonEmpty:]
 	}
 	d.basePosition += int64(firstNonEmpty)
+	// Pins and acks can also release events, so producers waiting for room must be woken here.
+	if d.fixed != nil {
+		d.wakeProducers()
+	}
 
-	runCallbacks(d.onBufsizeChange, len(d.buf))
+	runCallbacks(&d.mu, d.onBufsizeChange.fs, len(d.buf))
+	d.checkDrained()
+	d.startIdle()
 }
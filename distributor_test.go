@@ -1,6 +1,9 @@
 package eventdistributor_test
 
 import (
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -129,6 +132,198 @@ func TestDistibutor(t *testing.T) {
 	nowReady(t, s4)
 }
 
+func TestSubmitQuiet(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var consumed []int
+	options.OnFullyConsumed(func(e MyEvent) {
+		consumed = append(consumed, e.id)
+	})
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	d.SubmitQuiet(MyEvent{id: 0})
+	s1 := d.Submit(MyEvent{id: 1})
+
+	t.Log("quiet events are delivered and consumed as usual")
+	require.Equal(t, []int{0, 1}, drainIDs(&r))
+	require.Equal(t, []int{0, 1}, consumed)
+	nowReady(t, s1)
+
+	t.Log("quiet events can be removed and closed as usual")
+	d.SubmitQuiet(MyEvent{id: 2})
+	d.SubmitQuiet(MyEvent{id: 3})
+	require.Equal(t, 1, d.FilterInPlace(func(e MyEvent) bool { return e.id == 2 }))
+	d.Close()
+}
+
+func TestSubmitAllocations(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	// Checks are made without require, which may allocate.
+	allocs := testing.AllocsPerRun(100, func() {
+		select {
+		case <-d.Submit(MyEvent{id: 0}):
+		default:
+			t.Fatal("channel for discarded event not closed")
+		}
+	})
+	require.Zero(t, allocs, "discarding an event allocated")
+
+	t.Log("each buffered event only allocates its own channel")
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	allocs = testing.AllocsPerRun(100, func() {
+		d.Submit(MyEvent{id: 1})
+		r.Consume()
+	})
+	require.Equal(t, 1.0, allocs)
+}
+
+func TestSubmitWithCallback(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var calls []string
+	options.OnFullyConsumed(func(e MyEvent) {
+		calls = append(calls, fmt.Sprintf("fully consumed %d", e.id))
+	})
+	options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+		calls = append(calls, fmt.Sprintf("dropped %d: %v", e.id, reason))
+	})
+	d := eventdistributor.New(options)
+	done := func(id int) func() {
+		return func() { calls = append(calls, fmt.Sprintf("done %d", id)) }
+	}
+
+	t.Log("an event discarded immediately is done right away, after OnFullyConsumed")
+	d.SubmitWithCallback(MyEvent{id: 0}, done(0))
+	require.Equal(t, []string{"fully consumed 0", "done 0"}, calls)
+
+	t.Log("a buffered event is done once it's fully consumed, even if the buffer is compacted")
+	calls = nil
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	for i := 1; i <= 3; i++ {
+		d.SubmitWithCallback(MyEvent{id: i}, done(i))
+	}
+	require.Equal(t, 1, r.Consume().id)
+	d.Compact()
+	require.Equal(t, []string{"fully consumed 1", "done 1"}, calls)
+	require.Equal(t, 2, r.Consume().id)
+	require.Equal(t, []string{"fully consumed 1", "done 1", "fully consumed 2", "done 2"}, calls)
+
+	t.Log("dropped events are done as well, after OnDrop")
+	calls = nil
+	require.Equal(t, 1, d.FilterInPlace(func(e MyEvent) bool { return e.id == 3 }))
+	require.Equal(t, []string{"dropped 3: Removed", "done 3"}, calls)
+
+	t.Log("a nil callback is the same as SubmitQuiet")
+	calls = nil
+	d.SubmitWithCallback(MyEvent{id: 4}, nil)
+	require.Equal(t, 4, r.Consume().id)
+	require.Equal(t, []string{"fully consumed 4"}, calls)
+
+	t.Log("events dropped by Close, or submitted afterwards, are done exactly once")
+	calls = nil
+	d.SubmitWithCallback(MyEvent{id: 5}, done(5))
+	d.Close()
+	d.SubmitWithCallback(MyEvent{id: 6}, done(6))
+	require.Equal(t, []string{
+		"dropped 5: Closed",
+		"done 5",
+		"dropped 6: Sealed",
+		"done 6",
+	}, calls)
+}
+
+func TestOnConsume(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var calls []string
+	options.OnConsume(func(e MyEvent, readerID uint64) {
+		calls = append(calls, fmt.Sprintf("consume %d by %d", e.id, readerID))
+	})
+	options.OnFullyConsumed(func(e MyEvent) {
+		calls = append(calls, fmt.Sprintf("fully consumed %d", e.id))
+	})
+	d := eventdistributor.New(options)
+
+	r1 := d.Subscribe()
+	defer r1.Unsubscribe()
+	r2 := d.SubscribeFiltered(func(e MyEvent) bool { return e.id != 1 })
+	defer r2.Unsubscribe()
+
+	d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 1})
+	r1.Consume()
+	r2.Consume()
+	require.Equal(t, []string{
+		fmt.Sprintf("consume 0 by %d", r1.ID()),
+		fmt.Sprintf("consume 0 by %d", r2.ID()),
+		"fully consumed 0",
+	}, calls)
+
+	t.Log("events skipped by a Reader are not delivered to it")
+	calls = nil
+	r1.Consume()
+	_, err := r2.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrNoEvent)
+	require.Equal(t, []string{fmt.Sprintf("consume 1 by %d", r1.ID()), "fully consumed 1"}, calls)
+}
+
+func TestConsumeWithoutEvent(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r1 := d.Subscribe()
+	defer r1.Unsubscribe()
+
+	prefix := "eventdistributor: Consume called with no event available for Reader "
+
+	t.Log("with an empty buffer")
+	msg := fmt.Sprintf(prefix+"%d (position 0, buffer empty)", r1.ID())
+	require.PanicsWithValue(t, msg, func() { r1.Consume() })
+	_, err := r1.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrNoEvent)
+
+	t.Log("with the Reader at the end of the buffer")
+	r2 := d.Subscribe()
+	defer r2.Unsubscribe()
+	d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 1})
+	require.Equal(t, []int{0, 1}, drainIDs(&r1))
+	msg = fmt.Sprintf(prefix+"%d (position 2, buffer 0 to 1)", r1.ID())
+	require.PanicsWithValue(t, msg, func() { r1.Consume() })
+	_, err = r1.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrNoEvent)
+	require.Panics(t, func() { r1.ConsumeOwned() })
+
+	t.Log("the failed calls don't affect the buffer")
+	require.Equal(t, []int{0, 1}, drainIDs(&r2))
+	require.Equal(t, 0, d.Stats().Bufsize)
+}
+
+func BenchmarkSubmit(b *testing.B) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = d.Submit(MyEvent{id: i})
+		r.Consume()
+	}
+}
+
+func BenchmarkSubmitQuiet(b *testing.B) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d.SubmitQuiet(MyEvent{id: i})
+		r.Consume()
+	}
+}
+
 func notReady(t *testing.T, reader eventdistributor.Reader[MyEvent]) {
 	nowNotReady(t, reader.WaitChan())
 }
@@ -152,3 +347,119 @@ func nowReady(t *testing.T, c <-chan struct{}) {
 		require.True(t, false)
 	}
 }
+
+func TestUseAfterUnsubscribe(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+	require.True(t, r.Subscribed())
+	d.Submit(MyEvent{id: 0})
+
+	require.True(t, r.Unsubscribe())
+	require.False(t, r.Subscribed())
+	nowReady(t, r.WaitChan())
+	_, err := r.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrUnsubscribed)
+	require.ErrorIs(t, r.SeekTo(0), eventdistributor.ErrUnsubscribed)
+	msg := "eventdistributor: Consume called on unusable Reader: reader unsubscribed"
+	require.PanicsWithError(t, msg, func() { r.Consume() })
+	require.False(t, r.Unsubscribe())
+
+	t.Log("Readers of a closed Distributor are subscribed until Unsubscribe is called")
+	r2 := d.Subscribe()
+	d.Close()
+	require.True(t, r2.Subscribed())
+	_, err = r2.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrClosed)
+	r2.Unsubscribe()
+	require.False(t, r2.Subscribed())
+	_, err = r2.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrUnsubscribed)
+}
+
+func TestUnsubscribeIdempotent(t *testing.T) {
+	var fullyConsumed []int
+	var options eventdistributor.Options[MyEvent]
+	options.OnFullyConsumed(func(e MyEvent) { fullyConsumed = append(fullyConsumed, e.id) })
+	d := eventdistributor.New(options)
+
+	r1 := d.Subscribe()
+	r2 := d.Subscribe()
+	defer r2.Unsubscribe()
+	d.Submit(MyEvent{id: 0})
+
+	require.True(t, r1.Unsubscribe())
+	require.Equal(t, 1, d.Stats().Bufsize)
+
+	t.Log("the second Unsubscribe doesn't release r2's hold on the event")
+	require.False(t, r1.Unsubscribe())
+	require.Nil(t, r1.UnsubscribeOwned())
+	require.Equal(t, 1, d.Stats().Bufsize)
+	require.Equal(t, 1, d.Stats().Readers)
+	require.Empty(t, fullyConsumed)
+
+	require.Equal(t, []int{0}, drainIDs(&r2))
+	require.Equal(t, []int{0}, fullyConsumed)
+}
+
+func TestConcurrentUnsubscribe(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r1 := d.Subscribe()
+	r2 := d.Subscribe()
+	defer r2.Unsubscribe()
+	d.Submit(MyEvent{id: 0})
+
+	const callers = 8
+	var succeeded atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if r1.Unsubscribe() {
+				succeeded.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), succeeded.Load())
+	require.Equal(t, 1, d.Stats().Bufsize)
+	require.Equal(t, []int{0}, drainIDs(&r2))
+	require.Equal(t, 0, d.Stats().Bufsize)
+}
+
+func TestReaderCopiesShareSubscription(t *testing.T) {
+	var fullyConsumed []int
+	var options eventdistributor.Options[MyEvent]
+	options.OnFullyConsumed(func(e MyEvent) { fullyConsumed = append(fullyConsumed, e.id) })
+	d := eventdistributor.New(options)
+
+	r1 := d.Subscribe()
+	defer r1.Unsubscribe()
+	r2 := d.Subscribe()
+	// Copy r2 the ways that are easy to do by accident: into a slice that reallocates, and into a
+	// struct.
+	readers := []eventdistributor.Reader[MyEvent]{r2}
+	readers = append(readers, make([]eventdistributor.Reader[MyEvent], 16)...)
+	holder := struct {
+		r eventdistributor.Reader[MyEvent]
+	}{r: readers[0]}
+
+	d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 1})
+
+	t.Log("consuming from one copy advances them all")
+	require.Equal(t, MyEvent{id: 0}, r2.Consume())
+	require.Equal(t, MyEvent{id: 1}, holder.r.Consume())
+	notReady(t, readers[0])
+	require.Equal(t, int64(2), r2.Stats().Consumed)
+	require.Empty(t, fullyConsumed)
+
+	t.Log("events are only freed once r1 has consumed them as well")
+	require.Equal(t, []int{0, 1}, drainIDs(&r1))
+	require.Equal(t, []int{0, 1}, fullyConsumed)
+
+	require.True(t, holder.r.Unsubscribe())
+	require.False(t, r2.Unsubscribe())
+	require.Equal(t, 1, d.Stats().Readers)
+}
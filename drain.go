@@ -0,0 +1,111 @@
+package eventdistributor
+
+// Drain consumes every event that is currently available to the Reader, returning them in the
+// order they would have been returned by repeated calls to Consume(). If there are no events
+// available, or the Reader can no longer be used, Drain returns nil.
+//
+// Unlike calling Consume() in a loop, Drain only acquires the Distributor's lock once, and - for
+// Readers that are not in ack mode, LIFO, or backfilling from an Archiver - moves the Reader past
+// all of the events at once, so that fully consumed events are cleaned up together and
+// OnBufsizeChange callbacks are called at most once.
+//
+// Drain is thread-safe.
+func (r *Reader[T]) Drain() []T {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if err := r.prepare(); err != nil {
+		return nil
+	}
+
+	if r.hasBackfill() || r.lifo != nil || r.acks != nil {
+		var values []T
+		for r.hasPending() || r.hasQueued() {
+			value, _ := r.deliver()
+			values = append(values, value)
+			r.skipFiltered()
+		}
+		return values
+	}
+
+	return r.drain()
+}
+
+// drain implements Drain for Readers that consume events in order, directly from the buffer.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) drain() []T {
+	if !r.hasPending() {
+		return nil
+	}
+
+	start := int(r.position - r.d.basePosition)
+	var values []T
+	for _, e := range r.d.buf[start:] {
+		if r.filter == nil || r.filter(e.value) {
+			values = append(values, e.value)
+		}
+	}
+
+	r.endWait()
+	r.endDeferral()
+	if len(values) != 0 {
+		r.consumed += int64(len(values))
+		r.lastConsumed = r.d.getClock().Now()
+	}
+
+	r.d.buf[start].refcount -= 1
+	r.d.nextRefcount += 1
+	r.position = r.d.basePosition + int64(len(r.d.buf))
+	r.publishPosition()
+	r.checkLag()
+	r.checkReplayDone()
+	r.checkCaughtUp()
+	r.d.wakeProducers()
+
+	for _, value := range values {
+		runCallbacks2(&r.d.mu, r.d.onConsume, value, r.id)
+	}
+	r.d.cleanupOldEvents()
+	return values
+}
+
+// DiscardPending moves the Reader past every event it has not yet received, so that it only
+// receives events submitted afterwards, returning the number of events discarded - the events
+// counted as pending by Stats(). For Readers in ack mode, this includes nacked events waiting to be
+// delivered again, but not events that are still in flight.
+//
+// Unlike unsubscribing and subscribing again, the Reader stays registered throughout, so it
+// doesn't miss any events submitted concurrently. Discarded events count as skipped, and those that
+// are then fully consumed are cleaned up together, as with Drain().
+//
+// DiscardPending is thread-safe.
+func (r *Reader[T]) DiscardPending() int {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if err := r.prepare(); err != nil {
+		return 0
+	}
+
+	tail := r.d.basePosition + int64(len(r.d.buf))
+	discarded := int(tail-r.position) + len(r.backfill)
+	r.backfill = nil
+	if r.lifo != nil {
+		r.lifo.seen = nil
+	}
+	if r.acks != nil {
+		discarded += len(r.acks.redeliver)
+		r.acks.redeliver = nil
+		r.moveAckHold()
+	}
+	r.skipped += int64(discarded)
+
+	// Moving the Reader cleans up the events that are now fully consumed, but only if it moves.
+	if r.position == tail {
+		r.checkReplayDone()
+		r.d.cleanupOldEvents()
+	}
+	r.moveTo(tail)
+	return discarded
+}
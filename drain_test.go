@@ -0,0 +1,131 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestDrain(t *testing.T) {
+	var sizes []int
+	var consumed []int
+	var options eventdistributor.Options[MyEvent]
+	options.OnBufsizeChange(func(size int) { sizes = append(sizes, size) })
+	options.OnFullyConsumed(func(e MyEvent) { consumed = append(consumed, e.id) })
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	require.Nil(t, r.Drain())
+
+	var allConsumed []<-chan struct{}
+	for i := 0; i < 5; i++ {
+		allConsumed = append(allConsumed, d.Submit(MyEvent{id: i}))
+	}
+	sizes = nil
+
+	require.Equal(t, []MyEvent{{id: 0}, {id: 1}, {id: 2}, {id: 3}, {id: 4}}, r.Drain())
+	require.Equal(t, []int{0}, sizes)
+	require.Equal(t, []int{0, 1, 2, 3, 4}, consumed)
+	for _, ch := range allConsumed {
+		nowReady(t, ch)
+	}
+	notReady(t, r)
+	require.EqualValues(t, 5, r.Stats().Consumed)
+	require.Nil(t, r.Drain())
+
+	t.Log("events are kept for other Readers")
+	other := d.Subscribe()
+	defer other.Unsubscribe()
+	d.Submit(MyEvent{id: 5})
+	require.Equal(t, []MyEvent{{id: 5}}, r.Drain())
+	require.Equal(t, []int{5}, drainIDs(&other))
+}
+
+func TestDrainModes(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	filtered := d.SubscribeFiltered(func(e MyEvent) bool { return e.id%2 == 0 })
+	defer filtered.Unsubscribe()
+	lifo := d.SubscribeLIFO()
+	defer lifo.Unsubscribe()
+
+	for i := 0; i < 5; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+
+	require.Equal(t, []MyEvent{{id: 0}, {id: 2}, {id: 4}}, filtered.Drain())
+	require.Equal(t, []MyEvent{{id: 4}, {id: 3}, {id: 2}, {id: 1}, {id: 0}}, lifo.Drain())
+	notReady(t, filtered)
+	notReady(t, lifo)
+
+	t.Log("nothing is returned once the Distributor is closed")
+	d.Submit(MyEvent{id: 6})
+	d.Close()
+	require.Nil(t, filtered.Drain())
+}
+
+func TestDiscardPending(t *testing.T) {
+	var sizes []int
+	var consumed []int
+	var options eventdistributor.Options[MyEvent]
+	options.OnBufsizeChange(func(size int) { sizes = append(sizes, size) })
+	options.OnFullyConsumed(func(e MyEvent) { consumed = append(consumed, e.id) })
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	other := d.Subscribe()
+	defer other.Unsubscribe()
+	require.Equal(t, 0, r.DiscardPending())
+
+	for i := 0; i < 4; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+	other.Consume()
+	other.Consume()
+	sizes = nil
+
+	t.Log("events that every other Reader has consumed are cleaned up together, once each")
+	require.Equal(t, 4, r.DiscardPending())
+	require.Equal(t, []int{0, 1}, consumed)
+	require.Equal(t, []int{2}, sizes)
+	notReady(t, r)
+	require.EqualValues(t, 4, r.Stats().Skipped)
+	require.Zero(t, r.Stats().Pending)
+
+	t.Log("the Reader still receives later events")
+	d.Submit(MyEvent{id: 4})
+	require.Equal(t, []int{4}, drainIDs(&r))
+	require.Equal(t, []int{2, 3, 4}, drainIDs(&other))
+	require.Equal(t, []int{0, 1, 2, 3, 4}, consumed)
+}
+
+func TestDiscardPendingAcked(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.SubscribeAcked()
+	defer r.Unsubscribe()
+
+	first := d.Submit(MyEvent{id: 0})
+	second := d.Submit(MyEvent{id: 1})
+	last := d.Submit(MyEvent{id: 2})
+	_, seq0, err := r.TryConsumeSeq()
+	require.NoError(t, err)
+	_, seq1, err := r.TryConsumeSeq()
+	require.NoError(t, err)
+	require.NoError(t, r.Nack(seq1))
+
+	t.Log("nacked events are discarded, but in-flight events stay in flight")
+	require.Equal(t, 2, r.DiscardPending())
+	require.Equal(t, 1, r.InFlight())
+	notReady(t, r)
+
+	t.Log("the discarded events leave the buffer once the event in front of them is acked")
+	nowNotReady(t, first)
+	nowNotReady(t, second)
+	require.NoError(t, r.Ack(seq0))
+	nowReady(t, first)
+	nowReady(t, second)
+	nowReady(t, last)
+}
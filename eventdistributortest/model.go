@@ -0,0 +1,527 @@
+// Package eventdistributortest provides utilities for testing code built on eventdistributor.
+package eventdistributortest
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+// ModelConfig is the configuration for CheckModel
+type ModelConfig struct {
+	// Seed is the seed for the random sequence of operations. If it is zero, a seed is chosen
+	// randomly. The seed is logged if the test fails, so that the failure can be reproduced.
+	Seed int64
+	// Steps is the number of operations to perform. If it is zero, a default of 1000 is used.
+	Steps int
+	// MaxReaders is the maximum number of Readers subscribed at once. If it is zero, a default of
+	// 4 is used.
+	MaxReaders int
+
+	// LossyMaxAge, if non-zero, causes some Readers to be created with SubscribeLossyByAge, using
+	// LossyMaxAge as the maximum age. Time is simulated, and is advanced by random operations.
+	LossyMaxAge time.Duration
+	// Filter, if true, adds calls to FilterInPlace to the operations.
+	Filter bool
+	// Rewind, if true, adds calls to (*Reader[T]).Rewind to the operations.
+	Rewind bool
+	// Seek, if true, adds calls to (*Reader[T]).SeekTo to the operations.
+	Seek bool
+
+	// Options, if not nil, is called to set additional options on the Distributor before it is
+	// created. The options must not change which events are delivered to Readers.
+	Options func(o *eventdistributor.Options[int])
+	// AfterStep, if not nil, is called after every operation, so that callers can check their own
+	// invariants. The Distributor's events are unique, increasing integers.
+	AfterStep func(t *testing.T, d *eventdistributor.Distributor[int])
+}
+
+// CheckModel runs a random sequence of operations against a Distributor and against a simple
+// reference model, failing the test if they ever disagree.
+//
+// After every operation, CheckModel checks that the values delivered by each Reader, the readiness
+// of each Reader's WaitChan(), the readiness of the channels returned by Submit(), and the number
+// of calls to each callback all match the model.
+func CheckModel(t *testing.T, cfg ModelConfig) {
+	t.Helper()
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("eventdistributortest: CheckModel failed with seed %d", seed)
+		}
+	})
+
+	if cfg.Steps == 0 {
+		cfg.Steps = 1000
+	}
+	if cfg.MaxReaders == 0 {
+		cfg.MaxReaders = 4
+	}
+
+	c := newChecker(t, cfg, rand.New(rand.NewSource(seed)))
+	for step := 0; step < cfg.Steps && !t.Failed(); step++ {
+		op := c.step()
+		c.check(step, op)
+		if cfg.AfterStep != nil {
+			cfg.AfterStep(t, c.d)
+		}
+	}
+	c.unsubscribeAll()
+}
+
+// checker holds the state for a single run of CheckModel, both real and modelled
+type checker struct {
+	t     *testing.T
+	cfg   ModelConfig
+	rng   *rand.Rand
+	clock *modelClock
+
+	d *eventdistributor.Distributor[int]
+	// real callback counts
+	submits, fullyConsumed, drops int
+	bufsize                       int
+
+	// buffer is the model of the events still held by the Distributor, in order
+	buffer []modelEvent
+	// readers are the currently subscribed Readers
+	readers []*modelReader
+	// nextValue is the value of the next submitted event
+	nextValue int
+	// model callback counts
+	wantSubmits, wantFullyConsumed, wantDrops int
+	// pending maps the value of every submitted event that has not been fully consumed to the
+	// channel returned by Submit()
+	pending map[int]<-chan struct{}
+	nextID  int
+}
+
+type modelEvent struct {
+	value     int
+	timestamp time.Time
+}
+
+type modelReader struct {
+	id   int
+	real eventdistributor.Reader[int]
+	// maxAge is the maximum age of delivered events, if the Reader is lossy
+	maxAge time.Duration
+	// unseen are the events that the Reader has yet to consume, in order
+	unseen []modelEvent
+	// seen are the events that the Reader has consumed or skipped, in order
+	seen []modelEvent
+	// firstValue is the value of the first event submitted after the Reader subscribed
+	firstValue int
+}
+
+func newChecker(t *testing.T, cfg ModelConfig, rng *rand.Rand) *checker {
+	c := &checker{
+		t:       t,
+		cfg:     cfg,
+		rng:     rng,
+		clock:   &modelClock{now: time.Unix(1_700_000_000, 0)},
+		pending: make(map[int]<-chan struct{}),
+	}
+
+	var options eventdistributor.Options[int]
+	options.WithClock(c.clock)
+	if cfg.LossyMaxAge != 0 {
+		options.WithTimestamps()
+	}
+	options.OnSubmit(func(int) { c.submits += 1 })
+	options.OnFullyConsumed(func(int) { c.fullyConsumed += 1 })
+	options.OnDrop(func(int, eventdistributor.DropReason) { c.drops += 1 })
+	options.OnBufsizeChange(func(size int) { c.bufsize = size })
+	if cfg.Options != nil {
+		cfg.Options(&options)
+	}
+
+	c.d = eventdistributor.New(options)
+	return c
+}
+
+// step performs a single random operation, returning a description of it
+func (c *checker) step() string {
+	for {
+		switch c.rng.Intn(9) {
+		case 0, 1:
+			return c.submit()
+		case 2:
+			if len(c.readers) < c.cfg.MaxReaders {
+				return c.subscribe()
+			}
+		case 3, 4:
+			if len(c.readers) != 0 {
+				return c.consume(c.randomReader())
+			}
+		case 5:
+			if len(c.readers) != 0 {
+				return c.unsubscribe(c.randomReader())
+			}
+		case 6:
+			if c.cfg.LossyMaxAge != 0 {
+				return c.advance()
+			}
+		case 7:
+			if c.cfg.Filter && c.rng.Intn(2) == 0 {
+				return c.filter()
+			} else if c.cfg.Rewind && len(c.readers) != 0 {
+				return c.rewind(c.randomReader())
+			}
+		case 8:
+			if c.cfg.Seek && len(c.readers) != 0 {
+				return c.seek(c.randomReader())
+			}
+		}
+	}
+}
+
+func (c *checker) randomReader() *modelReader {
+	return c.readers[c.rng.Intn(len(c.readers))]
+}
+
+func (c *checker) submit() string {
+	value := c.nextValue
+	c.nextValue += 1
+
+	c.wantSubmits += 1
+	if len(c.readers) == 0 {
+		// Nobody's listening, so the event is immediately consumed.
+		c.wantFullyConsumed += 1
+	} else {
+		ev := modelEvent{value: value, timestamp: c.clock.Now()}
+		c.buffer = append(c.buffer, ev)
+		for _, r := range c.readers {
+			r.unseen = append(r.unseen, ev)
+		}
+	}
+
+	c.pending[value] = c.d.Submit(value)
+	return fmt.Sprintf("Submit(%d)", value)
+}
+
+func (c *checker) subscribe() string {
+	r := &modelReader{id: c.nextID, firstValue: c.nextValue}
+	c.nextID += 1
+
+	if c.cfg.LossyMaxAge != 0 && c.rng.Intn(2) == 0 {
+		r.maxAge = c.cfg.LossyMaxAge
+		r.real = c.d.SubscribeLossyByAge(r.maxAge)
+	} else {
+		r.real = c.d.Subscribe()
+	}
+	c.readers = append(c.readers, r)
+	return fmt.Sprintf("reader %d: Subscribe (maxAge = %v)", r.id, r.maxAge)
+}
+
+func (c *checker) consume(r *modelReader) string {
+	got, err := r.real.TryConsume()
+
+	c.skipStale(r)
+	if len(r.unseen) == 0 {
+		if err != eventdistributor.ErrNoEvent {
+			c.t.Errorf("reader %d: TryConsume() = (%d, %v), expected ErrNoEvent", r.id, got, err)
+		}
+	} else {
+		want := r.unseen[0]
+		r.unseen = r.unseen[1:]
+		r.seen = append(r.seen, want)
+		if err != nil || got != want.value {
+			c.t.Errorf("reader %d: TryConsume() = (%d, %v), expected %d", r.id, got, err, want.value)
+		}
+	}
+
+	c.collect()
+	return fmt.Sprintf("reader %d: TryConsume", r.id)
+}
+
+func (c *checker) unsubscribe(r *modelReader) string {
+	r.real.Unsubscribe()
+	for i := range c.readers {
+		if c.readers[i] == r {
+			c.readers = append(c.readers[:i], c.readers[i+1:]...)
+			break
+		}
+	}
+
+	c.collect()
+	return fmt.Sprintf("reader %d: Unsubscribe", r.id)
+}
+
+func (c *checker) advance() string {
+	d := time.Duration(c.rng.Int63n(int64(c.cfg.LossyMaxAge)))
+	c.clock.Advance(d)
+	return fmt.Sprintf("advance time by %v", d)
+}
+
+func (c *checker) filter() string {
+	// Remove about one in three events, chosen by a random offset.
+	offset := c.rng.Intn(3)
+	remove := func(v int) bool { return (v+offset)%3 == 0 }
+
+	removed := c.d.FilterInPlace(remove)
+
+	keep := func(events []modelEvent) []modelEvent {
+		var kept []modelEvent
+		for _, ev := range events {
+			if !remove(ev.value) {
+				kept = append(kept, ev)
+			}
+		}
+		return kept
+	}
+
+	oldLen := len(c.buffer)
+	c.buffer = keep(c.buffer)
+	wantRemoved := oldLen - len(c.buffer)
+	c.wantDrops += wantRemoved
+	for _, r := range c.readers {
+		r.unseen = keep(r.unseen)
+		r.seen = keep(r.seen)
+	}
+
+	if removed != wantRemoved {
+		c.t.Errorf("FilterInPlace() = %d, expected %d", removed, wantRemoved)
+	}
+
+	c.collect()
+	return fmt.Sprintf("FilterInPlace(offset = %d)", offset)
+}
+
+func (c *checker) rewind(r *modelReader) string {
+	n := c.rng.Intn(4)
+	got, err := r.real.Rewind(n)
+
+	// Only the events the Reader has seen that are still buffered can be rewound to.
+	available := 0
+	for available < len(r.seen) && c.isBuffered(r.seen[len(r.seen)-available-1]) {
+		available += 1
+	}
+	want := n
+	if want > available {
+		want = available
+	}
+
+	rewound := r.seen[len(r.seen)-want:]
+	r.unseen = append(append([]modelEvent(nil), rewound...), r.unseen...)
+	r.seen = r.seen[:len(r.seen)-want]
+
+	if err != nil || got != want {
+		c.t.Errorf("reader %d: Rewind(%d) = (%d, %v), expected %d", r.id, n, got, err, want)
+	}
+	return fmt.Sprintf("reader %d: Rewind(%d)", r.id, n)
+}
+
+// isBuffered returns whether an event the Reader has seen is still buffered. Events that were
+// removed by FilterInPlace are also removed from each Reader's seen events.
+func (c *checker) seek(r *modelReader) string {
+	// Values are assigned in the same order as sequence numbers, starting from zero, so they're
+	// the same. Pick a target around the Reader's current events, so that most seeks succeed.
+	seq := int64(c.nextValue - c.rng.Intn(8) + 1)
+	err := r.real.SeekTo(seq)
+
+	var wantErr error
+	if seq > int64(c.nextValue) {
+		wantErr = eventdistributor.ErrSeqInFuture
+	} else if seq < int64(c.nextValue) &&
+		(seq < int64(r.firstValue) || len(c.buffer) == 0 || seq < int64(c.buffer[0].value)) {
+		wantErr = eventdistributor.ErrSeqEvicted
+	} else {
+		var all []modelEvent
+		for _, ev := range r.seen {
+			if c.isBuffered(ev) {
+				all = append(all, ev)
+			}
+		}
+		all = append(all, r.unseen...)
+
+		r.seen, r.unseen = nil, nil
+		for _, ev := range all {
+			if int64(ev.value) < seq {
+				r.seen = append(r.seen, ev)
+			} else {
+				r.unseen = append(r.unseen, ev)
+			}
+		}
+		c.collect()
+	}
+
+	if err != wantErr {
+		c.t.Errorf("reader %d: SeekTo(%d) = %v, expected %v", r.id, seq, err, wantErr)
+	}
+	return fmt.Sprintf("reader %d: SeekTo(%d)", r.id, seq)
+}
+
+func (c *checker) isBuffered(ev modelEvent) bool {
+	return len(c.buffer) != 0 && c.buffer[0].value <= ev.value
+}
+
+// skipStale skips the events that a lossy Reader would skip at the current time
+func (c *checker) skipStale(r *modelReader) {
+	if r.maxAge == 0 {
+		return
+	}
+
+	now := c.clock.Now()
+	for len(r.unseen) != 0 && now.Sub(r.unseen[0].timestamp) > r.maxAge {
+		r.seen = append(r.seen, r.unseen[0])
+		r.unseen = r.unseen[1:]
+	}
+	c.collect()
+}
+
+// collect drops events from the front of the buffer that every Reader has moved past
+func (c *checker) collect() {
+	for len(c.buffer) != 0 {
+		front := c.buffer[0].value
+		for _, r := range c.readers {
+			if len(r.unseen) != 0 && r.unseen[0].value <= front {
+				return
+			}
+		}
+
+		c.buffer = c.buffer[1:]
+		c.wantFullyConsumed += 1
+	}
+}
+
+// check compares the real Distributor to the model after an operation
+func (c *checker) check(step int, op string) {
+	fail := func(format string, args ...any) {
+		c.t.Errorf("after step %d (%s): %s", step, op, fmt.Sprintf(format, args...))
+	}
+
+	for _, r := range c.readers {
+		// WaitChan() may skip stale events, so the model must do the same first.
+		ch := r.real.WaitChan()
+		c.skipStale(r)
+
+		ready := isClosed(ch)
+		if want := len(r.unseen) != 0; ready != want {
+			fail("reader %d: WaitChan() ready = %v, expected %v", r.id, ready, want)
+		}
+	}
+
+	if c.submits != c.wantSubmits {
+		fail("OnSubmit called %d times, expected %d", c.submits, c.wantSubmits)
+	}
+	if c.fullyConsumed != c.wantFullyConsumed {
+		fail("OnFullyConsumed called %d times, expected %d", c.fullyConsumed, c.wantFullyConsumed)
+	}
+	if c.drops != c.wantDrops {
+		fail("OnDrop called %d times, expected %d", c.drops, c.wantDrops)
+	}
+	if c.bufsize != len(c.buffer) {
+		fail("last OnBufsizeChange was %d, expected %d", c.bufsize, len(c.buffer))
+	}
+
+	for value, ch := range c.pending {
+		// Events that are no longer buffered were either fully consumed or removed.
+		done := !c.inBuffer(value)
+		if closed := isClosed(ch); closed != done {
+			fail("Submit(%d) channel closed = %v, expected %v", value, closed, done)
+		}
+		if done {
+			delete(c.pending, value)
+		}
+	}
+}
+
+func (c *checker) inBuffer(value int) bool {
+	for _, ev := range c.buffer {
+		if ev.value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) unsubscribeAll() {
+	for _, r := range c.readers {
+		r.real.Unsubscribe()
+	}
+	c.readers = nil
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// modelClock is an eventdistributor.Clock that only moves forward when Advance is called
+type modelClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*modelTimer
+}
+
+type modelTimer struct {
+	clock *modelClock
+	at    time.Time
+	f     func()
+}
+
+func (c *modelClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *modelClock) AfterFunc(d time.Duration, f func()) eventdistributor.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &modelTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock forward, synchronously running any timers that fire as a result
+func (c *modelClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	for {
+		next := -1
+		for i, t := range c.timers {
+			if !t.at.After(target) && (next == -1 || t.at.Before(c.timers[next].at)) {
+				next = i
+			}
+		}
+		if next == -1 {
+			break
+		}
+
+		t := c.timers[next]
+		c.timers = append(c.timers[:next], c.timers[next+1:]...)
+		c.now = t.at
+		c.mu.Unlock()
+		t.f()
+		c.mu.Lock()
+	}
+	c.now = target
+	c.mu.Unlock()
+}
+
+func (t *modelTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, other := range t.clock.timers {
+		if other == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package eventdistributor
+
+import (
+	"errors"
+)
+
+// ErrEvicted is returned by operations on a Reader that was evicted for falling too far behind.
+// See (*Options[T]).WithEvictOverLag().
+var ErrEvicted = errors.New("reader evicted")
+
+// WithEvictOverLag limits how far behind any Reader can fall by evicting it: whenever an event is
+// submitted that would leave a Reader more than n events behind, the Reader is unsubscribed,
+// releasing its hold on any buffered events. Events that are then no longer held by any Reader are
+// fully consumed, as usual.
+//
+// Afterwards, the evicted Reader's WaitChan() returns a closed channel, TryConsume() returns
+// ErrEvicted, and Evicted() returns true, as with Readers that expire. Calling Unsubscribe() on an
+// evicted Reader is not necessary, but is allowed.
+//
+// WithEvictOverLag panics if n is not positive.
+func (o *Options[T]) WithEvictOverLag(n int) {
+	if n <= 0 {
+		panic("eventdistributor: WithEvictOverLag requires n > 0")
+	}
+
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.evictOverLag = n
+	})
+}
+
+// Evicted returns whether the Reader was evicted for falling too far behind. See
+// (*Options[T]).WithEvictOverLag().
+//
+// Evicted is thread-safe.
+func (r *Reader[T]) Evicted() bool {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	return r.evicted
+}
+
+// evictLaggingReaders evicts every Reader that is more than d.evictOverLag events behind. See
+// WithEvictOverLag.
+//
+// d.mu must be held.
+func (d *Distributor[T]) evictLaggingReaders() {
+	// No Reader can be further behind than the size of the buffer.
+	if d.evictOverLag == 0 || len(d.buf) <= d.evictOverLag {
+		return
+	}
+
+	// Evicting a Reader removes it from d.readers, so the laggards are found first.
+	var lagging []*readerState[T]
+	tail := d.basePosition + int64(len(d.buf))
+	for _, r := range d.readers {
+		if tail-r.position > int64(d.evictOverLag) {
+			lagging = append(lagging, r)
+		}
+	}
+
+	for _, r := range lagging {
+		r.evicted = true
+		if r.expiry != nil {
+			r.expiry.timer.Stop()
+		}
+		d.emitMeta(MetaEvent{Kind: MetaEvict, Reader: r.reader().readerInfo()})
+		r.reader().release()
+	}
+}
+
+// checkReleased returns an error if the Reader was released because it was unsubscribed, expired,
+// or was evicted.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) checkReleased() error {
+	if r.unsubscribed {
+		return ErrUnsubscribed
+	} else if err := r.checkExpiry(); err != nil {
+		return err
+	} else if r.evicted {
+		return ErrEvicted
+	}
+	return nil
+}
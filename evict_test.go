@@ -0,0 +1,82 @@
+package eventdistributor_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestEvictOverLag(t *testing.T) {
+	var consumed []int
+	var options eventdistributor.Options[MyEvent]
+	options.WithEvictOverLag(2)
+	options.OnFullyConsumed(func(e MyEvent) { consumed = append(consumed, e.id) })
+	d := eventdistributor.New(options)
+
+	fast := d.Subscribe()
+	defer fast.Unsubscribe()
+	slow := d.Subscribe()
+	defer slow.Unsubscribe()
+
+	d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 1})
+	require.Equal(t, []int{0, 1}, drainIDs(&fast))
+	require.False(t, slow.Evicted())
+	require.Empty(t, consumed)
+
+	t.Log("the slow Reader is evicted, releasing the events it held")
+	allConsumed := d.Submit(MyEvent{id: 2})
+	require.True(t, slow.Evicted())
+	require.Equal(t, []int{0, 1}, consumed)
+	ready(t, slow)
+	_, err := slow.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrEvicted)
+
+	require.Equal(t, []int{2}, drainIDs(&fast))
+	nowReady(t, allConsumed)
+	require.Equal(t, 1, d.Stats().Readers)
+}
+
+func TestEvictOverLagConcurrent(t *testing.T) {
+	var options eventdistributor.Options[int]
+	options.WithEvictOverLag(4)
+	d := eventdistributor.New(options)
+
+	var wg sync.WaitGroup
+	var evicted []bool
+	var mu sync.Mutex
+	for i := 0; i < 8; i++ {
+		r := d.Subscribe()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer r.Unsubscribe()
+
+			for {
+				<-r.WaitChan()
+				if _, err := r.TryConsume(); err == eventdistributor.ErrEvicted {
+					mu.Lock()
+					evicted = append(evicted, r.Evicted())
+					mu.Unlock()
+					return
+				} else if err == eventdistributor.ErrClosed {
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 1000; i++ {
+		d.Submit(i)
+	}
+	d.Close()
+	wg.Wait()
+
+	for _, e := range evicted {
+		require.True(t, e)
+	}
+	require.Equal(t, 0, d.Stats().Bufsize)
+}
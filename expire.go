@@ -0,0 +1,99 @@
+package eventdistributor
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrExpired is returned by operations on a Reader created by SubscribeFor, after its duration has
+// elapsed
+var ErrExpired = errors.New("reader expired")
+
+// SubscribeFor creates a new Reader that is automatically unsubscribed once duration has elapsed,
+// according to the Distributor's Clock.
+//
+// Until then, the Reader behaves normally. Afterwards, its hold on any unconsumed events is
+// released, WaitChan() returns a closed channel, and TryConsume() returns ErrExpired. Events
+// consumed before expiry are unaffected. Calling Unsubscribe() before expiry cancels it, and
+// calling Unsubscribe() after expiry is not necessary, but is allowed.
+//
+// Expiry is enforced both by a timer (so that an idle Reader does not hold events indefinitely) and
+// whenever the Reader is used, so an operation racing with expiry either happens entirely before it
+// or observes the Reader as expired.
+//
+// SubscribeFor is thread-safe.
+func (d *Distributor[T]) SubscribeFor(duration time.Duration) Reader[T] {
+	r := d.Subscribe()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	clock := d.getClock()
+	r.expiry = &readerExpiry{
+		deadline: clock.Now().Add(duration),
+		timer:    nil,
+		expired:  false,
+	}
+	state := r.readerState
+	r.expiry.timer = clock.AfterFunc(duration, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		// The Reader may have already been unsubscribed.
+		if !state.unsubscribed {
+			state.reader().expire()
+		}
+	})
+
+	return r
+}
+
+// Expired returns whether the Reader was created by SubscribeFor and has since expired.
+//
+// Expired is thread-safe.
+func (r *Reader[T]) Expired() bool {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	return r.checkExpiry() != nil
+}
+
+type readerExpiry struct {
+	deadline time.Time
+	timer    Timer
+	expired  bool
+}
+
+// checkExpiry expires the Reader if its deadline has passed, returning ErrExpired if the Reader is
+// now expired.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) checkExpiry() error {
+	if r.expiry == nil {
+		return nil
+	}
+
+	if !r.expiry.expired && !r.d.getClock().Now().Before(r.expiry.deadline) {
+		r.expire()
+	}
+
+	if r.expiry.expired {
+		return ErrExpired
+	}
+	return nil
+}
+
+// expire marks the Reader as expired, releasing its hold on any buffered events.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) expire() {
+	// If the Distributor was closed or the Reader was evicted, it has already been released.
+	if r.expiry.expired || r.evicted || r.d.closed {
+		return
+	}
+
+	r.expiry.expired = true
+	r.expiry.timer.Stop()
+	r.d.emitMeta(MetaEvent{Kind: MetaExpire, Reader: r.readerInfo()})
+	r.release()
+}
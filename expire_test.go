@@ -0,0 +1,117 @@
+package eventdistributor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSubscribeFor(t *testing.T) {
+	clock := newFakeClock()
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	d := eventdistributor.New(options)
+
+	r := d.SubscribeFor(10 * time.Second)
+
+	t.Log("before expiry, the reader behaves normally")
+	d.Submit(MyEvent{id: 1})
+	e, err := r.TryConsume()
+	require.NoError(t, err)
+	require.Equal(t, 1, e.id)
+	require.False(t, r.Expired())
+
+	t.Log("a waiting reader is woken on expiry")
+	waitChan := r.WaitChan()
+	nowNotReady(t, waitChan)
+	s2 := d.Submit(MyEvent{id: 2})
+	s3 := d.Submit(MyEvent{id: 3})
+	_ = r.WaitChan()
+	nowNotReady(t, s2)
+
+	clock.Advance(10 * time.Second)
+	nowReady(t, waitChan)
+	require.True(t, r.Expired())
+	_, err = r.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrExpired)
+	nowReady(t, r.WaitChan())
+
+	t.Log("expiry releases pending events")
+	nowReady(t, s2)
+	nowReady(t, s3)
+	nowReady(t, d.Submit(MyEvent{id: 4}))
+
+	t.Log("unsubscribing after expiry is allowed")
+	r.Unsubscribe()
+}
+
+func TestSubscribeForLazyExpiry(t *testing.T) {
+	clock := newFakeClock()
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	d := eventdistributor.New(options)
+
+	r := d.SubscribeFor(10 * time.Second)
+	defer r.Unsubscribe()
+
+	d.Submit(MyEvent{id: 1})
+	ready(t, r)
+
+	t.Log("even if the timer hasn't fired yet, an expired reader can't consume")
+	clock.Skip(10 * time.Second)
+	_, err := r.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrExpired)
+}
+
+func TestSubscribeForUnsubscribeEarly(t *testing.T) {
+	clock := newFakeClock()
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	d := eventdistributor.New(options)
+
+	r := d.SubscribeFor(10 * time.Second)
+	d.Submit(MyEvent{id: 1})
+	r.Unsubscribe()
+
+	t.Log("unsubscribing cancels the timer")
+	clock.mu.Lock()
+	require.Empty(t, clock.timers)
+	clock.mu.Unlock()
+}
+
+func TestSubscribeForRacingConsume(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.SubscribeFor(5 * time.Millisecond)
+	defer r.Unsubscribe()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for id := 0; ; id++ {
+			select {
+			case <-stop:
+				return
+			default:
+				d.Submit(MyEvent{id: id})
+			}
+		}
+	}()
+
+	t.Log("every consume either succeeds or observes expiry, and expiry is final")
+	last := -1
+	for {
+		<-r.WaitChan()
+		e, err := r.TryConsume()
+		if err == eventdistributor.ErrExpired {
+			break
+		}
+		require.NoError(t, err)
+		require.Greater(t, e.id, last)
+		last = e.id
+	}
+	_, err := r.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrExpired)
+}
@@ -0,0 +1,109 @@
+// Package expvarstats publishes the Stats of Distributors with the expvar package, so that they're
+// shown at "/debug/vars" alongside the rest of a program's variables.
+//
+// Each Distributor is published as a JSON object with its current Stats, which are read each time
+// the variable is shown. See (*eventdistributor.Distributor[T]).Stats().
+//
+// This is a separate package because importing expvar registers its handler with
+// http.DefaultServeMux, which programs that don't use it shouldn't have to do.
+package expvarstats
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+var (
+	mu sync.Mutex
+	// vars has the variable published for each name, including those that were unpublished
+	vars map[string]*statsVar
+)
+
+// statsVar is the expvar.Var published for a Distributor
+type statsVar struct {
+	// stats returns the Distributor's Stats, or is nil if the name was unpublished. It is protected
+	// by mu.
+	stats func() eventdistributor.Stats
+}
+
+// statsView is the JSON representation of a Distributor's Stats
+type statsView struct {
+	Bufsize       int   `json:"bufsize"`
+	Readers       int   `json:"readers"`
+	Submitted     int64 `json:"submitted"`
+	FullyConsumed int64 `json:"fullyConsumed"`
+	Discarded     int64 `json:"discarded"`
+	Dropped       int64 `json:"dropped"`
+	Deduped       int64 `json:"deduped"`
+	MaxBufsize    int   `json:"maxBufsize"`
+}
+
+// String implements expvar.Var
+func (v *statsVar) String() string {
+	mu.Lock()
+	stats := v.stats
+	mu.Unlock()
+
+	if stats == nil {
+		return "null"
+	}
+
+	// The Distributor's lock is only held while its Stats are copied.
+	s := stats()
+	b, _ := json.Marshal(statsView{
+		Bufsize:       s.Bufsize,
+		Readers:       s.Readers,
+		Submitted:     s.Submitted,
+		FullyConsumed: s.FullyConsumed,
+		Discarded:     s.Discarded,
+		Dropped:       s.Dropped,
+		Deduped:       s.Deduped,
+		MaxBufsize:    s.MaxBufsize,
+	})
+	return string(b)
+}
+
+// Publish publishes the Stats of the Distributor as the expvar variable with the name.
+//
+// expvar variables can't be removed, so if a Distributor was already published under the name, it
+// is replaced - even if it was unpublished. Publish returns an error if the name is used by a
+// variable that wasn't published by this package.
+//
+// Publish is thread-safe.
+func Publish[T any](name string, d *eventdistributor.Distributor[T]) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if v, ok := vars[name]; ok {
+		v.stats = d.Stats
+		return nil
+	} else if expvar.Get(name) != nil {
+		return fmt.Errorf("expvar variable %q is already published", name)
+	}
+
+	if vars == nil {
+		vars = make(map[string]*statsVar)
+	}
+	v := &statsVar{stats: d.Stats}
+	vars[name] = v
+	expvar.Publish(name, v)
+	return nil
+}
+
+// Unpublish stops publishing the Distributor published under the name, if there is one, so that
+// it is no longer referenced. The variable remains, with the value null, because expvar variables
+// can't be removed.
+//
+// Unpublish is thread-safe.
+func Unpublish(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if v, ok := vars[name]; ok {
+		v.stats = nil
+	}
+}
@@ -0,0 +1,60 @@
+package expvarstats_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+	"github.com/sharnoff/eventdistributor/expvarstats"
+)
+
+func get(t *testing.T, name string) map[string]any {
+	v := expvar.Get(name)
+	require.NotNil(t, v)
+
+	var value map[string]any
+	require.NoError(t, json.Unmarshal([]byte(v.String()), &value))
+	return value
+}
+
+func TestPublish(t *testing.T) {
+	d1 := eventdistributor.New[int]()
+	d2 := eventdistributor.New[string]()
+	require.NoError(t, expvarstats.Publish("test-publish-1", d1))
+	require.NoError(t, expvarstats.Publish("test-publish-2", d2))
+
+	r := d1.Subscribe()
+	defer r.Unsubscribe()
+	d1.Submit(1)
+	d1.Submit(2)
+	r.Consume()
+
+	require.Equal(t, map[string]any{
+		"bufsize":       1.0,
+		"readers":       1.0,
+		"submitted":     2.0,
+		"fullyConsumed": 1.0,
+		"discarded":     0.0,
+		"dropped":       0.0,
+		"deduped":       0.0,
+		"maxBufsize":    2.0,
+	}, get(t, "test-publish-1"))
+	require.Equal(t, 0.0, get(t, "test-publish-2")["submitted"])
+
+	t.Log("publishing under the same name replaces the Distributor")
+	require.NoError(t, expvarstats.Publish("test-publish-2", d1))
+	require.Equal(t, 2.0, get(t, "test-publish-2")["submitted"])
+
+	t.Log("unpublished Distributors are shown as null")
+	expvarstats.Unpublish("test-publish-2")
+	require.Equal(t, "null", expvar.Get("test-publish-2").String())
+
+	t.Log("names used by other variables are rejected")
+	if expvar.Get("test-publish-other") == nil {
+		expvar.NewInt("test-publish-other")
+	}
+	require.Error(t, expvarstats.Publish("test-publish-other", d1))
+}
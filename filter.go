@@ -0,0 +1,133 @@
+package eventdistributor
+
+// FilterInPlace removes every buffered event for which remove returns true, returning the number
+// of events removed.
+//
+// Removed events are passed to any OnDrop callbacks with DropReasonRemoved, and the channels
+// returned by Submit for them are closed. Pinned events are removed as well, releasing their pins.
+// Each Reader's remaining unseen events are exactly the unseen events it had before, minus the
+// ones that were removed.
+//
+// remove is called while the Distributor's lock is held, so it must not call any methods on the
+// Distributor or its Readers.
+//
+// FilterInPlace is thread-safe.
+func (d *Distributor[T]) FilterInPlace(remove func(T) bool) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.removeEvents(func(e *eventInfo[T]) bool { return remove(e.value) }, DropReasonRemoved)
+}
+
+// Clear removes every buffered event, returning the number of events removed. Every Reader moves
+// to the end of the buffer, so it only receives events submitted afterwards.
+//
+// Removed events weren't consumed, so they're passed to any OnDiscarded callbacks, and to OnDrop
+// callbacks with DropReasonCleared, instead of to OnFullyConsumed callbacks. The channels returned
+// by Submit for them are closed. Pinned events are removed as well, releasing their pins. Channels
+// returned by WaitChan() that were already closed stay closed, but there is nothing to consume
+// until the next event is submitted.
+//
+// Clear is thread-safe.
+func (d *Distributor[T]) Clear() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.removeEvents(func(*eventInfo[T]) bool { return true }, DropReasonCleared)
+}
+
+// OnDiscarded adds a callback that is called with each event that is removed by
+// (*Distributor[T]).Clear(). Discarded events are also passed to any OnDrop callbacks, with
+// DropReasonCleared, but never to OnFullyConsumed callbacks, because they weren't consumed.
+func (o *Options[T]) OnDiscarded(callback func(item T)) {
+	site := captureRegistrationSite()
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.onDiscarded = append(d.onDiscarded, watchCallback(d.watchdog, "OnDiscarded", site, callback))
+	})
+}
+
+// removeEvents implements FilterInPlace, Clear, and (Submission[T]).Cancel(), dropping the removed
+// events for the reason.
+//
+// d.mu must be held.
+func (d *Distributor[T]) removeEvents(remove func(*eventInfo[T]) bool, reason DropReason) int {
+	// removedBefore[i] is the number of removed events with index < i, for 0 <= i <= len(d.buf).
+	// We use it to remap each Reader's position.
+	removedBefore := make([]int64, len(d.buf)+1)
+	removed := 0
+
+	// refcounts from removed events are carried forward to the next remaining event, because the
+	// Readers positioned at a removed event will be positioned at the next remaining event.
+	var carriedRefcount int64
+	kept := d.buf[:0]
+	for i, e := range d.buf {
+		removedBefore[i] = int64(removed)
+
+		if remove(&e) {
+			removed += 1
+			// Removal overrides pins and acks, so their references are not carried forward.
+			carriedRefcount += e.refcount - d.voidPins(e.seq) - d.voidAckHolds(e.seq)
+			if reason == DropReasonCleared {
+				runCallbacks(&d.mu, d.onDiscarded, e.value)
+			}
+			d.dropped(e.value, e.seq, reason)
+			d.finish(e.allConsumed, e.onDone)
+			continue
+		}
+
+		e.refcount += carriedRefcount
+		carriedRefcount = 0
+		kept = append(kept, e)
+	}
+	removedBefore[len(d.buf)] = int64(removed)
+
+	if removed == 0 {
+		return 0
+	}
+
+	// Clear the now-unused tail of the buffer, so that the removed values can be garbage collected.
+	for i := len(kept); i < len(d.buf); i += 1 {
+		d.buf[i] = eventInfo[T]{}
+	}
+
+	// Readers move back along with the end of the buffer, which WaitChan() must not see separately.
+	// See (*Reader[T]).availableFast().
+	d.fastFilter.Add(1)
+	for _, r := range d.readers {
+		r.position -= removedBefore[r.position-d.basePosition]
+		r.reader().publishPosition()
+		if r.replay != nil {
+			r.replay.end -= removedBefore[r.replay.end-d.basePosition]
+		}
+	}
+	// Handlers being stopped only handle the events that remain from those they were draining. See
+	// StopHandlers().
+	for _, h := range d.managed {
+		if h.drainTo >= d.basePosition {
+			h.drainTo -= removedBefore[h.drainTo-d.basePosition]
+		}
+	}
+
+	d.buf = kept
+	d.publishTail()
+	d.fastFilter.Add(1)
+	d.nextRefcount += carriedRefcount
+	d.pruneAcks()
+
+	for _, r := range d.readers {
+		r.reader().checkReplayDone()
+		r.reader().checkCaughtUp()
+		r.reader().checkLag()
+	}
+	d.wakeProducers()
+
+	runCallbacks(&d.mu, d.onBufsizeChange.fs, len(d.buf))
+	// Removing events from the front of the buffer may mean that there are now events there that
+	// have already been fully consumed.
+	d.cleanupOldEvents()
+	// The buffer may be empty without any events being cleaned up.
+	d.checkDrained()
+	d.startIdle()
+
+	return removed
+}
@@ -0,0 +1,180 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestFilterInPlace(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var dropped []MyEvent
+	options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+		require.Equal(t, eventdistributor.DropReasonRemoved, reason)
+		dropped = append(dropped, e)
+	})
+	var sizeChanges []int
+	options.OnBufsizeChange(func(size int) {
+		sizeChanges = append(sizeChanges, size)
+	})
+
+	d := eventdistributor.New(options)
+	before := d.Subscribe()
+	inside := d.Subscribe()
+	after := d.Subscribe()
+
+	var submitted []<-chan struct{}
+	for id := 1; id <= 6; id++ {
+		submitted = append(submitted, d.Submit(MyEvent{id: id}))
+	}
+
+	inside.Consume()
+	inside.Consume()
+	inside.Consume()
+	for i := 0; i < 5; i++ {
+		after.Consume()
+	}
+
+	t.Log("remove events 3 and 4")
+	sizeChanges = nil
+	removed := d.FilterInPlace(func(e MyEvent) bool {
+		return e.id == 3 || e.id == 4
+	})
+	require.Equal(t, 2, removed)
+	require.Equal(t, []MyEvent{{id: 3}, {id: 4}}, dropped)
+	require.Equal(t, []int{4}, sizeChanges)
+	nowReady(t, submitted[2])
+	nowReady(t, submitted[3])
+	nowNotReady(t, submitted[4])
+
+	require.Equal(t, []int{1, 2, 5, 6}, drainIDs(&before))
+	require.Equal(t, []int{5, 6}, drainIDs(&inside))
+	require.Equal(t, []int{6}, drainIDs(&after))
+
+	t.Log("nothing removed")
+	d.Submit(MyEvent{id: 7})
+	require.Equal(t, 0, d.FilterInPlace(func(MyEvent) bool { return false }))
+	require.Equal(t, []int{7}, drainIDs(&after))
+}
+
+func TestFilterInPlaceExhaustive(t *testing.T) {
+	const n = 6
+
+	// For every subset of events to remove, have a reader at every possible offset into the
+	// buffer, and check that each one sees exactly its previous unseen events, minus the removed
+	// ones.
+	for mask := 0; mask < 1<<n; mask++ {
+		mask := mask
+		t.Run(fmt.Sprintf("mask=%06b", mask), func(t *testing.T) {
+			var options eventdistributor.Options[MyEvent]
+			fullyConsumed := 0
+			options.OnFullyConsumed(func(MyEvent) { fullyConsumed++ })
+			dropped := 0
+			options.OnDrop(func(MyEvent, eventdistributor.DropReason) { dropped++ })
+
+			d := eventdistributor.New(options)
+
+			var readers []eventdistributor.Reader[MyEvent]
+			for i := 0; i <= n; i++ {
+				readers = append(readers, d.Subscribe())
+			}
+			for id := 0; id < n; id++ {
+				d.Submit(MyEvent{id: id})
+			}
+			for i, r := range readers {
+				for j := 0; j < i; j++ {
+					r.Consume()
+				}
+			}
+
+			isRemoved := func(id int) bool { return mask&(1<<id) != 0 }
+			removed := d.FilterInPlace(func(e MyEvent) bool { return isRemoved(e.id) })
+			require.Equal(t, dropped, removed)
+
+			for i := range readers {
+				var expected []int
+				for id := i; id < n; id++ {
+					if !isRemoved(id) {
+						expected = append(expected, id)
+					}
+				}
+				require.Equal(t, expected, drainIDs(&readers[i]), "reader %d", i)
+			}
+
+			require.Equal(t, n, fullyConsumed+dropped)
+
+			for i := range readers {
+				readers[i].Unsubscribe()
+			}
+		})
+	}
+}
+
+func TestClear(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var discarded []int
+	options.OnDiscarded(func(e MyEvent) { discarded = append(discarded, e.id) })
+	var dropped []int
+	options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+		require.Equal(t, eventdistributor.DropReasonCleared, reason)
+		dropped = append(dropped, e.id)
+	})
+	var consumed []int
+	options.OnFullyConsumed(func(e MyEvent) { consumed = append(consumed, e.id) })
+	d := eventdistributor.New(options)
+
+	t.Log("clearing an empty buffer does nothing")
+	require.Equal(t, 0, d.Clear())
+
+	behind := d.Subscribe()
+	defer behind.Unsubscribe()
+	ahead := d.Subscribe()
+	defer ahead.Unsubscribe()
+
+	var submitted []<-chan struct{}
+	for id := 1; id <= 4; id++ {
+		submitted = append(submitted, d.Submit(MyEvent{id: id}))
+	}
+	ahead.Consume()
+	ahead.Consume()
+	wait := behind.WaitChan()
+
+	t.Log("every buffered event is dropped, without being reported as consumed")
+	require.Equal(t, 4, d.Clear())
+	require.Equal(t, []int{1, 2, 3, 4}, discarded)
+	require.Equal(t, []int{1, 2, 3, 4}, dropped)
+	require.Empty(t, consumed)
+	for _, ch := range submitted {
+		nowReady(t, ch)
+	}
+	require.NoError(t, d.WaitForDrain(context.Background()))
+
+	t.Log("Readers have nothing to consume until the next event")
+	nowReady(t, wait)
+	_, err := behind.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrNoEvent)
+	notReady(t, behind)
+	notReady(t, ahead)
+
+	d.Submit(MyEvent{id: 5})
+	require.Equal(t, []int{5}, drainIDs(&behind))
+	require.Equal(t, []int{5}, drainIDs(&ahead))
+	require.Equal(t, []int{5}, consumed)
+}
+
+// drainIDs consumes every available event from the reader, returning their ids
+func drainIDs(r *eventdistributor.Reader[MyEvent]) []int {
+	var ids []int
+	for {
+		select {
+		case <-r.WaitChan():
+			ids = append(ids, r.Consume().id)
+		default:
+			return ids
+		}
+	}
+}
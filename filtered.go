@@ -0,0 +1,116 @@
+package eventdistributor
+
+// SubscribeFiltered creates a new Reader that only receives events for which match returns true.
+// Other events are consumed automatically, without being delivered.
+//
+// Events are matched when they are submitted, if the Reader has already seen every earlier event,
+// and otherwise lazily - when the Reader calls WaitChan(), Consume(), or TryConsume(). The Reader's
+// WaitChan() is only woken by matching events. The filter can be replaced with SetFilter().
+//
+// match is called while the Distributor's lock is held, so it must not call any methods on the
+// Distributor or its Readers.
+//
+// SubscribeFiltered is thread-safe.
+func (d *Distributor[T]) SubscribeFiltered(match func(T) bool) Reader[T] {
+	r := d.Subscribe()
+	r.SetFilter(match)
+	return r
+}
+
+// SetFilter replaces the Reader's filter, so that it only receives events for which match returns
+// true. If match is nil, the filter is removed, and the Reader receives every event. See
+// (*Distributor[T]).SubscribeFiltered().
+//
+// The new filter applies to every event that the Reader has not yet consumed, as well as future
+// events. Events that were already consumed automatically, because they did not match the old
+// filter, are not restored.
+//
+// Because the filter may change between WaitChan() and Consume(), Readers whose filter is replaced
+// concurrently should use TryConsume() instead of Consume().
+//
+// The filter is replaced while the Distributor's lock is held, so every event is matched against
+// either the old filter or the new one, never a mix of the two.
+//
+// SetFilter panics if the Reader was created by SubscribeLIFO(), or its Distributor with
+// WithOrderBy.
+//
+// SetFilter is thread-safe.
+func (r *Reader[T]) SetFilter(match func(T) bool) {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if r.d.orderBy != nil {
+		panic("eventdistributor: SetFilter called on a Reader of a Distributor with WithOrderBy")
+	} else if r.lifo != nil {
+		panic("eventdistributor: SetFilter called on a Reader created by SubscribeLIFO")
+	}
+
+	// Readers that have been unsubscribed or released by Close aren't in the registry, so they
+	// aren't counted.
+	registered := r.registryIdx < len(r.d.readers) && r.d.readers[r.registryIdx] == r.readerState
+	if registered {
+		if r.filter != nil {
+			r.d.numFiltered -= 1
+		}
+		if match != nil {
+			r.d.numFiltered += 1
+		}
+	}
+	r.filter = match
+	r.publishPosition()
+}
+
+// filterAtSubmit moves every filtered Reader at the end of the buffer past value if it doesn't
+// match, returning the number of Readers that were moved. It must be called just before value is
+// added to the buffer, or if value is discarded instead, followed by unfilterAtSubmit.
+//
+// d.mu must be held.
+func (d *Distributor[T]) filterAtSubmit(value T) int64 {
+	if d.numFiltered == 0 {
+		return 0
+	}
+
+	var rejected int64
+	tail := d.basePosition + int64(len(d.buf))
+	for _, r := range d.readers {
+		if r.filter != nil && r.position == tail && !r.filter(value) {
+			r.position = tail + 1
+			r.reader().publishPosition()
+			rejected += 1
+		}
+	}
+	return rejected
+}
+
+// unfilterAtSubmit undoes filterAtSubmit, for an event that was discarded instead of added to the
+// buffer.
+//
+// d.mu must be held.
+func (d *Distributor[T]) unfilterAtSubmit() {
+	tail := d.basePosition + int64(len(d.buf))
+	for _, r := range d.readers {
+		if r.position == tail+1 {
+			r.position = tail
+			r.reader().publishPosition()
+		}
+	}
+}
+
+// skipFiltered consumes all pending events at the front of the Reader's unseen events that don't
+// match its filter, if it has one.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) skipFiltered() {
+	if r.filter == nil {
+		return
+	}
+
+	for r.hasPending() {
+		idx := int(r.position - r.d.basePosition)
+		if r.filter(r.d.buf[idx].value) {
+			return
+		}
+
+		r.consume()
+	}
+}
@@ -0,0 +1,80 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func isEven(e MyEvent) bool { return e.id%2 == 0 }
+
+func TestSubscribeFiltered(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var consumed []int
+	options.OnFullyConsumed(func(e MyEvent) {
+		consumed = append(consumed, e.id)
+	})
+	d := eventdistributor.New(options)
+
+	r := d.SubscribeFiltered(isEven)
+	defer r.Unsubscribe()
+
+	t.Log("non-matching events don't wake the Reader, and aren't held for it")
+	ch := r.WaitChan()
+	nowReady(t, d.Submit(MyEvent{id: 1}))
+	nowNotReady(t, ch)
+	require.Equal(t, []int{1}, consumed)
+
+	d.Submit(MyEvent{id: 2})
+	nowReady(t, ch)
+	d.Submit(MyEvent{id: 3})
+	require.Equal(t, []int{2}, drainIDs(&r))
+	require.Equal(t, []int{1, 2, 3}, consumed)
+}
+
+func TestSubscribeFilteredBehind(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	r := d.SubscribeFiltered(isEven)
+	defer r.Unsubscribe()
+
+	d.Submit(MyEvent{id: 0})
+	t.Log("events submitted while the Reader is behind are filtered lazily")
+	for id := 1; id <= 4; id++ {
+		d.Submit(MyEvent{id: id})
+	}
+	require.Equal(t, []int{0, 2, 4}, drainIDs(&r))
+}
+
+func TestSetFilter(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	r := d.SubscribeFiltered(isEven)
+	defer r.Unsubscribe()
+	other := d.Subscribe()
+	defer other.Unsubscribe()
+
+	d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 1})
+	d.Submit(MyEvent{id: 2})
+	d.Submit(MyEvent{id: 3})
+	require.Equal(t, 0, r.Consume().id)
+
+	t.Log("the new filter applies to pending events, but skipped events aren't restored")
+	require.Equal(t, 2, r.Consume().id)
+	r.SetFilter(func(e MyEvent) bool { return e.id%2 == 1 })
+	require.Equal(t, []int{3}, drainIDs(&r))
+
+	t.Log("and to future events")
+	d.Submit(MyEvent{id: 4})
+	d.Submit(MyEvent{id: 5})
+	require.Equal(t, []int{5}, drainIDs(&r))
+
+	t.Log("removing the filter")
+	r.SetFilter(nil)
+	d.Submit(MyEvent{id: 6})
+	require.Equal(t, []int{6}, drainIDs(&r))
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6}, drainIDs(&other))
+}
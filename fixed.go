@@ -0,0 +1,230 @@
+package eventdistributor
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrAllocatingOption is matched by the errors returned by NewFixed() when it is given an option
+// that allocates for each submitted event
+var ErrAllocatingOption = errors.New("option allocates for each event")
+
+// ErrBufferFull is returned by SubmitChecked() when a Distributor created by NewFixed() with
+// OverflowBlock has no room for another event
+var ErrBufferFull = errors.New("buffer is full")
+
+// OverflowPolicy determines what happens to an event that is submitted to a Distributor created by
+// NewFixed() when its buffer is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes submission wait until there is room in the buffer, like
+	// WithMaxReaderLag. SubmitWait() stops waiting if its context is canceled, and SubmitChecked()
+	// returns ErrBufferFull instead of waiting. Other ways of submitting that can't wait, like
+	// SubmitAll(), drop the new event instead, like OverflowDropNewest.
+	OverflowBlock OverflowPolicy = iota + 1
+	// OverflowDropNewest discards the new event, passing it to any OnDrop callbacks with
+	// DropReasonOverflow
+	OverflowDropNewest
+	// OverflowDropOldest makes room by removing the oldest buffered event. Every Reader that
+	// hasn't consumed it skips it, as counted by (*Reader[T]).Skipped(), and it is passed to any
+	// OnDrop callbacks with DropReasonOverflow.
+	//
+	// If the oldest event can't be removed - because it's pinned, or it's held by a Reader created
+	// by SubscribeAcked() - the new event is discarded instead, like OverflowDropNewest.
+	OverflowDropOldest
+)
+
+// String implements fmt.Stringer
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "Block"
+	case OverflowDropNewest:
+		return "DropNewest"
+	case OverflowDropOldest:
+		return "DropOldest"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+	}
+}
+
+// fixedStorage is the capacity and overflow policy of a Distributor created by NewFixed()
+type fixedStorage[T any] struct {
+	capacity int
+	policy   OverflowPolicy
+}
+
+// NewFixed creates a new Distributor whose buffer holds at most capacity events, with policy
+// determining what happens when an event is submitted while it's full. The storage for the buffer
+// is allocated up front and never grows, so that submitting and consuming events doesn't allocate.
+//
+// To stay allocation-free, a Distributor created by NewFixed differs from one created by New() in
+// a few ways:
+//
+//   - Consumption is never tracked: like SubmitQuiet(), Submit(), SubmitWait(), and SubmitChecked()
+//     return a nil channel for events that are buffered, so the channel must not be waited on.
+//   - Readers should wait with (*Reader[T]).Signal(), which doesn't allocate, instead of
+//     WaitChan().
+//
+// Options that allocate for each submitted event are rejected with an error matching
+// ErrAllocatingOption. These are WithCallerCapture, WithArchiver, WithKeyFunc,
+// WithOrderCheckByKey, and WithSortWindow. Other features that allocate - like Events(), or
+// producers blocking because of OverflowBlock or WithMaxReaderLag - can still be used, at their
+// usual cost.
+//
+// NewFixed panics if capacity is not positive, or policy is not a valid OverflowPolicy.
+func NewFixed[T any](
+	capacity int,
+	policy OverflowPolicy,
+	options ...Options[T],
+) (*Distributor[T], error) {
+	if capacity <= 0 {
+		panic("eventdistributor: NewFixed requires capacity > 0")
+	} else if policy < OverflowBlock || policy > OverflowDropOldest {
+		panic(fmt.Sprintf("eventdistributor: NewFixed called with invalid policy %v", policy))
+	}
+
+	d := New(options...)
+
+	var option string
+	if d.callerDepth != 0 {
+		option = "WithCallerCapture"
+	} else if d.archive != nil {
+		option = "WithArchiver"
+	} else if d.keyIndex != nil {
+		option = "WithKeyFunc"
+	} else if _, ok := d.orderCheck.(*orderCheck[T]); d.orderCheck != nil && !ok {
+		option = "WithOrderCheckByKey"
+	} else if d.sorter != nil {
+		option = "WithSortWindow"
+	}
+	if option != "" {
+		return nil, fmt.Errorf("%s can't be used with NewFixed: %w", option, ErrAllocatingOption)
+	}
+
+	// The storage has room for twice the capacity, so that the buffer only needs to be moved back to
+	// its start once per capacity events, on average. See compactBuf.
+	d.fixed = &fixedStorage[T]{capacity: capacity, policy: policy}
+	d.storage = make([]eventInfo[T], 2*capacity)
+	d.buf = d.storage[:0]
+	// New() decided whether to publish without the lock before the overflow policy was known.
+	d.updateFastPublish()
+	return d, nil
+}
+
+// bufferFull returns whether the Distributor was created by NewFixed() with OverflowBlock and
+// there is no room for another event, so producers should wait.
+//
+// d.mu must be held.
+func (d *Distributor[T]) bufferFull() bool {
+	return d.fixed != nil && d.fixed.policy == OverflowBlock && len(d.buf) >= d.fixed.capacity
+}
+
+// hasRoom returns whether makeRoom would succeed, without changing anything.
+//
+// d.mu must be held.
+func (d *Distributor[T]) hasRoom() bool {
+	return d.hasRoomFor(1)
+}
+
+// hasRoomFor returns whether n more events could be added to the buffer, one after the other,
+// without dropping any of them, and without changing anything. The new events are assumed to stay
+// in the buffer.
+//
+// d.mu must be held.
+func (d *Distributor[T]) hasRoomFor(n int) bool {
+	if d.fixed == nil || len(d.buf)+n <= d.fixed.capacity {
+		return true
+	} else if d.fixed.policy != OverflowDropOldest {
+		return false
+	}
+
+	// Each of the oldest events that must be removed can only be removed if every reference to it
+	// is from a Reader that can skip it, rather than a pin or a Reader created by SubscribeAcked().
+	// Removing one doesn't change whether the next can be, because the Readers that skip it only
+	// move on to the next.
+	drop := len(d.buf) + n - d.fixed.capacity
+	if drop > len(d.buf) {
+		return false
+	}
+	for i := 0; i < drop; i++ {
+		var skippable int64
+		for _, r := range d.readers {
+			if r.position == d.basePosition+int64(i) && r.acks == nil {
+				skippable += 1
+			}
+		}
+		if d.buf[i].refcount != skippable {
+			return false
+		}
+	}
+	return true
+}
+
+// makeRoom ensures there is room in the buffer for another event, for Distributors created by
+// NewFixed(), returning false if the new event must be dropped instead.
+//
+// d.mu must be held.
+func (d *Distributor[T]) makeRoom() bool {
+	if !d.hasRoom() {
+		return false
+	} else if d.fixed == nil || len(d.buf) < d.fixed.capacity {
+		return true
+	}
+
+	d.dropFront(DropReasonOverflow)
+	return true
+}
+
+// dropFront drops the oldest buffered event for the reason, even if some Readers haven't consumed
+// it yet. Readers positioned at the event skip it, and its pins and ack holds are voided.
+//
+// Unlike events that are fully consumed, the dropped event is only reported to OnDrop callbacks -
+// but it's still archived, so that Readers created by SubscribeFrom() can receive it.
+//
+// d.mu must be held.
+func (d *Distributor[T]) dropFront(reason DropReason) {
+	e := d.buf[0]
+	base := d.basePosition
+
+	voidedAcks := d.voidAckHolds(e.seq)
+	// The remaining references are from the Readers positioned at the event, which move to the next
+	// one.
+	refcount := e.refcount - d.voidPins(e.seq) - voidedAcks
+	d.archiveEvent(e.seq, e.timestamp, e.value)
+	d.dropped(e.value, e.seq, reason)
+	d.finish(e.allConsumed, e.onDone)
+
+	d.buf[0] = eventInfo[T]{}
+	d.buf = d.buf[1:]
+	d.basePosition += 1
+	if len(d.buf) == 0 {
+		d.buf = d.emptyBuf()
+		d.nextRefcount += refcount
+	} else {
+		d.buf[0].refcount += refcount
+	}
+	if voidedAcks != 0 {
+		d.pruneAcks()
+	}
+
+	for _, r := range d.readers {
+		if r.position == base {
+			r.position += 1
+			r.reader().publishPosition()
+			r.skipped += 1
+			r.reader().checkLag()
+			r.reader().checkReplayDone()
+			r.reader().checkCaughtUp()
+			r.reader().skipSeen()
+		}
+	}
+	d.wakeProducers()
+
+	// The events after the dropped one may have already been consumed, for example by Readers
+	// created by SubscribeLIFO().
+	d.cleanupOldEvents()
+	d.checkDrained()
+	d.startIdle()
+}
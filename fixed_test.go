@@ -0,0 +1,151 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestNewFixedAllocationFree(t *testing.T) {
+	d, err := eventdistributor.NewFixed[MyEvent](4, eventdistributor.OverflowDropNewest)
+	require.NoError(t, err)
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	sig := r.Signal()
+	ctx := context.Background()
+
+	// Keep a couple of events buffered, so that the buffer moves through its storage.
+	d.SubmitQuiet(MyEvent{id: -2})
+	d.SubmitQuiet(MyEvent{id: -1})
+
+	// Checks are made without require, which may allocate.
+	i := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		if d.Submit(MyEvent{id: i}) != nil {
+			t.Fatal("consumption tracked for a buffered event")
+		} else if err := sig.Wait(ctx); err != nil {
+			t.Fatal(err)
+		} else if id := r.Consume().id; id != i-2 {
+			t.Fatalf("consumed %d, expected %d", id, i-2)
+		}
+		i += 1
+	})
+	require.Zero(t, allocs)
+	require.Equal(t, []int{i - 2, i - 1}, drainIDs(&r))
+}
+
+func TestNewFixedRejectsAllocatingOptions(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithCallerCapture(4)
+	_, err := eventdistributor.NewFixed(4, eventdistributor.OverflowBlock, options)
+	require.ErrorIs(t, err, eventdistributor.ErrAllocatingOption)
+	require.Contains(t, err.Error(), "WithCallerCapture")
+
+	options = eventdistributor.Options[MyEvent]{}
+	eventdistributor.WithKeyFunc(&options, func(e MyEvent) int { return e.id }, 0)
+	_, err = eventdistributor.NewFixed(4, eventdistributor.OverflowBlock, options)
+	require.ErrorIs(t, err, eventdistributor.ErrAllocatingOption)
+
+	options = eventdistributor.Options[MyEvent]{}
+	options.WithTimestamps()
+	_, err = eventdistributor.NewFixed(4, eventdistributor.OverflowBlock, options)
+	require.NoError(t, err)
+
+	require.Panics(t, func() {
+		_, _ = eventdistributor.NewFixed[MyEvent](0, eventdistributor.OverflowBlock)
+	})
+}
+
+func TestNewFixedOverflow(t *testing.T) {
+	newFixed := func(policy eventdistributor.OverflowPolicy) (
+		*eventdistributor.Distributor[MyEvent],
+		*[]eventdistributor.DropReason,
+	) {
+		var drops []eventdistributor.DropReason
+		var options eventdistributor.Options[MyEvent]
+		options.OnDrop(func(_ MyEvent, reason eventdistributor.DropReason) {
+			drops = append(drops, reason)
+		})
+		d, err := eventdistributor.NewFixed(2, policy, options)
+		require.NoError(t, err)
+		return d, &drops
+	}
+
+	t.Log("OverflowDropNewest discards the new event")
+	d, drops := newFixed(eventdistributor.OverflowDropNewest)
+	r := d.Subscribe()
+	for i := 0; i < 4; i++ {
+		d.SubmitQuiet(MyEvent{id: i})
+	}
+	require.Equal(t, []int{0, 1}, drainIDs(&r))
+	require.Equal(t, []eventdistributor.DropReason{
+		eventdistributor.DropReasonOverflow,
+		eventdistributor.DropReasonOverflow,
+	}, *drops)
+	r.Unsubscribe()
+
+	t.Log("OverflowDropOldest makes Readers skip the oldest event")
+	d, drops = newFixed(eventdistributor.OverflowDropOldest)
+	r = d.Subscribe()
+	other := d.Subscribe()
+	d.SubmitQuiet(MyEvent{id: 0})
+	require.Equal(t, 0, other.Consume().id)
+	for i := 1; i < 4; i++ {
+		d.SubmitQuiet(MyEvent{id: i})
+	}
+	require.Equal(t, []int{2, 3}, drainIDs(&r))
+	require.Equal(t, []int{2, 3}, drainIDs(&other))
+	require.EqualValues(t, 2, r.Skipped())
+	require.EqualValues(t, 1, other.Skipped())
+	require.Len(t, *drops, 2)
+	// Dropped events are not also fully consumed.
+	require.EqualValues(t, 2, d.Stats().FullyConsumed)
+
+	t.Log("OverflowDropOldest can't remove pinned events")
+	d.SubmitQuiet(MyEvent{id: 4})
+	unpin, err := d.Pin(4)
+	require.NoError(t, err)
+	d.SubmitQuiet(MyEvent{id: 5})
+	d.SubmitQuiet(MyEvent{id: 6})
+	require.Equal(t, []int{4, 5}, drainIDs(&r))
+	require.Equal(t, []int{4, 5}, drainIDs(&other))
+	unpin()
+	r.Unsubscribe()
+	other.Unsubscribe()
+}
+
+func TestNewFixedBlock(t *testing.T) {
+	d, err := eventdistributor.NewFixed[MyEvent](2, eventdistributor.OverflowBlock)
+	require.NoError(t, err)
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	d.SubmitQuiet(MyEvent{id: 0})
+	d.SubmitQuiet(MyEvent{id: 1})
+	_, err = d.SubmitChecked(MyEvent{id: 2})
+	require.ErrorIs(t, err, eventdistributor.ErrBufferFull)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = d.SubmitWait(ctx, MyEvent{id: 2})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	submitted := make(chan struct{})
+	go func() {
+		d.SubmitQuiet(MyEvent{id: 2})
+		close(submitted)
+	}()
+	select {
+	case <-submitted:
+		t.Fatal("submitted while the buffer was full")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	require.Equal(t, 0, r.Consume().id)
+	<-submitted
+	require.Equal(t, []int{1, 2}, drainIDs(&r))
+}
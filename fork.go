@@ -0,0 +1,89 @@
+package eventdistributor
+
+import (
+	"sync"
+)
+
+// ForkAt creates a new Distributor, pre-loaded with a copy of every event in this Distributor's
+// buffer with a sequence number of at least seq. After that, the two evolve independently - unless
+// tee is true, in which case every event submitted to this Distributor afterwards is also submitted
+// to the fork, until stop is called.
+//
+// The copied events are held by the fork until its first Reader subscribes, which receives them,
+// along with anything forwarded in the meantime. Readers that subscribe after that start at the
+// live edge, as usual. Sequence numbers in the fork
+// start from zero.
+//
+// The copy is taken while the Distributor's lock is held, so it is a consistent cut: with tee,
+// every event is in the fork exactly once. Forwarding is done by a background goroutine that does
+// not hold this Distributor's lock while submitting into the fork.
+//
+// ForkAt returns ErrSeqEvicted if seq is before the oldest buffered event, ErrSeqInFuture if it is
+// after the live edge, and ErrClosed if the Distributor is closed.
+//
+// The returned stop function stops forwarding and waits for the background goroutine to exit. It is
+// safe to call more than once, and does nothing if tee is false.
+//
+// ForkAt is thread-safe.
+func (d *Distributor[T]) ForkAt(seq int64, tee bool) (_ *Distributor[T], stop func(), _ error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return nil, nil, ErrClosed
+	} else if seq > d.nextSeq {
+		return nil, nil, ErrSeqInFuture
+	} else if seq < d.nextSeq && (len(d.buf) == 0 || seq < d.buf[0].seq) {
+		return nil, nil, ErrSeqEvicted
+	}
+
+	fork := New[T]()
+
+	fork.mu.Lock()
+	seed := fork.subscribe()
+	fork.seed = seed.readerState
+	idx, _ := d.findSeq(seq)
+	for _, e := range d.buf[idx:] {
+		fork.submit(e.value, e.caller, nil, nil, nil, false, false)
+	}
+	fork.mu.Unlock()
+
+	if !tee {
+		return fork, func() {}, nil
+	}
+
+	// Subscribing while the lock is still held means that the Reader starts exactly where the copy
+	// ended.
+	r := d.subscribe()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer r.Unsubscribe()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-r.WaitChan():
+			}
+
+			value, err := r.TryConsume()
+			if err == ErrNoEvent {
+				continue
+			} else if err != nil {
+				// The source was closed, so there's nothing left to forward.
+				return
+			}
+			fork.SubmitQuiet(value)
+		}
+	}()
+
+	var stopOnce sync.Once
+	return fork, func() {
+		stopOnce.Do(func() { close(done) })
+		wg.Wait()
+	}, nil
+}
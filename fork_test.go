@@ -0,0 +1,74 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestForkAt(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	for id := 0; id < 4; id++ {
+		d.Submit(MyEvent{id: id})
+	}
+	require.Equal(t, 0, r.Consume().id)
+
+	fork, stop, err := d.ForkAt(1, false)
+	require.NoError(t, err)
+	defer stop()
+
+	t.Log("the fork's first Reader receives the copied events")
+	d.Submit(MyEvent{id: 4})
+	fr := fork.Subscribe()
+	defer fr.Unsubscribe()
+	require.Equal(t, []int{1, 2, 3}, drainIDs(&fr))
+
+	t.Log("later Readers start at the live edge")
+	late := fork.Subscribe()
+	defer late.Unsubscribe()
+	notReady(t, late)
+
+	t.Log("the two evolve independently")
+	fork.Submit(MyEvent{id: 100})
+	require.Equal(t, []int{100}, drainIDs(&fr))
+	require.Equal(t, []int{1, 2, 3, 4}, drainIDs(&r))
+
+	_, _, err = d.ForkAt(0, false)
+	require.ErrorIs(t, err, eventdistributor.ErrSeqEvicted)
+	_, _, err = d.ForkAt(6, false)
+	require.ErrorIs(t, err, eventdistributor.ErrSeqInFuture)
+}
+
+func TestForkAtTee(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 1})
+
+	fork, stop, err := d.ForkAt(1, true)
+	require.NoError(t, err)
+
+	fr := fork.Subscribe()
+	defer fr.Unsubscribe()
+
+	t.Log("events submitted to the source are forwarded to the fork")
+	d.Submit(MyEvent{id: 2})
+	require.Equal(t, 1, fr.Consume().id)
+	<-fr.WaitChan()
+	require.Equal(t, 2, fr.Consume().id)
+
+	t.Log("but not after stop")
+	stop()
+	stop()
+	d.Submit(MyEvent{id: 3})
+	notReady(t, fr)
+	require.Equal(t, []int{0, 1, 2, 3}, drainIDs(&r))
+}
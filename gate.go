@@ -0,0 +1,518 @@
+package eventdistributor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrDecided is returned by (GatedEvent[T]).Approve() and Reject() when the event no longer needs
+// a decision from the gate - i.e., the gate already approved or rejected it, another gate
+// rejected it, or it timed out. See SubscribeGate().
+var ErrDecided = errors.New("gated event was already decided")
+
+// ErrGateTimeout is the reason passed to OnReject callbacks for events that are rejected because a
+// gate didn't decide in time. See (*Options[T]).WithGateTimeout().
+var ErrGateTimeout = errors.New("gate timed out")
+
+// GateTimeoutPolicy determines what happens to a gated event that isn't decided in time. See
+// (*Options[T]).WithGateTimeout().
+type GateTimeoutPolicy int
+
+const (
+	// GateTimeoutApprove approves the event on behalf of every gate that hasn't decided
+	GateTimeoutApprove GateTimeoutPolicy = iota + 1
+	// GateTimeoutReject rejects the event, with ErrGateTimeout as the reason
+	GateTimeoutReject
+)
+
+// String implements fmt.Stringer
+func (p GateTimeoutPolicy) String() string {
+	switch p {
+	case GateTimeoutApprove:
+		return "Approve"
+	case GateTimeoutReject:
+		return "Reject"
+	default:
+		return fmt.Sprintf("GateTimeoutPolicy(%d)", int(p))
+	}
+}
+
+// WithGateTimeout limits how long a gated event can wait for its gates to decide, with the policy
+// determining what happens once timeout has passed since it reached the gates. Without a timeout, a
+// gated event waits until every gate has decided or unsubscribed. See SubscribeGate().
+//
+// WithGateTimeout panics if timeout is not positive, or policy is not a valid GateTimeoutPolicy.
+func (o *Options[T]) WithGateTimeout(timeout time.Duration, policy GateTimeoutPolicy) {
+	if timeout <= 0 {
+		panic("eventdistributor: WithGateTimeout requires timeout > 0")
+	} else if policy != GateTimeoutApprove && policy != GateTimeoutReject {
+		panic(fmt.Sprintf("eventdistributor: WithGateTimeout called with invalid policy %v", policy))
+	}
+
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		s := d.getGateStage()
+		s.timeout = timeout
+		s.timeoutPolicy = policy
+	})
+}
+
+// OnReject adds a callback that is called with each gated event that is rejected, along with the
+// reason given to (GatedEvent[T]).Reject(). Rejected events are also passed to any OnDrop
+// callbacks, with DropReasonRejected. See SubscribeGate().
+func (o *Options[T]) OnReject(callback func(item T, reason error)) {
+	site := captureRegistrationSite()
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.onReject = append(d.onReject, watchCallback2(d.watchdog, "OnReject", site, callback))
+	})
+}
+
+// gateStage is the set of gates and the events waiting for them. See SubscribeGate().
+type gateStage[T any] struct {
+	gates []*gateState[T]
+	// events is the gated events that have not yet been released, in the order they were
+	// submitted, and base is the number of gated events released before events[0]
+	events []*gatedEvent[T]
+	base   int64
+	// waiters, if not nil, is closed when a new event is gated, to wake waiting gates
+	waiters chan struct{}
+
+	// timeout, if non-zero, is the maximum time a gated event waits, with timeoutPolicy determining
+	// what happens after it. See WithGateTimeout.
+	timeout       time.Duration
+	timeoutPolicy GateTimeoutPolicy
+	// timer, if not nil, decides the first undecided event once it times out
+	timer Timer
+}
+
+// gatedEvent is a submitted event waiting for its gates
+type gatedEvent[T any] struct {
+	value  T
+	caller []uintptr
+	// allConsumed is the channel returned when the event was submitted, if it was tracked
+	allConsumed chan struct{}
+	// onDone is the callback passed to SubmitWithCallback(), if any
+	onDone func(struct{})
+	// token is the event's identity, if any. See eventToken.
+	token     *eventToken
+	submitted time.Time
+	// waiting is the number of gates that have yet to approve the event
+	waiting  int
+	rejected bool
+}
+
+// decided returns whether the event no longer needs any decisions - i.e., it was approved by every
+// gate, or rejected
+func (e *gatedEvent[T]) decided() bool {
+	return e.waiting == 0 || e.rejected
+}
+
+// GateReader receives every submitted event before ordinary Readers, which only receive each event
+// once every gate has approved it. It is created by (*Distributor[T]).SubscribeGate().
+//
+// Copies of a GateReader refer to the same subscription.
+type GateReader[T any] struct {
+	*gateState[T]
+}
+
+// gateState is the state of a GateReader, shared between all copies of it
+type gateState[T any] struct {
+	d *Distributor[T]
+	// next is the index of the next gated event to deliver to the gate, counting from the first
+	// event ever gated
+	next int64
+	// undecided is the set of events delivered to the gate that it hasn't yet approved or rejected
+	undecided map[*gatedEvent[T]]struct{}
+}
+
+// GatedEvent is an event delivered to a gate, which must be approved or rejected. See
+// SubscribeGate().
+type GatedEvent[T any] struct {
+	// Value is the submitted value
+	Value T
+
+	gate  *gateState[T]
+	event *gatedEvent[T]
+}
+
+// SubscribeGate creates a new gate, which receives every event submitted afterwards before it's
+// made available to ordinary Readers. Each event must be approved or rejected by the gate with
+// (GatedEvent[T]).Approve() or Reject().
+//
+// While there are gates, submitted events are held in a stage that ordinary Readers can't see -
+// their WaitChan() isn't closed - until every gate that existed when the event was submitted has
+// approved it. Events are released to Readers in the order they were submitted, so an approved
+// event also waits for any earlier events that are still undecided. If any gate rejects an event,
+// it is dropped, passing it to any OnReject callbacks and OnDrop callbacks with DropReasonRejected.
+//
+// Gated events don't have sequence numbers until they're released, and callbacks other than
+// OnSubmit are only called once they are. If the Distributor has a sort window, events are gated
+// once they leave it. See (*Options[T]).WithSortWindow().
+//
+// Unsubscribing a gate approves every event that is waiting for it. When the Distributor is
+// closed, gated events are dropped with DropReasonClosed. See also (*Options[T]).WithGateTimeout().
+//
+// SubscribeGate is thread-safe.
+func (d *Distributor[T]) SubscribeGate() GateReader[T] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := d.getGateStage()
+	g := &gateState[T]{
+		d:         d,
+		next:      s.base + int64(len(s.events)),
+		undecided: make(map[*gatedEvent[T]]struct{}),
+	}
+	if !d.closed {
+		s.gates = append(s.gates, g)
+	}
+	return GateReader[T]{gateState: g}
+}
+
+// getGateStage returns the Distributor's gate stage, creating it if necessary.
+//
+// d.mu must be held, unless the Distributor is still being created.
+func (d *Distributor[T]) getGateStage() *gateStage[T] {
+	if d.gate == nil {
+		d.gate = &gateStage[T]{
+			gates:         nil,
+			events:        nil,
+			base:          0,
+			waiters:       nil,
+			timeout:       0,
+			timeoutPolicy: 0,
+			timer:         nil,
+		}
+	}
+	return d.gate
+}
+
+// WaitChan returns a channel that is closed once there is an event for the gate to consume, or the
+// Distributor is closed.
+//
+// WaitChan is thread-safe.
+func (g *GateReader[T]) WaitChan() <-chan struct{} {
+	g.d.mu.Lock()
+	defer g.d.mu.Unlock()
+
+	if g.d.closed || g.hasPending() {
+		return closedChannel
+	}
+
+	s := g.d.gate
+	if s.waiters == nil {
+		s.waiters = make(chan struct{})
+	}
+	return s.waiters
+}
+
+// Consume returns the next event for the gate to decide.
+//
+// Consume must only be called when there is an event available - i.e., after WaitChan() has been
+// closed. Because events may be decided by other gates in the meantime, TryConsume() is usually
+// more appropriate.
+//
+// Consume is thread-safe.
+func (g *GateReader[T]) Consume() GatedEvent[T] {
+	e, err := g.TryConsume()
+	if err != nil {
+		panic(fmt.Errorf("eventdistributor: Consume called on GateReader without an event: %w", err))
+	}
+	return e
+}
+
+// TryConsume is like Consume, but returns ErrNoEvent if there is no event available, and ErrClosed
+// if the Distributor has been closed.
+//
+// Events that were already decided - for example, because another gate rejected them - are
+// skipped.
+//
+// TryConsume is thread-safe.
+func (g *GateReader[T]) TryConsume() (GatedEvent[T], error) {
+	g.d.mu.Lock()
+	defer g.d.mu.Unlock()
+
+	if g.d.closed {
+		return GatedEvent[T]{}, ErrClosed
+	} else if !g.hasPending() {
+		return GatedEvent[T]{}, ErrNoEvent
+	}
+
+	s := g.d.gate
+	e := s.events[g.next-s.base]
+	g.next += 1
+	g.undecided[e] = struct{}{}
+	return GatedEvent[T]{Value: e.value, gate: g.gateState, event: e}, nil
+}
+
+// Unsubscribe removes the gate, approving every event that is waiting for it.
+//
+// Unsubscribe is thread-safe.
+func (g *GateReader[T]) Unsubscribe() {
+	d := g.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := d.gate
+	for i, other := range s.gates {
+		if other == g.gateState {
+			last := len(s.gates) - 1
+			s.gates[i] = s.gates[last]
+			s.gates[last] = nil
+			s.gates = s.gates[:last]
+			break
+		}
+	}
+
+	if !d.closed {
+		for e := range g.undecided {
+			if !e.decided() {
+				e.waiting -= 1
+			}
+		}
+		g.hasPending()
+		for _, e := range s.events[g.next-s.base:] {
+			if !e.decided() {
+				e.waiting -= 1
+			}
+		}
+		d.releaseGated()
+	}
+
+	g.undecided = nil
+	// For safety, remove the Distributor pointer so that future calls will panic, rather than
+	// silently corrupt the stage.
+	g.d = nil
+}
+
+// hasPending returns whether there is an event that the gate has not yet seen, skipping any events
+// that were already decided.
+//
+// g.d.mu must be held.
+func (g *gateState[T]) hasPending() bool {
+	s := g.d.gate
+	if g.next < s.base {
+		g.next = s.base
+	}
+	for g.next-s.base < int64(len(s.events)) && s.events[g.next-s.base].decided() {
+		g.next += 1
+	}
+	return g.next-s.base < int64(len(s.events))
+}
+
+// Approve approves the event on behalf of the gate. Once every gate has approved it, and every
+// earlier event has been released or dropped, it is made available to ordinary Readers.
+//
+// Approve returns ErrDecided if the event no longer needs a decision from the gate, and ErrClosed
+// if the Distributor has been closed.
+//
+// Approve is thread-safe.
+func (e GatedEvent[T]) Approve() error {
+	d := e.gate.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := e.take(); err != nil {
+		return err
+	}
+
+	e.event.waiting -= 1
+	d.releaseGated()
+	return nil
+}
+
+// Reject rejects the event, so that it is dropped instead of being made available to Readers. The
+// reason is passed to any OnReject callbacks.
+//
+// Reject returns ErrDecided if the event no longer needs a decision from the gate, and ErrClosed
+// if the Distributor has been closed.
+//
+// Reject is thread-safe.
+func (e GatedEvent[T]) Reject(reason error) error {
+	d := e.gate.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := e.take(); err != nil {
+		return err
+	}
+
+	d.rejectGated(e.event, reason)
+	d.releaseGated()
+	return nil
+}
+
+// take removes the event from the gate's undecided events, returning an error if it doesn't need a
+// decision from the gate.
+//
+// e.gate.d.mu must be held.
+func (e GatedEvent[T]) take() error {
+	if e.gate.d.closed {
+		return ErrClosed
+	} else if _, ok := e.gate.undecided[e.event]; !ok {
+		return ErrDecided
+	}
+
+	delete(e.gate.undecided, e.event)
+	if e.event.decided() {
+		return ErrDecided
+	}
+	return nil
+}
+
+// admit adds the event to the buffer, or holds it until it's approved if there are any gates.
+// Otherwise, it is the same as add.
+//
+// d.mu must be held.
+func (d *Distributor[T]) admit(
+	value T,
+	caller []uintptr,
+	allConsumed chan struct{},
+	onDone func(struct{}),
+	token *eventToken,
+	track bool,
+	direct bool,
+) (<-chan struct{}, int64) {
+	s := d.gate
+	if s == nil || len(s.gates) == 0 {
+		return d.add(value, caller, allConsumed, onDone, token, track, direct)
+	}
+
+	if allConsumed == nil && track {
+		allConsumed = make(chan struct{})
+	}
+	e := &gatedEvent[T]{
+		value:       value,
+		caller:      caller,
+		allConsumed: allConsumed,
+		onDone:      onDone,
+		token:       token,
+		submitted:   time.Time{},
+		waiting:     len(s.gates),
+		rejected:    false,
+	}
+	if s.timeout != 0 {
+		e.submitted = d.getClock().Now()
+	}
+	s.events = append(s.events, e)
+
+	if s.waiters != nil {
+		close(s.waiters)
+		s.waiters = nil
+	}
+	d.armGateTimer()
+	return allConsumed, -1
+}
+
+// rejectGated rejects the gated event, dropping it.
+//
+// d.mu must be held.
+func (d *Distributor[T]) rejectGated(e *gatedEvent[T], reason error) {
+	e.rejected = true
+	runCallbacks2(&d.mu, d.onReject, e.value, reason)
+	d.dropped(e.value, -1, DropReasonRejected)
+	d.finish(e.allConsumed, e.onDone)
+}
+
+// releaseGated adds the gated events at the front of the stage to the buffer, for as long as
+// they've been decided.
+//
+// d.mu must be held.
+func (d *Distributor[T]) releaseGated() {
+	s := d.gate
+	for len(s.events) != 0 && s.events[0].decided() {
+		e := s.events[0]
+		s.events[0] = nil
+		s.events = s.events[1:]
+		s.base += 1
+
+		if !e.rejected {
+			d.add(e.value, e.caller, e.allConsumed, e.onDone, e.token, false, false)
+		}
+	}
+	// Rejected events, and those discarded because there are no Readers, leave nothing buffered.
+	d.checkDrained()
+}
+
+// armGateTimer arranges for the first undecided gated event to be decided once it times out, if
+// there is a gate timeout.
+//
+// d.mu must be held.
+func (d *Distributor[T]) armGateTimer() {
+	s := d.gate
+	if s.timeout == 0 || s.timer != nil {
+		return
+	}
+
+	for _, e := range s.events {
+		if e.decided() {
+			continue
+		}
+
+		wait := e.submitted.Add(s.timeout).Sub(d.getClock().Now())
+		s.timer = d.getClock().AfterFunc(wait, func() {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+
+			s.timer = nil
+			if !d.closed {
+				d.expireGated()
+			}
+		})
+		return
+	}
+}
+
+// expireGated decides every gated event that has timed out, according to the timeout policy.
+//
+// d.mu must be held.
+func (d *Distributor[T]) expireGated() {
+	s := d.gate
+	now := d.getClock().Now()
+	for _, e := range s.events {
+		if e.decided() {
+			continue
+		} else if now.Before(e.submitted.Add(s.timeout)) {
+			break
+		}
+
+		if s.timeoutPolicy == GateTimeoutApprove {
+			e.waiting = 0
+		} else {
+			d.rejectGated(e, ErrGateTimeout)
+		}
+	}
+
+	d.releaseGated()
+	d.armGateTimer()
+}
+
+// dropGated drops every gated event, because the Distributor was closed.
+//
+// d.mu must be held.
+func (d *Distributor[T]) dropGated() {
+	s := d.gate
+	if s == nil {
+		return
+	}
+
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	for _, e := range s.events {
+		if !e.rejected {
+			d.dropped(e.value, -1, DropReasonClosed)
+			d.finish(e.allConsumed, e.onDone)
+		}
+	}
+	s.base += int64(len(s.events))
+	s.events = nil
+	s.gates = nil
+
+	// Wake every waiting gate, so that they can observe that the Distributor is closed.
+	if s.waiters != nil {
+		close(s.waiters)
+		s.waiters = nil
+	}
+}
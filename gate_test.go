@@ -0,0 +1,166 @@
+package eventdistributor_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSubscribeGate(t *testing.T) {
+	var rejected []error
+	var drops []eventdistributor.DropReason
+	var options eventdistributor.Options[MyEvent]
+	options.OnReject(func(_ MyEvent, reason error) { rejected = append(rejected, reason) })
+	options.OnDrop(func(_ MyEvent, reason eventdistributor.DropReason) {
+		drops = append(drops, reason)
+	})
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	gate := d.SubscribeGate()
+
+	allConsumed := d.Submit(MyEvent{id: 1})
+	d.Submit(MyEvent{id: 2})
+	d.Submit(MyEvent{id: 3})
+	notReady(t, r)
+	nowReady(t, gate.WaitChan())
+
+	e1 := gate.Consume()
+	e2 := gate.Consume()
+	e3 := gate.Consume()
+	require.Equal(t, 1, e1.Value.id)
+	nowNotReady(t, gate.WaitChan())
+
+	t.Log("approved events wait for earlier undecided events")
+	require.NoError(t, e2.Approve())
+	notReady(t, r)
+	require.NoError(t, e1.Approve())
+	require.Equal(t, []int{1, 2}, drainIDs(&r))
+	nowReady(t, allConsumed)
+	require.ErrorIs(t, e1.Approve(), eventdistributor.ErrDecided)
+
+	t.Log("rejected events are dropped")
+	reason := errors.New("invalid")
+	require.NoError(t, e3.Reject(reason))
+	require.Equal(t, []error{reason}, rejected)
+	require.Equal(t, []eventdistributor.DropReason{eventdistributor.DropReasonRejected}, drops)
+	notReady(t, r)
+
+	t.Log("events submitted after the last gate unsubscribes are not gated")
+	gate.Unsubscribe()
+	d.Submit(MyEvent{id: 4})
+	require.Equal(t, []int{4}, drainIDs(&r))
+}
+
+func TestSubscribeGateMultiple(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	g1 := d.SubscribeGate()
+	defer g1.Unsubscribe()
+	g2 := d.SubscribeGate()
+
+	d.Submit(MyEvent{id: 1})
+	d.Submit(MyEvent{id: 2})
+	d.Submit(MyEvent{id: 3})
+
+	t.Log("every gate must approve")
+	require.NoError(t, g1.Consume().Approve())
+	notReady(t, r)
+	require.NoError(t, g2.Consume().Approve())
+	require.Equal(t, []int{1}, drainIDs(&r))
+
+	t.Log("one rejection is enough to drop an event")
+	e2 := g1.Consume()
+	require.NoError(t, e2.Reject(nil))
+	require.ErrorIs(t, e2.Reject(nil), eventdistributor.ErrDecided)
+	// g2 skips the rejected event.
+	e3 := g2.Consume()
+	require.Equal(t, 3, e3.Value.id)
+	require.NoError(t, e3.Approve())
+	notReady(t, r)
+
+	t.Log("unsubscribing a gate approves what's waiting for it")
+	d.Submit(MyEvent{id: 4})
+	g2.Unsubscribe()
+	notReady(t, r)
+	require.NoError(t, g1.Consume().Approve())
+	require.NoError(t, g1.Consume().Approve())
+	require.Equal(t, []int{3, 4}, drainIDs(&r))
+
+	_, err := g1.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrNoEvent)
+}
+
+func TestWithGateTimeout(t *testing.T) {
+	clock := newFakeClock()
+	var rejected []error
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	options.WithGateTimeout(time.Second, eventdistributor.GateTimeoutReject)
+	options.OnReject(func(_ MyEvent, reason error) { rejected = append(rejected, reason) })
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	gate := d.SubscribeGate()
+	defer gate.Unsubscribe()
+
+	d.Submit(MyEvent{id: 1})
+	clock.Advance(500 * time.Millisecond)
+	d.Submit(MyEvent{id: 2})
+	e1 := gate.Consume()
+
+	clock.Advance(500 * time.Millisecond)
+	require.Equal(t, []error{eventdistributor.ErrGateTimeout}, rejected)
+	require.ErrorIs(t, e1.Approve(), eventdistributor.ErrDecided)
+	notReady(t, r)
+
+	require.NoError(t, gate.Consume().Approve())
+	require.Equal(t, []int{2}, drainIDs(&r))
+
+	t.Log("GateTimeoutApprove releases stalled events")
+	options = eventdistributor.Options[MyEvent]{}
+	options.WithClock(clock)
+	options.WithGateTimeout(time.Second, eventdistributor.GateTimeoutApprove)
+	d = eventdistributor.New(options)
+	r2 := d.Subscribe()
+	defer r2.Unsubscribe()
+	gate2 := d.SubscribeGate()
+	defer gate2.Unsubscribe()
+
+	d.Submit(MyEvent{id: 3})
+	clock.Advance(time.Second)
+	require.Equal(t, []int{3}, drainIDs(&r2))
+}
+
+func TestSubscribeGateClose(t *testing.T) {
+	var drops []eventdistributor.DropReason
+	var options eventdistributor.Options[MyEvent]
+	options.OnDrop(func(_ MyEvent, reason eventdistributor.DropReason) {
+		drops = append(drops, reason)
+	})
+	d := eventdistributor.New(options)
+
+	gate := d.SubscribeGate()
+	defer gate.Unsubscribe()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	allConsumed := d.Submit(MyEvent{id: 1})
+	e := gate.Consume()
+	wait := gate.WaitChan()
+
+	d.Close()
+	nowReady(t, allConsumed)
+	nowReady(t, wait)
+	require.Equal(t, []eventdistributor.DropReason{eventdistributor.DropReasonClosed}, drops)
+	require.ErrorIs(t, e.Approve(), eventdistributor.ErrClosed)
+	_, err := gate.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrClosed)
+}
@@ -0,0 +1,234 @@
+package eventdistributor
+
+// Group is a set of GroupReaders that compete for events, like the workers of a work queue: each
+// event is received by exactly one member of the group. It is created by
+// (*Distributor[T]).NewGroup().
+//
+// To the Distributor, the Group is a single Reader - it holds events from the time it's created
+// until any member receives them, whether or not it has any members in the meantime - so limits
+// like WithMaxReaderLag apply to the Group as a whole.
+//
+// Copies of a Group refer to the same group.
+type Group[T any] struct {
+	*groupState[T]
+}
+
+// groupState is the state of a Group, shared between all copies of it
+type groupState[T any] struct {
+	// r is the Group's Reader, shared by its members. Members claim events by consuming them from
+	// r, which can only happen once for each event.
+	r Reader[T]
+	// waiting is the members waiting for an event, in the order they started waiting. Waiting
+	// members are woken one at a time, as events become available. See wake().
+	waiting []*groupMember[T]
+}
+
+// NewGroup creates a new Group, which receives every event submitted afterwards, and passes each of
+// them to one of its members. Members are added with (Group[T]).Subscribe().
+//
+// It is STRONGLY recommended to defer (Group[T]).Unsubscribe() immediately after creating the
+// Group.
+//
+// NewGroup is thread-safe.
+func (d *Distributor[T]) NewGroup() Group[T] {
+	g := &groupState[T]{
+		r:       d.Subscribe(),
+		waiting: nil,
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	g.r.notify = g.wake
+	return Group[T]{groupState: g}
+}
+
+// Subscribe adds a new member to the Group, which competes with the other members for the Group's
+// events.
+//
+// Subscribe is thread-safe.
+func (g Group[T]) Subscribe() GroupReader[T] {
+	return GroupReader[T]{groupMember: &groupMember[T]{
+		g:            g.groupState,
+		waitCh:       nil,
+		woken:        false,
+		unsubscribed: false,
+	}}
+}
+
+// Unsubscribe de-registers the Group, freeing any buffered events that may have been kept for it,
+// and waking its waiting members, which can then observe that it's no longer usable. It returns
+// whether this call was the one that unsubscribed the Group. For more information, see
+// (*Reader[T]).Unsubscribe().
+//
+// Unsubscribe is thread-safe.
+func (g Group[T]) Unsubscribe() bool {
+	return g.r.Unsubscribe()
+}
+
+// wake wakes the first waiting member, once an event is available for the Group. The others keep
+// waiting, so that each event wakes one more member. If the Group can no longer be used, every
+// waiting member is woken instead.
+//
+// It is called as the Group's Reader is woken. See (*Reader[T]).wakeOwnWaiter().
+//
+// d.mu must be held.
+func (g *groupState[T]) wake() {
+	if g.r.d.closed || g.r.checkReleased() != nil {
+		for _, m := range g.waiting {
+			close(m.waitCh)
+			m.waitCh = nil
+		}
+		g.waiting = nil
+		return
+	}
+
+	g.wakeNext()
+	if len(g.waiting) != 0 {
+		g.r.notifyWaiting = true
+	}
+}
+
+// wakeNext wakes the first waiting member, if there is one.
+//
+// d.mu must be held.
+func (g *groupState[T]) wakeNext() {
+	if len(g.waiting) == 0 {
+		return
+	}
+
+	m := g.waiting[0]
+	g.waiting[0] = nil
+	g.waiting = g.waiting[1:]
+	close(m.waitCh)
+	m.waitCh = nil
+	m.woken = true
+}
+
+// passOn wakes the next waiting member if the Group still has an event available, after a member
+// that was woken for it has unsubscribed.
+//
+// d.mu must be held.
+func (g *groupState[T]) passOn() {
+	if g.r.prepare() == nil && g.r.available() {
+		g.wakeNext()
+	}
+}
+
+// GroupReader is a member of a Group, which competes with the other members for the Group's events.
+// It is created by (Group[T]).Subscribe().
+//
+// A GroupReader is used like a Reader, with WaitChan() and TryConsume(). There is no Consume(),
+// because the event that closed the channel returned by WaitChan() may be received by another
+// member first, in which case TryConsume() returns ErrNoEvent, and the member should wait again.
+//
+// Copies of a GroupReader refer to the same member.
+type GroupReader[T any] struct {
+	*groupMember[T]
+}
+
+// groupMember is the state of a GroupReader, shared between all copies of it
+type groupMember[T any] struct {
+	g *groupState[T]
+	// waitCh, if not nil, is the channel returned by WaitChan() while the member is in g.waiting
+	waitCh chan struct{}
+	// woken is true if the member was woken for an event, and hasn't yet tried to receive it. If it
+	// unsubscribes instead, the next waiting member is woken in its place.
+	woken        bool
+	unsubscribed bool
+}
+
+// WaitChan returns a channel that will be closed once the Group has an event that no member has
+// received yet, or once the member can no longer be used.
+//
+// Waiting members are woken one at a time, in the order they started waiting, with one member woken
+// for each event. If a woken member unsubscribes instead of receiving an event, the next waiting
+// member is woken in its place. So, as long as woken members try to receive their events, no event
+// is left waiting while members are.
+//
+// WaitChan is thread-safe.
+func (m GroupReader[T]) WaitChan() <-chan struct{} {
+	g := m.g
+	if g.r.availableFast() {
+		return closedChannel
+	}
+
+	d := g.r.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if m.unsubscribed || g.r.prepare() != nil || g.r.available() {
+		return closedChannel
+	}
+
+	m.woken = false
+	if m.waitCh == nil {
+		m.waitCh = make(chan struct{})
+		g.waiting = append(g.waiting, m.groupMember)
+	}
+
+	r := g.r
+	r.startWait()
+	if !r.isOwnWaiter() {
+		d.ownWaiters = append(d.ownWaiters, r.readerState)
+	}
+	r.notifyWaiting = true
+	return m.waitCh
+}
+
+// TryConsume returns the first event that no member of the Group has received yet, marking it as
+// received by this member. If there is no such event, TryConsume returns ErrNoEvent. If the member
+// or its Group can no longer be used, TryConsume returns the reason, like
+// (*Reader[T]).TryConsume().
+//
+// TryConsume is thread-safe.
+func (m GroupReader[T]) TryConsume() (T, error) {
+	d := m.g.r.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if m.unsubscribed {
+		var zero T
+		return zero, ErrUnsubscribed
+	}
+
+	m.woken = false
+	value, _, err := m.g.r.tryConsume()
+	return value, err
+}
+
+// Unsubscribe removes the member from its Group, returning whether this call was the one that
+// unsubscribed it. The Group keeps receiving events for its other members, and if the member was
+// woken for an event that it didn't receive, the next waiting member is woken instead.
+//
+// Unsubscribe is thread-safe.
+func (m GroupReader[T]) Unsubscribe() bool {
+	g := m.g
+	d := g.r.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if m.unsubscribed {
+		return false
+	}
+	m.unsubscribed = true
+
+	if m.waitCh != nil {
+		for i, other := range g.waiting {
+			if other == m.groupMember {
+				last := len(g.waiting) - 1
+				copy(g.waiting[i:], g.waiting[i+1:])
+				g.waiting[last] = nil
+				g.waiting = g.waiting[:last]
+				break
+			}
+		}
+		close(m.waitCh)
+		m.waitCh = nil
+	}
+	if m.woken {
+		m.woken = false
+		g.passOn()
+	}
+	return true
+}
@@ -0,0 +1,212 @@
+package eventdistributor_test
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestGroup(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	g := d.NewGroup()
+	defer g.Unsubscribe()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	m1 := g.Subscribe()
+	defer m1.Unsubscribe()
+	m2 := g.Subscribe()
+	defer m2.Unsubscribe()
+
+	for i := 0; i < 4; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+
+	t.Log("each event is received by one member, while other Readers receive every event")
+	var ids []int
+	for _, m := range []eventdistributor.GroupReader[MyEvent]{m1, m2, m2, m1} {
+		nowReady(t, m.WaitChan())
+		e, err := m.TryConsume()
+		require.NoError(t, err)
+		ids = append(ids, e.id)
+	}
+	require.Equal(t, []int{0, 1, 2, 3}, ids)
+	for _, m := range []eventdistributor.GroupReader[MyEvent]{m1, m2} {
+		nowNotReady(t, m.WaitChan())
+		_, err := m.TryConsume()
+		require.ErrorIs(t, err, eventdistributor.ErrNoEvent)
+	}
+	require.Equal(t, []int{0, 1, 2, 3}, drainIDs(&r))
+
+	t.Log("the Group holds events even while it has no members")
+	require.True(t, m1.Unsubscribe())
+	require.False(t, m1.Unsubscribe())
+	require.True(t, m2.Unsubscribe())
+	done := d.Submit(MyEvent{id: 4})
+	require.Equal(t, []int{4}, drainIDs(&r))
+	nowNotReady(t, done)
+
+	m3 := g.Subscribe()
+	defer m3.Unsubscribe()
+	e, err := m3.TryConsume()
+	require.NoError(t, err)
+	require.Equal(t, 4, e.id)
+	nowReady(t, done)
+
+	t.Log("unsubscribed members can't be used")
+	nowReady(t, m1.WaitChan())
+	_, err = m1.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrUnsubscribed)
+}
+
+func TestGroupWaitChan(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	g := d.NewGroup()
+	defer g.Unsubscribe()
+
+	m1, m2, m3 := g.Subscribe(), g.Subscribe(), g.Subscribe()
+	w1, w2, w3 := m1.WaitChan(), m2.WaitChan(), m3.WaitChan()
+	require.Equal(t, w1, m1.WaitChan())
+
+	t.Log("each event wakes one more waiting member, in the order they started waiting")
+	d.Submit(MyEvent{id: 0})
+	nowReady(t, w1)
+	nowNotReady(t, w2)
+	nowNotReady(t, w3)
+	d.Submit(MyEvent{id: 1})
+	nowReady(t, w2)
+	nowNotReady(t, w3)
+
+	t.Log("any member can receive an available event, even one woken for another member")
+	_, err := m1.TryConsume()
+	require.NoError(t, err)
+	nowNotReady(t, w3)
+	_, err = m1.TryConsume()
+	require.NoError(t, err)
+	nowNotReady(t, w3)
+	d.Submit(MyEvent{id: 2})
+	nowReady(t, w3)
+	e, err := m3.TryConsume()
+	require.NoError(t, err)
+	require.Equal(t, 2, e.id)
+
+	t.Log("a woken member whose event was received by another member waits again")
+	_, err = m2.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrNoEvent)
+	w2 = m2.WaitChan()
+	nowNotReady(t, w2)
+	d.Submit(MyEvent{id: 3})
+	nowReady(t, w2)
+}
+
+func TestGroupUnsubscribeWhileWoken(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	g := d.NewGroup()
+	defer g.Unsubscribe()
+
+	m1, m2, m3 := g.Subscribe(), g.Subscribe(), g.Subscribe()
+	defer m2.Unsubscribe()
+	defer m3.Unsubscribe()
+	w1, w2, w3 := m1.WaitChan(), m2.WaitChan(), m3.WaitChan()
+
+	t.Log("a woken member that unsubscribes without receiving its event wakes the next member")
+	d.Submit(MyEvent{id: 0})
+	nowReady(t, w1)
+	nowNotReady(t, w2)
+	require.True(t, m1.Unsubscribe())
+	nowReady(t, w2)
+	nowNotReady(t, w3)
+	e, err := m2.TryConsume()
+	require.NoError(t, err)
+	require.Equal(t, 0, e.id)
+
+	t.Log("a waiting member that unsubscribes is no longer woken")
+	w2 = m2.WaitChan()
+	require.True(t, m2.Unsubscribe())
+	nowReady(t, w2)
+	nowNotReady(t, w3)
+	d.Submit(MyEvent{id: 1})
+	nowReady(t, w3)
+}
+
+func TestGroupUnusable(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	g := d.NewGroup()
+	m1, m2 := g.Subscribe(), g.Subscribe()
+	w1, w2 := m1.WaitChan(), m2.WaitChan()
+
+	t.Log("unsubscribing the Group wakes every waiting member")
+	require.True(t, g.Unsubscribe())
+	require.False(t, g.Unsubscribe())
+	nowReady(t, w1)
+	nowReady(t, w2)
+	_, err := m1.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrUnsubscribed)
+
+	t.Log("closing the Distributor wakes every waiting member")
+	g = d.NewGroup()
+	defer g.Unsubscribe()
+	m1, m2 = g.Subscribe(), g.Subscribe()
+	w1, w2 = m1.WaitChan(), m2.WaitChan()
+	d.Close()
+	nowReady(t, w1)
+	nowReady(t, w2)
+	_, err = m2.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrClosed)
+}
+
+// TestGroupConcurrent checks that every event is received by exactly one member, while members
+// wait, receive, and leave concurrently
+func TestGroupConcurrent(t *testing.T) {
+	const numEvents = 1000
+	d := eventdistributor.New[MyEvent]()
+	g := d.NewGroup()
+	defer g.Unsubscribe()
+
+	var mu sync.Mutex
+	var ids []int
+	var wg sync.WaitGroup
+	work := func(m eventdistributor.GroupReader[MyEvent], limit int) {
+		defer wg.Done()
+		defer m.Unsubscribe()
+		for received := 0; received < limit; {
+			<-m.WaitChan()
+			e, err := m.TryConsume()
+			if err == eventdistributor.ErrNoEvent {
+				continue
+			} else if err != nil {
+				return
+			}
+			received += 1
+			mu.Lock()
+			ids = append(ids, e.id)
+			mu.Unlock()
+		}
+	}
+
+	// One member leaves early, so that the others must pick up anything it was woken for.
+	wg.Add(4)
+	go work(g.Subscribe(), 10)
+	for i := 0; i < 3; i++ {
+		go work(g.Subscribe(), numEvents)
+	}
+	var done []<-chan struct{}
+	for i := 0; i < numEvents; i++ {
+		done = append(done, d.Submit(MyEvent{id: i}))
+	}
+	for _, ch := range done {
+		<-ch
+	}
+	d.Close()
+	wg.Wait()
+
+	sort.Ints(ids)
+	require.Len(t, ids, numEvents)
+	for i, id := range ids {
+		require.Equal(t, i, id)
+	}
+}
@@ -0,0 +1,241 @@
+package eventdistributor
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Handler states. See handlerSub.
+const (
+	// handlerIdle indicates that the handler isn't running
+	handlerIdle int32 = iota
+	// handlerDirect indicates that the handler has been claimed by a producer, to call it directly
+	handlerDirect
+	// handlerWorker indicates that the handler's goroutine is delivering buffered events
+	handlerWorker
+)
+
+// SubscribeHandler calls fn with every event submitted from now on, until the returned stop
+// function is called.
+//
+// When the handler is keeping up, events are delivered directly: the producer calls fn itself,
+// after releasing the Distributor's lock, without the event being buffered for the handler.
+// Otherwise, events are buffered and delivered by the handler's own goroutine, as with any other
+// Reader. Either way, fn receives every event, in order, and is never called concurrently with
+// itself.
+//
+// The rule for direct delivery is that an event submitted with Submit(), SubmitQuiet(),
+// SubmitWait(), SubmitChecked(), or SubmitUnlessPending() and its variants is delivered directly
+// if, when it is submitted, the handler has received every earlier event and fn is not running -
+// i.e., at most one event is ever in flight. A directly delivered event is considered consumed by
+// the handler as soon as it is submitted: it is still buffered for other Readers, but if there are
+// none, it is discarded immediately, even though fn may not have been called yet. Events submitted
+// in other ways are always buffered.
+//
+// Because fn may be called by producers, it delays their return, and must not submit to the
+// Distributor or call any methods that would block on the producer.
+//
+// Once stop returns, fn is only called for an event that was already claimed for direct delivery,
+// if there is one. stop may be called from within fn, and calling it more than once has no effect.
+// If the Distributor is closed, the handler stops receiving events, but stop must still be called
+// to release it.
+//
+// SubscribeHandler is thread-safe.
+func (d *Distributor[T]) SubscribeHandler(fn func(T)) (stop func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	h := &handlerSub[T]{
+		r:          d.subscribe(),
+		fn:         fn,
+		state:      atomic.Int32{},
+		missed:     atomic.Bool{},
+		kick:       make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+		direct:     *new(T),
+		nextDirect: nil,
+	}
+	d.handlers = append(d.handlers, h)
+	go h.run()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			d.mu.Lock()
+			d.removeHandler(h)
+			d.mu.Unlock()
+
+			close(h.stop)
+		})
+	}
+}
+
+// handlerSub is a handler created by SubscribeHandler()
+type handlerSub[T any] struct {
+	r  Reader[T]
+	fn func(T)
+
+	// state is the handler's state - handlerIdle, handlerDirect, or handlerWorker. It can only be
+	// changed from handlerIdle while holding d.mu, but a producer making a direct call changes it
+	// back without the lock.
+	state atomic.Int32
+	// missed is set by the handler's goroutine when it was woken during a direct call, so that the
+	// producer making the call wakes it again, with kick, once it's done
+	missed atomic.Bool
+	kick   chan struct{}
+	// stop is closed by the stop function returned by SubscribeHandler()
+	stop chan struct{}
+
+	// direct is the event for the direct call, and nextDirect is the next handler in
+	// d.directCalls. They are only used while state is handlerDirect.
+	direct     T
+	nextDirect *handlerSub[T]
+}
+
+// removeHandler removes the handler from d.handlers, so that it's no longer claimed by producers.
+//
+// d.mu must be held.
+func (d *Distributor[T]) removeHandler(h *handlerSub[T]) {
+	for i, other := range d.handlers {
+		if other == h {
+			last := len(d.handlers) - 1
+			d.handlers[i] = d.handlers[last]
+			d.handlers[last] = nil
+			d.handlers = d.handlers[:last]
+			return
+		}
+	}
+}
+
+// claimAtSubmit claims every handler at the end of the buffer that isn't running, to receive value
+// directly, moving their Readers past it, and returning the number of Readers that were moved. It
+// must be called just before value is added to the buffer, or if value is discarded instead,
+// followed by unfilterAtSubmit.
+//
+// The caller must make the direct calls with runDirectCalls, after releasing d.mu.
+//
+// d.mu must be held.
+func (d *Distributor[T]) claimAtSubmit(value T) int64 {
+	if len(d.handlers) == 0 {
+		return 0
+	}
+
+	var claimed int64
+	tail := d.basePosition + int64(len(d.buf))
+	for _, h := range d.handlers {
+		if h.r.position == tail && h.state.CompareAndSwap(handlerIdle, handlerDirect) {
+			h.r.position = tail + 1
+			h.r.reader().publishPosition()
+			h.direct = value
+			h.nextDirect = d.directCalls
+			d.directCalls = h
+			claimed += 1
+		}
+	}
+	return claimed
+}
+
+// takeDirectCalls returns the handlers claimed by the submitted event, for runDirectCalls.
+//
+// d.mu must be held.
+func (d *Distributor[T]) takeDirectCalls() *handlerSub[T] {
+	h := d.directCalls
+	d.directCalls = nil
+	return h
+}
+
+// runDirectCalls calls each of the handlers returned by takeDirectCalls with its event.
+//
+// d.mu must NOT be held.
+func runDirectCalls[T any](h *handlerSub[T]) {
+	for h != nil {
+		next := h.nextDirect
+		value := h.direct
+		h.nextDirect = nil
+		h.direct = *new(T)
+
+		h.callDirect(value)
+		h = next
+	}
+}
+
+// callDirect calls the handler with the event it was claimed for, waking the handler's goroutine
+// afterwards if it was woken in the meantime.
+func (h *handlerSub[T]) callDirect(value T) {
+	defer func() {
+		h.state.Store(handlerIdle)
+		if h.missed.Swap(false) {
+			select {
+			case h.kick <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	h.fn(value)
+}
+
+// run delivers buffered events to the handler, until it's stopped or the Distributor is closed
+func (h *handlerSub[T]) run() {
+	defer h.r.Unsubscribe()
+
+	d := h.r.d
+	var waitCh <-chan struct{} = closedChannel
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-waitCh:
+		case <-h.kick:
+		}
+		if h.stopped() {
+			return
+		}
+
+		d.mu.Lock()
+		if !h.state.CompareAndSwap(handlerIdle, handlerWorker) {
+			// A direct call is in progress. The producer making it wakes us once it's done,
+			// unless it finished before seeing that we were here.
+			h.missed.Store(true)
+			if h.state.Load() == handlerIdle {
+				waitCh = closedChannel
+			} else {
+				waitCh = nil
+			}
+			d.mu.Unlock()
+			continue
+		}
+
+		for {
+			value, _, err := h.r.tryConsume()
+			if err == ErrNoEvent {
+				// Nothing is pending, so producers may deliver directly again.
+				h.state.Store(handlerIdle)
+				waitCh = h.r.waitChan()
+				d.mu.Unlock()
+				break
+			} else if err != nil {
+				d.mu.Unlock()
+				return
+			}
+			d.mu.Unlock()
+
+			h.fn(value)
+
+			if h.stopped() {
+				return
+			}
+			d.mu.Lock()
+		}
+	}
+}
+
+// stopped returns whether the handler's stop function has been called
+func (h *handlerSub[T]) stopped() bool {
+	select {
+	case <-h.stop:
+		return true
+	default:
+		return false
+	}
+}
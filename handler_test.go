@@ -0,0 +1,165 @@
+package eventdistributor_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSubscribeHandler(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	var mu sync.Mutex
+	var received []int
+	block := make(chan struct{})
+	blocked := make(chan struct{})
+	stop := d.SubscribeHandler(func(e MyEvent) {
+		if e.id == 1 {
+			close(blocked)
+			<-block
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, e.id)
+	})
+	defer stop()
+
+	receivedIDs := func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]int{}, received...)
+	}
+
+	t.Log("a caught-up handler is called directly by the producer")
+	d.Submit(MyEvent{id: 0})
+	require.Equal(t, []int{0}, receivedIDs())
+
+	t.Log("events submitted while the handler is busy are buffered")
+	go d.Submit(MyEvent{id: 1})
+	<-blocked
+	for i := 2; i < 5; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+	require.Equal(t, []int{0}, receivedIDs())
+	close(block)
+	require.Eventually(t, func() bool { return len(receivedIDs()) == 5 }, time.Second, time.Millisecond)
+	require.Equal(t, []int{0, 1, 2, 3, 4}, receivedIDs())
+
+	t.Log("once the backlog is drained, events are delivered directly again")
+	require.Eventually(t, func() bool {
+		before := len(receivedIDs())
+		d.Submit(MyEvent{id: before})
+		return len(receivedIDs()) == before+1
+	}, time.Second, time.Millisecond)
+
+	t.Log("stopped handlers aren't called")
+	stop()
+	n := len(receivedIDs())
+	d.Submit(MyEvent{id: -1})
+	time.Sleep(10 * time.Millisecond)
+	require.Len(t, receivedIDs(), n)
+}
+
+func TestSubscribeHandlerOrdering(t *testing.T) {
+	d := eventdistributor.New[[2]int]()
+
+	const producers = 4
+	const perProducer = 2000
+
+	var running atomic.Int32
+	var mu sync.Mutex
+	next := make([]int, producers)
+	count := 0
+	done := make(chan struct{})
+	stop := d.SubscribeHandler(func(e [2]int) {
+		require.Equal(t, int32(1), running.Add(1), "handler called concurrently")
+		defer running.Add(-1)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, next[e[0]], e[1], "events from producer %d out of order", e[0])
+		next[e[0]] += 1
+		count += 1
+		if count == producers*perProducer {
+			close(done)
+		}
+	})
+	defer stop()
+
+	// Another Reader, so that some events are buffered even when delivered directly
+	r := d.Subscribe()
+	go func() {
+		defer r.Unsubscribe()
+		for {
+			<-r.WaitChan()
+			if _, err := r.TryConsume(); err == eventdistributor.ErrClosed {
+				return
+			}
+		}
+	}()
+	defer d.Close()
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				if i%2 == 0 {
+					d.Submit([2]int{p, i})
+				} else {
+					d.SubmitQuiet([2]int{p, i})
+				}
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for handler")
+	}
+}
+
+func BenchmarkHandlerLatency(b *testing.B) {
+	b.Run("direct", func(b *testing.B) {
+		d := eventdistributor.New[MyEvent]()
+		var last int
+		stop := d.SubscribeHandler(func(e MyEvent) { last = e.id })
+		defer stop()
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			d.SubmitQuiet(MyEvent{id: i})
+		}
+		_ = last
+	})
+
+	b.Run("buffered", func(b *testing.B) {
+		d := eventdistributor.New[MyEvent]()
+		r := d.Subscribe()
+		received := make(chan struct{})
+		go func() {
+			for {
+				<-r.WaitChan()
+				if _, err := r.TryConsume(); err == eventdistributor.ErrClosed {
+					return
+				}
+				received <- struct{}{}
+			}
+		}()
+		defer d.Close()
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			d.SubmitQuiet(MyEvent{id: i})
+			<-received
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package eventdistributor
+
+import (
+	"sort"
+	"time"
+)
+
+// WithHistory makes the Distributor keep the last n events added to its buffer, so that Readers
+// created by SubscribeWithHistory() can receive recent events that were submitted before they
+// were created.
+//
+// The history is kept separately from the buffer: events in the history don't hold up the buffer,
+// and are fully consumed as usual. At most n events are kept, no matter what Readers do. Readers
+// can also be moved back to kept events that they have already received, with (*Reader[T]).Rewind()
+// and (*Reader[T]).SeekTo().
+//
+// WithHistory panics if n is not positive.
+func (o *Options[T]) WithHistory(n int) {
+	if n <= 0 {
+		panic("eventdistributor: WithHistory requires n > 0")
+	}
+
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.history = &eventHistory[T]{
+			events: make([]ArchivedEvent[T], 0, n),
+			start:  0,
+		}
+	})
+}
+
+// SubscribeWithHistory creates a new Reader that first receives up to k of the most recent events
+// kept by WithHistory, followed by future events, as with Subscribe(). If fewer than k events are
+// kept, the Reader receives all of them.
+//
+// Kept events that are still buffered are received from the buffer; the rest are received in the
+// same way as archived events are for Readers created by SubscribeFrom(). Either way, they are
+// treated as a replay. See ReplayDone().
+//
+// SubscribeWithHistory panics if the Distributor doesn't keep history, or if k is negative.
+//
+// SubscribeWithHistory is thread-safe.
+func (d *Distributor[T]) SubscribeWithHistory(k int) Reader[T] {
+	if d.history == nil {
+		panic("eventdistributor: SubscribeWithHistory requires history. See (*Options[T]).WithHistory()")
+	} else if k < 0 {
+		panic("eventdistributor: SubscribeWithHistory requires k >= 0")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	events := d.history.last(k)
+	r := d.subscribe()
+	if d.closed || len(events) == 0 {
+		return r
+	}
+
+	// Kept events from the first buffered one onwards are received from the buffer.
+	buf := d.buf
+	first := events[0].Seq
+	idx := sort.Search(len(buf), func(i int) bool { return buf[i].seq >= first })
+	archived := len(events)
+	if len(buf) != 0 {
+		archived = sort.Search(len(events), func(i int) bool { return events[i].Seq >= buf[0].seq })
+	}
+
+	r.firstSeq = first
+	r.moveTo(d.basePosition + int64(idx))
+	if archived != 0 {
+		r.backfill = events[:archived]
+		// If the Reader was moved back into the buffer, it's already replaying.
+		if r.replay == nil {
+			r.replay = &readerReplay{end: r.position, done: nil}
+		}
+	}
+	return r
+}
+
+// keptBeforeBuffer returns a copy of the kept events with sequence numbers of at least from that
+// are no longer buffered, from oldest to newest. Readers can be moved back to them by Rewind() and
+// SeekTo().
+//
+// d.mu must be held.
+func (d *Distributor[T]) keptBeforeBuffer(from int64) []ArchivedEvent[T] {
+	if d.history == nil {
+		return nil
+	}
+
+	events := d.history.last(len(d.history.events))
+	end := len(events)
+	if len(d.buf) != 0 {
+		first := d.buf[0].seq
+		end = sort.Search(len(events), func(i int) bool { return events[i].Seq >= first })
+	}
+	start := sort.Search(end, func(i int) bool { return events[i].Seq >= from })
+	return events[start:end]
+}
+
+// replayKept makes the Reader, which must be at the start of the buffer, receive the kept events
+// before any others, in the same way as for SubscribeWithHistory(). They are treated as a replay.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) replayKept(events []ArchivedEvent[T]) {
+	if len(events) == 0 {
+		return
+	}
+
+	r.backfill = append(events, r.backfill...)
+	if r.replay == nil {
+		r.replay = &readerReplay{end: r.position, done: nil}
+	}
+	r.wakeOwn()
+	r.d.wakeShared()
+	r.endWait()
+}
+
+// eventHistory is a ring buffer of the most recent events. See WithHistory.
+type eventHistory[T any] struct {
+	// events stores the kept events, oldest first when rotated by start. Its capacity is the number
+	// of events to keep.
+	events []ArchivedEvent[T]
+	// start is the index in events of the oldest event, once events is full
+	start int
+}
+
+// add records an event, replacing the oldest one if the history is full.
+func (h *eventHistory[T]) add(seq int64, timestamp time.Time, value T) {
+	e := ArchivedEvent[T]{Seq: seq, Timestamp: timestamp, Value: value}
+	if len(h.events) < cap(h.events) {
+		h.events = append(h.events, e)
+		return
+	}
+
+	h.events[h.start] = e
+	h.start = (h.start + 1) % len(h.events)
+}
+
+// last returns a copy of the k most recent events, or all of them if fewer are kept, from oldest
+// to newest.
+func (h *eventHistory[T]) last(k int) []ArchivedEvent[T] {
+	if k > len(h.events) {
+		k = len(h.events)
+	}
+
+	events := make([]ArchivedEvent[T], 0, k)
+	for i := len(h.events) - k; i < len(h.events); i++ {
+		events = append(events, h.events[(h.start+i)%len(h.events)])
+	}
+	return events
+}
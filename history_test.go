@@ -0,0 +1,55 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSubscribeWithHistory(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithHistory(3)
+	d := eventdistributor.New(options)
+
+	t.Log("without history, the Reader starts at the live edge")
+	r := d.SubscribeWithHistory(2)
+	nowReady(t, r.ReplayDone())
+	r.Unsubscribe()
+
+	t.Log("history is kept after events are fully consumed")
+	for i := 1; i <= 4; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+	require.Equal(t, 0, d.Stats().Bufsize)
+
+	r1 := d.SubscribeWithHistory(2)
+	defer r1.Unsubscribe()
+	nowNotReady(t, r1.ReplayDone())
+	require.Equal(t, []int{3, 4}, drainIDs(&r1))
+	nowReady(t, r1.ReplayDone())
+
+	t.Log("k is clamped to the size of the history")
+	r2 := d.SubscribeWithHistory(10)
+	defer r2.Unsubscribe()
+	require.Equal(t, []int{2, 3, 4}, drainIDs(&r2))
+
+	t.Log("buffered events are received from the buffer")
+	d.Submit(MyEvent{id: 5})
+	d.Submit(MyEvent{id: 6})
+	require.Equal(t, 2, d.Stats().Bufsize)
+
+	r3 := d.SubscribeWithHistory(3)
+	d.Submit(MyEvent{id: 7})
+	require.Equal(t, []int{4, 5, 6, 7}, drainIDs(&r3))
+	nowReady(t, r3.ReplayDone())
+
+	t.Log("history doesn't hold up the buffer")
+	require.Equal(t, []int{5, 6, 7}, drainIDs(&r2))
+	require.Equal(t, []int{5, 6, 7}, drainIDs(&r1))
+	require.Equal(t, 0, d.Stats().Bufsize)
+	r3.Unsubscribe()
+
+	require.Panics(t, func() { eventdistributor.New[MyEvent]().SubscribeWithHistory(1) })
+}
@@ -0,0 +1,93 @@
+package eventdistributor
+
+import (
+	"time"
+)
+
+// OnIdle adds a callback that is called once the buffer has been empty for the duration - for
+// example, to power down downstream machinery while the Distributor is quiet. The callback is only
+// called once per idle period, which ends when the next event is submitted. The idle period starts
+// again once the buffer is next empty.
+//
+// The buffer of a new Distributor is empty, so the callback is also called if nothing is submitted
+// for the duration after the Distributor is created. Timing uses the Distributor's Clock. See
+// WithClock. Once the Distributor is closed, the callback is no longer called.
+//
+// OnIdle panics if after is not positive.
+func (o *Options[T]) OnIdle(after time.Duration, callback func()) {
+	if after <= 0 {
+		panic("eventdistributor: OnIdle requires after > 0")
+	}
+
+	site := captureRegistrationSite()
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.idleWatches = append(d.idleWatches, &idleWatch{
+			after:    after,
+			callback: watchCallback(d.watchdog, "OnIdle", site, func(struct{}) { callback() }),
+			timer:    nil,
+			gen:      0,
+			fired:    false,
+		})
+	})
+}
+
+// idleWatch is a callback registered with OnIdle
+type idleWatch struct {
+	after    time.Duration
+	callback func(struct{})
+	// timer, if not nil, calls the callback once the buffer has been empty for long enough
+	timer Timer
+	// gen is incremented whenever timer is stopped, so that a timer that fires anyway can tell
+	// that it's stale
+	gen uint64
+	// fired is true if the callback was called during the current idle period
+	fired bool
+}
+
+// startIdle starts the OnIdle timers, if the buffer is empty and they haven't already been started
+// or fired during the current idle period.
+//
+// d.mu must be held.
+func (d *Distributor[T]) startIdle() {
+	if len(d.idleWatches) == 0 || len(d.buf) != 0 || d.closed {
+		return
+	}
+
+	for _, w := range d.idleWatches {
+		if w.timer != nil || w.fired {
+			continue
+		}
+
+		w, gen := w, w.gen
+		w.timer = d.getClock().AfterFunc(w.after, func() {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+
+			if w.gen != gen || d.closed {
+				return
+			}
+			w.timer = nil
+			w.fired = true
+			runCallbacks(&d.mu, []func(struct{}){w.callback}, struct{}{})
+		})
+	}
+}
+
+// endIdle ends the current idle period, because an event was submitted, stopping the OnIdle timers.
+//
+// d.mu must be held.
+func (d *Distributor[T]) endIdle() {
+	for _, w := range d.idleWatches {
+		w.stop()
+		w.fired = false
+	}
+}
+
+// stop stops the watch's timer, if it's running.
+func (w *idleWatch) stop() {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.gen += 1
+}
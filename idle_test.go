@@ -0,0 +1,69 @@
+package eventdistributor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestOnIdle(t *testing.T) {
+	clock := newFakeClock()
+	idle := 0
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	options.OnIdle(time.Second, func() { idle += 1 })
+	d := eventdistributor.New(options)
+
+	t.Log("a new Distributor is idle once nothing has been submitted for the duration")
+	clock.Advance(999 * time.Millisecond)
+	require.Equal(t, 0, idle)
+	clock.Advance(time.Millisecond)
+	require.Equal(t, 1, idle)
+
+	t.Log("the callback is only called once per idle period")
+	clock.Advance(time.Hour)
+	require.Equal(t, 1, idle)
+
+	t.Log("the buffer isn't empty while an event is waiting to be consumed")
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(MyEvent{id: 1})
+	clock.Advance(time.Hour)
+	require.Equal(t, 1, idle)
+
+	t.Log("the next idle period starts once the buffer is empty again")
+	r.Consume()
+	clock.Advance(999 * time.Millisecond)
+	require.Equal(t, 1, idle)
+	clock.Advance(time.Millisecond)
+	require.Equal(t, 2, idle)
+
+	t.Log("each submission ends the idle period, and a new one starts once the buffer is empty")
+	clock.Advance(time.Hour)
+	d.Submit(MyEvent{id: 2})
+	r.Consume()
+	clock.Advance(500 * time.Millisecond)
+	d.Submit(MyEvent{id: 3})
+	r.Consume()
+	clock.Advance(500 * time.Millisecond)
+	require.Equal(t, 2, idle)
+	clock.Advance(500 * time.Millisecond)
+	require.Equal(t, 3, idle)
+
+	t.Log("a closed Distributor is never idle")
+	d.Submit(MyEvent{id: 4})
+	r.Consume()
+	d.Close()
+	clock.Advance(time.Hour)
+	require.Equal(t, 3, idle)
+}
+
+func TestOnIdleRequiresPositiveDuration(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	require.Panics(t, func() {
+		options.OnIdle(0, func() {})
+	})
+}
@@ -0,0 +1,123 @@
+package eventdistributor
+
+import (
+	"container/list"
+	"fmt"
+)
+
+// WithKeyFunc enables tracking the latest submitted value for each key, as determined by the key
+// function. The latest values can be retrieved with LatestByKey() and Keys().
+//
+// The values are tracked independently of the buffer - a value remains available after it has
+// been consumed, until it is replaced by a newer value with the same key, or the Distributor is
+// closed.
+//
+// If maxKeys is greater than zero, at most maxKeys keys are tracked; when a new key would exceed
+// the limit, the least recently submitted key is evicted.
+func WithKeyFunc[T any, K comparable](o *Options[T], key func(T) K, maxKeys int) {
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.keyIndex = &keyIndex[K, T]{
+			key:     key,
+			maxKeys: maxKeys,
+			entries: make(map[K]*list.Element),
+			lru:     list.New(),
+		}
+	})
+}
+
+// LatestByKey returns the most recently submitted value with the given key, if there is one.
+//
+// LatestByKey panics if the Distributor was not created with WithKeyFunc using the same key type.
+//
+// LatestByKey is thread-safe.
+func LatestByKey[K comparable, T any](d *Distributor[T], k K) (T, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	idx := getKeyIndex[K](d)
+	if e, ok := idx.entries[k]; ok {
+		return e.Value.(*keyEntry[K, T]).value, true
+	}
+
+	var zero T
+	return zero, false
+}
+
+// Keys returns the keys currently tracked by the Distributor, from most to least recently
+// submitted.
+//
+// Keys panics if the Distributor was not created with WithKeyFunc using the same key type.
+//
+// Keys is thread-safe.
+func Keys[K comparable, T any](d *Distributor[T]) []K {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	idx := getKeyIndex[K](d)
+	keys := make([]K, 0, idx.lru.Len())
+	for e := idx.lru.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(*keyEntry[K, T]).key)
+	}
+	return keys
+}
+
+// keyIndexer is the type-erased interface to a *keyIndex, so that it can be stored in the
+// Distributor without an extra type parameter
+type keyIndexer[T any] interface {
+	update(value T, seq int64)
+	clear()
+}
+
+type keyIndex[K comparable, T any] struct {
+	key     func(T) K
+	maxKeys int
+
+	entries map[K]*list.Element
+	// lru stores *keyEntry[K, T], ordered from most to least recently submitted
+	lru *list.List
+}
+
+type keyEntry[K comparable, T any] struct {
+	key   K
+	value T
+	// seq is the sequence number of the event with the latest value
+	seq int64
+}
+
+// getKeyIndex returns the Distributor's keyIndex, panicking if it doesn't have one for the key
+// type.
+//
+// d.mu must be held.
+func getKeyIndex[K comparable, T any](d *Distributor[T]) *keyIndex[K, T] {
+	idx, ok := d.keyIndex.(*keyIndex[K, T])
+	if !ok {
+		var k K
+		panic(fmt.Sprintf("eventdistributor: Distributor does not have a key function with key type %T", k))
+	}
+	return idx
+}
+
+func (idx *keyIndex[K, T]) update(value T, seq int64) {
+	k := idx.key(value)
+
+	if e, ok := idx.entries[k]; ok {
+		entry := e.Value.(*keyEntry[K, T])
+		entry.value = value
+		entry.seq = seq
+		idx.lru.MoveToFront(e)
+		return
+	}
+
+	if idx.maxKeys > 0 && idx.lru.Len() >= idx.maxKeys {
+		oldest := idx.lru.Back()
+		idx.lru.Remove(oldest)
+		delete(idx.entries, oldest.Value.(*keyEntry[K, T]).key)
+	}
+
+	idx.entries[k] = idx.lru.PushFront(&keyEntry[K, T]{key: k, value: value, seq: seq})
+}
+
+func (idx *keyIndex[K, T]) clear() {
+	idx.entries = make(map[K]*list.Element)
+	idx.lru.Init()
+}
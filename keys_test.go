@@ -0,0 +1,69 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+type keyedEvent struct {
+	key   string
+	value int
+}
+
+func TestLatestByKey(t *testing.T) {
+	var options eventdistributor.Options[keyedEvent]
+	eventdistributor.WithKeyFunc(&options, func(e keyedEvent) string { return e.key }, 0)
+	d := eventdistributor.New(options)
+
+	_, ok := eventdistributor.LatestByKey(d, "a")
+	require.False(t, ok)
+
+	t.Log("values are tracked even when there are no readers")
+	d.Submit(keyedEvent{key: "a", value: 1})
+	d.Submit(keyedEvent{key: "b", value: 2})
+	d.Submit(keyedEvent{key: "a", value: 3})
+
+	e, ok := eventdistributor.LatestByKey(d, "a")
+	require.True(t, ok)
+	require.Equal(t, 3, e.value)
+	e, ok = eventdistributor.LatestByKey(d, "b")
+	require.True(t, ok)
+	require.Equal(t, 2, e.value)
+
+	require.Equal(t, []string{"a", "b"}, eventdistributor.Keys[string](d))
+
+	t.Log("values remain after they're consumed")
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(keyedEvent{key: "c", value: 4})
+	r.Consume()
+	e, ok = eventdistributor.LatestByKey(d, "c")
+	require.True(t, ok)
+	require.Equal(t, 4, e.value)
+}
+
+func TestLatestByKeyEviction(t *testing.T) {
+	var options eventdistributor.Options[keyedEvent]
+	eventdistributor.WithKeyFunc(&options, func(e keyedEvent) string { return e.key }, 2)
+	d := eventdistributor.New(options)
+
+	d.Submit(keyedEvent{key: "a", value: 1})
+	d.Submit(keyedEvent{key: "b", value: 2})
+	d.Submit(keyedEvent{key: "a", value: 3})
+	d.Submit(keyedEvent{key: "c", value: 4})
+
+	t.Log("b was the least recently submitted, so it's evicted")
+	require.Equal(t, []string{"c", "a"}, eventdistributor.Keys[string](d))
+	_, ok := eventdistributor.LatestByKey(d, "b")
+	require.False(t, ok)
+}
+
+func TestLatestByKeyWrongType(t *testing.T) {
+	d := eventdistributor.New[keyedEvent]()
+	require.Panics(t, func() {
+		eventdistributor.LatestByKey(d, "a")
+	})
+}
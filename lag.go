@@ -0,0 +1,254 @@
+package eventdistributor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrReaderLagging is matched by the errors returned by SubmitChecked() when an event can't be
+// submitted because a Reader is too far behind. See (*Options[T]).WithMaxReaderLag().
+//
+// The returned error is a *ReaderLaggingError, which identifies the Reader.
+var ErrReaderLagging = errors.New("reader is lagging too far behind")
+
+// ReaderLaggingError is the error returned by SubmitChecked() when an event can't be submitted
+// because a Reader is too far behind. It matches ErrReaderLagging with errors.Is.
+type ReaderLaggingError struct {
+	// Reader is the Reader that is too far behind
+	Reader ReaderInfo
+}
+
+// Error implements the error interface
+func (e *ReaderLaggingError) Error() string {
+	return fmt.Sprintf("reader %d is lagging %d events behind", e.Reader.ID, e.Reader.Lag)
+}
+
+// Is allows errors.Is(err, ErrReaderLagging) to match
+func (e *ReaderLaggingError) Is(target error) bool {
+	return target == ErrReaderLagging
+}
+
+// ReaderInfo describes a Reader at a single point in time
+type ReaderInfo struct {
+	// ID is the Reader's ID, as returned by (*Reader[T]).ID()
+	ID uint64
+	// Lag is the number of buffered events that the Reader has not yet seen
+	Lag int
+}
+
+// ID returns a number identifying the Reader, unique among the Readers of its Distributor.
+//
+// ID is thread-safe.
+func (r *Reader[T]) ID() uint64 {
+	return r.id
+}
+
+// WithMaxReaderLag limits how far behind any Reader can fall: an event can't be submitted if doing
+// so would leave some Reader more than k events behind.
+//
+// While a Reader is too far behind, Submit() and SubmitWait() block until it catches up, is
+// unsubscribed, or expires (SubmitWait() also stops waiting when its context is canceled).
+// SubmitChecked() instead returns a *ReaderLaggingError, matching ErrReaderLagging. Readers that
+// are keeping up are unaffected, other than by the producers they share.
+//
+// If the Distributor is sealed or closed while a producer is waiting, the producer stops waiting
+// and the event is dropped as usual.
+//
+// WithMaxReaderLag panics if k is not positive.
+func (o *Options[T]) WithMaxReaderLag(k int) {
+	if k <= 0 {
+		panic("eventdistributor: WithMaxReaderLag requires k > 0")
+	}
+
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.maxReaderLag = k
+	})
+}
+
+// WithSkipOverLag limits how far behind any Reader can fall by skipping events: whenever an event
+// is submitted that would leave a Reader more than n events behind, the Reader moves forward so
+// that it is exactly n events behind, skipping the oldest events it hasn't seen. Skipped events
+// that are no longer held by any Reader are fully consumed, as usual.
+//
+// Unlike WithMaxReaderLag, producers are never blocked, so the buffer holds at most about n events
+// for lagging Readers. Readers can find out how many events they skipped with
+// (*Reader[T]).Skipped() or (*Reader[T]).TakeSkipped().
+//
+// If WithMaxReaderLag is also set with a limit of at most n, it takes precedence, because Readers
+// never get far enough behind to skip anything.
+//
+// WithSkipOverLag panics if n is not positive.
+func (o *Options[T]) WithSkipOverLag(n int) {
+	if n <= 0 {
+		panic("eventdistributor: WithSkipOverLag requires n > 0")
+	}
+
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.skipOverLag = n
+	})
+}
+
+// skipLaggingReaders moves every Reader that is more than d.skipOverLag events behind forward, so
+// that it is exactly d.skipOverLag events behind. See WithSkipOverLag.
+//
+// d.mu must be held.
+func (d *Distributor[T]) skipLaggingReaders() {
+	// No Reader can be further behind than the size of the buffer.
+	if d.skipOverLag == 0 || len(d.buf) <= d.skipOverLag {
+		return
+	}
+
+	tail := d.basePosition + int64(len(d.buf))
+	for _, r := range d.readers {
+		if lag := tail - r.position; lag > int64(d.skipOverLag) {
+			r.skipped += lag - int64(d.skipOverLag)
+			r.reader().moveTo(tail - int64(d.skipOverLag))
+			r.reader().skipSeen()
+		}
+	}
+}
+
+// laggingReader returns information about a Reader that would be more than d.maxReaderLag events
+// behind if another event were submitted, if there is one.
+//
+// d.mu must be held.
+func (d *Distributor[T]) laggingReader() (ReaderInfo, bool) {
+	// No Reader can be further behind than the size of the buffer.
+	if d.maxReaderLag == 0 || len(d.buf) < d.maxReaderLag {
+		return ReaderInfo{}, false
+	}
+
+	tail := d.basePosition + int64(len(d.buf))
+	for _, r := range d.readers {
+		if lag := int(tail - r.position); lag >= d.maxReaderLag {
+			return ReaderInfo{ID: r.id, Lag: lag}, true
+		}
+	}
+	return ReaderInfo{}, false
+}
+
+// waitForLaggingReaders blocks until no Reader is too far behind for another event to be submitted,
+// or the Distributor is sealed. For Distributors created by NewFixed() with OverflowBlock, it also
+// waits until there is room in the buffer. If block is false, it returns a *ReaderLaggingError or
+// ErrBufferFull instead of waiting, and if ctx is canceled while waiting, it returns ctx.Err().
+//
+// d.mu must be held. It is released while waiting.
+func (d *Distributor[T]) waitForLaggingReaders(ctx context.Context, block bool) error {
+	for !d.sealed {
+		info, lagging := d.laggingReader()
+		if lagging {
+			if !block {
+				return &ReaderLaggingError{Reader: info}
+			}
+			d.emitMeta(MetaEvent{Kind: MetaProducerBlocked, Reader: info})
+		} else if d.bufferFull() {
+			if !block {
+				return ErrBufferFull
+			}
+		} else {
+			return nil
+		}
+
+		if d.producerWait == nil {
+			d.producerWait = make(chan struct{})
+		}
+		wait := d.producerWait
+
+		d.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+		}
+		d.mu.Lock()
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wakeProducers wakes any producers waiting for lagging Readers, so that they can check again.
+//
+// d.mu must be held.
+func (d *Distributor[T]) wakeProducers() {
+	if d.producerWait != nil {
+		close(d.producerWait)
+		d.producerWait = nil
+	}
+}
+
+// OnReaderLag adds a callback that is called with a Reader's ID and lag - the number of buffered
+// events it has not yet seen - whenever its lag reaches threshold, and again when it falls back
+// below threshold. In between, the callback isn't called for that Reader, no matter how many
+// events are submitted.
+//
+// A Reader's lag includes events it will skip, for example because they don't match its filter.
+//
+// OnReaderLag panics if threshold is not positive.
+func (o *Options[T]) OnReaderLag(threshold int, callback func(readerID uint64, lag int)) {
+	if threshold <= 0 {
+		panic("eventdistributor: OnReaderLag requires threshold > 0")
+	}
+
+	site := captureRegistrationSite()
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.lagWatches = append(d.lagWatches, &lagWatch{
+			threshold: threshold,
+			callback:  queueCallback2(&d.mu, watchCallback2(d.watchdog, "OnReaderLag", site, callback)),
+			lagging:   make(map[uint64]struct{}),
+		})
+	})
+}
+
+// lagWatch is a callback registered with OnReaderLag
+type lagWatch struct {
+	threshold int
+	callback  func(readerID uint64, lag int)
+	// lagging is the set of IDs of the Readers whose lag is at least threshold
+	lagging map[uint64]struct{}
+}
+
+// checkLags calls OnReaderLag callbacks for every Reader whose lag crossed a threshold.
+//
+// d.mu must be held.
+func (d *Distributor[T]) checkLags() {
+	if len(d.lagWatches) == 0 {
+		return
+	}
+
+	for _, r := range d.readers {
+		r.reader().checkLag()
+	}
+}
+
+// checkLag calls OnReaderLag callbacks if the Reader's lag crossed a threshold.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) checkLag() {
+	if len(r.d.lagWatches) == 0 {
+		return
+	}
+
+	lag := r.readerInfo().Lag
+	for _, w := range r.d.lagWatches {
+		_, lagging := w.lagging[r.id]
+		if !lagging && lag >= w.threshold {
+			w.lagging[r.id] = struct{}{}
+			w.callback(r.id, lag)
+		} else if lagging && lag < w.threshold {
+			delete(w.lagging, r.id)
+			w.callback(r.id, lag)
+		}
+	}
+}
+
+// forgetLag removes the Reader from the OnReaderLag state, once it's no longer registered.
+//
+// r.d.mu must be held.
+func (d *Distributor[T]) forgetLag(r *readerState[T]) {
+	for _, w := range d.lagWatches {
+		delete(w.lagging, r.id)
+	}
+}
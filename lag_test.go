@@ -0,0 +1,159 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestMaxReaderLag(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithMaxReaderLag(2)
+	d := eventdistributor.New(options)
+
+	fast := d.Subscribe()
+	defer fast.Unsubscribe()
+	slow := d.Subscribe()
+
+	d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 1})
+	require.Equal(t, []int{0, 1}, drainIDs(&fast))
+
+	t.Log("a slow reader blocks producers")
+	_, err := d.SubmitChecked(MyEvent{id: 2})
+	require.ErrorIs(t, err, eventdistributor.ErrReaderLagging)
+	var lagErr *eventdistributor.ReaderLaggingError
+	require.True(t, errors.As(err, &lagErr))
+	require.Equal(t, eventdistributor.ReaderInfo{ID: slow.ID(), Lag: 2}, lagErr.Reader)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = d.SubmitWait(ctx, MyEvent{id: 2})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	t.Log("consuming makes room")
+	require.Equal(t, 0, slow.Consume().id)
+	_, err = d.SubmitChecked(MyEvent{id: 2})
+	require.NoError(t, err)
+
+	t.Log("the fast reader is unaffected")
+	require.Equal(t, []int{2}, drainIDs(&fast))
+
+	t.Log("unsubscribing the slow reader unblocks waiting producers")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.Submit(MyEvent{id: 3})
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Submit returned while a reader was lagging")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	slow.Unsubscribe()
+	<-done
+	require.Equal(t, []int{3}, drainIDs(&fast))
+}
+
+func TestMaxReaderLagSeal(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithMaxReaderLag(1)
+	options.OnDrop(func(MyEvent, eventdistributor.DropReason) {})
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(MyEvent{id: 0})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.Submit(MyEvent{id: 1})
+	}()
+
+	t.Log("sealing drops the waiting event instead")
+	time.Sleep(10 * time.Millisecond)
+	d.Seal()
+	<-done
+	require.Equal(t, []int{0}, drainIDs(&r))
+}
+
+func TestOnReaderLag(t *testing.T) {
+	var calls []eventdistributor.ReaderInfo
+	var options eventdistributor.Options[MyEvent]
+	options.OnReaderLag(2, func(readerID uint64, lag int) {
+		calls = append(calls, eventdistributor.ReaderInfo{ID: readerID, Lag: lag})
+	})
+	d := eventdistributor.New(options)
+
+	fast := d.Subscribe()
+	defer fast.Unsubscribe()
+	slow := d.Subscribe()
+
+	d.Submit(MyEvent{id: 0})
+	fast.Consume()
+	require.Empty(t, calls)
+
+	t.Log("the callback is called once when a Reader's lag reaches the threshold")
+	d.Submit(MyEvent{id: 1})
+	fast.Consume()
+	d.Submit(MyEvent{id: 2})
+	fast.Consume()
+	require.Equal(t, []eventdistributor.ReaderInfo{{ID: slow.ID(), Lag: 2}}, calls)
+
+	t.Log("and again when it falls below")
+	calls = nil
+	slow.Consume()
+	slow.Consume()
+	require.Equal(t, []eventdistributor.ReaderInfo{{ID: slow.ID(), Lag: 1}}, calls)
+
+	t.Log("unsubscribed Readers are forgotten")
+	calls = nil
+	d.Submit(MyEvent{id: 3})
+	fast.Consume()
+	require.Equal(t, []eventdistributor.ReaderInfo{{ID: slow.ID(), Lag: 2}}, calls)
+	slow.Unsubscribe()
+	d.Submit(MyEvent{id: 4})
+	fast.Consume()
+	require.Len(t, calls, 1)
+}
+
+func TestSkipOverLag(t *testing.T) {
+	var consumed []int
+	var options eventdistributor.Options[MyEvent]
+	options.WithSkipOverLag(2)
+	options.OnFullyConsumed(func(e MyEvent) { consumed = append(consumed, e.id) })
+	d := eventdistributor.New(options)
+
+	fast := d.Subscribe()
+	defer fast.Unsubscribe()
+	slow := d.Subscribe()
+	defer slow.Unsubscribe()
+
+	for i := 0; i < 5; i++ {
+		d.Submit(MyEvent{id: i})
+		fast.Consume()
+	}
+
+	t.Log("the slow Reader skips the oldest events, which are then fully consumed")
+	require.Equal(t, []int{0, 1, 2}, consumed)
+	require.EqualValues(t, 3, slow.TakeSkipped())
+	require.EqualValues(t, 0, slow.TakeSkipped())
+	require.Equal(t, []int{3, 4}, drainIDs(&slow))
+
+	d.Submit(MyEvent{id: 5})
+	d.Submit(MyEvent{id: 6})
+	d.Submit(MyEvent{id: 7})
+	require.EqualValues(t, 1, slow.TakeSkipped())
+	require.EqualValues(t, 4, slow.Skipped())
+	require.Equal(t, []int{6, 7}, drainIDs(&slow))
+	require.Equal(t, []int{6, 7}, drainIDs(&fast))
+	require.EqualValues(t, 1, fast.Skipped())
+}
@@ -0,0 +1,69 @@
+package eventdistributor
+
+import (
+	"time"
+)
+
+// OnConsumedWithLatency adds a callback that is called whenever an event is fully consumed, like
+// OnFullyConsumed, with how long the event was in the buffer, according to the Distributor's Clock.
+// Events that are discarded immediately because no Reader would receive them have a duration of
+// zero.
+//
+// OnConsumedWithLatency enables timestamps, as if by WithTimestamps(), because they're required to
+// measure the time in the buffer.
+func (o *Options[T]) OnConsumedWithLatency(callback func(item T, inBuffer time.Duration)) {
+	site := captureRegistrationSite()
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.timestamps = true
+		watched := watchCallback2(d.watchdog, "OnConsumedWithLatency", site, callback)
+		d.onLatency.add(latencyCaller(watched), nil)
+	})
+}
+
+// OnConsumedWithLatency adds a callback that is called whenever an event is fully consumed, with
+// how long it was in the buffer, like (*Options[T]).OnConsumedWithLatency(), returning a function
+// that removes it. See OnSubmit().
+//
+// Timestamps can't be enabled once the Distributor has been created, so if it doesn't record them,
+// the callback isn't added, and ok is false. See (*Options[T]).WithTimestamps().
+//
+// OnConsumedWithLatency is thread-safe.
+func (d *Distributor[T]) OnConsumedWithLatency(
+	callback func(item T, inBuffer time.Duration),
+) (remove func(), ok bool) {
+	site := captureRegistrationSite()
+	watched := watchCallback2(d.watchdog, "OnConsumedWithLatency", site, callback)
+
+	d.mu.Lock()
+	timestamps := d.timestamps
+	d.mu.Unlock()
+	if !timestamps {
+		return func() {}, false
+	}
+	return addCallback(&d.mu, &d.onLatency, latencyCaller(watched)), true
+}
+
+// consumedLatency is the pair of arguments for OnConsumedWithLatency callbacks, so that they can
+// be stored in a callbackList
+type consumedLatency[T any] struct {
+	item     T
+	inBuffer time.Duration
+}
+
+// latencyCaller returns a function that calls f with the fields of a consumedLatency
+func latencyCaller[T any](f func(T, time.Duration)) func(consumedLatency[T]) {
+	return func(l consumedLatency[T]) { f(l.item, l.inBuffer) }
+}
+
+// reportLatency calls OnConsumedWithLatency callbacks for an event that was fully consumed after
+// being buffered since timestamp.
+//
+// d.mu must be held.
+func (d *Distributor[T]) reportLatency(value T, timestamp time.Time) {
+	if len(d.onLatency.fs) == 0 {
+		return
+	}
+
+	inBuffer := d.getClock().Now().Sub(timestamp)
+	runCallbacks(&d.mu, d.onLatency.fs, consumedLatency[T]{item: value, inBuffer: inBuffer})
+}
@@ -0,0 +1,78 @@
+package eventdistributor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestOnConsumedWithLatency(t *testing.T) {
+	type latency struct {
+		id       int
+		inBuffer time.Duration
+	}
+
+	clock := newFakeClock()
+	var latencies []latency
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	options.OnConsumedWithLatency(func(e MyEvent, inBuffer time.Duration) {
+		latencies = append(latencies, latency{id: e.id, inBuffer: inBuffer})
+	})
+	d := eventdistributor.New(options)
+
+	t.Log("events discarded immediately have no latency")
+	clock.Advance(time.Second)
+	d.Submit(MyEvent{id: 0})
+	require.Equal(t, []latency{{id: 0, inBuffer: 0}}, latencies)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(MyEvent{id: 1})
+	clock.Advance(time.Second)
+	d.Submit(MyEvent{id: 2})
+	clock.Advance(time.Second)
+	require.Equal(t, []int{1, 2}, drainIDs(&r))
+	require.Equal(t, []latency{
+		{id: 0, inBuffer: 0},
+		{id: 1, inBuffer: 2 * time.Second},
+		{id: 2, inBuffer: time.Second},
+	}, latencies)
+}
+
+func TestDistributorOnConsumedWithLatency(t *testing.T) {
+	t.Log("without timestamps, the callback isn't added")
+	d := eventdistributor.New[MyEvent]()
+	_, ok := d.OnConsumedWithLatency(func(MyEvent, time.Duration) {
+		t.Fatal("unexpected call")
+	})
+	require.False(t, ok)
+	d.Submit(MyEvent{id: 0})
+
+	t.Log("with timestamps, it's called until removed")
+	clock := newFakeClock()
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	options.WithTimestamps()
+	d = eventdistributor.New(options)
+	var latencies []time.Duration
+	remove, ok := d.OnConsumedWithLatency(func(_ MyEvent, inBuffer time.Duration) {
+		latencies = append(latencies, inBuffer)
+	})
+	require.True(t, ok)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(MyEvent{id: 1})
+	clock.Advance(time.Second)
+	require.Equal(t, []int{1}, drainIDs(&r))
+	require.Equal(t, []time.Duration{time.Second}, latencies)
+
+	remove()
+	d.Submit(MyEvent{id: 2})
+	require.Equal(t, []int{2}, drainIDs(&r))
+	require.Equal(t, []time.Duration{time.Second}, latencies)
+}
@@ -0,0 +1,66 @@
+package eventdistributor
+
+import (
+	"runtime"
+)
+
+// WithLeakCheck enables detecting Readers that are garbage collected without being unsubscribed.
+// Each Reader records the stack at the point where it was created, and if every copy of it becomes
+// unreachable while it's still holding on to buffered events, report is called with its ID and
+// that stack.
+//
+// A leaked Reader keeps every event submitted after its position in the buffer forever, so this
+// option is intended for tracking down slowly growing buffers. It is only a debugging aid: the
+// Reader is not unsubscribed, and report is called some time after the Reader becomes unreachable
+// - whenever the garbage collector finds it - or not at all, if the program exits first. Readers
+// that were released by Close(), or that expired or were evicted, are not reported.
+//
+// When this option is not set, Readers are not tracked at all. WithLeakCheck must be set when the
+// Distributor is created.
+func (o *Options[T]) WithLeakCheck(report func(readerID uint64, stack []byte)) {
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.leakCheck = report
+	})
+}
+
+// maxLeakDepth is the maximum number of stack frames recorded when a Reader is created with
+// WithLeakCheck
+const maxLeakDepth = 32
+
+// readerLeak is referenced only by the copies of a Reader, so that it becomes unreachable once the
+// Reader does, even though the Distributor still references the Reader's state. See WithLeakCheck.
+type readerLeak[T any] struct {
+	state *readerState[T]
+	// pcs is the stack at the point where the Reader was created
+	pcs []uintptr
+}
+
+// newReader returns a Reader for the state of a newly created Reader, tracking it if the
+// Distributor has WithLeakCheck.
+//
+// d.mu must be held.
+func (d *Distributor[T]) newReader(state *readerState[T]) Reader[T] {
+	if d.leakCheck == nil {
+		return Reader[T]{readerState: state, leak: nil}
+	}
+
+	var pcs [maxLeakDepth]uintptr
+	// skip runtime.Callers and newReader
+	n := runtime.Callers(2, pcs[:])
+	leak := &readerLeak[T]{state: state, pcs: pcs[:n]}
+	runtime.SetFinalizer(leak, (*readerLeak[T]).check)
+	return Reader[T]{readerState: state, leak: leak}
+}
+
+// check reports the Reader if it's still holding on to buffered events. It's called by the garbage
+// collector once the Reader is unreachable.
+func (l *readerLeak[T]) check() {
+	d := l.state.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed || l.state.reader().checkReleased() != nil {
+		return
+	}
+	queueCallback2(&d.mu, d.leakCheck)(l.state.id, formatStack(l.pcs))
+}
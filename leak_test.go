@@ -0,0 +1,80 @@
+package eventdistributor_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+type leakReport struct {
+	readerID uint64
+	stack    string
+}
+
+func newLeakChecked() (*eventdistributor.Distributor[MyEvent], <-chan leakReport) {
+	reports := make(chan leakReport, 10)
+	var options eventdistributor.Options[MyEvent]
+	options.WithLeakCheck(func(readerID uint64, stack []byte) {
+		reports <- leakReport{readerID: readerID, stack: string(stack)}
+	})
+	return eventdistributor.New(options), reports
+}
+
+// collect runs the garbage collector until a report arrives, or gives up after a while
+func collect(reports <-chan leakReport) (leakReport, bool) {
+	for i := 0; i < 20; i++ {
+		runtime.GC()
+		select {
+		case report := <-reports:
+			return report, true
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return leakReport{}, false
+}
+
+//go:noinline
+func subscribeAndDrop(d *eventdistributor.Distributor[MyEvent]) uint64 {
+	r := d.Subscribe()
+	return r.ID()
+}
+
+func TestLeakCheck(t *testing.T) {
+	d, reports := newLeakChecked()
+	id := subscribeAndDrop(d)
+	d.Submit(MyEvent{id: 0})
+
+	report, ok := collect(reports)
+	require.True(t, ok, "leaked Reader not reported")
+	require.Equal(t, id, report.readerID)
+	require.Contains(t, report.stack, "subscribeAndDrop")
+
+	t.Log("the leaked Reader is still holding on to the event")
+	require.Equal(t, 1, d.Stats().Bufsize)
+}
+
+func TestLeakCheckIgnoresUnsubscribed(t *testing.T) {
+	d, reports := newLeakChecked()
+	func() {
+		r := d.Subscribe()
+		r.Unsubscribe()
+	}()
+	func() {
+		r := d.SubscribeFor(time.Nanosecond)
+		time.Sleep(time.Millisecond)
+		_, err := r.TryConsume()
+		require.Error(t, err)
+	}()
+	r := d.Subscribe()
+	d.Close()
+	// r is unreachable from here on, but was released by Close.
+	_, err := r.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrClosed)
+
+	_, ok := collect(reports)
+	require.False(t, ok, "released Reader reported as leaked")
+}
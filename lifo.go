@@ -0,0 +1,158 @@
+package eventdistributor
+
+import (
+	"sort"
+)
+
+// readerLIFO is the state of a Reader that receives the newest events first. See SubscribeLIFO().
+type readerLIFO struct {
+	// seen is the sorted, non-overlapping ranges of sequence numbers of the events after the
+	// Reader's position that it has already consumed. Every buffered event within a range has been
+	// consumed.
+	seen []seqRange
+}
+
+// seqRange is an inclusive range of sequence numbers
+type seqRange struct {
+	lo, hi int64
+}
+
+// SubscribeLIFO creates a new Reader that receives the newest event it has not yet seen first,
+// followed by progressively older ones. During a backlog, this means the freshest events are
+// handled first.
+//
+// As with any Reader, each event is kept in the buffer until the Reader has consumed it - but
+// because the Reader consumes out of order, it also keeps every later event, until it has consumed
+// everything before them. Other Readers are unaffected.
+//
+// Readers created by SubscribeLIFO cannot have a filter. See SetFilter(). SubscribeLIFO panics if
+// the Distributor was created with WithOrderBy.
+//
+// SubscribeLIFO is thread-safe.
+func (d *Distributor[T]) SubscribeLIFO() Reader[T] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.orderBy != nil {
+		panic("eventdistributor: SubscribeLIFO called on a Distributor with WithOrderBy")
+	}
+
+	r := d.subscribe()
+	r.lifo = &readerLIFO{seen: nil}
+	r.publishPosition()
+	return r
+}
+
+// consumeOutOfOrder implements Consume for Readers created by SubscribeLIFO, or of a Distributor
+// with WithOrderBy, additionally returning the sequence number of the consumed event.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) consumeOutOfOrder() (T, int64) {
+	buf := r.d.buf
+	posIdx := int(r.position - r.d.basePosition)
+
+	var idx int
+	if r.d.orderBy != nil {
+		idx = r.firstByPriority()
+	} else {
+		idx = r.newestUnseen()
+	}
+
+	value, seq := buf[idx].value, buf[idx].seq
+	if idx == posIdx {
+		r.moveTo(r.position + 1)
+		r.skipSeen()
+		return value, seq
+	}
+
+	prevSeq := buf[idx-1].seq
+	nextSeq := int64(-1)
+	if idx+1 < len(buf) {
+		nextSeq = buf[idx+1].seq
+	}
+	r.lifo.markSeen(seq, prevSeq, nextSeq)
+	return value, seq
+}
+
+// newestUnseen returns the index in the buffer of the newest event that the Reader has not yet
+// consumed, for Readers created by SubscribeLIFO.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) newestUnseen() int {
+	buf := r.d.buf
+	posIdx := int(r.position - r.d.basePosition)
+
+	// The event at the Reader's position is never seen, so this always finds an event.
+	idx := len(buf) - 1
+	for idx > posIdx {
+		lo, ok := r.lifo.rangeContaining(buf[idx].seq)
+		if !ok {
+			break
+		}
+		idx, _ = r.d.findSeq(lo)
+		idx -= 1
+	}
+	return idx
+}
+
+// skipSeen moves the Reader past any events at its position that it has already consumed, for
+// Readers created by SubscribeLIFO.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) skipSeen() {
+	if r.lifo == nil {
+		return
+	}
+
+	for r.hasPending() {
+		seq := r.d.buf[r.position-r.d.basePosition].seq
+
+		// Ranges entirely before the Reader's position can be left behind by SeekTo().
+		seen := r.lifo.seen
+		for len(seen) != 0 && seen[0].hi < seq {
+			seen = seen[1:]
+		}
+		r.lifo.seen = seen
+		if len(seen) == 0 || seen[0].lo > seq {
+			return
+		}
+
+		buf := r.d.buf
+		end := sort.Search(len(buf), func(i int) bool { return buf[i].seq > seen[0].hi })
+		r.lifo.seen = seen[1:]
+		r.moveTo(r.d.basePosition + int64(end))
+	}
+}
+
+// rangeContaining returns the start of the seen range containing seq, if there is one
+func (l *readerLIFO) rangeContaining(seq int64) (int64, bool) {
+	i := sort.Search(len(l.seen), func(i int) bool { return l.seen[i].hi >= seq })
+	if i < len(l.seen) && l.seen[i].lo <= seq {
+		return l.seen[i].lo, true
+	}
+	return 0, false
+}
+
+// markSeen records that the event with the sequence number seq was consumed, merging it with the
+// ranges of its neighbours in the buffer, whose sequence numbers are prevSeq and nextSeq. If there
+// is no next event, nextSeq is -1.
+func (l *readerLIFO) markSeen(seq, prevSeq, nextSeq int64) {
+	i := sort.Search(len(l.seen), func(i int) bool { return l.seen[i].lo > seq })
+
+	extendsPrev := i > 0 && l.seen[i-1].hi == prevSeq
+	extendsNext := nextSeq != -1 && i < len(l.seen) && l.seen[i].lo == nextSeq
+
+	switch {
+	case extendsPrev && extendsNext:
+		l.seen[i-1].hi = l.seen[i].hi
+		l.seen = append(l.seen[:i], l.seen[i+1:]...)
+	case extendsPrev:
+		l.seen[i-1].hi = seq
+	case extendsNext:
+		l.seen[i].lo = seq
+	default:
+		l.seen = append(l.seen, seqRange{})
+		copy(l.seen[i+1:], l.seen[i:])
+		l.seen[i] = seqRange{lo: seq, hi: seq}
+	}
+}
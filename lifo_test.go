@@ -0,0 +1,77 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSubscribeLIFO(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var consumed []int
+	options.OnFullyConsumed(func(e MyEvent) {
+		consumed = append(consumed, e.id)
+	})
+	d := eventdistributor.New(options)
+
+	fifo := d.Subscribe()
+	defer fifo.Unsubscribe()
+	lifo := d.SubscribeLIFO()
+	defer lifo.Unsubscribe()
+
+	var allConsumed []<-chan struct{}
+	for i := 0; i < 5; i++ {
+		allConsumed = append(allConsumed, d.Submit(MyEvent{id: i}))
+	}
+
+	t.Log("the newest events are received first")
+	require.Equal(t, MyEvent{id: 4}, lifo.Consume())
+	require.Equal(t, MyEvent{id: 3}, lifo.Consume())
+
+	t.Log("FIFO readers are unaffected")
+	require.Equal(t, []int{0, 1, 2, 3, 4}, drainIDs(&fifo))
+
+	t.Log("events are only released once everything before them is consumed")
+	require.Empty(t, consumed)
+	nowNotReady(t, allConsumed[4])
+
+	d.Submit(MyEvent{id: 5})
+	require.Equal(t, MyEvent{id: 5}, lifo.Consume())
+	require.Equal(t, MyEvent{id: 2}, lifo.Consume())
+	require.Equal(t, MyEvent{id: 1}, lifo.Consume())
+	ready(t, lifo)
+	require.Empty(t, consumed)
+
+	require.Equal(t, MyEvent{id: 0}, lifo.Consume())
+	require.Equal(t, []int{0, 1, 2, 3, 4}, consumed)
+	nowReady(t, allConsumed[4])
+	notReady(t, lifo)
+
+	t.Log("a new event after catching up is received as usual")
+	d.Submit(MyEvent{id: 6})
+	require.Equal(t, []int{5, 6}, drainIDs(&fifo))
+	require.Equal(t, []int{6}, drainIDs(&lifo))
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6}, consumed)
+}
+
+func TestSubscribeLIFOFilterInPlace(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	lifo := d.SubscribeLIFO()
+	defer lifo.Unsubscribe()
+
+	for i := 0; i < 6; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+	require.Equal(t, MyEvent{id: 5}, lifo.Consume())
+	require.Equal(t, MyEvent{id: 4}, lifo.Consume())
+
+	t.Log("removing events doesn't disturb what has been seen")
+	d.FilterInPlace(func(e MyEvent) bool { return e.id == 0 || e.id == 3 })
+	require.Equal(t, []int{2, 1}, drainIDs(&lifo))
+	notReady(t, lifo)
+
+	require.Panics(t, func() { lifo.SetFilter(func(MyEvent) bool { return true }) })
+}
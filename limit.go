@@ -0,0 +1,162 @@
+package eventdistributor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrThrottled is returned by SubmitChecked() and SubmitWait() when an event is rejected by the
+// Distributor's submit limiter, with ThrottleError
+var ErrThrottled = errors.New("event submission throttled")
+
+// Limiter limits the rate at which events can be submitted to a Distributor. See
+// (*Options[T]).WithSubmitLimiter().
+//
+// Limiter is satisfied by *rate.Limiter from golang.org/x/time/rate. The ratelimit submodule
+// provides a helper for using one, without requiring that dependency here.
+type Limiter interface {
+	// Allow reports whether an event may be submitted now, consuming a token if so
+	Allow() bool
+	// Wait blocks until an event may be submitted, consuming a token, or returns an error if ctx
+	// is canceled first
+	Wait(ctx context.Context) error
+}
+
+// ThrottlePolicy determines what happens to an event that is submitted when the submit limiter has
+// no tokens available. See (*Options[T]).WithSubmitLimiter().
+type ThrottlePolicy int
+
+const (
+	// ThrottleBlock makes submission wait until the limiter allows the event. SubmitWait() stops
+	// waiting if its context is canceled.
+	ThrottleBlock ThrottlePolicy = iota + 1
+	// ThrottleDrop discards the event, passing it to any OnDrop callbacks with
+	// DropReasonThrottled
+	ThrottleDrop
+	// ThrottleError makes SubmitChecked() and SubmitWait() return ErrThrottled. Because Submit()
+	// can't return an error, it instead discards the event, like ThrottleDrop.
+	ThrottleError
+)
+
+// String implements fmt.Stringer
+func (p ThrottlePolicy) String() string {
+	switch p {
+	case ThrottleBlock:
+		return "Block"
+	case ThrottleDrop:
+		return "Drop"
+	case ThrottleError:
+		return "Error"
+	default:
+		return fmt.Sprintf("ThrottlePolicy(%d)", int(p))
+	}
+}
+
+// WithSubmitLimiter sets a Limiter that every submitted event must take a token from, with the
+// policy determining what happens when none are available.
+//
+// The same Limiter may be shared between Distributors, to limit their combined rate.
+//
+// The limiter is consulted before the Distributor's lock is acquired, so a blocked producer does
+// not stall Readers or other producers that aren't throttled.
+//
+// WithSubmitLimiter panics if policy is not one of the ThrottlePolicy constants.
+func (o *Options[T]) WithSubmitLimiter(l Limiter, policy ThrottlePolicy) {
+	if policy < ThrottleBlock || policy > ThrottleError {
+		panic(fmt.Sprintf("eventdistributor: invalid ThrottlePolicy %v", policy))
+	}
+
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.limiter = l
+		d.throttlePolicy = policy
+	})
+}
+
+// SubmitChecked is like Submit, but returns ErrThrottled instead of discarding the event if the
+// Distributor's submit limiter rejects it with ThrottleError. It also returns a
+// *ReaderLaggingError instead of blocking if a Reader is too far behind, or ErrBufferFull if the
+// buffer of a Distributor created by NewFixed() with OverflowBlock is full. See
+// (*Options[T]).WithMaxReaderLag().
+//
+// With ThrottleBlock, SubmitChecked waits for the limiter without a deadline. To stop waiting
+// early, use SubmitWait().
+//
+// SubmitChecked is thread-safe.
+func (d *Distributor[T]) SubmitChecked(value T) (<-chan struct{}, error) {
+	return d.submitWait(context.Background(), value, d.captureCaller(), false)
+}
+
+// SubmitWait is like Submit, but stops waiting for the Distributor's submit limiter or lagging
+// Readers when ctx is canceled, returning the error. If the limiter rejects the event with
+// ThrottleError, SubmitWait returns ErrThrottled.
+//
+// If an error is returned, the event was not submitted, and is not passed to any callbacks.
+//
+// SubmitWait is thread-safe.
+func (d *Distributor[T]) SubmitWait(ctx context.Context, value T) (<-chan struct{}, error) {
+	return d.submitWait(ctx, value, d.captureCaller(), true)
+}
+
+// submitWait implements SubmitWait and SubmitChecked, for an event submitted from caller. See
+// WithCallerCapture. If waitForReaders is false, lagging Readers cause an error instead of
+// blocking.
+func (d *Distributor[T]) submitWait(
+	ctx context.Context,
+	value T,
+	caller []uintptr,
+	waitForReaders bool,
+) (<-chan struct{}, error) {
+	d.enterProducer()
+	defer d.exitProducer()
+
+	if err := d.throttle(ctx); err != nil {
+		if d.throttlePolicy != ThrottleDrop {
+			return nil, err
+		}
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		d.absorbPublished()
+		d.noteSubmitted(value)
+		d.dropped(value, -1, DropReasonThrottled)
+		return closedChannel, nil
+	}
+
+	// Direct calls are made once the lock is released. See SubscribeHandler().
+	var directCalls *handlerSub[T]
+	defer func() { runDirectCalls(directCalls) }()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.absorbPublished()
+	if err := d.waitForLaggingReaders(ctx, waitForReaders); err != nil {
+		return nil, err
+	}
+
+	allConsumed, _ := d.submit(value, caller, nil, nil, nil, true, true)
+	directCalls = d.takeDirectCalls()
+	return allConsumed, nil
+}
+
+// throttle takes a token from the submit limiter, if there is one, returning a non-nil error if
+// the event should not be submitted.
+//
+// d.mu must NOT be held, because the limiter may block.
+func (d *Distributor[T]) throttle(ctx context.Context) error {
+	if d.limiter == nil {
+		return nil
+	}
+
+	switch d.throttlePolicy {
+	case ThrottleBlock:
+		return d.limiter.Wait(ctx)
+	default:
+		if !d.limiter.Allow() {
+			return ErrThrottled
+		}
+		return nil
+	}
+}
@@ -0,0 +1,153 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+// tokenLimiter is an eventdistributor.Limiter that allows one event per token added with give
+type tokenLimiter struct {
+	tokens chan struct{}
+}
+
+func newTokenLimiter() *tokenLimiter {
+	return &tokenLimiter{tokens: make(chan struct{}, 100)}
+}
+
+func (l *tokenLimiter) give(n int) {
+	for i := 0; i < n; i++ {
+		l.tokens <- struct{}{}
+	}
+}
+
+func (l *tokenLimiter) Allow() bool {
+	select {
+	case <-l.tokens:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *tokenLimiter) Wait(ctx context.Context) error {
+	select {
+	case <-l.tokens:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestSubmitLimiterDrop(t *testing.T) {
+	limiter := newTokenLimiter()
+
+	var options eventdistributor.Options[MyEvent]
+	options.WithSubmitLimiter(limiter, eventdistributor.ThrottleDrop)
+	var dropped []MyEvent
+	options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+		require.Equal(t, eventdistributor.DropReasonThrottled, reason)
+		dropped = append(dropped, e)
+	})
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	limiter.give(1)
+	nowNotReady(t, d.Submit(MyEvent{id: 1}))
+	nowReady(t, d.Submit(MyEvent{id: 2}))
+	allConsumed, err := d.SubmitChecked(MyEvent{id: 3})
+	require.NoError(t, err)
+	nowReady(t, allConsumed)
+
+	require.Equal(t, []MyEvent{{id: 2}, {id: 3}}, dropped)
+	require.Equal(t, []int{1}, drainIDs(&r))
+}
+
+func TestSubmitLimiterError(t *testing.T) {
+	limiter := newTokenLimiter()
+
+	var options eventdistributor.Options[MyEvent]
+	options.WithSubmitLimiter(limiter, eventdistributor.ThrottleError)
+	var dropped []MyEvent
+	options.OnDrop(func(e MyEvent, _ eventdistributor.DropReason) {
+		dropped = append(dropped, e)
+	})
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	limiter.give(1)
+	_, err := d.SubmitChecked(MyEvent{id: 1})
+	require.NoError(t, err)
+
+	t.Log("SubmitChecked reports the error, without dropping")
+	_, err = d.SubmitChecked(MyEvent{id: 2})
+	require.ErrorIs(t, err, eventdistributor.ErrThrottled)
+	require.Empty(t, dropped)
+
+	t.Log("Submit can't report the error, so it drops")
+	nowReady(t, d.Submit(MyEvent{id: 3}))
+	require.Equal(t, []MyEvent{{id: 3}}, dropped)
+
+	require.Equal(t, []int{1}, drainIDs(&r))
+}
+
+func TestSubmitLimiterBlock(t *testing.T) {
+	limiter := newTokenLimiter()
+
+	var options eventdistributor.Options[MyEvent]
+	options.WithSubmitLimiter(limiter, eventdistributor.ThrottleBlock)
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	t.Log("SubmitWait gives up when the context is canceled")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := d.SubmitWait(ctx, MyEvent{id: 1})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	t.Log("Submit waits for a token")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.Submit(MyEvent{id: 2})
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Submit returned without a token")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	t.Log("Readers are not blocked by a throttled producer")
+	_, err = r.TryConsume()
+	require.Equal(t, eventdistributor.ErrNoEvent, err)
+
+	limiter.give(1)
+	<-done
+	require.Equal(t, []int{2}, drainIDs(&r))
+}
+
+func TestSubmitLimiterShared(t *testing.T) {
+	limiter := newTokenLimiter()
+
+	var options eventdistributor.Options[MyEvent]
+	options.WithSubmitLimiter(limiter, eventdistributor.ThrottleError)
+	d1 := eventdistributor.New(options)
+	d2 := eventdistributor.New(options)
+
+	limiter.give(1)
+	_, err := d1.SubmitChecked(MyEvent{id: 1})
+	require.NoError(t, err)
+	_, err = d2.SubmitChecked(MyEvent{id: 2})
+	require.ErrorIs(t, err, eventdistributor.ErrThrottled)
+}
@@ -0,0 +1,80 @@
+package eventdistributor
+
+import (
+	"time"
+)
+
+// SubscribeLossyByAge creates a new Reader that skips any events older than maxAge, instead of
+// delivering them.
+//
+// Events are checked lazily - when the Reader calls WaitChan(), Consume(), or TryConsume() - and
+// every pending event that is older than maxAge at that time is skipped, as if it had been
+// consumed. Other Readers are unaffected. The number of skipped events is available from
+// (*Reader[T]).Skipped().
+//
+// Because events may become too old between WaitChan() and Consume(), Readers created by
+// SubscribeLossyByAge should use TryConsume() instead of Consume().
+//
+// SubscribeLossyByAge requires the Distributor to record timestamps, and panics otherwise. See
+// (*Options[T]).WithTimestamps().
+//
+// SubscribeLossyByAge is thread-safe.
+func (d *Distributor[T]) SubscribeLossyByAge(maxAge time.Duration) Reader[T] {
+	if !d.timestamps {
+		panic("eventdistributor: SubscribeLossyByAge requires timestamps. See (*Options[T]).WithTimestamps()")
+	} else if maxAge <= 0 {
+		panic("eventdistributor: SubscribeLossyByAge requires maxAge > 0")
+	}
+
+	r := d.Subscribe()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	r.maxAge = maxAge
+	r.publishPosition()
+	return r
+}
+
+// Skipped returns the total number of events that this Reader has skipped without delivering them.
+//
+// Skipped is thread-safe.
+func (r *Reader[T]) Skipped() int64 {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	return r.skipped
+}
+
+// TakeSkipped returns the number of events that this Reader has skipped without delivering them
+// since the last call to TakeSkipped, or since it was created. See Skipped().
+//
+// TakeSkipped is thread-safe.
+func (r *Reader[T]) TakeSkipped() int64 {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	n := r.skipped - r.skippedTaken
+	r.skippedTaken = r.skipped
+	return n
+}
+
+// skipStale skips all pending events at the front of the reader's unseen events that are older
+// than r.maxAge, if it is set.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) skipStale() {
+	if r.maxAge == 0 {
+		return
+	}
+
+	now := r.d.getClock().Now()
+	for r.hasPending() {
+		idx := int(r.position - r.d.basePosition)
+		if now.Sub(r.d.buf[idx].timestamp) <= r.maxAge {
+			return
+		}
+
+		r.consume()
+		r.skipped += 1
+	}
+}
@@ -0,0 +1,75 @@
+package eventdistributor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSubscribeLossyByAge(t *testing.T) {
+	clock := newFakeClock()
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	options.WithTimestamps()
+	var consumed []MyEvent
+	options.OnFullyConsumed(func(e MyEvent) {
+		consumed = append(consumed, e)
+	})
+
+	d := eventdistributor.New(options)
+
+	lossy := d.SubscribeLossyByAge(2 * time.Second)
+	defer lossy.Unsubscribe()
+	normal := d.Subscribe()
+	defer normal.Unsubscribe()
+
+	t.Log("fresh events are delivered")
+	d.Submit(MyEvent{id: 1})
+	ready(t, lossy)
+	e, err := lossy.TryConsume()
+	require.NoError(t, err)
+	require.Equal(t, 1, e.id)
+
+	t.Log("old events are skipped, up to the first fresh one")
+	d.Submit(MyEvent{id: 2})
+	d.Submit(MyEvent{id: 3})
+	clock.Advance(1500 * time.Millisecond)
+	d.Submit(MyEvent{id: 4})
+	clock.Advance(1 * time.Second)
+	ready(t, lossy)
+	require.Equal(t, int64(2), lossy.Skipped())
+	e, err = lossy.TryConsume()
+	require.NoError(t, err)
+	require.Equal(t, 4, e.id)
+
+	t.Log("skipping releases the events, but other readers are unaffected")
+	require.Len(t, consumed, 0)
+	for _, id := range []int{1, 2, 3, 4} {
+		require.Equal(t, id, normal.Consume().id)
+	}
+	require.Len(t, consumed, 4)
+
+	t.Log("if every pending event is too old, the reader is not ready")
+	d.Submit(MyEvent{id: 5})
+	clock.Advance(3 * time.Second)
+	notReady(t, lossy)
+	require.Equal(t, int64(3), lossy.Skipped())
+
+	t.Log("events that became too old after WaitChan are skipped by TryConsume")
+	d.Submit(MyEvent{id: 6})
+	ready(t, lossy)
+	clock.Advance(3 * time.Second)
+	_, err = lossy.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrNoEvent)
+	require.Equal(t, int64(4), lossy.Skipped())
+}
+
+func TestSubscribeLossyByAgeRequiresTimestamps(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	require.Panics(t, func() {
+		d.SubscribeLossyByAge(time.Second)
+	})
+}
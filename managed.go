@@ -0,0 +1,235 @@
+package eventdistributor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// HandlerFunc handles an event for a handler created by AddHandlerFunc(). ctx is canceled once
+// the handler is stopped.
+type HandlerFunc[T any] func(ctx context.Context, item T) error
+
+// HandlerOptions contains a set of options for handlers created by AddHandler() or
+// AddHandlerFunc().
+//
+// Like Options, HandlerOptions can be set by calling its methods, or built inline with the
+// function of the same name as each method - prefixed by "With" for callbacks.
+//
+// The zero value is safe to use.
+type HandlerOptions[T any] struct {
+	modify []func(*managedHandler[T])
+}
+
+// AddHandler calls f with every event submitted from now on, in order, from a goroutine dedicated
+// to the handler, until the returned stop function is called. It runs the loop that would
+// otherwise be written by hand around Subscribe(), Next(), and Unsubscribe().
+//
+// Each handler has its own Reader, so handlers are independent of each other and of producers: a
+// slow handler only falls behind on its own events, which are buffered for it like for any other
+// Reader. Unlike SubscribeHandler(), f is never called by producers.
+//
+// If f panics and the Distributor was created with OnCallbackPanic, the recovered value is passed
+// to that handler, and the handler continues with the next event. Otherwise, the handler stops,
+// and the panic is re-raised by the next call to stop, so that it isn't lost.
+//
+// stop unsubscribes the handler and waits for any call to f that's in progress to return. Once it
+// returns, f is not called again. Calling stop more than once has no effect, and it must not be
+// called from within f, because it would wait for itself. If the Distributor is closed, the
+// handler stops receiving events, but stop must still be called to wait for it. To let handlers
+// finish the events they've fallen behind on first, see StopHandlers().
+//
+// AddHandler is thread-safe.
+func (d *Distributor[T]) AddHandler(f func(T), options ...HandlerOptions[T]) (stop func()) {
+	return d.AddHandlerFunc(func(_ context.Context, item T) error {
+		f(item)
+		return nil
+	}, options...)
+}
+
+// AddHandlerFunc is like AddHandler(), but for a HandlerFunc, which may fail: if f returns an
+// error, the event is retried as configured by (*HandlerOptions[T]).WithRetry(), and reported to
+// (*HandlerOptions[T]).OnFailed() if it never succeeds. Retries are part of handling the event, so
+// the handler doesn't move on to later events until the event succeeds or is given up on.
+//
+// The context passed to f is canceled once stop is called, which also interrupts any wait between
+// retries. An event that's being retried when the handler is stopped, or halted by
+// StopHandlers(), is abandoned without being reported.
+//
+// AddHandlerFunc is thread-safe.
+func (d *Distributor[T]) AddHandlerFunc(
+	f HandlerFunc[T],
+	options ...HandlerOptions[T],
+) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &managedHandler[T]{
+		r:          d.Subscribe(),
+		fn:         f,
+		name:       "",
+		retry:      handlerRetry{initial: 0, multiplier: 1, maxAttempts: 1},
+		onFailed:   nil,
+		middleware: nil,
+		ctx:        ctx,
+		stopping:   make(chan struct{}),
+		drainTo:    -1,
+		halt:       make(chan struct{}),
+		halted:     false,
+		done:       make(chan struct{}),
+		panicked:   false,
+		recovered:  nil,
+	}
+	for _, os := range options {
+		for _, modify := range os.modify {
+			modify(h)
+		}
+	}
+	h.fn = wrap(h.fn, h.middleware)
+	if h.name == "" {
+		h.name = fmt.Sprintf("handler %d", h.r.ID())
+	}
+
+	d.mu.Lock()
+	d.managed = append(d.managed, h)
+	d.mu.Unlock()
+	go h.run()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-h.done
+			if h.panicked {
+				panic(h.recovered)
+			}
+		})
+	}
+}
+
+// managedHandler is a handler created by AddHandler() or AddHandlerFunc()
+type managedHandler[T any] struct {
+	r  Reader[T]
+	fn HandlerFunc[T]
+
+	name       string
+	retry      handlerRetry
+	onFailed   []func(item T, err error)
+	middleware []Middleware[T]
+
+	// ctx is canceled by the handler's stop function
+	ctx context.Context
+	// stopping is closed by StopHandlers(), once drainTo is set. drainTo is the position up to
+	// which the handler handles events before exiting, or -1 if StopHandlers() hasn't been called.
+	// It is protected by d.mu.
+	stopping chan struct{}
+	drainTo  int64
+	// halt is closed, with halted set, if StopHandlers() gives up waiting for the handler to
+	// drain, so that it exits once any call to fn in progress returns. halted is protected by d.mu.
+	halt   chan struct{}
+	halted bool
+	// done is closed once the handler's goroutine has exited, after which the fields below can
+	// be read without synchronization
+	done chan struct{}
+
+	// panicked is true if fn panicked without OnCallbackPanic, with the value recovered from the
+	// panic
+	panicked  bool
+	recovered any
+}
+
+// run calls the handler's fn with each event, until it's stopped or drained, the Reader can no
+// longer be used, or fn panics without OnCallbackPanic
+func (h *managedHandler[T]) run() {
+	defer close(h.done)
+	defer h.remove()
+
+	for {
+		value, ok := h.next()
+		if !ok || !h.call(value) {
+			return
+		}
+	}
+}
+
+// next waits for the next event for the handler and consumes it, like (*Reader[T]).Next(),
+// returning false if the handler should exit instead
+func (h *managedHandler[T]) next() (T, bool) {
+	var zero T
+	for {
+		// Check first, so that a handler that was halted or has drained doesn't handle another
+		// event just because the select below chose it.
+		select {
+		case <-h.ctx.Done():
+			return zero, false
+		case <-h.halt:
+			return zero, false
+		default:
+		}
+		select {
+		case <-h.stopping:
+			if h.drained() {
+				return zero, false
+			}
+		default:
+		}
+
+		// The handler's Reader is its own, so it can reuse the same channel for every wait.
+		select {
+		case <-h.ctx.Done():
+			return zero, false
+		case <-h.halt:
+			return zero, false
+		case <-h.stopping:
+			if h.drained() {
+				return zero, false
+			}
+		case <-h.r.waitSignal():
+		}
+
+		value, err := h.r.TryConsume()
+		if err == nil {
+			return value, true
+		} else if err != ErrNoEvent {
+			return zero, false
+		}
+	}
+}
+
+// remove unsubscribes the handler's Reader, and removes it from d.managed, once its goroutine is
+// exiting
+func (h *managedHandler[T]) remove() {
+	d := h.r.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	h.r.unsubscribe()
+	for i, other := range d.managed {
+		if other == h {
+			last := len(d.managed) - 1
+			copy(d.managed[i:], d.managed[i+1:])
+			d.managed[last] = nil
+			d.managed = d.managed[:last]
+			return
+		}
+	}
+}
+
+// call calls fn with value, retrying it if it fails, and returning false if it panicked and the
+// handler must stop
+func (h *managedHandler[T]) call(value T) (ok bool) {
+	onPanic := h.r.d.mu.onPanic
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if onPanic != nil {
+				onPanic(recovered)
+				ok = true
+			} else {
+				h.panicked = true
+				h.recovered = recovered
+				ok = false
+			}
+		}
+	}()
+
+	h.handle(value)
+	return true
+}
@@ -0,0 +1,115 @@
+package eventdistributor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestAddHandler(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	received := make(chan int)
+	release := make(chan struct{})
+	stop := d.AddHandler(func(e MyEvent) {
+		received <- e.id
+		<-release
+	})
+
+	t.Log("the handler receives every event, in order")
+	for i := 0; i < 3; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+	for i := 0; i < 3; i++ {
+		require.Equal(t, i, <-received)
+		release <- struct{}{}
+	}
+
+	t.Log("stop waits for the call that's in progress")
+	d.Submit(MyEvent{id: 3})
+	require.Equal(t, 3, <-received)
+	stopped := make(chan struct{})
+	go func() {
+		stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("stop returned while the handler was running")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+	<-stopped
+
+	t.Log("stopped handlers aren't called, and don't hold events")
+	done := d.Submit(MyEvent{id: 4})
+	nowReady(t, done)
+	require.NotPanics(t, stop)
+}
+
+func TestAddHandlerIndependent(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	block := make(chan struct{})
+	stopSlow := d.AddHandler(func(MyEvent) { <-block })
+	defer stopSlow()
+	defer close(block)
+
+	received := make(chan int)
+	stop := d.AddHandler(func(e MyEvent) { received <- e.id })
+	defer stop()
+
+	t.Log("a blocked handler doesn't hold up producers or other handlers")
+	for i := 0; i < 3; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+	for i := 0; i < 3; i++ {
+		require.Equal(t, i, <-received)
+	}
+}
+
+func TestAddHandlerPanic(t *testing.T) {
+	t.Log("with OnCallbackPanic, panics are reported, and the handler continues")
+	recovered := make(chan any, 1)
+	var options eventdistributor.Options[MyEvent]
+	options.OnCallbackPanic(func(r any) { recovered <- r })
+	d := eventdistributor.New(options)
+
+	received := make(chan int)
+	stop := d.AddHandler(func(e MyEvent) {
+		if e.id == 0 {
+			panic("handler panicked")
+		}
+		received <- e.id
+	})
+	d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 1})
+	require.Equal(t, 1, <-received)
+	require.Equal(t, "handler panicked", <-recovered)
+	require.NotPanics(t, stop)
+
+	t.Log("without OnCallbackPanic, the handler stops, and stop re-raises the panic")
+	d = eventdistributor.New[MyEvent]()
+	stop = d.AddHandler(func(e MyEvent) { panic(e.id) })
+	done := d.Submit(MyEvent{id: 2})
+	<-done
+	require.PanicsWithValue(t, 2, stop)
+	require.NotPanics(t, stop)
+}
+
+func TestAddHandlerClosed(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	var received []int
+	stop := d.AddHandler(func(e MyEvent) { received = append(received, e.id) })
+	done := d.Submit(MyEvent{id: 0})
+	<-done
+
+	t.Log("closing the Distributor stops the handler")
+	d.Close()
+	stop()
+	require.Equal(t, []int{0}, received)
+}
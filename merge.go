@@ -0,0 +1,316 @@
+package eventdistributor
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// MergeOrdered merges events from all of the sources into a single new Distributor, ordered by
+// less. It is equivalent to OrderedMerge[T]{Less: less, MaxSkew: maxSkew}.Start(sources...).
+//
+// For more information, see OrderedMerge.
+func MergeOrdered[T any](
+	less func(a, b T) bool,
+	maxSkew time.Duration,
+	sources ...*Distributor[T],
+) (_ *Distributor[T], stop func()) {
+	return OrderedMerge[T]{
+		Less:         less,
+		MaxSkew:      maxSkew,
+		OnSourceIdle: nil,
+		Clock:        nil,
+		Options:      nil,
+	}.Start(sources...)
+}
+
+// OrderedMerge is the configuration for merging multiple Distributors into one, with the events
+// ordered between sources.
+//
+// Each source is expected to produce events in order, but events from different sources may
+// arrive out of order with respect to each other, within MaxSkew. An event is emitted into the
+// output once every source has produced an event that is not less than it (so nothing earlier can
+// still arrive), or once it has been held for MaxSkew, whichever comes first. The second case
+// ensures that a slow or idle source only delays the output by at most MaxSkew.
+//
+// Events that arrive after a later event has already been emitted - i.e. more than MaxSkew late -
+// are emitted immediately, out of order.
+type OrderedMerge[T any] struct {
+	// Less defines the order of events. It is required.
+	Less func(a, b T) bool
+	// MaxSkew is the maximum time that an event is held while waiting for other sources.
+	MaxSkew time.Duration
+	// OnSourceIdle, if not nil, is called with the index of a source whenever an event is emitted
+	// without waiting for that source, because it has not produced anything for MaxSkew. It is
+	// called at most once per idle period; the period ends when the source produces an event.
+	OnSourceIdle func(source int)
+	// Clock is the Clock used for timing. If it is nil, the time package is used directly.
+	Clock Clock
+	// Options are the options for the output Distributor
+	Options []Options[T]
+}
+
+// Start begins merging events from the sources, returning the output Distributor.
+//
+// The returned stop function stops merging, emits any events that are still being held, and
+// unsubscribes from the sources. It waits for the background goroutines to exit, and it is safe to
+// call more than once. Closing a source is also allowed, and causes the merge to stop waiting for
+// that source.
+func (m OrderedMerge[T]) Start(sources ...*Distributor[T]) (_ *Distributor[T], stop func()) {
+	output := New(m.Options...)
+
+	clock := m.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	s := &orderedMergeState[T]{
+		cfg:       m,
+		clock:     clock,
+		output:    output,
+		sources:   make([]mergeSource[T], len(sources)),
+		held:      mergeHeap[T]{less: m.Less, items: nil},
+		nextOrder: 0,
+		incoming:  make(chan mergeItem[T]),
+		wakeup:    make(chan struct{}, 1),
+		timer:     nil,
+		done:      make(chan struct{}),
+	}
+
+	start := clock.Now()
+	for i := range sources {
+		s.sources[i].lastArrival = start
+	}
+
+	// Subscribe to all sources before returning, so that no events submitted after Start returns
+	// are missed.
+	readers := make([]Reader[T], len(sources))
+	for i, src := range sources {
+		readers[i] = src.Subscribe()
+	}
+
+	s.wg.Add(len(sources) + 1)
+	for i := range sources {
+		go s.pump(i, readers[i])
+	}
+	go s.run()
+
+	var stopOnce sync.Once
+	return output, func() {
+		stopOnce.Do(func() { close(s.done) })
+		s.wg.Wait()
+	}
+}
+
+type orderedMergeState[T any] struct {
+	cfg    OrderedMerge[T]
+	clock  Clock
+	output *Distributor[T]
+
+	// sources and held are only accessed by the run goroutine
+	sources   []mergeSource[T]
+	held      mergeHeap[T]
+	nextOrder uint64
+
+	incoming chan mergeItem[T]
+	// wakeup receives a value when the timer for the next held event fires
+	wakeup chan struct{}
+	timer  Timer
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+type mergeSource[T any] struct {
+	// last is the most recent event from the source, if hasLast is true
+	last    T
+	hasLast bool
+	// lastArrival is the time at which the last event from the source arrived, or the time the
+	// merge started if it hasn't produced anything yet
+	lastArrival time.Time
+	// idleReported is true if OnSourceIdle has been called since the last event from the source
+	idleReported bool
+	// closed is true if the source was closed, in which case it's no longer waited for
+	closed bool
+}
+
+type mergeItem[T any] struct {
+	source  int
+	value   T
+	arrival time.Time
+	// closed is true if the item is not an event, but instead notice that the source was closed
+	closed bool
+	// order is the order in which items were received, used to break ties between equal events
+	order uint64
+}
+
+// pump forwards events from a single source into s.incoming
+func (s *orderedMergeState[T]) pump(source int, r Reader[T]) {
+	defer s.wg.Done()
+	defer r.Unsubscribe()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-r.WaitChan():
+		}
+
+		value, err := r.TryConsume()
+		if err == ErrNoEvent {
+			continue
+		}
+
+		item := mergeItem[T]{
+			source:  source,
+			value:   value,
+			arrival: time.Time{},
+			closed:  err != nil,
+			order:   0,
+		}
+		select {
+		case <-s.done:
+			return
+		case s.incoming <- item:
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// run handles incoming events, emitting them into the output once they are ready
+func (s *orderedMergeState[T]) run() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.done:
+			if s.timer != nil {
+				s.timer.Stop()
+			}
+			// Emit everything that's left, in order.
+			for s.held.Len() != 0 {
+				s.output.SubmitQuiet(heap.Pop(&s.held).(mergeItem[T]).value)
+			}
+			return
+		case item := <-s.incoming:
+			s.receive(item)
+		case <-s.wakeup:
+			s.timer = nil
+		}
+
+		s.emitReady()
+	}
+}
+
+func (s *orderedMergeState[T]) receive(item mergeItem[T]) {
+	src := &s.sources[item.source]
+	if item.closed {
+		src.closed = true
+		return
+	}
+
+	item.arrival = s.clock.Now()
+	item.order = s.nextOrder
+	s.nextOrder += 1
+
+	src.last = item.value
+	src.hasLast = true
+	src.lastArrival = item.arrival
+	src.idleReported = false
+
+	heap.Push(&s.held, item)
+}
+
+// emitReady emits all held events that are ready, and then arranges to be woken when the next one
+// will be.
+func (s *orderedMergeState[T]) emitReady() {
+	now := s.clock.Now()
+
+	for s.held.Len() != 0 {
+		next := s.held.items[0]
+		heldTooLong := !now.Before(next.arrival.Add(s.cfg.MaxSkew))
+
+		ready := true
+		for i := range s.sources {
+			if !s.sourceCaughtUp(i, next.value) {
+				ready = false
+				break
+			}
+		}
+
+		if !ready && !heldTooLong {
+			break
+		}
+
+		if !ready {
+			// We're bypassing at least one source. Report the ones that have been idle.
+			for i := range s.sources {
+				src := &s.sources[i]
+				idle := !now.Before(src.lastArrival.Add(s.cfg.MaxSkew))
+				if !s.sourceCaughtUp(i, next.value) && idle && !src.idleReported {
+					src.idleReported = true
+					if s.cfg.OnSourceIdle != nil {
+						s.cfg.OnSourceIdle(i)
+					}
+				}
+			}
+		}
+
+		heap.Pop(&s.held)
+		s.output.SubmitQuiet(next.value)
+	}
+
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.held.Len() != 0 {
+		wait := s.held.items[0].arrival.Add(s.cfg.MaxSkew).Sub(now)
+		s.timer = s.clock.AfterFunc(wait, func() {
+			select {
+			case s.wakeup <- struct{}{}:
+			default:
+			}
+		})
+	}
+}
+
+// sourceCaughtUp returns whether the source can no longer produce anything earlier than value
+func (s *orderedMergeState[T]) sourceCaughtUp(source int, value T) bool {
+	src := &s.sources[source]
+	return src.closed || (src.hasLast && !s.cfg.Less(src.last, value))
+}
+
+// mergeHeap is a heap of held events, implementing heap.Interface. Ties are broken by the order
+// in which the events were received.
+type mergeHeap[T any] struct {
+	less  func(a, b T) bool
+	items []mergeItem[T]
+}
+
+func (h *mergeHeap[T]) Len() int { return len(h.items) }
+
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if h.less(a.value, b.value) {
+		return true
+	} else if h.less(b.value, a.value) {
+		return false
+	}
+	return a.order < b.order
+}
+
+func (h *mergeHeap[T]) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *mergeHeap[T]) Push(x any) { h.items = append(h.items, x.(mergeItem[T])) }
+
+func (h *mergeHeap[T]) Pop() any {
+	last := len(h.items) - 1
+	item := h.items[last]
+	h.items[last] = mergeItem[T]{}
+	h.items = h.items[:last]
+	return item
+}
@@ -0,0 +1,151 @@
+package eventdistributor_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func lessByID(a, b MyEvent) bool {
+	return a.id < b.id
+}
+
+func TestMergeOrderedOutOfOrderWithinSkew(t *testing.T) {
+	a := eventdistributor.New[MyEvent]()
+	b := eventdistributor.New[MyEvent]()
+
+	merged, stop := eventdistributor.MergeOrdered(lessByID, time.Second, a, b)
+	defer stop()
+	r := merged.Subscribe()
+	defer r.Unsubscribe()
+
+	a.Submit(MyEvent{id: 1})
+	a.Submit(MyEvent{id: 3})
+	time.Sleep(10 * time.Millisecond)
+
+	t.Log("nothing is emitted until b has produced something")
+	notReady(t, r)
+
+	t.Log("b's later event fills in the gap")
+	b.Submit(MyEvent{id: 2})
+	require.Equal(t, 1, await(t, &r).id)
+	require.Equal(t, 2, await(t, &r).id)
+
+	t.Log("3 is held until b catches up")
+	time.Sleep(10 * time.Millisecond)
+	notReady(t, r)
+	b.Submit(MyEvent{id: 4})
+	require.Equal(t, 3, await(t, &r).id)
+}
+
+func TestMergeOrderedSlowSource(t *testing.T) {
+	a := eventdistributor.New[MyEvent]()
+	b := eventdistributor.New[MyEvent]()
+
+	merged, stop := eventdistributor.MergeOrdered(lessByID, time.Second, a, b)
+	defer stop()
+	r := merged.Subscribe()
+	defer r.Unsubscribe()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for id := 0; id < 20; id += 2 {
+			a.Submit(MyEvent{id: id})
+		}
+		a.Submit(MyEvent{id: 100})
+	}()
+	go func() {
+		defer wg.Done()
+		for id := 1; id < 20; id += 2 {
+			time.Sleep(time.Millisecond)
+			b.Submit(MyEvent{id: id})
+		}
+		b.Submit(MyEvent{id: 100})
+	}()
+	wg.Wait()
+
+	t.Log("events from the slow source are interleaved in order")
+	for id := 0; id < 20; id++ {
+		require.Equal(t, id, await(t, &r).id)
+	}
+}
+
+func TestMergeOrderedIdleSource(t *testing.T) {
+	a := eventdistributor.New[MyEvent]()
+	idle := eventdistributor.New[MyEvent]()
+
+	var mu sync.Mutex
+	var idleSources []int
+	merged, stop := eventdistributor.OrderedMerge[MyEvent]{
+		Less:    lessByID,
+		MaxSkew: 20 * time.Millisecond,
+		OnSourceIdle: func(source int) {
+			mu.Lock()
+			defer mu.Unlock()
+			idleSources = append(idleSources, source)
+		},
+	}.Start(a, idle)
+	defer stop()
+	r := merged.Subscribe()
+	defer r.Unsubscribe()
+
+	start := time.Now()
+	a.Submit(MyEvent{id: 1})
+	a.Submit(MyEvent{id: 2})
+
+	t.Log("events are emitted after MaxSkew, bypassing the idle source")
+	require.Equal(t, 1, await(t, &r).id)
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	require.Equal(t, 2, await(t, &r).id)
+
+	mu.Lock()
+	require.Equal(t, []int{1}, idleSources)
+	mu.Unlock()
+
+	t.Log("once the idle source produces something, it's waited for again")
+	idle.Submit(MyEvent{id: 3})
+	a.Submit(MyEvent{id: 4})
+	require.Equal(t, 3, await(t, &r).id)
+}
+
+func TestMergeOrderedStop(t *testing.T) {
+	a := eventdistributor.New[MyEvent]()
+	b := eventdistributor.New[MyEvent]()
+
+	merged, stop := eventdistributor.MergeOrdered(lessByID, time.Hour, a, b)
+	r := merged.Subscribe()
+	defer r.Unsubscribe()
+
+	a.Submit(MyEvent{id: 2})
+	a.Submit(MyEvent{id: 1})
+	time.Sleep(10 * time.Millisecond)
+	notReady(t, r)
+
+	t.Log("stop emits held events, in order")
+	stop()
+	stop()
+	require.Equal(t, []int{1, 2}, drainIDs(&r))
+
+	t.Log("after stop, sources are no longer subscribed to")
+	nowReady(t, a.Submit(MyEvent{id: 3}))
+}
+
+func TestMergeOrderedClosedSource(t *testing.T) {
+	a := eventdistributor.New[MyEvent]()
+	b := eventdistributor.New[MyEvent]()
+
+	merged, stop := eventdistributor.MergeOrdered(lessByID, time.Hour, a, b)
+	defer stop()
+	r := merged.Subscribe()
+	defer r.Unsubscribe()
+
+	a.Submit(MyEvent{id: 1})
+	b.Close()
+	require.Equal(t, 1, await(t, &r).id)
+}
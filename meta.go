@@ -0,0 +1,137 @@
+package eventdistributor
+
+import (
+	"fmt"
+)
+
+// MetaEvent is a record of something that happened inside a Distributor, delivered by the
+// Distributor returned by Events().
+//
+// Only the fields relevant to the Kind are set; the rest are zero.
+type MetaEvent struct {
+	Kind MetaEventKind
+	// Seq is the sequence number of the event concerned, or -1 if it didn't get one. Set for
+	// MetaDrop.
+	Seq int64
+	// Reader is the Reader concerned. Set for MetaSubscribe, MetaUnsubscribe, MetaExpire,
+	// MetaProducerBlocked, and MetaEvict.
+	Reader ReaderInfo
+	// DropReason is the reason the event was dropped. Set for MetaDrop.
+	DropReason DropReason
+	// BufferSize is the number of buffered events at the time. Set for every Kind.
+	BufferSize int
+}
+
+// MetaEventKind is the kind of a MetaEvent
+type MetaEventKind int
+
+const (
+	// MetaSubscribe indicates that a Reader was created
+	MetaSubscribe MetaEventKind = iota + 1
+	// MetaUnsubscribe indicates that a Reader was unsubscribed
+	MetaUnsubscribe
+	// MetaExpire indicates that a Reader created by SubscribeFor expired
+	MetaExpire
+	// MetaDrop indicates that an event was dropped, as reported to OnDrop callbacks
+	MetaDrop
+	// MetaProducerBlocked indicates that a producer is blocked because the Reader is too far
+	// behind. See (*Options[T]).WithMaxReaderLag().
+	MetaProducerBlocked
+	// MetaSeal indicates that the Distributor was sealed
+	MetaSeal
+	// MetaClose indicates that the Distributor was closed. It is always the last MetaEvent.
+	MetaClose
+	// MetaEvict indicates that a Reader was evicted for falling too far behind. See
+	// (*Options[T]).WithEvictOverLag().
+	MetaEvict
+)
+
+// String implements fmt.Stringer
+func (k MetaEventKind) String() string {
+	switch k {
+	case MetaSubscribe:
+		return "Subscribe"
+	case MetaUnsubscribe:
+		return "Unsubscribe"
+	case MetaExpire:
+		return "Expire"
+	case MetaDrop:
+		return "Drop"
+	case MetaProducerBlocked:
+		return "ProducerBlocked"
+	case MetaSeal:
+		return "Seal"
+	case MetaClose:
+		return "Close"
+	case MetaEvict:
+		return "Evict"
+	default:
+		return fmt.Sprintf("MetaEventKind(%d)", int(k))
+	}
+}
+
+// Events returns a Distributor that reports what happens inside this one, as a stream of
+// MetaEvents. It is created by the first call, and every call returns the same one. Until then,
+// nothing is recorded, so a Distributor whose Events() are never requested pays almost nothing.
+//
+// MetaEvents are submitted while this Distributor's lock is held, so they are in the same order as
+// the things they describe. As with any Distributor, MetaEvents are only kept for Readers that
+// exist when they're submitted.
+//
+// When this Distributor is closed, the returned Distributor is sealed after the final MetaClose
+// event, so that its Readers can drain what remains.
+//
+// The returned Distributor only reports on this one; it does not generate MetaEvents about itself.
+//
+// Events is thread-safe.
+func (d *Distributor[T]) Events() *Distributor[MetaEvent] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.meta == nil {
+		d.meta = New[MetaEvent]()
+		if d.closed {
+			d.meta.Seal()
+		}
+	}
+	return d.meta
+}
+
+// emitMeta submits e to the Distributor returned by Events(), if it has been requested.
+//
+// d.mu must be held.
+func (d *Distributor[T]) emitMeta(e MetaEvent) {
+	if d.meta == nil {
+		return
+	}
+
+	e.BufferSize = len(d.buf)
+
+	// Lock ordering is always d.mu, then d.meta.mu, so this can't deadlock.
+	d.meta.mu.Lock()
+	d.meta.submit(e, nil, nil, nil, nil, false, false)
+	// Callbacks registered on the returned Distributor may call methods on this one, so they're
+	// only run once d.mu is released.
+	if len(d.meta.mu.queued) != 0 {
+		d.mu.queue(d.meta.mu.flush)
+	}
+	d.meta.mu.unlockQuietly()
+}
+
+// readerInfo returns the current ReaderInfo for the Reader, which must be registered.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) readerInfo() ReaderInfo {
+	tail := r.d.basePosition + int64(len(r.d.buf))
+	return ReaderInfo{ID: r.id, Lag: int(tail - r.position)}
+}
+
+// dropped reports that value was dropped for the reason, to OnDrop callbacks and Events(). seq is
+// the sequence number of the event, or -1 if it didn't get one.
+//
+// d.mu must be held.
+func (d *Distributor[T]) dropped(value T, seq int64, reason DropReason) {
+	runCallbacks2(&d.mu, d.onDrop, value, reason)
+	d.stats.Dropped += 1
+	d.emitMeta(MetaEvent{Kind: MetaDrop, Seq: seq, DropReason: reason})
+}
@@ -0,0 +1,91 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func drainMeta(r *eventdistributor.Reader[eventdistributor.MetaEvent]) []eventdistributor.MetaEvent {
+	var events []eventdistributor.MetaEvent
+	for {
+		e, err := r.TryConsume()
+		if err != nil {
+			return events
+		}
+		events = append(events, e)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.OnDrop(func(MyEvent, eventdistributor.DropReason) {})
+	d := eventdistributor.New(options)
+
+	meta := d.Events()
+	require.Same(t, meta, d.Events())
+	mr := meta.Subscribe()
+	defer mr.Unsubscribe()
+
+	r := d.Subscribe()
+	d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 1})
+	require.Equal(t, 1, d.FilterInPlace(func(e MyEvent) bool { return e.id == 1 }))
+	r.Unsubscribe()
+	d.Seal()
+	d.Submit(MyEvent{id: 2})
+	d.Close()
+
+	require.Equal(t, []eventdistributor.MetaEvent{
+		{Kind: eventdistributor.MetaSubscribe, Reader: eventdistributor.ReaderInfo{ID: r.ID(), Lag: 0}},
+		{
+			Kind:       eventdistributor.MetaDrop,
+			Seq:        1,
+			DropReason: eventdistributor.DropReasonRemoved,
+			BufferSize: 2,
+		},
+		{
+			Kind:       eventdistributor.MetaUnsubscribe,
+			Reader:     eventdistributor.ReaderInfo{ID: r.ID(), Lag: 1},
+			BufferSize: 1,
+		},
+		{Kind: eventdistributor.MetaSeal},
+		{Kind: eventdistributor.MetaDrop, Seq: -1, DropReason: eventdistributor.DropReasonSealed},
+		{Kind: eventdistributor.MetaClose},
+	}, drainMeta(&mr))
+
+	t.Log("the meta-distributor is sealed after the Distributor is closed")
+	nowReady(t, meta.Submit(eventdistributor.MetaEvent{}))
+	nowNotReady(t, mr.WaitChan())
+}
+
+func TestEventsProducerBlocked(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithMaxReaderLag(1)
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	mr := d.Events().Subscribe()
+	defer mr.Unsubscribe()
+
+	d.Submit(MyEvent{id: 0})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.Submit(MyEvent{id: 1})
+	}()
+
+	<-mr.WaitChan()
+	require.Equal(t, eventdistributor.MetaEvent{
+		Kind:       eventdistributor.MetaProducerBlocked,
+		Reader:     eventdistributor.ReaderInfo{ID: r.ID(), Lag: 1},
+		BufferSize: 1,
+	}, mr.Consume())
+
+	require.Equal(t, 0, r.Consume().id)
+	<-done
+}
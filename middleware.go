@@ -0,0 +1,78 @@
+package eventdistributor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrHandlerPanicked is wrapped by the errors returned by handlers wrapped with Recover(), when
+// they panic
+var ErrHandlerPanicked = errors.New("handler panicked")
+
+// Middleware wraps a HandlerFunc, returning a new HandlerFunc that typically calls next - for
+// example, to log or measure each call. It may also return without calling next, to skip the
+// event.
+//
+// Middleware is added to handlers with (*HandlerOptions[T]).WithMiddleware().
+type Middleware[T any] func(next HandlerFunc[T]) HandlerFunc[T]
+
+// WithMiddleware adds middleware to the handler, in order: the first Middleware is the outermost,
+// so it's called first, and calls the next one, and so on, until the last one calls the handler's
+// own function. Middleware added by later calls to WithMiddleware is inside the Middleware added
+// by earlier ones.
+//
+// Middleware wraps each attempt at handling an event, so with (*HandlerOptions[T]).WithRetry(),
+// it's called again for each retry, and any error it returns is treated like an error from the
+// handler.
+func (o *HandlerOptions[T]) WithMiddleware(middleware ...Middleware[T]) {
+	o.modify = append(o.modify, func(h *managedHandler[T]) {
+		h.middleware = append(h.middleware, middleware...)
+	})
+}
+
+// wrap returns fn wrapped by the middleware, with the first Middleware outermost
+func wrap[T any](fn HandlerFunc[T], middleware []Middleware[T]) HandlerFunc[T] {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		fn = middleware[i](fn)
+	}
+	return fn
+}
+
+// Recover returns Middleware that recovers panics from the rest of the handler, turning them into
+// errors that wrap ErrHandlerPanicked. Combined with (*HandlerOptions[T]).WithRetry(), panicking
+// events are retried, and reported to (*HandlerOptions[T]).OnFailed() if they never succeed,
+// instead of being handled as described by AddHandler().
+func Recover[T any]() Middleware[T] {
+	return func(next HandlerFunc[T]) HandlerFunc[T] {
+		return func(ctx context.Context, item T) (err error) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					err = fmt.Errorf("%w: %v", ErrHandlerPanicked, recovered)
+				}
+			}()
+			return next(ctx, item)
+		}
+	}
+}
+
+// MeasureLatency returns Middleware that calls report after each call to the rest of the handler,
+// with how long it took, according to clock, and the error it returned. If clock is nil, the time
+// package is used directly.
+func MeasureLatency[T any](
+	clock Clock,
+	report func(item T, took time.Duration, err error),
+) Middleware[T] {
+	if clock == nil {
+		clock = realClock{}
+	}
+	return func(next HandlerFunc[T]) HandlerFunc[T] {
+		return func(ctx context.Context, item T) error {
+			start := clock.Now()
+			err := next(ctx, item)
+			report(item, clock.Now().Sub(start), err)
+			return err
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(call string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, call)
+	}
+	named := func(name string) eventdistributor.Middleware[MyEvent] {
+		return func(next eventdistributor.HandlerFunc[MyEvent]) eventdistributor.HandlerFunc[MyEvent] {
+			return func(ctx context.Context, e MyEvent) error {
+				record(fmt.Sprintf("%s %d", name, e.id))
+				return next(ctx, e)
+			}
+		}
+	}
+	skipOdd := func(next eventdistributor.HandlerFunc[MyEvent]) eventdistributor.HandlerFunc[MyEvent] {
+		return func(ctx context.Context, e MyEvent) error {
+			if e.id%2 == 1 {
+				return nil
+			}
+			return next(ctx, e)
+		}
+	}
+
+	handled := make(chan int)
+	stop := d.AddHandler(func(e MyEvent) {
+		record(fmt.Sprintf("handler %d", e.id))
+		handled <- e.id
+	},
+		eventdistributor.WithMiddleware(named("first"), named("second")),
+		eventdistributor.WithMiddleware(skipOdd, named("third")),
+	)
+	defer stop()
+
+	t.Log("middleware is called in the order it was added, and can skip events")
+	for i := 0; i < 3; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+	require.Equal(t, 0, <-handled)
+	require.Equal(t, 2, <-handled)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{
+		"first 0", "second 0", "third 0", "handler 0",
+		"first 1", "second 1",
+		"first 2", "second 2", "third 2", "handler 2",
+	}, calls)
+}
+
+func TestRecover(t *testing.T) {
+	clock := newFakeClock()
+	d := eventdistributor.New(eventdistributor.WithClock[MyEvent](clock))
+
+	var latencies []time.Duration
+	var errs []error
+	failed := make(chan error)
+	handled := make(chan int)
+	stop := d.AddHandler(func(e MyEvent) {
+		clock.Skip(time.Duration(e.id) * time.Second)
+		if e.id == 0 {
+			panic("handler panicked")
+		}
+		handled <- e.id
+	},
+		eventdistributor.WithMiddleware(
+			eventdistributor.MeasureLatency(clock, func(_ MyEvent, took time.Duration, err error) {
+				latencies = append(latencies, took)
+				errs = append(errs, err)
+			}),
+			eventdistributor.Recover[MyEvent](),
+		),
+		eventdistributor.WithOnFailed(func(_ MyEvent, err error) { failed <- err }),
+	)
+
+	t.Log("with Recover, panics are reported as errors, and the handler continues")
+	d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 2})
+	err := <-failed
+	require.ErrorIs(t, err, eventdistributor.ErrHandlerPanicked)
+	require.EqualError(t, err, "handler panicked: handler panicked")
+	require.Equal(t, 2, <-handled)
+
+	t.Log("MeasureLatency reports how long each call took, with its error")
+	require.NotPanics(t, stop)
+	require.Equal(t, []time.Duration{0, 2 * time.Second}, latencies)
+	require.Len(t, errs, 2)
+	require.True(t, errors.Is(errs[0], eventdistributor.ErrHandlerPanicked))
+	require.NoError(t, errs[1])
+}
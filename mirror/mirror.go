@@ -0,0 +1,411 @@
+// Package mirror serves the events of a Distributor over network connections, so that they can be
+// mirrored into a Distributor in another process.
+//
+// The protocol is deliberately simple. After connecting, the client sends the sequence number of
+// the first event it wants, as a big-endian int64, or -1 to start at the live edge, followed by the
+// epoch of the server it last received events from, as a big-endian uint64, or 0 if there wasn't
+// one. The server replies with its own epoch, and a single byte that is 1 if it is resuming at the
+// requested event, or 0 if it is starting at the live edge instead. It then sends each event as a
+// frame: its sequence number (big-endian int64), the length of the encoded event (big-endian
+// uint32), and the encoded event itself.
+//
+// The epoch is chosen randomly each time Serve is called, so that a client can tell when the
+// server has restarted, and the sequence numbers it has seen no longer refer to the same events.
+package mirror
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+const (
+	// maxPending is the maximum number of events waiting to be written to a single connection. A
+	// connection that falls further behind is closed, so that it can't hold events in the server's
+	// buffer.
+	maxPending = 1024
+	// maxFrameSize is the maximum size of a single encoded event
+	maxFrameSize = 1 << 24
+
+	// minBackoff and maxBackoff bound the delay between a Mirror's connection attempts
+	minBackoff = 50 * time.Millisecond
+	maxBackoff = 5 * time.Second
+)
+
+const (
+	// requestSize is the size of the client's request: the sequence number to resume at, and the
+	// epoch of the server it was received from
+	requestSize = 16
+	// replySize is the size of the server's reply: its epoch, and whether it's resuming
+	replySize = 9
+	// frameHeaderSize is the size of the sequence number and length that precede each encoded event
+	frameHeaderSize = 12
+)
+
+// Serve accepts connections from lis, streaming the events submitted to d to each one, encoded
+// with encode, until ctx is canceled. Serve closes lis before returning.
+//
+// Each connection has its own Reader, which starts at the live edge - or, if the client asks to
+// resume, at the requested event, if it is still buffered. Requests to resume are rejected if they
+// were for events from a different call to Serve, or for events that haven't been submitted yet, in
+// which case the Reader starts at the live edge, and the client is told so. Events are taken from
+// the Reader as soon as they're available, and queued for the connection. If too many are waiting
+// to be written because the connection is slow or dead, it is closed, so that it doesn't hold
+// events in d's buffer; the client can reconnect and resume from where it left off.
+//
+// Events that can't be encoded, or are larger than 16 MiB once encoded, are skipped. If d is
+// closed, each connection is closed once its queued events have been written.
+//
+// Serve returns ctx.Err() once ctx is canceled, or the error from lis.Accept() if it fails for any
+// other reason. Either way, every connection is closed before it returns.
+func Serve[T any](
+	ctx context.Context,
+	lis net.Listener,
+	d *eventdistributor.Distributor[T],
+	encode func(T) ([]byte, error),
+) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	epoch := newEpoch()
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-stop:
+		}
+		lis.Close()
+	}()
+
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serveConn(ctx, stop, conn, epoch, d, encode)
+		}()
+	}
+}
+
+// serveConn implements Serve for a single connection, returning once it is closed.
+func serveConn[T any](
+	ctx context.Context,
+	stop <-chan struct{},
+	conn net.Conn,
+	epoch uint64,
+	d *eventdistributor.Distributor[T],
+	encode func(T) ([]byte, error),
+) {
+	defer conn.Close()
+
+	// Closing the connection unblocks any pending reads or writes.
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-stop:
+		case <-finished:
+		}
+		conn.Close()
+	}()
+
+	var request [requestSize]byte
+	if _, err := io.ReadFull(conn, request[:]); err != nil {
+		return
+	}
+	resume := int64(binary.BigEndian.Uint64(request[0:8]))
+	clientEpoch := binary.BigEndian.Uint64(request[8:16])
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	// Sequence numbers from another server mean nothing here, even if they happen to be valid.
+	resumed := resume >= 0 && clientEpoch == epoch && resume <= d.NextSeq()
+	if !resumed {
+		resume = -1
+	}
+	var reply [replySize]byte
+	binary.BigEndian.PutUint64(reply[0:8], epoch)
+	if resumed {
+		reply[8] = 1
+	}
+	if _, err := conn.Write(reply[:]); err != nil {
+		return
+	}
+
+	// Events submitted before the Reader subscribed are only available while they're still
+	// buffered. Later events are delivered by the Reader as well, so lastSent is used to skip them.
+	var history []eventdistributor.BufferedEvent[T]
+	if resume >= 0 {
+		d.ForEachBuffered(func(e eventdistributor.BufferedEvent[T]) bool {
+			if e.Seq >= resume {
+				history = append(history, e)
+			}
+			return true
+		})
+	}
+
+	queue := make(chan []byte, len(history)+maxPending)
+	lastSent := int64(-1)
+	for _, e := range history {
+		if frame, ok := encodeFrame(e.Seq, e.Value, encode); ok {
+			queue <- frame
+		}
+		lastSent = e.Seq
+	}
+
+	quit := make(chan struct{})
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+
+		w := bufio.NewWriter(conn)
+		for {
+			var frame []byte
+			var ok bool
+			select {
+			case <-quit:
+				return
+			case frame, ok = <-queue:
+			}
+			if !ok {
+				return
+			}
+
+			if _, err := w.Write(frame); err != nil {
+				return
+			}
+			// Only flush once there's nothing else ready to write, to batch small events.
+			if len(queue) == 0 {
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+		}
+	}()
+	defer func() {
+		close(quit)
+		conn.Close()
+		<-writerDone
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-writerDone:
+			return
+		case <-r.WaitChan():
+		}
+
+		value, seq, err := r.TryConsumeSeq()
+		if err == eventdistributor.ErrNoEvent {
+			continue
+		} else if err != nil {
+			// The Distributor was closed. Finish writing what's queued before disconnecting.
+			close(queue)
+			<-writerDone
+			return
+		}
+
+		if seq <= lastSent {
+			continue
+		}
+		frame, ok := encodeFrame(seq, value, encode)
+		if !ok {
+			continue
+		}
+
+		select {
+		case queue <- frame:
+		default:
+			// The connection is too far behind.
+			return
+		}
+	}
+}
+
+// newEpoch returns a random, non-zero epoch for a call to Serve
+func newEpoch() uint64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// Without randomness, the time is still very unlikely to be repeated.
+		binary.BigEndian.PutUint64(b[:], uint64(time.Now().UnixNano()))
+	}
+	if epoch := binary.BigEndian.Uint64(b[:]); epoch != 0 {
+		return epoch
+	}
+	return 1
+}
+
+// encodeFrame returns the encoded frame for the event, or false if it should be skipped
+func encodeFrame[T any](seq int64, value T, encode func(T) ([]byte, error)) ([]byte, bool) {
+	payload, err := encode(value)
+	if err != nil || len(payload) > maxFrameSize {
+		return nil, false
+	}
+
+	frame := make([]byte, frameHeaderSize+len(payload))
+	binary.BigEndian.PutUint64(frame[0:8], uint64(seq))
+	binary.BigEndian.PutUint32(frame[8:12], uint32(len(payload)))
+	copy(frame[frameHeaderSize:], payload)
+	return frame, true
+}
+
+// Mirror creates a Distributor with the provided options, and submits to it every event streamed
+// from a server running Serve, decoded with decode, until ctx is canceled. Once ctx is canceled,
+// the returned Distributor is sealed, so that its Readers can drain what remains.
+//
+// Connections are made with dial. The first connection starts at the server's live edge. If the
+// connection fails, Mirror reconnects with exponential backoff, resuming after the last event it
+// received. Events are only resumed while the server still buffers them - i.e., while some other
+// Reader of the server's Distributor has not yet consumed them. Otherwise, the mirror misses
+// them, and continues with the oldest event that is still available. If the server has restarted
+// since the last event was received, or otherwise rejects the request to resume, the mirror
+// continues from the server's live edge instead.
+//
+// Events that can't be decoded are skipped.
+func Mirror[T any](
+	ctx context.Context,
+	dial func() (net.Conn, error),
+	decode func([]byte) (T, error),
+	options ...eventdistributor.Options[T],
+) *eventdistributor.Distributor[T] {
+	d := eventdistributor.New(options...)
+
+	go func() {
+		defer d.Seal()
+
+		next := int64(-1)
+		var epoch uint64
+		backoff := minBackoff
+		for ctx.Err() == nil {
+			if conn, err := dial(); err == nil {
+				var received bool
+				next, epoch, received = mirrorConn(ctx, conn, d, decode, next, epoch)
+				if received {
+					backoff = minBackoff
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+	}()
+
+	return d
+}
+
+// errFrameTooLarge is returned by readFrame if the server sends a frame that is too large
+var errFrameTooLarge = errors.New("frame too large")
+
+// mirrorConn implements Mirror for a single connection, requesting events starting from next, from
+// the server with the epoch, until the connection fails. It returns the sequence number of the
+// next event to request and the server's epoch, and whether any events were received.
+func mirrorConn[T any](
+	ctx context.Context,
+	conn net.Conn,
+	d *eventdistributor.Distributor[T],
+	decode func([]byte) (T, error),
+	next int64,
+	epoch uint64,
+) (int64, uint64, bool) {
+	defer conn.Close()
+
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-finished:
+		}
+		conn.Close()
+	}()
+
+	var request [requestSize]byte
+	binary.BigEndian.PutUint64(request[0:8], uint64(next))
+	binary.BigEndian.PutUint64(request[8:16], epoch)
+	if _, err := conn.Write(request[:]); err != nil {
+		return next, epoch, false
+	}
+
+	br := bufio.NewReader(conn)
+	var reply [replySize]byte
+	if _, err := io.ReadFull(br, reply[:]); err != nil {
+		return next, epoch, false
+	}
+	// If the server didn't resume, the events it sends are unrelated to the ones received before.
+	epoch = binary.BigEndian.Uint64(reply[0:8])
+	if reply[8] != 1 {
+		next = -1
+	}
+
+	received := false
+	for {
+		seq, payload, err := readFrame(br)
+		if err != nil {
+			return next, epoch, received
+		}
+		received = true
+
+		// The server never repeats events, but a misbehaving one shouldn't cause duplicates.
+		if next >= 0 && seq < next {
+			continue
+		}
+		next = seq + 1
+
+		value, err := decode(payload)
+		if err != nil {
+			continue
+		}
+		d.SubmitQuiet(value)
+	}
+}
+
+// readFrame reads a single frame written by Serve, returning the sequence number and encoded event
+func readFrame(r io.Reader) (int64, []byte, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	seq := int64(binary.BigEndian.Uint64(header[0:8]))
+	size := binary.BigEndian.Uint32(header[8:12])
+	if size > maxFrameSize {
+		return 0, nil, errFrameTooLarge
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return seq, payload, nil
+}
@@ -0,0 +1,191 @@
+package mirror_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+	"github.com/sharnoff/eventdistributor/mirror"
+)
+
+func encode(v int) ([]byte, error) {
+	return []byte(strconv.Itoa(v)), nil
+}
+
+func decode(b []byte) (int, error) {
+	return strconv.Atoi(string(b))
+}
+
+// receive consumes n events from the Reader, failing the test if they don't arrive in time
+func receive(t *testing.T, r *eventdistributor.Reader[int], n int) []int {
+	var values []int
+	for len(values) < n {
+		select {
+		case <-r.WaitChan():
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out after receiving %v", values)
+		}
+
+		v, err := r.TryConsume()
+		if errors.Is(err, eventdistributor.ErrNoEvent) {
+			continue
+		}
+		require.NoError(t, err)
+		values = append(values, v)
+	}
+	return values
+}
+
+// awaitSubscribe waits for the next Reader to subscribe to the Distributor whose Events() are
+// meta
+func awaitSubscribe(t *testing.T, meta *eventdistributor.Reader[eventdistributor.MetaEvent]) {
+	for {
+		select {
+		case <-meta.WaitChan():
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for subscription")
+		}
+
+		e, err := meta.TryConsume()
+		if errors.Is(err, eventdistributor.ErrNoEvent) {
+			continue
+		}
+		require.NoError(t, err)
+		if e.Kind == eventdistributor.MetaSubscribe {
+			return
+		}
+	}
+}
+
+func TestMirror(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := eventdistributor.New[int]()
+	meta := src.Events().Subscribe()
+	defer meta.Unsubscribe()
+
+	// Keep every event buffered, so that the mirror can resume.
+	hold := src.Subscribe()
+	defer hold.Unsubscribe()
+	awaitSubscribe(t, &meta)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	served := make(chan error)
+	go func() {
+		served <- mirror.Serve(ctx, lis, src, encode)
+	}()
+
+	var mu sync.Mutex
+	var conns []net.Conn
+	dial := func() (net.Conn, error) {
+		conn, err := net.Dial("tcp", lis.Addr().String())
+		if err == nil {
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+		return conn, err
+	}
+
+	m := mirror.Mirror(ctx, dial, decode)
+	r := m.Subscribe()
+	defer r.Unsubscribe()
+
+	t.Log("events are mirrored from the live edge")
+	awaitSubscribe(t, &meta)
+	for i := 0; i < 5; i++ {
+		src.Submit(i)
+	}
+	require.Equal(t, []int{0, 1, 2, 3, 4}, receive(t, &r, 5))
+
+	t.Log("after reconnecting, the mirror resumes where it left off")
+	mu.Lock()
+	conns[0].Close()
+	mu.Unlock()
+	for i := 5; i < 10; i++ {
+		src.Submit(i)
+	}
+	require.Equal(t, []int{5, 6, 7, 8, 9}, receive(t, &r, 5))
+
+	mu.Lock()
+	require.Len(t, conns, 2)
+	mu.Unlock()
+
+	t.Log("canceling the context stops the server")
+	cancel()
+	select {
+	case err := <-served:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for Serve to return")
+	}
+}
+
+func TestMirrorServerRestart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+
+	// serve starts a server on lis for a new Distributor, returning it and a function that stops
+	// the server
+	serve := func(lis net.Listener) (*eventdistributor.Distributor[int], func()) {
+		src := eventdistributor.New[int]()
+		meta := src.Events().Subscribe()
+		t.Cleanup(func() { meta.Unsubscribe() })
+		hold := src.Subscribe()
+		t.Cleanup(func() { hold.Unsubscribe() })
+		awaitSubscribe(t, &meta)
+
+		serverCtx, stopServer := context.WithCancel(ctx)
+		served := make(chan struct{})
+		go func() {
+			defer close(served)
+			mirror.Serve(serverCtx, lis, src, encode)
+		}()
+		stop := func() {
+			stopServer()
+			<-served
+		}
+		t.Cleanup(stop)
+
+		// Wait for the mirror to connect, so that no events are missed.
+		awaitSubscribe(t, &meta)
+		return src, stop
+	}
+
+	dial := func() (net.Conn, error) {
+		return net.Dial("tcp", addr)
+	}
+	m := mirror.Mirror(ctx, dial, decode)
+	r := m.Subscribe()
+	defer r.Unsubscribe()
+
+	src, stop := serve(lis)
+	for i := 0; i < 5; i++ {
+		src.Submit(i)
+	}
+	require.Equal(t, []int{0, 1, 2, 3, 4}, receive(t, &r, 5))
+	stop()
+
+	t.Log("after the server restarts, the mirror starts again at its live edge")
+	lis, err = net.Listen("tcp", addr)
+	require.NoError(t, err)
+	src, _ = serve(lis)
+	for i := 100; i < 103; i++ {
+		src.Submit(i)
+	}
+	require.Equal(t, []int{100, 101, 102}, receive(t, &r, 3))
+}
@@ -0,0 +1,54 @@
+package eventdistributor_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sharnoff/eventdistributor/eventdistributortest"
+)
+
+func TestModel(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  eventdistributortest.ModelConfig
+	}{
+		{
+			name: "basic",
+			cfg:  eventdistributortest.ModelConfig{},
+		},
+		{
+			name: "single reader",
+			cfg:  eventdistributortest.ModelConfig{MaxReaders: 1},
+		},
+		{
+			name: "lossy readers",
+			cfg:  eventdistributortest.ModelConfig{LossyMaxAge: time.Second},
+		},
+		{
+			name: "filter, rewind, and seek",
+			cfg:  eventdistributortest.ModelConfig{Filter: true, Rewind: true, Seek: true, MaxReaders: 6},
+		},
+		{
+			name: "everything",
+			cfg: eventdistributortest.ModelConfig{
+				Steps:       5000,
+				LossyMaxAge: time.Second,
+				Filter:      true,
+				Rewind:      true,
+				Seek:        true,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				t.Run(fmt.Sprint(i), func(t *testing.T) {
+					eventdistributortest.CheckModel(t, c.cfg)
+				})
+			}
+		})
+	}
+}
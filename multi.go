@@ -0,0 +1,140 @@
+package eventdistributor
+
+import (
+	"sync"
+)
+
+// Multi is a set of Distributors, one for each topic, identified by a key of type K. Each topic's
+// Distributor is created when it is first used.
+//
+// The options for each topic are, in order: the options set by DefaultOptions(), the options
+// returned by the function set by TopicOptionsFunc(), and the options set for the topic by
+// SetTopicOptions(). Later options take precedence over earlier ones, where they conflict.
+//
+// The zero value of a Multi is valid, and has no options.
+type Multi[K comparable, T any] struct {
+	mu sync.Mutex
+
+	topics map[K]*Distributor[T]
+
+	defaults    []Options[T]
+	optionsFunc func(K) []Options[T]
+	overrides   map[K][]Options[T]
+
+	// matching is the subscriptions that cover multiple topics. See SubscribeMatching().
+	matching []*matchingSub[K, T]
+}
+
+// DefaultOptions sets the options for every topic created afterwards, replacing any previously set
+// defaults. Topics that already exist are unaffected.
+//
+// DefaultOptions is thread-safe.
+func (m *Multi[K, T]) DefaultOptions(options ...Options[T]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.defaults = options
+}
+
+// TopicOptionsFunc sets a function that provides additional options for each topic created
+// afterwards, so that topics can be configured by rules instead of individually. It replaces any
+// previously set function, and if f is nil, the function is removed. Topics that already exist are
+// unaffected.
+//
+// f is called while the Multi's lock is held, so it must not call any methods on the Multi.
+//
+// TopicOptionsFunc is thread-safe.
+func (m *Multi[K, T]) TopicOptionsFunc(f func(K) []Options[T]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.optionsFunc = f
+}
+
+// SetTopicOptions adds options for the topic, taking precedence over the defaults and
+// TopicOptionsFunc(). If the topic already exists, the options are applied to it immediately, and
+// only affect what happens afterwards - for example, new callbacks are not called for events that
+// were already submitted. Otherwise, they are applied when the topic is created.
+//
+// SetTopicOptions returns ErrClosed if the topic exists and its Distributor has been closed.
+//
+// SetTopicOptions is thread-safe.
+func (m *Multi[K, T]) SetTopicOptions(key K, options ...Options[T]) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if d, ok := m.topics[key]; ok {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		if d.closed {
+			return ErrClosed
+		}
+		d.configure(options)
+	}
+
+	if m.overrides == nil {
+		m.overrides = make(map[K][]Options[T])
+	}
+	m.overrides[key] = append(m.overrides[key], options...)
+	return nil
+}
+
+// Topic returns the Distributor for the topic, creating it if it doesn't already exist.
+//
+// Topic is thread-safe.
+func (m *Multi[K, T]) Topic(key K) *Distributor[T] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if d, ok := m.topics[key]; ok {
+		return d
+	}
+
+	options := append([]Options[T]{}, m.defaults...)
+	if m.optionsFunc != nil {
+		options = append(options, m.optionsFunc(key)...)
+	}
+	options = append(options, m.overrides[key]...)
+
+	d := New(options...)
+	if m.topics == nil {
+		m.topics = make(map[K]*Distributor[T])
+	}
+	m.topics[key] = d
+
+	// Subscriptions that cover the new topic start receiving from it immediately. Subscriptions
+	// that were unsubscribed are removed along the way.
+	kept := m.matching[:0]
+	for _, s := range m.matching {
+		if s.stopped() {
+			continue
+		}
+		kept = append(kept, s)
+		if s.match(key) {
+			s.attach(key, d)
+		}
+	}
+	for i := len(kept); i < len(m.matching); i++ {
+		m.matching[i] = nil
+	}
+	m.matching = kept
+
+	return d
+}
+
+// Submit submits the value to the topic's Distributor, creating it if it doesn't already exist.
+// See (*Distributor[T]).Submit().
+//
+// Submit is thread-safe.
+func (m *Multi[K, T]) Submit(key K, value T) <-chan struct{} {
+	return m.Topic(key).Submit(value)
+}
+
+// Subscribe creates a new Reader for the topic, creating its Distributor if it doesn't already
+// exist. See (*Distributor[T]).Subscribe().
+//
+// Subscribe is thread-safe.
+func (m *Multi[K, T]) Subscribe(key K) Reader[T] {
+	return m.Topic(key).Subscribe()
+}
@@ -0,0 +1,79 @@
+package eventdistributor_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestMultiTopicOptions(t *testing.T) {
+	var m eventdistributor.Multi[string, MyEvent]
+
+	var defaults eventdistributor.Options[MyEvent]
+	defaults.WithMaxReaderLag(2)
+	m.DefaultOptions(defaults)
+
+	limiter := newTokenLimiter()
+	limiter.give(2)
+	var dropped []int
+	m.TopicOptionsFunc(func(topic string) []eventdistributor.Options[MyEvent] {
+		if !strings.HasPrefix(topic, "metrics.") {
+			return nil
+		}
+
+		var options eventdistributor.Options[MyEvent]
+		options.WithSubmitLimiter(limiter, eventdistributor.ThrottleDrop)
+		options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+			require.Equal(t, eventdistributor.DropReasonThrottled, reason)
+			dropped = append(dropped, e.id)
+		})
+		return []eventdistributor.Options[MyEvent]{options}
+	})
+
+	audit := m.Subscribe("audit")
+	defer audit.Unsubscribe()
+	metrics := m.Subscribe("metrics.cpu")
+	defer metrics.Unsubscribe()
+
+	t.Log("under the same load, the audit topic rejects events while the metrics topic drops them")
+	var auditErrs int
+	for i := 0; i < 5; i++ {
+		if _, err := m.Topic("audit").SubmitChecked(MyEvent{id: i}); err != nil {
+			require.ErrorIs(t, err, eventdistributor.ErrReaderLagging)
+			auditErrs += 1
+		}
+		m.Submit("metrics.cpu", MyEvent{id: i})
+	}
+	require.Equal(t, 3, auditErrs)
+	require.Equal(t, []int{0, 1}, drainIDs(&audit))
+	require.Equal(t, []int{0, 1}, drainIDs(&metrics))
+	require.Equal(t, []int{2, 3, 4}, dropped)
+
+	t.Log("options set for an existing topic apply from then on")
+	var submitted []int
+	var extra eventdistributor.Options[MyEvent]
+	extra.OnSubmit(func(e MyEvent) {
+		submitted = append(submitted, e.id)
+	})
+	require.NoError(t, m.SetTopicOptions("audit", extra))
+	m.Submit("audit", MyEvent{id: 5})
+	require.Equal(t, []int{5}, submitted)
+	require.Equal(t, []int{5}, drainIDs(&audit))
+
+	t.Log("options set before a topic exists apply when it is created")
+	var other []int
+	var early eventdistributor.Options[MyEvent]
+	early.OnSubmit(func(e MyEvent) {
+		other = append(other, e.id)
+	})
+	require.NoError(t, m.SetTopicOptions("other", early))
+	m.Submit("other", MyEvent{id: 6})
+	require.Equal(t, []int{6}, other)
+	require.Equal(t, []int{5}, submitted)
+
+	m.Topic("other").Close()
+	require.ErrorIs(t, m.SetTopicOptions("other", early), eventdistributor.ErrClosed)
+}
@@ -0,0 +1,35 @@
+package eventdistributor
+
+import (
+	"context"
+)
+
+// Next blocks until there is an event available for the Reader and consumes it, or until ctx is
+// canceled, returning ctx.Err(). It is the loop that would otherwise be written by hand around
+// WaitChan() and TryConsume().
+//
+// If ctx is already canceled, Next returns ctx.Err() without consuming anything. Like TryConsume,
+// Next returns ErrClosed if the Distributor is closed, and ErrExpired if the Reader expires.
+//
+// Next is thread-safe.
+func (r *Reader[T]) Next(ctx context.Context) (T, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			var zero T
+			return zero, err
+		}
+
+		select {
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		case <-r.WaitChan():
+		}
+
+		// Skipped or expiring events may mean that there's nothing to consume after all.
+		value, err := r.TryConsume()
+		if err != ErrNoEvent {
+			return value, err
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestNext(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	d.Submit(MyEvent{id: 1})
+	e, err := r.Next(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, e.id)
+
+	t.Log("Next waits for an event")
+	go func() {
+		time.Sleep(time.Millisecond)
+		d.Submit(MyEvent{id: 2})
+	}()
+	e, err = r.Next(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, e.id)
+
+	t.Log("Next doesn't consume anything once ctx is done")
+	d.Submit(MyEvent{id: 3})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = r.Next(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, []int{3}, drainIDs(&r))
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = r.Next(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	t.Log("Next returns ErrClosed once the Distributor is closed")
+	d.Close()
+	_, err = r.Next(context.Background())
+	require.ErrorIs(t, err, eventdistributor.ErrClosed)
+}
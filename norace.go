@@ -0,0 +1,7 @@
+//go:build !race
+
+package eventdistributor
+
+// raceEnabled is true in builds with the race detector, which check for misuse that is too costly
+// to check for otherwise. See WithSingleProducer.
+const raceEnabled = false
@@ -0,0 +1,113 @@
+package eventdistributor
+
+import (
+	"context"
+)
+
+// Observe registers a best-effort tap on the Distributor, returning a channel that receives every
+// event submitted from now on, for as long as ctx is not done.
+//
+// Unlike a Reader, an observer never holds events in the buffer and never slows down producers:
+// each event is offered to the channel with a non-blocking send, and if the channel's buffer is
+// full, the event is missed instead. The number of missed events is available from
+// ObserverMissed().
+//
+// Observers are not Readers. They don't count as subscribers, so an event submitted while there
+// are no Readers is still immediately discarded - but it is delivered to observers first. Events
+// dropped because the Distributor is sealed, or because of the submit limiter, are not delivered.
+//
+// The channel is closed once ctx is done, or when the Distributor is closed.
+//
+// Observe panics if buffer is negative.
+//
+// Observe is thread-safe.
+func (d *Distributor[T]) Observe(ctx context.Context, buffer int) <-chan T {
+	if buffer < 0 {
+		panic("eventdistributor: Observe requires buffer >= 0")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	o := &observer[T]{
+		ch:      make(chan T, buffer),
+		missed:  0,
+		removed: make(chan struct{}),
+	}
+
+	if d.closed {
+		close(o.ch)
+		return o.ch
+	}
+
+	d.observers = append(d.observers, o)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-o.removed:
+			return
+		}
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		for i, other := range d.observers {
+			if other == o {
+				d.observers = append(d.observers[:i], d.observers[i+1:]...)
+				o.remove()
+				break
+			}
+		}
+	}()
+
+	return o.ch
+}
+
+// ObserverMissed returns the number of events that the observer with the channel ch has missed
+// because its buffer was full. See Observe().
+//
+// If ch is not the channel of an active observer, ObserverMissed returns zero.
+//
+// ObserverMissed is thread-safe.
+func (d *Distributor[T]) ObserverMissed(ch <-chan T) int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, o := range d.observers {
+		if (<-chan T)(o.ch) == ch {
+			return o.missed
+		}
+	}
+	return 0
+}
+
+type observer[T any] struct {
+	ch     chan T
+	missed int64
+	// removed is closed when the observer is removed, so that the goroutine waiting for its
+	// context can exit
+	removed chan struct{}
+}
+
+// remove closes the observer's channels. The observer must already have been removed from
+// d.observers.
+//
+// d.mu must be held.
+func (o *observer[T]) remove() {
+	close(o.ch)
+	close(o.removed)
+}
+
+// notifyObservers offers the value to every observer, without blocking.
+//
+// d.mu must be held.
+func (d *Distributor[T]) notifyObservers(value T) {
+	for _, o := range d.observers {
+		select {
+		case o.ch <- value:
+		default:
+			o.missed += 1
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestObserve(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := d.Observe(ctx, 2)
+
+	t.Log("observers receive events even when there are no Readers, without holding them")
+	nowReady(t, d.Submit(MyEvent{id: 1}))
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	allConsumed := d.Submit(MyEvent{id: 2})
+
+	t.Log("a full observer misses events instead of blocking")
+	d.Submit(MyEvent{id: 3})
+	require.Equal(t, int64(1), d.ObserverMissed(ch))
+	require.Equal(t, []int{2, 3}, drainIDs(&r))
+
+	require.Equal(t, MyEvent{id: 1}, <-ch)
+	require.Equal(t, MyEvent{id: 2}, <-ch)
+	nowReady(t, allConsumed)
+
+	t.Log("the channel is closed when the context is canceled")
+	cancel()
+	_, ok := <-ch
+	require.False(t, ok)
+	require.Equal(t, int64(0), d.ObserverMissed(ch))
+	d.Submit(MyEvent{id: 4})
+}
+
+func TestObserveClose(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.OnDrop(func(MyEvent, eventdistributor.DropReason) {})
+	d := eventdistributor.New(options)
+
+	ch := d.Observe(context.Background(), 1)
+	d.Seal()
+	d.Submit(MyEvent{id: 1})
+	d.Close()
+
+	t.Log("sealed events are not observed, and Close closes the channel")
+	_, ok := <-ch
+	require.False(t, ok)
+
+	_, ok = <-d.Observe(context.Background(), 1)
+	require.False(t, ok)
+}
@@ -0,0 +1,21 @@
+package eventdistributor
+
+// SubscribeFromOldest creates a new Reader that starts at the oldest buffered event, receiving
+// every event that is still buffered for other Readers, followed by future events. If there are no
+// buffered events, it's the same as Subscribe().
+//
+// The buffered events are treated as a replay. See ReplayDone().
+//
+// SubscribeFromOldest is thread-safe.
+func (d *Distributor[T]) SubscribeFromOldest() Reader[T] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	r := d.subscribe()
+	// The buffer of a closed Distributor is always empty.
+	if len(d.buf) != 0 {
+		r.firstSeq = d.buf[0].seq
+		r.moveTo(d.basePosition)
+	}
+	return r
+}
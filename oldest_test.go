@@ -0,0 +1,48 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSubscribeFromOldest(t *testing.T) {
+	var fullyConsumed []int
+	var options eventdistributor.Options[MyEvent]
+	options.OnFullyConsumed(func(e MyEvent) { fullyConsumed = append(fullyConsumed, e.id) })
+	d := eventdistributor.New(options)
+
+	t.Log("with an empty buffer, the Reader starts at the live edge")
+	r1 := d.SubscribeFromOldest()
+	defer r1.Unsubscribe()
+	nowReady(t, r1.ReplayDone())
+	notReady(t, r1)
+
+	d.Submit(MyEvent{id: 1})
+	d.Submit(MyEvent{id: 2})
+	d.Submit(MyEvent{id: 3})
+
+	t.Log("the Reader receives every buffered event")
+	r2 := d.SubscribeFromOldest()
+	nowNotReady(t, r2.ReplayDone())
+	require.Equal(t, []int{1, 2, 3}, drainIDs(&r1))
+	require.Empty(t, fullyConsumed)
+	require.Equal(t, []int{1, 2, 3}, drainIDs(&r2))
+	nowReady(t, r2.ReplayDone())
+	require.Equal(t, []int{1, 2, 3}, fullyConsumed)
+
+	t.Log("unsubscribing partway through the buffer releases the Reader's events")
+	d.Submit(MyEvent{id: 4})
+	d.Submit(MyEvent{id: 5})
+	r3 := d.SubscribeFromOldest()
+	_, err := r3.TryConsume()
+	require.NoError(t, err)
+	r3.Unsubscribe()
+	require.Equal(t, []int{1, 2, 3}, fullyConsumed)
+	require.Equal(t, []int{4, 5}, drainIDs(&r2))
+	require.Equal(t, []int{4, 5}, drainIDs(&r1))
+	require.Equal(t, []int{1, 2, 3, 4, 5}, fullyConsumed)
+	require.Equal(t, 0, d.Stats().Bufsize)
+}
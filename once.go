@@ -0,0 +1,78 @@
+package eventdistributor
+
+import (
+	"context"
+)
+
+// SubscribeOnce creates a subscription that receives exactly one event and then unsubscribes
+// automatically.
+//
+// The first event submitted after SubscribeOnce returns is sent on the returned channel, which is
+// then closed. The event counts as consumed as soon as it is sent, and the subscription is
+// released at the same time, so later events are never kept in the buffer on its behalf. If ctx is
+// canceled or the returned cancel function is called before an event arrives, the subscription is
+// released and the channel is closed without sending anything. The same happens if the
+// Distributor is closed.
+//
+// The channel is buffered, so the subscription is always released, even if the caller stops
+// receiving from the channel. Calling cancel is only necessary to release the subscription before
+// an event arrives or ctx is canceled; it is safe to call more than once.
+//
+// SubscribeOnce is thread-safe.
+func (d *Distributor[T]) SubscribeOnce(ctx context.Context) (_ <-chan T, cancel func()) {
+	r := d.Subscribe()
+	ch := make(chan T, 1)
+	ctx, cancel = context.WithCancel(ctx)
+
+	go func() {
+		defer close(ch)
+
+		for {
+			select {
+			case <-ctx.Done():
+				r.Unsubscribe()
+				return
+			case <-r.WaitChan():
+			}
+
+			// Consume and unsubscribe under the same lock, so that no other event can be held on
+			// our behalf in between.
+			d.mu.Lock()
+			value, _, err := r.tryConsume()
+			if err == ErrNoEvent {
+				// Woken without an event - for example, because it was removed by Clear().
+				d.mu.Unlock()
+				continue
+			}
+			r.unsubscribe()
+			d.mu.Unlock()
+
+			if err == nil {
+				ch <- value
+			}
+			cancel()
+			return
+		}
+	}()
+
+	return ch, cancel
+}
+
+// Once waits for the next event submitted to the Distributor and returns it, or returns ctx.Err()
+// if ctx is canceled first, or ErrClosed if the Distributor is closed first. For more information,
+// see SubscribeOnce().
+//
+// Once is thread-safe.
+func (d *Distributor[T]) Once(ctx context.Context) (T, error) {
+	ch, cancel := d.SubscribeOnce(ctx)
+	defer cancel()
+
+	value, ok := <-ch
+	if !ok {
+		if err := ctx.Err(); err != nil {
+			return value, err
+		}
+		return value, ErrClosed
+	}
+	return value, nil
+}
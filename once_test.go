@@ -0,0 +1,146 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSubscribeOnce(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var consumed []MyEvent
+	options.OnFullyConsumed(func(e MyEvent) {
+		consumed = append(consumed, e)
+	})
+	d := eventdistributor.New(options)
+
+	ch, cancel := d.SubscribeOnce(context.Background())
+	defer cancel()
+
+	t.Log("only the first of two nearly simultaneous events is delivered")
+	s1 := d.Submit(MyEvent{id: 1})
+	s2 := d.Submit(MyEvent{id: 2})
+
+	e, ok := <-ch
+	require.True(t, ok)
+	require.Equal(t, 1, e.id)
+	_, ok = <-ch
+	require.False(t, ok)
+
+	t.Log("the delivered event is consumed, and the second is not kept")
+	nowReady(t, s1)
+	nowReady(t, s2)
+	require.Equal(t, []MyEvent{{id: 1}, {id: 2}}, consumed)
+	nowReady(t, d.Submit(MyEvent{id: 3}))
+}
+
+func TestSubscribeOnceCanceled(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	ch, cancel := d.SubscribeOnce(ctx)
+	defer cancel()
+
+	cancelCtx()
+	_, ok := <-ch
+	require.False(t, ok)
+
+	t.Log("after cancellation, the subscription is released")
+	nowReady(t, d.Submit(MyEvent{id: 1}))
+}
+
+func TestSubscribeOnceAbandoned(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	_, cancel := d.SubscribeOnce(context.Background())
+	defer cancel()
+
+	t.Log("the subscription is released even if nothing receives from the channel")
+	s1 := d.Submit(MyEvent{id: 1})
+	select {
+	case <-s1:
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for event to be consumed")
+	}
+	nowReady(t, d.Submit(MyEvent{id: 2}))
+}
+
+func TestOnce(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	type result struct {
+		e   MyEvent
+		err error
+	}
+	results := make(chan result)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		e, err := d.Once(ctx)
+		results <- result{e: e, err: err}
+	}()
+
+	// There's no way to tell when Once has subscribed, so keep submitting until it returns.
+	var res result
+	for received := false; !received; {
+		d.Submit(MyEvent{id: 1})
+		select {
+		case res = <-results:
+			received = true
+		case <-time.After(time.Millisecond):
+		}
+	}
+	require.NoError(t, res.err)
+	require.Equal(t, 1, res.e.id)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := d.Once(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	d.Close()
+	_, err = d.Once(context.Background())
+	require.ErrorIs(t, err, eventdistributor.ErrClosed)
+}
+
+func TestSubscribeOnceSpuriousWakeup(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	waiting := make(chan struct{}, 1)
+	options.OnWaitStart(func(uint64) {
+		select {
+		case waiting <- struct{}{}:
+		default:
+		}
+	})
+	d := eventdistributor.New(options)
+
+	holder := d.Subscribe()
+	defer holder.Unsubscribe()
+	other := d.Subscribe()
+	defer other.Unsubscribe()
+	d.Submit(MyEvent{id: 0})
+	require.Equal(t, 0, other.Consume().id)
+
+	ch, cancel := d.SubscribeOnce(context.Background())
+	defer cancel()
+	<-waiting
+
+	t.Log("waking every waiting Reader doesn't end the subscription without an event")
+	n, err := other.Rewind(1)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	select {
+	case _, ok := <-ch:
+		t.Fatalf("received from channel before any event (ok = %v)", ok)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	d.Submit(MyEvent{id: 1})
+	e, ok := <-ch
+	require.True(t, ok)
+	require.Equal(t, 1, e.id)
+}
@@ -0,0 +1,343 @@
+package eventdistributor
+
+import (
+	"time"
+)
+
+// WithOnBufsizeChange returns new Options with (*Options[T]).OnBufsizeChange() applied. See
+// Options.
+func WithOnBufsizeChange[T any](callback func(size int)) Options[T] {
+	var o Options[T]
+	o.OnBufsizeChange(callback)
+	return o
+}
+
+// WithOnSubmit returns new Options with (*Options[T]).OnSubmit() applied. See Options.
+func WithOnSubmit[T any](callback func(item T)) Options[T] {
+	var o Options[T]
+	o.OnSubmit(callback)
+	return o
+}
+
+// WithOnFullyConsumed returns new Options with (*Options[T]).OnFullyConsumed() applied. See
+// Options.
+func WithOnFullyConsumed[T any](callback func(item T)) Options[T] {
+	var o Options[T]
+	o.OnFullyConsumed(callback)
+	return o
+}
+
+// WithOnConsume returns new Options with (*Options[T]).OnConsume() applied. See Options.
+func WithOnConsume[T any](callback func(item T, readerID uint64)) Options[T] {
+	var o Options[T]
+	o.OnConsume(callback)
+	return o
+}
+
+// WithOnConsumedWithLatency returns new Options with (*Options[T]).OnConsumedWithLatency()
+// applied. See Options.
+func WithOnConsumedWithLatency[T any](callback func(item T, inBuffer time.Duration)) Options[T] {
+	var o Options[T]
+	o.OnConsumedWithLatency(callback)
+	return o
+}
+
+// WithOnDrop returns new Options with (*Options[T]).OnDrop() applied. See Options.
+func WithOnDrop[T any](callback func(item T, reason DropReason)) Options[T] {
+	var o Options[T]
+	o.OnDrop(callback)
+	return o
+}
+
+// WithOnLate returns new Options with (*Options[T]).OnLate() applied. See Options.
+func WithOnLate[T any](callback func(item T)) Options[T] {
+	var o Options[T]
+	o.OnLate(callback)
+	return o
+}
+
+// WithOnReject returns new Options with (*Options[T]).OnReject() applied. See Options.
+func WithOnReject[T any](callback func(item T, reason error)) Options[T] {
+	var o Options[T]
+	o.OnReject(callback)
+	return o
+}
+
+// WithOnCancelled returns new Options with (*Options[T]).OnCancelled() applied. See Options.
+func WithOnCancelled[T any](callback func(item T)) Options[T] {
+	var o Options[T]
+	o.OnCancelled(callback)
+	return o
+}
+
+// WithOnDiscarded returns new Options with (*Options[T]).OnDiscarded() applied. See Options.
+func WithOnDiscarded[T any](callback func(item T)) Options[T] {
+	var o Options[T]
+	o.OnDiscarded(callback)
+	return o
+}
+
+// WithOnFirstSubscriber returns new Options with (*Options[T]).OnFirstSubscriber() applied. See
+// Options.
+func WithOnFirstSubscriber[T any](callback func()) Options[T] {
+	var o Options[T]
+	o.OnFirstSubscriber(callback)
+	return o
+}
+
+// WithOnLastUnsubscribed returns new Options with (*Options[T]).OnLastUnsubscribed() applied. See
+// Options.
+func WithOnLastUnsubscribed[T any](callback func()) Options[T] {
+	var o Options[T]
+	o.OnLastUnsubscribed(callback)
+	return o
+}
+
+// WithOnReaderLag returns new Options with (*Options[T]).OnReaderLag() applied. See Options.
+func WithOnReaderLag[T any](threshold int, callback func(readerID uint64, lag int)) Options[T] {
+	var o Options[T]
+	o.OnReaderLag(threshold, callback)
+	return o
+}
+
+// WithOnWaitStart returns new Options with (*Options[T]).OnWaitStart() applied. See Options.
+func WithOnWaitStart[T any](callback func(readerID uint64)) Options[T] {
+	var o Options[T]
+	o.OnWaitStart(callback)
+	return o
+}
+
+// WithOnWaitEnd returns new Options with (*Options[T]).OnWaitEnd() applied. See Options.
+func WithOnWaitEnd[T any](callback func(readerID uint64, waited time.Duration)) Options[T] {
+	var o Options[T]
+	o.OnWaitEnd(callback)
+	return o
+}
+
+// WithOnIdle returns new Options with (*Options[T]).OnIdle() applied. See Options.
+func WithOnIdle[T any](after time.Duration, callback func()) Options[T] {
+	var o Options[T]
+	o.OnIdle(after, callback)
+	return o
+}
+
+// WithOnArchiveError returns new Options with (*Options[T]).OnArchiveError() applied. See Options.
+func WithOnArchiveError[T any](callback func(seq int64, err error)) Options[T] {
+	var o Options[T]
+	o.OnArchiveError(callback)
+	return o
+}
+
+// WithOnCallbackPanic returns new Options with (*Options[T]).OnCallbackPanic() applied. See
+// Options.
+func WithOnCallbackPanic[T any](handler func(recovered any)) Options[T] {
+	var o Options[T]
+	o.OnCallbackPanic(handler)
+	return o
+}
+
+// WithArchiver returns new Options with (*Options[T]).WithArchiver() applied. See Options.
+func WithArchiver[T any](a Archiver[T]) Options[T] {
+	var o Options[T]
+	o.WithArchiver(a)
+	return o
+}
+
+// WithCallerCapture returns new Options with (*Options[T]).WithCallerCapture() applied. See
+// Options.
+func WithCallerCapture[T any](depth int) Options[T] {
+	var o Options[T]
+	o.WithCallerCapture(depth)
+	return o
+}
+
+// WithClock returns new Options with (*Options[T]).WithClock() applied. See Options.
+func WithClock[T any](clock Clock) Options[T] {
+	var o Options[T]
+	o.WithClock(clock)
+	return o
+}
+
+// WithTimestamps returns new Options with (*Options[T]).WithTimestamps() applied. See Options.
+func WithTimestamps[T any]() Options[T] {
+	var o Options[T]
+	o.WithTimestamps()
+	return o
+}
+
+// WithCoalesce returns new Options with (*Options[T]).WithCoalesce() applied. See Options.
+func WithCoalesce[T any](merge func(older, newer T) (merged T, ok bool)) Options[T] {
+	var o Options[T]
+	o.WithCoalesce(merge)
+	return o
+}
+
+// WithDedupPending returns new Options with (*Options[T]).WithDedupPending() applied. See Options.
+func WithDedupPending[T any](eq func(a, b T) bool, wholeBuffer bool) Options[T] {
+	var o Options[T]
+	o.WithDedupPending(eq, wholeBuffer)
+	return o
+}
+
+// WithEvictOverLag returns new Options with (*Options[T]).WithEvictOverLag() applied. See Options.
+func WithEvictOverLag[T any](n int) Options[T] {
+	var o Options[T]
+	o.WithEvictOverLag(n)
+	return o
+}
+
+// WithGateTimeout returns new Options with (*Options[T]).WithGateTimeout() applied. See Options.
+func WithGateTimeout[T any](timeout time.Duration, policy GateTimeoutPolicy) Options[T] {
+	var o Options[T]
+	o.WithGateTimeout(timeout, policy)
+	return o
+}
+
+// WithHistory returns new Options with (*Options[T]).WithHistory() applied. See Options.
+func WithHistory[T any](n int) Options[T] {
+	var o Options[T]
+	o.WithHistory(n)
+	return o
+}
+
+// WithMaxReaderLag returns new Options with (*Options[T]).WithMaxReaderLag() applied. See Options.
+func WithMaxReaderLag[T any](k int) Options[T] {
+	var o Options[T]
+	o.WithMaxReaderLag(k)
+	return o
+}
+
+// WithSkipOverLag returns new Options with (*Options[T]).WithSkipOverLag() applied. See Options.
+func WithSkipOverLag[T any](n int) Options[T] {
+	var o Options[T]
+	o.WithSkipOverLag(n)
+	return o
+}
+
+// WithSubmitLimiter returns new Options with (*Options[T]).WithSubmitLimiter() applied. See
+// Options.
+func WithSubmitLimiter[T any](l Limiter, policy ThrottlePolicy) Options[T] {
+	var o Options[T]
+	o.WithSubmitLimiter(l, policy)
+	return o
+}
+
+// WithOrderCheck returns new Options with (*Options[T]).WithOrderCheck() applied. See Options.
+func WithOrderCheck[T any](less func(a, b T) bool, onViolation func(prev, next T)) Options[T] {
+	var o Options[T]
+	o.WithOrderCheck(less, onViolation)
+	return o
+}
+
+// WithMaxPins returns new Options with (*Options[T]).WithMaxPins() applied. See Options.
+func WithMaxPins[T any](max int) Options[T] {
+	var o Options[T]
+	o.WithMaxPins(max)
+	return o
+}
+
+// WithSingleProducer returns new Options with (*Options[T]).WithSingleProducer() applied. See
+// Options.
+func WithSingleProducer[T any]() Options[T] {
+	var o Options[T]
+	o.WithSingleProducer()
+	return o
+}
+
+// WithRetainLast returns new Options with (*Options[T]).WithRetainLast() applied. See Options.
+func WithRetainLast[T any]() Options[T] {
+	var o Options[T]
+	o.WithRetainLast()
+	return o
+}
+
+// WithSortWindow returns new Options with (*Options[T]).WithSortWindow() applied. See Options.
+func WithSortWindow[T any](window time.Duration, less func(a, b T) bool) Options[T] {
+	var o Options[T]
+	o.WithSortWindow(window, less)
+	return o
+}
+
+// WithEventTTL returns new Options with (*Options[T]).WithEventTTL() applied. See Options.
+func WithEventTTL[T any](ttl time.Duration) Options[T] {
+	var o Options[T]
+	o.WithEventTTL(ttl)
+	return o
+}
+
+// WithCallbackWatchdog returns new Options with (*Options[T]).WithCallbackWatchdog() applied. See
+// Options.
+func WithCallbackWatchdog[T any](
+	threshold time.Duration,
+	report func(kind string, took time.Duration, stack []byte),
+) Options[T] {
+	var o Options[T]
+	o.WithCallbackWatchdog(threshold, report)
+	return o
+}
+
+// WithLeakCheck returns new Options with (*Options[T]).WithLeakCheck() applied. See Options.
+func WithLeakCheck[T any](report func(readerID uint64, stack []byte)) Options[T] {
+	var o Options[T]
+	o.WithLeakCheck(report)
+	return o
+}
+
+// WithSubscribeCapture returns new Options with (*Options[T]).WithSubscribeCapture() applied. See
+// Options.
+func WithSubscribeCapture[T any](depth int) Options[T] {
+	var o Options[T]
+	o.WithSubscribeCapture(depth)
+	return o
+}
+
+// WithBufferCapacity returns new Options with (*Options[T]).WithBufferCapacity() applied. See
+// Options.
+func WithBufferCapacity[T any](n int) Options[T] {
+	var o Options[T]
+	o.WithBufferCapacity(n)
+	return o
+}
+
+// WithRetry returns new HandlerOptions with (*HandlerOptions[T]).WithRetry() applied. See
+// HandlerOptions.
+func WithRetry[T any](
+	initial time.Duration,
+	multiplier float64,
+	maxAttempts int,
+) HandlerOptions[T] {
+	var o HandlerOptions[T]
+	o.WithRetry(initial, multiplier, maxAttempts)
+	return o
+}
+
+// WithOnFailed returns new HandlerOptions with (*HandlerOptions[T]).OnFailed() applied. See
+// HandlerOptions.
+func WithOnFailed[T any](callback func(item T, err error)) HandlerOptions[T] {
+	var o HandlerOptions[T]
+	o.OnFailed(callback)
+	return o
+}
+
+// WithMiddleware returns new HandlerOptions with (*HandlerOptions[T]).WithMiddleware() applied.
+// See HandlerOptions.
+func WithMiddleware[T any](middleware ...Middleware[T]) HandlerOptions[T] {
+	var o HandlerOptions[T]
+	o.WithMiddleware(middleware...)
+	return o
+}
+
+// WithName returns new HandlerOptions with (*HandlerOptions[T]).WithName() applied. See
+// HandlerOptions.
+func WithName[T any](name string) HandlerOptions[T] {
+	var o HandlerOptions[T]
+	o.WithName(name)
+	return o
+}
+
+// WithOrderBy returns new Options with (*Options[T]).WithOrderBy() applied. See Options.
+func WithOrderBy[T any](less func(a, b T) bool) Options[T] {
+	var o Options[T]
+	o.WithOrderBy(less)
+	return o
+}
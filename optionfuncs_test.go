@@ -0,0 +1,45 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestOptionFuncs(t *testing.T) {
+	var submitted, fullyConsumed []int
+	var sizes []int
+	var options eventdistributor.Options[MyEvent]
+	options.OnFullyConsumed(func(e MyEvent) { fullyConsumed = append(fullyConsumed, e.id) })
+
+	t.Log("option functions can be mixed with Options built by its methods")
+	d := eventdistributor.New(
+		eventdistributor.WithOnSubmit(func(e MyEvent) { submitted = append(submitted, e.id) }),
+		eventdistributor.WithOnBufsizeChange[MyEvent](func(size int) { sizes = append(sizes, size) }),
+		eventdistributor.WithHistory[MyEvent](2),
+		options,
+	)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(MyEvent{id: 1})
+	d.Submit(MyEvent{id: 2})
+	require.Equal(t, []int{1, 2}, drainIDs(&r))
+	require.Equal(t, []int{1, 2}, submitted)
+	require.Equal(t, []int{1, 2}, fullyConsumed)
+	require.Equal(t, []int{1, 2, 1, 0}, sizes)
+
+	r2 := d.SubscribeWithHistory(2)
+	defer r2.Unsubscribe()
+	require.Equal(t, []int{1, 2}, drainIDs(&r2))
+
+	t.Log("option functions also work with NewFixed")
+	fixed, err := eventdistributor.NewFixed(4, eventdistributor.OverflowDropNewest,
+		eventdistributor.WithOnSubmit(func(e MyEvent) { submitted = append(submitted, e.id) }),
+	)
+	require.NoError(t, err)
+	fixed.Submit(MyEvent{id: 3})
+	require.Equal(t, []int{1, 2, 3}, submitted)
+}
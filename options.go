@@ -1,10 +1,32 @@
 package eventdistributor
 
+import (
+	"fmt"
+)
+
 // Options contains a set of options for Distributor initialization.
 //
+// Options can be set by calling its methods, or built inline with the function of the same name as
+// each method - prefixed by "With" for callbacks - which returns new Options with only that option
+// set. New and NewFixed combine every Options they're given, so the two styles can be mixed:
+//
+//	d := New(WithOnSubmit(logEvent), WithOnBufsizeChange[Event](gauge.Set), options)
+//
+// Options set by functions that take an *Options - WithKeyFunc, WithOrderCheckByKey, and
+// WithConflateBy - can only be set on an existing Options.
+//
+// Callbacks (OnSubmit, OnFullyConsumed, OnBufsizeChange, etc.) are called once the Distributor's
+// lock is released, so they may call methods on the Distributor - for example, to submit a
+// follow-up event. Callbacks are called one at a time, in the same order as the changes they
+// report, but not necessarily by the goroutine that made the change: if another goroutine is
+// already calling callbacks, it calls the new ones too. So, a callback may be called shortly after
+// the method that caused it has returned, and OnBufsizeChange may lag slightly behind the actual
+// size of the buffer.
+//
 // The zero value is safe to use.
 type Options[T any] struct {
-	modify []func(*Distributor[T])
+	modify   []func(*Distributor[T])
+	watchdog *callbackWatchdog
 }
 
 // OnBufsizeChange adds a callback to the options that will be called whenever the number of items
@@ -13,8 +35,9 @@ type Options[T any] struct {
 // NOTE: This is typically called during the Distributor's Submit(), Consume(), and
 // Unsubscribe().
 func (o *Options[T]) OnBufsizeChange(callback func(size int)) {
+	site := captureRegistrationSite()
 	o.modify = append(o.modify, func(d *Distributor[T]) {
-		d.onBufsizeChange = append(d.onBufsizeChange, callback)
+		d.onBufsizeChange.add(watchCallback(d.watchdog, "OnBufsizeChange", site, callback), nil)
 	})
 }
 
@@ -24,18 +47,111 @@ func (o *Options[T]) OnBufsizeChange(callback func(size int)) {
 // In the edge case where an item is immediately ignored because there's no readers, OnSubmit will
 // be called before OnfullyConsumed.
 func (o *Options[T]) OnSubmit(callback func(item T)) {
+	site := captureRegistrationSite()
 	o.modify = append(o.modify, func(d *Distributor[T]) {
-		d.onSubmit = append(d.onSubmit, callback)
+		d.onSubmit.add(watchCallback(d.watchdog, "OnSubmit", site, callback), nil)
 	})
 }
 
 // OnFullyConsumed adds a callback to the options that will be called whenever an item is dropped
 // from the buffer.
 //
-// NOTE: If there are no active subscribers, the callback will be called *during* the call to
-// (*Distributor[T]).Submit().
+// Like every callback, it's queued while the Distributor's lock is held, and called once the lock
+// is released, so it may call methods on the Distributor. If there are no active subscribers, that
+// happens before the call to (*Distributor[T]).Submit() returns, but after the event was discarded.
+// See OnCallbackPanic.
 func (o *Options[T]) OnFullyConsumed(callback func(item T)) {
+	site := captureRegistrationSite()
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.onFullyConsumed.add(watchCallback(d.watchdog, "OnFullyConsumed", site, callback), nil)
+	})
+}
+
+// OnConsume adds a callback to the options that will be called whenever an event is delivered to a
+// Reader, with the event and the Reader's ID - i.e., by each call to Consume(), TryConsume(), and
+// their variants that returns an event. Events skipped by a Reader, for example because they don't
+// match its filter, are not delivered to it.
+//
+// If the Reader was the last one holding the event, OnConsume is called before OnFullyConsumed.
+func (o *Options[T]) OnConsume(callback func(item T, readerID uint64)) {
+	site := captureRegistrationSite()
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.onConsume = append(d.onConsume, watchCallback2(d.watchdog, "OnConsume", site, callback))
+	})
+}
+
+// OnDrop adds a callback to the options that will be called whenever an item is removed from the
+// buffer before all Readers have consumed it, with the reason it was removed.
+//
+// Items that are dropped do not also trigger OnFullyConsumed.
+func (o *Options[T]) OnDrop(callback func(item T, reason DropReason)) {
+	site := captureRegistrationSite()
 	o.modify = append(o.modify, func(d *Distributor[T]) {
-		d.onFullyConsumed = append(d.onFullyConsumed, callback)
+		d.onDrop = append(d.onDrop, watchCallback2(d.watchdog, "OnDrop", site, callback))
 	})
 }
+
+// DropReason is the reason an item was dropped from the buffer, passed to OnDrop callbacks
+type DropReason int
+
+const (
+	// DropReasonRemoved indicates that the item was removed by (*Distributor[T]).FilterInPlace()
+	DropReasonRemoved DropReason = iota + 1
+	// DropReasonSealed indicates that the item was submitted after the Distributor was sealed or
+	// closed
+	DropReasonSealed
+	// DropReasonClosed indicates that the item was still buffered when the Distributor was closed
+	DropReasonClosed
+	// DropReasonThrottled indicates that the item was discarded by the Distributor's submit
+	// limiter. See (*Options[T]).WithSubmitLimiter().
+	DropReasonThrottled
+	// DropReasonOverflow indicates that the item was discarded because the buffer of a Distributor
+	// created by NewFixed() was full. See OverflowPolicy.
+	DropReasonOverflow
+	// DropReasonRejected indicates that the item was rejected by a gate. See
+	// (*Distributor[T]).SubscribeGate().
+	DropReasonRejected
+	// DropReasonExpired indicates that the item was buffered for longer than the Distributor's
+	// TTL. See (*Options[T]).WithEventTTL().
+	DropReasonExpired
+	// DropReasonCoalesced indicates that the item was merged with a newer one. See
+	// (*Options[T]).WithCoalesce().
+	DropReasonCoalesced
+	// DropReasonConflated indicates that the item was replaced by a newer one with the same key.
+	// See WithConflateBy().
+	DropReasonConflated
+	// DropReasonCleared indicates that the item was removed by (*Distributor[T]).Clear()
+	DropReasonCleared
+	// DropReasonCancelled indicates that the item was withdrawn by (Submission[T]).Cancel()
+	DropReasonCancelled
+)
+
+// String implements fmt.Stringer
+func (r DropReason) String() string {
+	switch r {
+	case DropReasonRemoved:
+		return "Removed"
+	case DropReasonSealed:
+		return "Sealed"
+	case DropReasonClosed:
+		return "Closed"
+	case DropReasonThrottled:
+		return "Throttled"
+	case DropReasonOverflow:
+		return "Overflow"
+	case DropReasonRejected:
+		return "Rejected"
+	case DropReasonExpired:
+		return "Expired"
+	case DropReasonCoalesced:
+		return "Coalesced"
+	case DropReasonConflated:
+		return "Conflated"
+	case DropReasonCleared:
+		return "Cleared"
+	case DropReasonCancelled:
+		return "Cancelled"
+	default:
+		return fmt.Sprintf("DropReason(%d)", int(r))
+	}
+}
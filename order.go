@@ -0,0 +1,119 @@
+package eventdistributor
+
+import (
+	"fmt"
+)
+
+// WithOrderCheck enables checking that events are submitted in order. Each submitted event is
+// compared with the greatest event submitted before it, and if the new event is less than it -
+// i.e., if less(next, prev) is true - onViolation is called with both events. If onViolation is
+// nil, the Distributor panics instead.
+//
+// An event that violates the ordering does not replace the greatest event, so a single event that
+// is out of order is reported once, instead of causing every event after it to be reported. Events
+// submitted after the Distributor is sealed are not checked.
+//
+// Like other callbacks, onViolation is called once the Distributor's lock is released, by the call
+// that submitted the event. Without WithOrderCheck, there is no cost to submitting events.
+//
+// See also WithOrderCheckByKey, to check ordering independently for each key.
+func (o *Options[T]) WithOrderCheck(less func(a, b T) bool, onViolation func(prev, next T)) {
+	site := captureRegistrationSite()
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.orderCheck = &orderCheck[T]{
+			less:        less,
+			onViolation: watchOrderViolation(&d.mu, d.watchdog, site, onViolation),
+			prev:        *new(T),
+			hasPrev:     false,
+		}
+	})
+}
+
+// WithOrderCheckByKey is like WithOrderCheck, but only requires events to be ordered relative to
+// other events with the same key, as determined by the key function. The greatest event for each
+// key is retained until the Distributor is closed.
+func WithOrderCheckByKey[T any, K comparable](
+	o *Options[T],
+	key func(T) K,
+	less func(a, b T) bool,
+	onViolation func(prev, next T),
+) {
+	site := captureRegistrationSite()
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.orderCheck = &keyedOrderCheck[K, T]{
+			key:         key,
+			less:        less,
+			onViolation: watchOrderViolation(&d.mu, d.watchdog, site, onViolation),
+			prev:        make(map[K]T),
+		}
+	})
+}
+
+// watchOrderViolation returns the function to call when the ordering is violated, which queues a
+// call to onViolation once m is unlocked, or panics immediately if onViolation is nil
+func watchOrderViolation[T any](
+	m *callbackMutex,
+	w *callbackWatchdog,
+	site registrationSite,
+	onViolation func(prev, next T),
+) func(prev, next T) {
+	if onViolation == nil {
+		return func(prev, next T) {
+			msg := fmt.Sprintf("event %v submitted after %v, violating ordering", next, prev)
+			panic("eventdistributor: " + msg)
+		}
+	}
+	return queueCallback2(m, watchCallback2(w, "OnOrderViolation", site, onViolation))
+}
+
+// orderChecker is the type-erased interface to an *orderCheck or *keyedOrderCheck, so that it can
+// be stored in the Distributor without an extra type parameter
+type orderChecker[T any] interface {
+	check(value T)
+	clear()
+}
+
+type orderCheck[T any] struct {
+	less        func(a, b T) bool
+	onViolation func(prev, next T)
+
+	// prev is the greatest event submitted so far, if hasPrev is true
+	prev    T
+	hasPrev bool
+}
+
+func (c *orderCheck[T]) check(value T) {
+	if c.hasPrev && c.less(value, c.prev) {
+		c.onViolation(c.prev, value)
+		return
+	}
+	c.prev = value
+	c.hasPrev = true
+}
+
+func (c *orderCheck[T]) clear() {
+	c.prev = *new(T)
+	c.hasPrev = false
+}
+
+type keyedOrderCheck[K comparable, T any] struct {
+	key         func(T) K
+	less        func(a, b T) bool
+	onViolation func(prev, next T)
+
+	// prev is the greatest event submitted so far for each key
+	prev map[K]T
+}
+
+func (c *keyedOrderCheck[K, T]) check(value T) {
+	k := c.key(value)
+	if prev, ok := c.prev[k]; ok && c.less(value, prev) {
+		c.onViolation(prev, value)
+		return
+	}
+	c.prev[k] = value
+}
+
+func (c *keyedOrderCheck[K, T]) clear() {
+	c.prev = make(map[K]T)
+}
@@ -0,0 +1,51 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestOrderCheck(t *testing.T) {
+	var violations [][2]int
+	var options eventdistributor.Options[MyEvent]
+	options.WithOrderCheck(lessByID, func(prev, next MyEvent) {
+		violations = append(violations, [2]int{prev.id, next.id})
+	})
+	d := eventdistributor.New(options)
+
+	// Without any Readers, events are discarded immediately, but are still checked.
+	for _, id := range []int{1, 2, 2, 5, 3, 4, 6} {
+		d.Submit(MyEvent{id: id})
+	}
+	require.Equal(t, [][2]int{{5, 3}, {5, 4}}, violations)
+}
+
+func TestOrderCheckPanics(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithOrderCheck(lessByID, nil)
+	d := eventdistributor.New(options)
+
+	d.Submit(MyEvent{id: 1})
+	require.Panics(t, func() { d.Submit(MyEvent{id: 0}) })
+}
+
+func TestOrderCheckByKey(t *testing.T) {
+	var violations [][2]int
+	var options eventdistributor.Options[MyEvent]
+	eventdistributor.WithOrderCheckByKey(&options, func(e MyEvent) int { return e.id % 2 }, lessByID,
+		func(prev, next MyEvent) {
+			violations = append(violations, [2]int{prev.id, next.id})
+		})
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	for _, id := range []int{4, 1, 2, 3, 7, 5} {
+		d.Submit(MyEvent{id: id})
+	}
+	require.Equal(t, [][2]int{{4, 2}, {7, 5}}, violations)
+	require.Equal(t, []int{4, 1, 2, 3, 7, 5}, drainIDs(&r))
+}
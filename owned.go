@@ -0,0 +1,82 @@
+package eventdistributor
+
+import (
+	"fmt"
+)
+
+// ConsumeOwned is like Consume, but additionally returns whether this Reader was the last one
+// holding the event - i.e., whether consuming it caused the Distributor to discard it. Exactly one
+// Reader sees last = true for each event that is discarded by being consumed, so it can be used to
+// decide which Reader should release any resources owned by the event.
+//
+// The Distributor also releases events in other ways, which are not reported by ConsumeOwned:
+//
+//   - If there are no Readers when an event is submitted, it is discarded immediately. See
+//     SubmitOwned().
+//   - If the last Reader holding an event unsubscribes, it is discarded. See UnsubscribeOwned().
+//   - Events skipped by a Reader - for example, because they don't match its filter, or are too
+//     old - and events that are dropped are only reported to OnFullyConsumed and OnDrop callbacks.
+//
+// For Readers created by SubscribeAcked(), events are held until they are acknowledged, and for
+// Readers created by SubscribeFrom(), events loaded from the Archiver were already discarded, so
+// in both cases, last is false.
+//
+// ConsumeOwned must only be called when there is an event available, like Consume.
+//
+// ConsumeOwned is thread-safe.
+func (r *Reader[T]) ConsumeOwned() (value T, last bool) {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if err := r.prepare(); err != nil {
+		panic(fmt.Errorf("eventdistributor: ConsumeOwned called on unusable Reader: %w", err))
+	}
+	r.mustHaveEvent("ConsumeOwned")
+
+	archived := r.hasBackfill()
+	value, seq := r.deliver()
+	if archived {
+		return value, false
+	}
+
+	_, buffered := r.d.findSeq(seq)
+	return value, !buffered
+}
+
+// UnsubscribeOwned is like Unsubscribe, but additionally returns the values of the events that
+// were discarded because this Reader was the last one holding them, in order. See ConsumeOwned().
+// If the Reader was already unsubscribed, UnsubscribeOwned returns nil.
+//
+// UnsubscribeOwned is thread-safe.
+func (r *Reader[T]) UnsubscribeOwned() []T {
+	d := r.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var owned []T
+	d.owned = &owned
+	r.unsubscribe()
+	d.owned = nil
+	return owned
+}
+
+// SubmitOwned is like SubmitQuiet, but additionally returns whether the Distributor took ownership
+// of the event - i.e., whether it was buffered for at least one Reader. If SubmitOwned returns
+// false, the event was discarded immediately, either because there were no Readers or because it
+// was dropped, and the caller remains responsible for it. Otherwise, the Reader that discards it
+// is told so by ConsumeOwned() or UnsubscribeOwned(). See ConsumeOwned() for the exceptions.
+//
+// Handlers created by SubscribeHandler() do not take part in ownership: an event delivered
+// directly to a handler may be discarded before the handler is called.
+//
+// SubmitOwned is thread-safe.
+func (d *Distributor[T]) SubmitOwned(value T) bool {
+	d.enterProducer()
+	defer d.exitProducer()
+
+	// Events that aren't buffered always get closedChannel. Published events get a new channel
+	// before it's known whether they'll be buffered, so the event is always submitted with the
+	// lock held. See WithSingleProducer.
+	allConsumed, _ := d.submitLocked(value, d.captureCaller(), true, nil, nil, nil)
+	return allConsumed != closedChannel
+}
@@ -0,0 +1,88 @@
+package eventdistributor_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestConsumeOwned(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	t.Log("without Readers, the producer keeps ownership")
+	require.False(t, d.SubmitOwned(MyEvent{id: 0}))
+
+	r1 := d.Subscribe()
+	defer r1.Unsubscribe()
+	r2 := d.Subscribe()
+
+	require.True(t, d.SubmitOwned(MyEvent{id: 1}))
+	require.True(t, d.SubmitOwned(MyEvent{id: 2}))
+	require.True(t, d.SubmitOwned(MyEvent{id: 3}))
+
+	t.Log("only the last Reader to consume an event owns it")
+	e, last := r1.ConsumeOwned()
+	require.Equal(t, 1, e.id)
+	require.False(t, last)
+	e, last = r2.ConsumeOwned()
+	require.Equal(t, 1, e.id)
+	require.True(t, last)
+
+	t.Log("a Reader that is ahead doesn't own events it can't see the end of")
+	e, last = r2.ConsumeOwned()
+	require.Equal(t, 2, e.id)
+	require.False(t, last)
+	e, last = r1.ConsumeOwned()
+	require.Equal(t, 2, e.id)
+	require.True(t, last)
+
+	t.Log("unsubscribing returns the events that were only held by the Reader")
+	e, last = r1.ConsumeOwned()
+	require.Equal(t, 3, e.id)
+	require.False(t, last)
+	d.Submit(MyEvent{id: 4})
+	require.Equal(t, []MyEvent{{id: 3}}, r2.UnsubscribeOwned())
+	require.Equal(t, []int{4}, drainIDs(&r1))
+
+	d.Seal()
+	require.False(t, d.SubmitOwned(MyEvent{id: 5}))
+}
+
+func TestConsumeOwnedConcurrent(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	const readers = 4
+	rs := make([]eventdistributor.Reader[MyEvent], readers)
+	for i := range rs {
+		rs[i] = d.Subscribe()
+		defer rs[i].Unsubscribe()
+	}
+
+	for i := 0; i < 1000; i++ {
+		d.Submit(MyEvent{id: i})
+
+		var wg sync.WaitGroup
+		lasts := make(chan bool, readers)
+		for _, r := range rs {
+			wg.Add(1)
+			go func(r eventdistributor.Reader[MyEvent]) {
+				defer wg.Done()
+				_, last := r.ConsumeOwned()
+				lasts <- last
+			}(r)
+		}
+		wg.Wait()
+		close(lasts)
+
+		count := 0
+		for last := range lasts {
+			if last {
+				count += 1
+			}
+		}
+		require.Equal(t, 1, count, "event %d", i)
+	}
+}
@@ -0,0 +1,131 @@
+package eventdistributor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Keyed is an event from one of the topics of a Multi, along with the topic's key. It is received
+// by Readers created by (*Multi[K, T]).SubscribeMatching() and SubscribePattern().
+type Keyed[K comparable, T any] struct {
+	Key   K
+	Value T
+}
+
+// matchingSub is a subscription to every topic of a Multi that matches. See SubscribeMatching().
+type matchingSub[K comparable, T any] struct {
+	match func(K) bool
+	// out receives the events from every matching topic
+	out *Distributor[Keyed[K, T]]
+	// stop is closed once the Reader of out is released
+	stop chan struct{}
+}
+
+// SubscribeMatching creates a new Reader that receives the events from every topic whose key
+// matches - including topics that are created afterwards. Each event is received along with its
+// topic's key.
+//
+// Events from each topic are received in the order they were submitted, but there is no ordering
+// between topics. Events are forwarded from each topic by a background goroutine, so they may be
+// received shortly after Readers of the topic itself receive them. Unsubscribing the returned
+// Reader detaches it from every topic shortly after.
+//
+// match is called while the Multi's lock is held, so it must not call any methods on the Multi.
+//
+// SubscribeMatching is thread-safe.
+func (m *Multi[K, T]) SubscribeMatching(match func(K) bool) Reader[Keyed[K, T]] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := &matchingSub[K, T]{
+		match: match,
+		out:   New[Keyed[K, T]](),
+		stop:  make(chan struct{}),
+	}
+	r := s.out.Subscribe()
+	r.onRelease = func() { close(s.stop) }
+
+	for key, d := range m.topics {
+		if match(key) {
+			s.attach(key, d)
+		}
+	}
+	m.matching = append(m.matching, s)
+	return r
+}
+
+// stopped returns whether the subscription's Reader has been released
+func (s *matchingSub[K, T]) stopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// attach starts forwarding events from the topic's Distributor d. Every event submitted after
+// attach returns is forwarded.
+func (s *matchingSub[K, T]) attach(key K, d *Distributor[T]) {
+	r := d.Subscribe()
+
+	go func() {
+		defer r.Unsubscribe()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-r.WaitChan():
+			}
+
+			value, err := r.TryConsume()
+			if err == ErrNoEvent {
+				continue
+			} else if err != nil {
+				// The topic was closed, so there's nothing left to forward.
+				return
+			}
+			s.out.SubmitQuiet(Keyed[K, T]{Key: key, Value: value})
+		}
+	}()
+}
+
+// SubscribePattern creates a new Reader that receives the events from every topic whose key
+// matches the pattern - including topics that are created afterwards. See
+// (*Multi[K, T]).SubscribeMatching().
+//
+// Keys are treated as paths, with segments separated by '/'. In the pattern, a '*' segment matches
+// any single segment, and a final '>' segment matches one or more remaining segments. For example,
+// "cluster/*/cpu" matches "cluster/node-7/cpu", and "cluster/>" matches "cluster/node-7/cpu" and
+// "cluster/node-7", but not "cluster".
+//
+// SubscribePattern panics if '>' is used anywhere other than the final segment.
+//
+// SubscribePattern is thread-safe.
+func SubscribePattern[T any](m *Multi[string, T], pattern string) Reader[Keyed[string, T]] {
+	segments := strings.Split(pattern, "/")
+	for i, seg := range segments {
+		if seg == ">" && i != len(segments)-1 {
+			msg := fmt.Sprintf("invalid pattern %q: '>' must be the last segment", pattern)
+			panic("eventdistributor: " + msg)
+		}
+	}
+
+	return m.SubscribeMatching(func(key string) bool {
+		return matchPattern(segments, strings.Split(key, "/"))
+	})
+}
+
+// matchPattern returns whether the segments of a key match the segments of a pattern. See
+// SubscribePattern.
+func matchPattern(pattern, key []string) bool {
+	for i, seg := range pattern {
+		if seg == ">" {
+			return len(key) > i
+		} else if i >= len(key) || (seg != "*" && seg != key[i]) {
+			return false
+		}
+	}
+	return len(key) == len(pattern)
+}
@@ -0,0 +1,88 @@
+package eventdistributor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+// receiveKeyed waits for the next event from a Reader created by SubscribeMatching, which may
+// arrive asynchronously
+func receiveKeyed(
+	t *testing.T,
+	r *eventdistributor.Reader[eventdistributor.Keyed[string, MyEvent]],
+) eventdistributor.Keyed[string, MyEvent] {
+	select {
+	case <-r.WaitChan():
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	e, err := r.TryConsume()
+	require.NoError(t, err)
+	return e
+}
+
+func TestSubscribePattern(t *testing.T) {
+	var m eventdistributor.Multi[string, MyEvent]
+
+	// Created before subscribing
+	m.Topic("cluster/a/cpu")
+
+	all := eventdistributor.SubscribePattern(&m, "cluster/>")
+	defer all.Unsubscribe()
+	cpu := eventdistributor.SubscribePattern(&m, "cluster/*/cpu")
+	defer cpu.Unsubscribe()
+
+	m.Submit("cluster/a/cpu", MyEvent{id: 0})
+	m.Submit("cluster/a/mem", MyEvent{id: 1})
+	m.Submit("other/a/cpu", MyEvent{id: 2})
+	m.Submit("cluster", MyEvent{id: 3})
+	m.Submit("cluster/b", MyEvent{id: 4})
+	m.Submit("cluster/a/cpu", MyEvent{id: 5})
+
+	t.Log("'>' matches one or more segments, including topics created after subscribing")
+	got := make(map[string][]int)
+	for i := 0; i < 4; i++ {
+		e := receiveKeyed(t, &all)
+		got[e.Key] = append(got[e.Key], e.Value.id)
+	}
+	require.Equal(t, map[string][]int{
+		"cluster/a/cpu": {0, 5},
+		"cluster/a/mem": {1},
+		"cluster/b":     {4},
+	}, got)
+
+	t.Log("'*' matches a single segment")
+	require.Equal(t, 0, receiveKeyed(t, &cpu).Value.id)
+	require.Equal(t, 5, receiveKeyed(t, &cpu).Value.id)
+
+	time.Sleep(10 * time.Millisecond)
+	_, err := all.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrNoEvent)
+	_, err = cpu.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrNoEvent)
+
+	require.Panics(t, func() { eventdistributor.SubscribePattern(&m, "cluster/>/cpu") })
+}
+
+func TestSubscribeMatchingUnsubscribe(t *testing.T) {
+	var m eventdistributor.Multi[string, MyEvent]
+
+	r := m.SubscribeMatching(func(key string) bool { return key == "a" })
+	m.Topic("a")
+	r.Unsubscribe()
+
+	// Once the forwarding Reader is detached, the topic has no Readers, so submitted events are
+	// immediately consumed.
+	require.Eventually(t, func() bool {
+		select {
+		case <-m.Submit("a", MyEvent{id: 0}):
+			return true
+		default:
+			return false
+		}
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,62 @@
+package eventdistributor
+
+// SubmitUnlessPending submits the value, unless there is already a pending event that matches,
+// returning whether the value was submitted. An event is pending if it is still in the buffer -
+// i.e., there is at least one Reader that hasn't consumed it. Whether any particular Reader has
+// consumed it is not considered.
+//
+// The buffer is checked and the value is added while holding the Distributor's lock, so no other
+// producer can submit in between. match is called with each buffered event, from oldest to newest,
+// until it returns true; it is called while the Distributor's lock is held, so it must not call
+// any methods on the Distributor or its Readers.
+//
+// Otherwise, SubmitUnlessPending behaves like SubmitQuiet(), and also returns false if the value is
+// dropped because it was throttled or the Distributor is sealed. If the Distributor has a submit
+// limiter, the value is throttled before the buffer is checked, so a token may be used even if it
+// isn't submitted.
+//
+// See also SubmitUnlessKeyPending, which avoids checking every buffered event.
+//
+// SubmitUnlessPending is thread-safe.
+func (d *Distributor[T]) SubmitUnlessPending(value T, match func(pending T) bool) bool {
+	_, submitted := d.submitBlocking(value, d.captureCaller(), false, nil, nil, func() bool {
+		for _, e := range d.buf {
+			if match(e.value) {
+				return true
+			}
+		}
+		return false
+	})
+	return submitted
+}
+
+// SubmitUnlessKeyPending is like SubmitUnlessPending, but only skips submitting the value if a
+// pending event has the same key, using the key function set by WithKeyFunc. Instead of checking
+// every buffered event, it checks whether the most recent event with the key is still buffered.
+//
+// If the key is no longer tracked because it was evicted by WithKeyFunc's maxKeys limit, the
+// buffer is checked event by event instead. If the most recent event with the key was removed by
+// FilterInPlace(), earlier events with the same key are not considered.
+//
+// SubmitUnlessKeyPending panics if the Distributor was not created with WithKeyFunc using the same
+// key type.
+//
+// SubmitUnlessKeyPending is thread-safe.
+func SubmitUnlessKeyPending[K comparable, T any](d *Distributor[T], value T) bool {
+	_, submitted := d.submitBlocking(value, d.captureCaller(), false, nil, nil, func() bool {
+		idx := getKeyIndex[K](d)
+		k := idx.key(value)
+		if e, ok := idx.entries[k]; ok {
+			_, buffered := d.findSeq(e.Value.(*keyEntry[K, T]).seq)
+			return buffered
+		}
+
+		for _, e := range d.buf {
+			if idx.key(e.value) == k {
+				return true
+			}
+		}
+		return false
+	})
+	return submitted
+}
@@ -0,0 +1,69 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSubmitUnlessPending(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	sameID := func(id int) func(MyEvent) bool {
+		return func(e MyEvent) bool { return e.id == id }
+	}
+
+	t.Log("without Readers, nothing is ever pending")
+	require.True(t, d.SubmitUnlessPending(MyEvent{id: 0}, sameID(0)))
+	require.True(t, d.SubmitUnlessPending(MyEvent{id: 0}, sameID(0)))
+
+	r1 := d.Subscribe()
+	defer r1.Unsubscribe()
+	r2 := d.Subscribe()
+	defer r2.Unsubscribe()
+
+	require.True(t, d.SubmitUnlessPending(MyEvent{id: 1}, sameID(1)))
+	require.False(t, d.SubmitUnlessPending(MyEvent{id: 1}, sameID(1)))
+	require.True(t, d.SubmitUnlessPending(MyEvent{id: 2}, sameID(2)))
+
+	t.Log("an event is pending while any Reader hasn't consumed it")
+	require.Equal(t, []int{1, 2}, drainIDs(&r1))
+	require.False(t, d.SubmitUnlessPending(MyEvent{id: 1}, sameID(1)))
+	require.Equal(t, []int{1, 2}, drainIDs(&r2))
+	require.True(t, d.SubmitUnlessPending(MyEvent{id: 1}, sameID(1)))
+
+	d.Seal()
+	require.False(t, d.SubmitUnlessPending(MyEvent{id: 3}, sameID(3)))
+}
+
+func TestSubmitUnlessKeyPending(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	eventdistributor.WithKeyFunc(&options, func(e MyEvent) int { return e.id % 10 }, 1)
+	d := eventdistributor.New(options)
+
+	submit := func(id int) bool {
+		return eventdistributor.SubmitUnlessKeyPending[int](d, MyEvent{id: id})
+	}
+
+	require.True(t, submit(1))
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	require.True(t, submit(11))
+	require.False(t, submit(21))
+	require.True(t, submit(2))
+
+	t.Log("keys evicted from the index are found by checking the buffer")
+	require.False(t, submit(31))
+	require.False(t, submit(12))
+
+	require.Equal(t, []int{11, 2}, drainIDs(&r))
+	require.True(t, submit(31))
+	require.True(t, submit(12))
+	require.Equal(t, []int{31, 12}, drainIDs(&r))
+
+	require.Panics(t, func() {
+		eventdistributor.SubmitUnlessKeyPending[string](d, MyEvent{id: 0})
+	})
+}
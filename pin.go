@@ -0,0 +1,162 @@
+package eventdistributor
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrTooManyPins is returned by (*Distributor[T]).Pin() when the Distributor already has the
+// maximum number of pins. See (*Options[T]).WithMaxPins().
+var ErrTooManyPins = errors.New("too many pinned events")
+
+// defaultMaxPins is the maximum number of pins held at once, if not set by WithMaxPins
+const defaultMaxPins = 64
+
+// WithMaxPins sets the maximum number of pins that can be held at once. By default, the maximum is
+// 64. See (*Distributor[T]).Pin().
+//
+// WithMaxPins panics if max is not positive.
+func (o *Options[T]) WithMaxPins(max int) {
+	if max <= 0 {
+		panic("eventdistributor: WithMaxPins requires max > 0")
+	}
+
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.maxPins = max
+	})
+}
+
+// pinnedEvent tracks the pins on a single event
+type pinnedEvent struct {
+	// count is the number of pins on the event that haven't been released
+	count int64
+	// voided is true if the event was removed from the buffer despite the pins, in which case
+	// releasing them does nothing
+	voided bool
+}
+
+// Pin keeps the currently buffered event with the sequence number seq in the buffer, even after
+// every Reader has consumed it, until the returned unpin function is called. While it is pinned,
+// the event can be retrieved with ReadPinned().
+//
+// Because the buffer is ordered, a pinned event also keeps every later event in the buffer. Events
+// are only fully consumed - and the channels returned by Submit closed - once they are unpinned.
+// The same applies to WaitForDrain(), which waits for all pins to be released.
+//
+// Pins do not override explicit removal: FilterInPlace() and Close() drop pinned events as they
+// would any other, passing them to OnDrop. After that, unpinning does nothing.
+//
+// The number of pins held at once is limited, so that leaked pins can't hold events forever. If the
+// limit is reached, Pin returns ErrTooManyPins. See (*Options[T]).WithMaxPins(). If the event is
+// not buffered, Pin returns ErrSeqEvicted or ErrSeqInFuture, and if the Distributor is closed, it
+// returns ErrClosed.
+//
+// The returned unpin function is safe to call more than once.
+//
+// Pin is thread-safe.
+func (d *Distributor[T]) Pin(seq int64) (unpin func(), _ error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return nil, ErrClosed
+	} else if seq >= d.nextSeq {
+		return nil, ErrSeqInFuture
+	}
+
+	idx, ok := d.findSeq(seq)
+	if !ok {
+		return nil, ErrSeqEvicted
+	}
+
+	maxPins := d.maxPins
+	if maxPins == 0 {
+		maxPins = defaultMaxPins
+	}
+	if d.numPins >= maxPins {
+		return nil, ErrTooManyPins
+	}
+
+	return d.pin(seq, idx), nil
+}
+
+// pin implements Pin for the buffered event at idx, with the sequence number seq, without checking
+// the limit on the number of pins.
+//
+// d.mu must be held. It must NOT be held when calling the returned function.
+func (d *Distributor[T]) pin(seq int64, idx int) (unpin func()) {
+	if d.pins == nil {
+		d.pins = make(map[int64]*pinnedEvent)
+	}
+	p, ok := d.pins[seq]
+	if !ok {
+		p = &pinnedEvent{count: 0, voided: false}
+		d.pins[seq] = p
+	}
+	p.count += 1
+	d.numPins += 1
+	d.buf[idx].refcount += 1
+
+	released := false
+	return func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		if released || p.voided {
+			return
+		}
+		released = true
+
+		p.count -= 1
+		d.numPins -= 1
+		if p.count == 0 {
+			delete(d.pins, seq)
+		}
+
+		idx, _ := d.findSeq(seq)
+		d.buf[idx].refcount -= 1
+		d.cleanupOldEvents()
+	}
+}
+
+// ReadPinned returns the value of the pinned event with the sequence number seq, if there is one.
+// See Pin().
+//
+// ReadPinned is thread-safe.
+func (d *Distributor[T]) ReadPinned(seq int64) (T, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.pins[seq]; !ok {
+		var zero T
+		return zero, false
+	}
+
+	idx, _ := d.findSeq(seq)
+	return d.buf[idx].value, true
+}
+
+// findSeq returns the index in the buffer of the event with the sequence number seq, if it is
+// buffered.
+//
+// d.mu must be held.
+func (d *Distributor[T]) findSeq(seq int64) (int, bool) {
+	idx := sort.Search(len(d.buf), func(i int) bool { return d.buf[i].seq >= seq })
+	return idx, idx < len(d.buf) && d.buf[idx].seq == seq
+}
+
+// voidPins releases all pins on the event with the sequence number seq, because it is being
+// removed from the buffer, returning the number of references they held.
+//
+// d.mu must be held.
+func (d *Distributor[T]) voidPins(seq int64) int64 {
+	p, ok := d.pins[seq]
+	if !ok {
+		return 0
+	}
+
+	delete(d.pins, seq)
+	p.voided = true
+	d.numPins -= int(p.count)
+	return p.count
+}
@@ -0,0 +1,93 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestPin(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithMaxPins(2)
+	var consumed []int
+	options.OnFullyConsumed(func(e MyEvent) {
+		consumed = append(consumed, e.id)
+	})
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	d.Submit(MyEvent{id: 0})
+	allConsumed := d.Submit(MyEvent{id: 1})
+	d.Submit(MyEvent{id: 2})
+
+	unpin, err := d.Pin(1)
+	require.NoError(t, err)
+	unpinAgain, err := d.Pin(1)
+	require.NoError(t, err)
+
+	t.Log("pins are limited")
+	_, err = d.Pin(2)
+	require.ErrorIs(t, err, eventdistributor.ErrTooManyPins)
+
+	t.Log("pinned events outlive consumption")
+	require.Equal(t, []int{0, 1, 2}, drainIDs(&r))
+	require.Equal(t, []int{0}, consumed)
+	nowNotReady(t, allConsumed)
+
+	e, ok := d.ReadPinned(1)
+	require.True(t, ok)
+	require.Equal(t, 1, e.id)
+	_, ok = d.ReadPinned(2)
+	require.False(t, ok)
+
+	t.Log("unpin is idempotent, and the event is released once all pins are")
+	unpin()
+	unpin()
+	nowNotReady(t, allConsumed)
+	unpinAgain()
+	nowReady(t, allConsumed)
+	require.Equal(t, []int{0, 1, 2}, consumed)
+
+	_, ok = d.ReadPinned(1)
+	require.False(t, ok)
+	_, err = d.Pin(1)
+	require.ErrorIs(t, err, eventdistributor.ErrSeqEvicted)
+	_, err = d.Pin(5)
+	require.ErrorIs(t, err, eventdistributor.ErrSeqInFuture)
+}
+
+func TestPinOverridden(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var dropped []int
+	options.OnDrop(func(e MyEvent, _ eventdistributor.DropReason) {
+		dropped = append(dropped, e.id)
+	})
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 1})
+	unpin0, err := d.Pin(0)
+	require.NoError(t, err)
+	unpin1, err := d.Pin(1)
+	require.NoError(t, err)
+
+	t.Log("FilterInPlace removes pinned events")
+	require.Equal(t, 1, d.FilterInPlace(func(e MyEvent) bool { return e.id == 0 }))
+	require.Equal(t, []int{0}, dropped)
+	_, ok := d.ReadPinned(0)
+	require.False(t, ok)
+	unpin0()
+	require.Equal(t, []int{1}, drainIDs(&r))
+
+	t.Log("Close drops pinned events")
+	d.Close()
+	require.Equal(t, []int{0, 1}, dropped)
+	unpin1()
+}
@@ -0,0 +1,75 @@
+package eventdistributor
+
+// WithOrderBy makes every Reader receive events in priority order instead of the order they were
+// submitted: each call to Consume() returns the Reader's highest-priority event that it has not yet
+// seen, where a has a higher priority than b if less(a, b) is true. Events with equal priority are
+// received in the order they were submitted.
+//
+// Each event is still received exactly once by every Reader, and is kept in the buffer until every
+// Reader has received it - but because Readers consume out of order, each Reader also keeps every
+// later event, until it has received everything before them. In effect, every Reader behaves like
+// one created by SubscribeLIFO(), with a different order, and with the same limitations: events
+// can't be merged or replaced by WithCoalesce or WithConflateBy, or cancelled, and Readers can't
+// have filters or be in ack mode.
+//
+// Finding the highest-priority event takes time proportional to the number of events the Reader
+// hasn't yet seen. Distributors without WithOrderBy are unaffected.
+//
+// less is called while the Distributor's lock is held, so it must not call any methods on the
+// Distributor or its Readers.
+//
+// WithOrderBy panics if less is nil.
+func (o *Options[T]) WithOrderBy(less func(a, b T) bool) {
+	if less == nil {
+		panic("eventdistributor: WithOrderBy requires a less function")
+	}
+
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.orderBy = less
+	})
+}
+
+// orderByPriority prepares a new Reader to receive events in priority order, if the Distributor
+// has WithOrderBy.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) orderByPriority() {
+	if r.d.orderBy != nil && r.lifo == nil {
+		r.lifo = &readerLIFO{seen: nil}
+	}
+}
+
+// firstByPriority returns the index in the buffer of the highest-priority event that the Reader
+// has not yet consumed, for Readers of a Distributor with WithOrderBy.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) firstByPriority() int {
+	buf := r.d.buf
+	less := r.d.orderBy
+
+	// The event at the Reader's position is never seen, and comes before any others, so it wins
+	// ties.
+	best := int(r.position - r.d.basePosition)
+	seen := r.lifo.seen
+	for idx := best + 1; idx < len(buf); idx++ {
+		seq := buf[idx].seq
+		for len(seen) != 0 && seen[0].hi < seq {
+			seen = seen[1:]
+		}
+		if len(seen) != 0 && seen[0].lo <= seq {
+			// Every buffered event in the range has been seen, so skip to its end. The last
+			// event in the range may have been removed from the buffer since.
+			end, found := r.d.findSeq(seen[0].hi)
+			if !found {
+				end -= 1
+			}
+			idx = end
+			continue
+		}
+
+		if less(buf[idx].value, buf[best].value) {
+			best = idx
+		}
+	}
+	return best
+}
@@ -0,0 +1,148 @@
+package eventdistributor_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+// urgentFirst orders events with IDs of 100 or more first, and otherwise keeps them in the order
+// they were submitted
+func urgentFirst(a, b MyEvent) bool {
+	return a.id >= 100 && b.id < 100
+}
+
+func TestWithOrderBy(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var consumed []int
+	options.OnFullyConsumed(func(e MyEvent) { consumed = append(consumed, e.id) })
+	options.WithOrderBy(urgentFirst)
+	d := eventdistributor.New(options)
+
+	r1 := d.Subscribe()
+	defer r1.Unsubscribe()
+	r2 := d.Subscribe()
+	defer r2.Unsubscribe()
+
+	for _, id := range []int{0, 1, 100, 2} {
+		d.Submit(MyEvent{id: id})
+	}
+
+	t.Log("urgent events are received first, even if they were submitted later")
+	require.Equal(t, 100, r1.Consume().id)
+	require.Equal(t, 0, r1.Consume().id)
+	d.Submit(MyEvent{id: 101})
+	require.Equal(t, 101, r1.Consume().id)
+	require.Equal(t, []int{1, 2}, drainIDs(&r1))
+
+	t.Log("each Reader receives every event once, in its own order")
+	require.Equal(t, []int{100, 101, 0, 1, 2}, drainIDs(&r2))
+	notReady(t, r1)
+	notReady(t, r2)
+
+	t.Log("events are released once every Reader has received them")
+	require.Equal(t, []int{0, 1, 100, 2, 101}, consumed)
+	require.Equal(t, 0, d.Stats().Bufsize)
+}
+
+// TestWithOrderByRandom checks that each Reader receives every event exactly once, always
+// receiving the highest-priority event it hasn't yet seen, while events are submitted, consumed,
+// and removed in a random order
+func TestWithOrderByRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	less := func(a, b MyEvent) bool { return a.id%7 > b.id%7 }
+	d := eventdistributor.New(eventdistributor.WithOrderBy(less))
+
+	const numReaders = 3
+	readers := make([]eventdistributor.Reader[MyEvent], numReaders)
+	unseen := make([][]int, numReaders)
+	received := make([][]int, numReaders)
+	for i := range readers {
+		readers[i] = d.Subscribe()
+		defer readers[i].Unsubscribe()
+	}
+
+	var removed []int
+	next := 0
+	for step := 0; step < 3000; step++ {
+		switch op := rng.Intn(10); {
+		case op < 4:
+			d.Submit(MyEvent{id: next})
+			for i := range unseen {
+				unseen[i] = append(unseen[i], next)
+			}
+			next += 1
+		case op < 9:
+			i := rng.Intn(numReaders)
+			e, err := readers[i].TryConsume()
+			if len(unseen[i]) == 0 {
+				require.ErrorIs(t, err, eventdistributor.ErrNoEvent)
+				continue
+			}
+			require.NoError(t, err)
+
+			// The expected event is the first of the highest priority.
+			best := 0
+			for j, id := range unseen[i] {
+				if less(MyEvent{id: id}, MyEvent{id: unseen[i][best]}) {
+					best = j
+				}
+			}
+			require.Equal(t, unseen[i][best], e.id, "step %d, reader %d", step, i)
+			unseen[i] = append(unseen[i][:best], unseen[i][best+1:]...)
+			received[i] = append(received[i], e.id)
+		default:
+			// Recent events are more likely to still be buffered.
+			target := next - 1 - rng.Intn(10)
+			if d.FilterInPlace(func(e MyEvent) bool { return e.id == target }) == 0 {
+				continue
+			}
+			removed = append(removed, target)
+			for i := range unseen {
+				for j, id := range unseen[i] {
+					if id == target {
+						unseen[i] = append(unseen[i][:j], unseen[i][j+1:]...)
+						break
+					}
+				}
+			}
+		}
+	}
+
+	for i := range readers {
+		received[i] = append(received[i], drainIDs(&readers[i])...)
+		sort.Ints(received[i])
+		for j := 1; j < len(received[i]); j++ {
+			require.NotEqual(t, received[i][j-1], received[i][j], "reader %d", i)
+		}
+		for id := 0; id < next; id++ {
+			if !containsInt(removed, id) {
+				require.Contains(t, received[i], id, "reader %d", i)
+			}
+		}
+	}
+	require.Equal(t, 0, d.Stats().Bufsize)
+}
+
+func TestWithOrderByUnsupported(t *testing.T) {
+	d := eventdistributor.New(eventdistributor.WithOrderBy(urgentFirst))
+	require.PanicsWithValue(t, "eventdistributor: SubscribeAcked called on a Distributor with WithOrderBy", func() {
+		d.SubscribeAcked()
+	})
+	require.Panics(t, func() { d.SubscribeLIFO() })
+	require.Panics(t, func() { d.SubscribeFiltered(func(MyEvent) bool { return true }) })
+	require.Panics(t, func() { eventdistributor.WithOrderBy[MyEvent](nil) })
+}
+
+func containsInt(ids []int, id int) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package eventdistributor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ProcessErrorPolicy determines what (*Reader[T]).ProcessParallel() does when processing an event
+// fails
+type ProcessErrorPolicy int
+
+const (
+	// ProcessStopOnError stops receiving events once processing any event fails. ProcessParallel
+	// returns the first error, once the events already being processed have finished.
+	ProcessStopOnError ProcessErrorPolicy = iota + 1
+	// ProcessCollectErrors keeps processing events after failures, treating failed events as
+	// finished. ProcessParallel returns every error, joined with errors.Join(), once it stops for
+	// some other reason.
+	ProcessCollectErrors
+)
+
+// String implements fmt.Stringer
+func (p ProcessErrorPolicy) String() string {
+	switch p {
+	case ProcessStopOnError:
+		return "StopOnError"
+	case ProcessCollectErrors:
+		return "CollectErrors"
+	default:
+		return fmt.Sprintf("ProcessErrorPolicy(%d)", int(p))
+	}
+}
+
+// ProcessParallel receives events from the Reader and calls fn for each of them, with up to workers
+// calls running at once, until ctx is canceled or the Reader can no longer be used. The Reader must
+// be in ack mode - see SubscribeAcked() - so that events can be received before earlier ones have
+// finished processing, but are only acked in order: each event is acked once it and every event
+// received before it have finished, so the buffer is never cleaned up past an event that's still
+// being processed.
+//
+// If fn returns an error, policy determines whether ProcessParallel stops receiving events. Either
+// way, and if ctx is canceled, ProcessParallel waits for the events that are already being
+// processed to finish - fn must return once its context is canceled - before returning. Events
+// that were received but not acked - because they failed with ProcessStopOnError, or come after
+// one that did - are nacked, so that they're received again by the Reader's next consumer. So, with
+// ProcessStopOnError, some events may be processed more than once.
+//
+// ProcessParallel returns ctx.Err() if ctx is canceled, and the reason the Reader can no longer be
+// used - like ErrClosed - if that happens first, along with any errors from fn as described by
+// policy.
+//
+// ProcessParallel panics if the Reader is not in ack mode, if workers is not positive, or if policy
+// is not a valid ProcessErrorPolicy.
+//
+// ProcessParallel must not be called concurrently with other ways of consuming from the Reader.
+func (r *Reader[T]) ProcessParallel(
+	ctx context.Context,
+	workers int,
+	policy ProcessErrorPolicy,
+	fn func(context.Context, T) error,
+) error {
+	if r.acks == nil {
+		panic("eventdistributor: ProcessParallel requires a Reader in ack mode")
+	} else if workers <= 0 {
+		panic("eventdistributor: ProcessParallel requires workers > 0")
+	} else if policy != ProcessStopOnError && policy != ProcessCollectErrors {
+		panic(fmt.Sprintf("eventdistributor: ProcessParallel called with invalid policy %v", policy))
+	}
+
+	// pending is the events being processed or waiting to be acked, in the order they were received
+	var pending []*processedEvent
+	finished := make(chan *processedEvent, workers)
+	running := 0
+
+	var errs []error
+	stopping := false
+	stop := func(err error) {
+		if !stopping || policy == ProcessCollectErrors {
+			errs = append(errs, err)
+		}
+		stopping = true
+	}
+
+	done := ctx.Done()
+	for !stopping || running != 0 {
+		var wait <-chan struct{}
+		if !stopping && running < workers {
+			wait = r.WaitChan()
+		}
+
+		select {
+		case <-done:
+			// done stays closed, so it's only handled once.
+			done = nil
+			stop(ctx.Err())
+		case <-wait:
+			value, seq, err := r.TryConsumeSeq()
+			if err == ErrNoEvent {
+				continue
+			} else if err != nil {
+				stop(err)
+				continue
+			}
+
+			e := &processedEvent{seq: seq, finished: false, err: nil}
+			pending = append(pending, e)
+			running += 1
+			go func() {
+				e.err = fn(ctx, value)
+				finished <- e
+			}()
+		case e := <-finished:
+			running -= 1
+			e.finished = true
+			if e.err != nil {
+				if policy == ProcessCollectErrors {
+					errs = append(errs, e.err)
+				} else {
+					stop(e.err)
+				}
+			}
+
+			for len(pending) != 0 && pending[0].finished {
+				if pending[0].err != nil && policy == ProcessStopOnError {
+					break
+				}
+				// Errors mean the event can't be acked anymore - for example, because the
+				// Distributor was closed - so there's nothing else to do with it.
+				_ = r.Ack(pending[0].seq)
+				pending[0] = nil
+				pending = pending[1:]
+			}
+		}
+	}
+
+	for _, e := range pending {
+		_ = r.Nack(e.seq)
+	}
+	return errors.Join(errs...)
+}
+
+// processedEvent is an event received by ProcessParallel
+type processedEvent struct {
+	seq int64
+	// finished is true once fn has returned for the event, with err
+	finished bool
+	err      error
+}
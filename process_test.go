@@ -0,0 +1,155 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestProcessParallel(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.SubscribeAcked()
+	defer r.Unsubscribe()
+
+	var done []<-chan struct{}
+	release := make(map[int]chan struct{})
+	for i := 0; i < 4; i++ {
+		done = append(done, d.Submit(MyEvent{id: i}))
+		release[i] = make(chan struct{})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan int)
+	result := make(chan error)
+	go func() {
+		result <- r.ProcessParallel(ctx, 2, eventdistributor.ProcessStopOnError,
+			func(_ context.Context, e MyEvent) error {
+				started <- e.id
+				<-release[e.id]
+				return nil
+			})
+	}()
+
+	t.Log("only as many events as there are workers are processed at once")
+	require.ElementsMatch(t, []int{0, 1}, []int{<-started, <-started})
+	select {
+	case id := <-started:
+		t.Fatalf("started processing event %d with every worker busy", id)
+	default:
+	}
+
+	t.Log("events are only acked once every earlier event has finished")
+	close(release[1])
+	require.Equal(t, 2, <-started)
+	nowNotReady(t, done[1])
+	close(release[0])
+	require.Equal(t, 3, <-started)
+	nowReady(t, done[0])
+	nowReady(t, done[1])
+
+	t.Log("canceling ctx waits for the events being processed")
+	cancel()
+	select {
+	case err := <-result:
+		t.Fatalf("ProcessParallel returned %v with events still being processed", err)
+	default:
+	}
+	close(release[3])
+	nowNotReady(t, done[3])
+	close(release[2])
+	require.ErrorIs(t, <-result, context.Canceled)
+	nowReady(t, done[2])
+	nowReady(t, done[3])
+	require.Equal(t, 0, r.InFlight())
+}
+
+func TestProcessParallelStopOnError(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.SubscribeAcked()
+	defer r.Unsubscribe()
+	for i := 0; i < 4; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+
+	t.Log("the first error stops processing, and the events that weren't acked are nacked")
+	failed := errors.New("failed")
+	var processed []int
+	err := r.ProcessParallel(context.Background(), 1, eventdistributor.ProcessStopOnError,
+		func(_ context.Context, e MyEvent) error {
+			processed = append(processed, e.id)
+			if e.id == 1 {
+				return failed
+			}
+			return nil
+		})
+	require.ErrorIs(t, err, failed)
+	require.Equal(t, []int{0, 1}, processed)
+	require.Equal(t, 0, r.InFlight())
+	require.Equal(t, []int{1, 2, 3}, drainIDs(&r))
+}
+
+func TestProcessParallelCollectErrors(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.SubscribeAcked()
+	defer r.Unsubscribe()
+
+	var done []<-chan struct{}
+	for i := 0; i < 4; i++ {
+		done = append(done, d.Submit(MyEvent{id: i}))
+	}
+
+	t.Log("failed events are treated as finished, and every error is returned")
+	ctx, cancel := context.WithCancel(context.Background())
+	err := r.ProcessParallel(ctx, 2, eventdistributor.ProcessCollectErrors,
+		func(_ context.Context, e MyEvent) error {
+			if e.id == 3 {
+				cancel()
+			}
+			if e.id%2 == 1 {
+				return fmt.Errorf("event %d failed", e.id)
+			}
+			return nil
+		})
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorContains(t, err, "event 1 failed")
+	require.ErrorContains(t, err, "event 3 failed")
+	for _, ch := range done {
+		nowReady(t, ch)
+	}
+}
+
+func TestProcessParallelClosed(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.SubscribeAcked()
+	defer r.Unsubscribe()
+
+	d.Close()
+	err := r.ProcessParallel(context.Background(), 1, eventdistributor.ProcessStopOnError,
+		func(context.Context, MyEvent) error { return nil })
+	require.ErrorIs(t, err, eventdistributor.ErrClosed)
+}
+
+func TestProcessParallelInvalid(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	fn := func(context.Context, MyEvent) error { return nil }
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	require.PanicsWithValue(t, "eventdistributor: ProcessParallel requires a Reader in ack mode", func() {
+		r.ProcessParallel(context.Background(), 1, eventdistributor.ProcessStopOnError, fn)
+	})
+
+	acked := d.SubscribeAcked()
+	defer acked.Unsubscribe()
+	require.Panics(t, func() {
+		acked.ProcessParallel(context.Background(), 0, eventdistributor.ProcessStopOnError, fn)
+	})
+	require.Panics(t, func() {
+		acked.ProcessParallel(context.Background(), 1, 0, fn)
+	})
+}
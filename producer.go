@@ -0,0 +1,210 @@
+package eventdistributor
+
+import (
+	"sync/atomic"
+)
+
+// WithSingleProducer declares that events are only ever submitted by one goroutine at a time, so
+// that Submit(), SubmitQuiet(), SubmitWithCallback(), and SubmitCancellable() can publish events
+// without waiting for the Distributor's lock.
+//
+// Instead of adding each event to the buffer itself, the producer writes it to the next slot of a
+// ring, and then atomically advances the ring's published position. If the lock is free, the
+// producer then adds the published events to the buffer as usual. Otherwise, they're added by the
+// goroutine holding the lock, once it's released - so every Reader, and every other method of the
+// Distributor, still observes each event exactly as if it had been submitted normally, just
+// slightly later. In a one-producer, many-consumer topology, this means the producer no longer
+// waits while consumers hold the lock, unless the ring is full.
+//
+// Because of this, callbacks for published events, like OnSubmit, may be called by a consumer's
+// goroutine instead of the producer's, and events aren't delivered directly to handlers created by
+// SubscribeHandler(). Events are still submitted while holding the lock if the ring is full, if
+// the Distributor has a submit limiter or a maximum Reader lag, or was created by NewFixed() with
+// OverflowBlock - because the producer may have to wait - and always by SubmitOwned(), which must
+// know whether the event was buffered, and by SubmitWait(), SubmitChecked(), and
+// SubmitUnlessPending(). Submissions that take the lock first add any events that were already
+// published, so events are always added in the order they were submitted.
+//
+// Submitting from more than one goroutine at a time corrupts the ring. Misuse is only detected in
+// builds with the race detector enabled, where any submission that starts while another is in
+// progress panics. Other builds don't check, because the check would cost the producer an atomic
+// read-modify-write on every submission.
+//
+// WithSingleProducer must be set when the Distributor is created.
+func (o *Options[T]) WithSingleProducer() {
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.singleProducer = true
+		if d.publisher == nil {
+			d.publisher = &publishRing[T]{
+				d:         d,
+				slots:     make([]publishedEvent[T], publishRingSize),
+				published: atomic.Int64{},
+				absorbed:  atomic.Int64{},
+			}
+			d.mu.publisher = d.publisher
+		}
+	})
+}
+
+// lockFreePublisher is the part of a publishRing used by callbackMutex, which doesn't know the
+// Distributor's type
+type lockFreePublisher interface {
+	// pending returns whether there are published events that haven't been added to the buffer.
+	// It's called without the mutex.
+	pending() bool
+	// absorb adds every published event to the buffer. The mutex must be held.
+	absorb()
+}
+
+// unlockPublisher implements Unlock for the mutex of a Distributor with a single producer. If
+// events were published while the mutex was held, they're added to the buffer once it's released,
+// because the producer only adds them itself if the mutex is free. See publishRing.
+func (m *callbackMutex) unlockPublisher() {
+	m.unlockAndDispatch()
+	// If another goroutine has locked the mutex since, it's responsible for the events instead.
+	for m.publisher.pending() && m.mu.TryLock() {
+		m.publisher.absorb()
+		m.unlockAndDispatch()
+	}
+}
+
+// publishRingSize is the number of events that can be published by a single producer without
+// being added to the buffer. It must be a power of two.
+const publishRingSize = 256
+
+// publishRing holds the events published by a single producer, until they're added to the buffer.
+// See WithSingleProducer.
+//
+// Only the producer writes to published, and to the slots from absorbed to published - which it
+// only writes before advancing published. Only goroutines holding d.mu write to absorbed, and to
+// the slots before published - which they only read or clear before advancing absorbed.
+type publishRing[T any] struct {
+	d     *Distributor[T]
+	slots []publishedEvent[T]
+	// published is the number of events that have been published, and absorbed is the number of
+	// those that have been added to the buffer. The event with index i is in slots[i %
+	// publishRingSize].
+	published atomic.Int64
+	absorbed  atomic.Int64
+}
+
+// publishedEvent is an event that was published by a single producer, with the arguments for
+// submit
+type publishedEvent[T any] struct {
+	value       T
+	caller      []uintptr
+	allConsumed chan struct{}
+	onDone      func(struct{})
+	token       *eventToken
+}
+
+// publish adds the event to the ring, returning false if it's full, and then adds it to the buffer
+// if d.mu is free.
+//
+// It must only be called by the producer, without d.mu.
+func (p *publishRing[T]) publish(e publishedEvent[T]) bool {
+	i := p.published.Load()
+	if i-p.absorbed.Load() == publishRingSize {
+		return false
+	}
+
+	p.slots[i%publishRingSize] = e
+	p.published.Store(i + 1)
+
+	// If the lock is held, whoever holds it will add the event once it's released.
+	if p.d.mu.mu.TryLock() {
+		p.absorb()
+		p.d.mu.Unlock()
+	}
+	return true
+}
+
+// pending returns whether there are published events that haven't been added to the buffer
+func (p *publishRing[T]) pending() bool {
+	return p.absorbed.Load() != p.published.Load()
+}
+
+// absorb adds every published event to the buffer, in order.
+//
+// d.mu must be held.
+func (p *publishRing[T]) absorb() {
+	end := p.published.Load()
+	for i := p.absorbed.Load(); i < end; i++ {
+		slot := &p.slots[i%publishRingSize]
+		e := *slot
+		*slot = publishedEvent[T]{}
+		p.absorbed.Store(i + 1)
+
+		p.d.submit(e.value, e.caller, e.allConsumed, e.onDone, e.token, false, false)
+	}
+}
+
+// publishFast submits the event with the ring, if the Distributor has a single producer and the
+// event doesn't have to be submitted with the lock held, returning the channel that Submit would
+// return for it. It returns false if the event must be submitted normally.
+//
+// d.mu must NOT be held.
+func (d *Distributor[T]) publishFast(
+	value T,
+	caller []uintptr,
+	track bool,
+	onDone func(struct{}),
+	token *eventToken,
+) (<-chan struct{}, bool) {
+	if d.publisher == nil || !d.fastPublish.Load() {
+		return nil, false
+	}
+
+	var allConsumed chan struct{}
+	if track && d.fixed == nil {
+		allConsumed = make(chan struct{})
+	}
+	e := publishedEvent[T]{
+		value:       value,
+		caller:      caller,
+		allConsumed: allConsumed,
+		onDone:      onDone,
+		token:       token,
+	}
+	if !d.publisher.publish(e) {
+		return nil, false
+	}
+	return allConsumed, true
+}
+
+// absorbPublished adds any events that were published without the lock to the buffer, so that an
+// event submitted with the lock held isn't added before them. See WithSingleProducer.
+//
+// d.mu must be held.
+func (d *Distributor[T]) absorbPublished() {
+	if d.publisher != nil {
+		d.publisher.absorb()
+	}
+}
+
+// updateFastPublish sets whether events can be published without the lock, after the Distributor's
+// options have changed. See WithSingleProducer.
+//
+// d.mu must be held, unless the Distributor is still being created.
+func (d *Distributor[T]) updateFastPublish() {
+	blocking := d.fixed != nil && d.fixed.policy == OverflowBlock
+	d.fastPublish.Store(d.publisher != nil && d.limiter == nil && d.maxReaderLag == 0 && !blocking)
+}
+
+// enterProducer marks the start of a submission, panicking if the Distributor has a single
+// producer and another submission is already in progress, in builds with the race detector. It
+// must be followed by exitProducer.
+//
+// d.mu must NOT be held, so that concurrent submissions are detected instead of waiting.
+func (d *Distributor[T]) enterProducer() {
+	if raceEnabled && d.singleProducer && !d.producing.CompareAndSwap(false, true) {
+		panic("eventdistributor: concurrent submissions to a Distributor with WithSingleProducer")
+	}
+}
+
+// exitProducer marks the end of a submission started with enterProducer.
+func (d *Distributor[T]) exitProducer() {
+	if raceEnabled && d.singleProducer {
+		d.producing.Store(false)
+	}
+}
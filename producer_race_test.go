@@ -0,0 +1,38 @@
+//go:build race
+
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSingleProducerConcurrentSubmit(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithSingleProducer()
+	submitting := make(chan struct{})
+	release := make(chan struct{})
+	options.OnSubmit(func(e MyEvent) {
+		if e.id == 0 {
+			close(submitting)
+			<-release
+		}
+	})
+	d := eventdistributor.New(options)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.Submit(MyEvent{id: 0})
+	}()
+	<-submitting
+
+	t.Log("a concurrent submission panics")
+	require.Panics(t, func() { d.SubmitQuiet(MyEvent{id: 1}) })
+
+	close(release)
+	<-done
+}
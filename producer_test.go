@@ -0,0 +1,293 @@
+package eventdistributor_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSingleProducer(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithSingleProducer()
+	d := eventdistributor.New(options)
+
+	t.Log("events published while consumers hold the lock are delivered in order")
+	const n = 1000
+	wait := consumeConcurrently(t, d, 4)
+	var last <-chan struct{}
+	for i := 0; i < n; i++ {
+		last = d.Submit(MyEvent{id: i})
+	}
+	<-last
+	d.Close()
+	for _, ids := range wait() {
+		require.Equal(t, idRange(0, n), ids)
+	}
+
+	t.Log("submissions with and without the lock are added in order")
+	d = eventdistributor.New(options)
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(MyEvent{id: 2})
+	_, err := d.SubmitChecked(MyEvent{id: 3})
+	require.NoError(t, err)
+	d.SubmitQuiet(MyEvent{id: 4})
+	require.True(t, d.SubmitOwned(MyEvent{id: 5}))
+	require.Equal(t, []int{2, 3, 4, 5}, drainIDs(&r))
+}
+
+func TestSingleProducerRingFull(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithSingleProducer()
+	d := eventdistributor.New(options)
+
+	// The filter blocks while checking event 0 for a consumer, holding the Distributor's lock.
+	var armed atomic.Bool
+	holding := make(chan struct{})
+	release := make(chan struct{})
+	blocker := d.SubscribeFiltered(func(e MyEvent) bool {
+		if e.id == 0 && armed.CompareAndSwap(true, false) {
+			close(holding)
+			<-release
+		}
+		return true
+	})
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	d.SubmitQuiet(MyEvent{id: 0})
+	armed.Store(true)
+	go func() { _, _ = blocker.TryConsume() }()
+	<-holding
+
+	t.Log("while the lock is held, the producer publishes until the ring is full")
+	const n = 1000
+	var submitted atomic.Int64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i < n; i++ {
+			d.SubmitQuiet(MyEvent{id: i})
+			submitted.Add(1)
+		}
+	}()
+	require.Eventually(t, func() bool { return submitted.Load() > 0 }, time.Second, time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("submitted every event while the lock was held")
+	case <-time.After(10 * time.Millisecond):
+	}
+	require.Less(t, submitted.Load(), int64(n-1))
+
+	t.Log("once the lock is released, the rest are submitted with it, in order")
+	close(release)
+	<-done
+	require.Equal(t, idRange(0, n), drainIDs(&r))
+	require.Equal(t, idRange(1, n), drainIDs(&blocker))
+	blocker.Unsubscribe()
+}
+
+func TestSingleProducerFixed(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithSingleProducer()
+	var drops atomic.Int64
+	options.OnDrop(func(MyEvent, eventdistributor.DropReason) { drops.Add(1) })
+
+	t.Log("OverflowBlock still blocks the producer")
+	d, err := eventdistributor.NewFixed(1, eventdistributor.OverflowBlock, options)
+	require.NoError(t, err)
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	d.SubmitQuiet(MyEvent{id: 0})
+	submitted := make(chan struct{})
+	go func() {
+		d.SubmitQuiet(MyEvent{id: 1})
+		d.SubmitQuiet(MyEvent{id: 2})
+		close(submitted)
+	}()
+	select {
+	case <-submitted:
+		t.Fatal("submitted while the buffer was full")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	require.Equal(t, 0, r.Consume().id)
+	require.Eventually(t, func() bool {
+		select {
+		case <-r.WaitChan():
+			return r.Consume().id == 1
+		default:
+			return false
+		}
+	}, time.Second, time.Millisecond)
+	<-submitted
+	require.Equal(t, []int{2}, drainIDs(&r))
+	require.Zero(t, drops.Load())
+
+	t.Log("other policies publish without the lock")
+	d, err = eventdistributor.NewFixed(2, eventdistributor.OverflowDropNewest, options)
+	require.NoError(t, err)
+	r = d.Subscribe()
+	defer r.Unsubscribe()
+	for i := 0; i < 4; i++ {
+		d.SubmitQuiet(MyEvent{id: i})
+	}
+	require.Equal(t, []int{0, 1}, drainIDs(&r))
+	require.Equal(t, int64(2), drops.Load())
+}
+
+func TestSingleProducerOwned(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithSingleProducer()
+	d := eventdistributor.New(options)
+
+	t.Log("without Readers, the producer keeps ownership")
+	require.False(t, d.SubmitOwned(MyEvent{id: 0}))
+
+	r := d.Subscribe()
+	require.True(t, d.SubmitOwned(MyEvent{id: 1}))
+	e, last := r.ConsumeOwned()
+	require.Equal(t, 1, e.id)
+	require.True(t, last)
+	r.Unsubscribe()
+
+	d.Seal()
+	require.False(t, d.SubmitOwned(MyEvent{id: 2}))
+}
+
+func TestSingleProducerClose(t *testing.T) {
+	t.Log("events published while the Distributor is closed are submitted or dropped, never lost")
+	for attempt := 0; attempt < 20; attempt++ {
+		var options eventdistributor.Options[MyEvent]
+		options.WithSingleProducer()
+		var mu sync.Mutex
+		var dropped []int
+		options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+			if reason != eventdistributor.DropReasonSealed {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			dropped = append(dropped, e.id)
+		})
+		d := eventdistributor.New(options)
+		wait := consumeConcurrently(t, d, 2)
+
+		const n = 500
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			d.Close()
+		}()
+		var channels []<-chan struct{}
+		for i := 0; i < n; i++ {
+			channels = append(channels, d.Submit(MyEvent{id: i}))
+		}
+		<-closed
+
+		for _, ch := range channels {
+			select {
+			case <-ch:
+			case <-time.After(time.Second):
+				t.Fatal("published event was never released")
+			}
+		}
+		mu.Lock()
+		require.Equal(t, idRange(n-len(dropped), n), dropped)
+		mu.Unlock()
+		for _, ids := range wait() {
+			require.Equal(t, idRange(0, len(ids)), ids)
+		}
+	}
+}
+
+// consumeConcurrently subscribes the given number of Readers to d, each consuming in its own
+// goroutine until d is closed. The returned function waits for them to stop, and returns the IDs
+// received by each.
+func consumeConcurrently(
+	t *testing.T,
+	d *eventdistributor.Distributor[MyEvent],
+	readers int,
+) (wait func() [][]int) {
+	received := make([][]int, readers)
+	var wg sync.WaitGroup
+	for i := 0; i < readers; i++ {
+		i, r := i, d.Subscribe()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer r.Unsubscribe()
+			for {
+				<-r.WaitChan()
+				e, err := r.TryConsume()
+				if err == eventdistributor.ErrClosed {
+					return
+				} else if err != nil {
+					t.Errorf("unexpected error from TryConsume: %v", err)
+					return
+				}
+				received[i] = append(received[i], e.id)
+			}
+		}()
+	}
+	return func() [][]int {
+		wg.Wait()
+		return received
+	}
+}
+
+// idRange returns the IDs from start up to, but not including, end
+func idRange(start, end int) []int {
+	var ids []int
+	for i := start; i < end; i++ {
+		ids = append(ids, i)
+	}
+	return ids
+}
+
+// BenchmarkOneProducerManyConsumers measures a single producer submitting to several Readers,
+// each consuming in its own goroutine
+func BenchmarkOneProducerManyConsumers(b *testing.B) {
+	for _, single := range []bool{false, true} {
+		for _, consumers := range []int{1, 4, 16} {
+			name := fmt.Sprintf("single=%v/consumers=%d", single, consumers)
+			b.Run(name, func(b *testing.B) {
+				var options eventdistributor.Options[MyEvent]
+				if single {
+					options.WithSingleProducer()
+				}
+				d := eventdistributor.New(options)
+
+				var wg sync.WaitGroup
+				for i := 0; i < consumers; i++ {
+					r := d.Subscribe()
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						defer r.Unsubscribe()
+						for {
+							<-r.WaitChan()
+							if _, err := r.TryConsume(); err == eventdistributor.ErrClosed {
+								return
+							}
+						}
+					}()
+				}
+
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					d.SubmitQuiet(MyEvent{id: i})
+				}
+				d.Close()
+				wg.Wait()
+			})
+		}
+	}
+}
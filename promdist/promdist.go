@@ -0,0 +1,166 @@
+// Package promdist provides a Prometheus collector for the state of a Distributor.
+//
+// It's a separate module so that the core package doesn't depend on the Prometheus client.
+package promdist
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+// NewCollector returns a Collector that reports the Distributor's Stats, with labels
+// added to every metric. See (*eventdistributor.Distributor[T]).Stats().
+//
+// The metrics are:
+//
+//   - eventdistributor_buffered_events: the number of events in the buffer
+//   - eventdistributor_max_buffered_events: the largest number of events in the buffer at once
+//   - eventdistributor_readers: the number of active Readers
+//   - eventdistributor_submitted_total: the total number of events submitted
+//   - eventdistributor_fully_consumed_total: the total number of events fully consumed
+//   - eventdistributor_discarded_total: the total number of events discarded immediately, because
+//     no Reader would receive them
+//   - eventdistributor_dropped_total: the total number of events dropped
+//   - eventdistributor_deduped_total: the total number of events not buffered because an equal
+//     event already was. See (*eventdistributor.Options[T]).WithDedupPending().
+//   - eventdistributor_oldest_event_age_seconds: how long ago the oldest buffered event was
+//     submitted, according to the Distributor's Clock. It's only reported if the Distributor
+//     records timestamps and has buffered events.
+//     See (*eventdistributor.Options[T]).WithTimestamps().
+//   - eventdistributor_time_in_buffer_seconds: a histogram of how long each event was in the buffer
+//     before it was fully consumed, with prometheus.DefBuckets. It's only reported if the
+//     Distributor records timestamps.
+//     See (*eventdistributor.Distributor[T]).OnConsumedWithLatency().
+//
+// The histogram is updated by a callback added to the Distributor, which stays until the Collector
+// is closed. See (*Collector[T]).Close(). Collecting the metrics only holds the Distributor's lock while its Stats and
+// oldest event are copied.
+//
+// To collect metrics for multiple Distributors with the same registry, each must have different
+// labels.
+func NewCollector[T any](
+	d *eventdistributor.Distributor[T],
+	labels prometheus.Labels,
+) *Collector[T] {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("eventdistributor_"+name, help, nil, labels)
+	}
+
+	c := &Collector[T]{
+		d: d,
+
+		buffered:      desc("buffered_events", "Number of events in the buffer."),
+		maxBuffered:   desc("max_buffered_events", "Largest number of events in the buffer at once."),
+		readers:       desc("readers", "Number of active Readers."),
+		submitted:     desc("submitted_total", "Total number of events submitted."),
+		fullyConsumed: desc("fully_consumed_total", "Total number of events fully consumed."),
+		discarded: desc(
+			"discarded_total",
+			"Total number of events discarded when submitted, because no Reader would receive them.",
+		),
+		dropped: desc("dropped_total", "Total number of events dropped."),
+		deduped: desc(
+			"deduped_total",
+			"Total number of events not buffered because an equal event already was.",
+		),
+		oldestAge: desc(
+			"oldest_event_age_seconds",
+			"Time since the oldest buffered event was submitted.",
+		),
+	}
+
+	inBuffer := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:        "eventdistributor_time_in_buffer_seconds",
+		Help:        "Time each event was in the buffer before it was fully consumed.",
+		ConstLabels: labels,
+		Buckets:     prometheus.DefBuckets,
+	})
+	observe := func(_ T, d time.Duration) { inBuffer.Observe(d.Seconds()) }
+	if remove, ok := d.OnConsumedWithLatency(observe); ok {
+		c.inBuffer = inBuffer
+		c.removeObserver = remove
+	}
+	return c
+}
+
+// Collector is a prometheus.Collector for the state of a Distributor, created by NewCollector()
+type Collector[T any] struct {
+	d *eventdistributor.Distributor[T]
+
+	buffered      *prometheus.Desc
+	maxBuffered   *prometheus.Desc
+	readers       *prometheus.Desc
+	submitted     *prometheus.Desc
+	fullyConsumed *prometheus.Desc
+	discarded     *prometheus.Desc
+	dropped       *prometheus.Desc
+	deduped       *prometheus.Desc
+	oldestAge     *prometheus.Desc
+	// inBuffer is nil if the Distributor doesn't record timestamps
+	inBuffer prometheus.Histogram
+	// removeObserver removes the callback that updates inBuffer. It's nil if inBuffer is.
+	removeObserver func()
+}
+
+// Close removes the callback that NewCollector() added to the Distributor, so that the Collector
+// can be garbage collected once it's unregistered, even if the Distributor isn't. The Collector can
+// still be collected after Close, but the time_in_buffer histogram no longer changes.
+//
+// Calling Close more than once has no effect.
+//
+// Close is thread-safe.
+func (c *Collector[T]) Close() {
+	if c.removeObserver != nil {
+		c.removeObserver()
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector[T]) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.buffered
+	ch <- c.maxBuffered
+	ch <- c.readers
+	ch <- c.submitted
+	ch <- c.fullyConsumed
+	ch <- c.discarded
+	ch <- c.dropped
+	ch <- c.deduped
+	ch <- c.oldestAge
+	if c.inBuffer != nil {
+		c.inBuffer.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector[T]) Collect(ch chan<- prometheus.Metric) {
+	stats := c.d.Stats()
+	gauge := func(desc *prometheus.Desc, value int) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(value))
+	}
+	counter := func(desc *prometheus.Desc, value int64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(value))
+	}
+
+	gauge(c.buffered, stats.Bufsize)
+	gauge(c.maxBuffered, stats.MaxBufsize)
+	gauge(c.readers, stats.Readers)
+	counter(c.submitted, stats.Submitted)
+	counter(c.fullyConsumed, stats.FullyConsumed)
+	counter(c.discarded, stats.Discarded)
+	counter(c.dropped, stats.Dropped)
+	counter(c.deduped, stats.Deduped)
+
+	// The snapshot is taken separately from the Stats, so the oldest event may have changed in the
+	// meantime. That's fine for a gauge.
+	oldest := c.d.Snapshot(1).Oldest
+	if len(oldest) != 0 && !oldest[0].Timestamp.IsZero() {
+		age := c.d.Clock().Now().Sub(oldest[0].Timestamp).Seconds()
+		ch <- prometheus.MustNewConstMetric(c.oldestAge, prometheus.GaugeValue, age)
+	}
+	if c.inBuffer != nil {
+		c.inBuffer.Collect(ch)
+	}
+}
@@ -0,0 +1,184 @@
+package promdist_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+	"github.com/sharnoff/eventdistributor/promdist"
+)
+
+func TestCollector(t *testing.T) {
+	d := eventdistributor.New[int]()
+	c := promdist.NewCollector(d, prometheus.Labels{"name": "test"})
+
+	d.Submit(0)
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(1)
+	d.Submit(2)
+	r.Consume()
+
+	expected := `
+# HELP eventdistributor_buffered_events Number of events in the buffer.
+# TYPE eventdistributor_buffered_events gauge
+eventdistributor_buffered_events{name="test"} 1
+# HELP eventdistributor_deduped_total Total number of events not buffered because an equal event already was.
+# TYPE eventdistributor_deduped_total counter
+eventdistributor_deduped_total{name="test"} 0
+# HELP eventdistributor_discarded_total Total number of events discarded when submitted, because no Reader would receive them.
+# TYPE eventdistributor_discarded_total counter
+eventdistributor_discarded_total{name="test"} 1
+# HELP eventdistributor_dropped_total Total number of events dropped.
+# TYPE eventdistributor_dropped_total counter
+eventdistributor_dropped_total{name="test"} 0
+# HELP eventdistributor_fully_consumed_total Total number of events fully consumed.
+# TYPE eventdistributor_fully_consumed_total counter
+eventdistributor_fully_consumed_total{name="test"} 2
+# HELP eventdistributor_max_buffered_events Largest number of events in the buffer at once.
+# TYPE eventdistributor_max_buffered_events gauge
+eventdistributor_max_buffered_events{name="test"} 2
+# HELP eventdistributor_readers Number of active Readers.
+# TYPE eventdistributor_readers gauge
+eventdistributor_readers{name="test"} 1
+# HELP eventdistributor_submitted_total Total number of events submitted.
+# TYPE eventdistributor_submitted_total counter
+eventdistributor_submitted_total{name="test"} 3
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+
+	t.Log("the age of the oldest event is only reported with timestamps")
+	var options eventdistributor.Options[int]
+	options.WithTimestamps()
+	d = eventdistributor.New(options)
+	c = promdist.NewCollector(d, nil)
+	r2 := d.Subscribe()
+	defer r2.Unsubscribe()
+	d.Submit(0)
+	require.Equal(t, 1, testutil.CollectAndCount(c, "eventdistributor_oldest_event_age_seconds"))
+}
+
+func TestCollectorTimeInBuffer(t *testing.T) {
+	t.Log("without timestamps, there's no histogram")
+	d := eventdistributor.New[int]()
+	c := promdist.NewCollector(d, nil)
+	d.Submit(0)
+	require.Equal(t, 0, testutil.CollectAndCount(c, "eventdistributor_time_in_buffer_seconds"))
+
+	t.Log("with timestamps, each fully consumed event is observed")
+	clock := &stepClock{now: time.Unix(1_700_000_000, 0)}
+	var options eventdistributor.Options[int]
+	options.WithClock(clock)
+	options.WithTimestamps()
+	d = eventdistributor.New(options)
+	c = promdist.NewCollector(d, prometheus.Labels{"name": "test"})
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(0)
+	clock.advance(500 * time.Millisecond)
+	r.Consume()
+	d.Submit(1)
+	clock.advance(2 * time.Second)
+	r.Consume()
+
+	expected := `
+# HELP eventdistributor_time_in_buffer_seconds Time each event was in the buffer before it was fully consumed.
+# TYPE eventdistributor_time_in_buffer_seconds histogram
+eventdistributor_time_in_buffer_seconds_bucket{name="test",le="0.005"} 0
+eventdistributor_time_in_buffer_seconds_bucket{name="test",le="0.01"} 0
+eventdistributor_time_in_buffer_seconds_bucket{name="test",le="0.025"} 0
+eventdistributor_time_in_buffer_seconds_bucket{name="test",le="0.05"} 0
+eventdistributor_time_in_buffer_seconds_bucket{name="test",le="0.1"} 0
+eventdistributor_time_in_buffer_seconds_bucket{name="test",le="0.25"} 0
+eventdistributor_time_in_buffer_seconds_bucket{name="test",le="0.5"} 1
+eventdistributor_time_in_buffer_seconds_bucket{name="test",le="1"} 1
+eventdistributor_time_in_buffer_seconds_bucket{name="test",le="2.5"} 2
+eventdistributor_time_in_buffer_seconds_bucket{name="test",le="5"} 2
+eventdistributor_time_in_buffer_seconds_bucket{name="test",le="10"} 2
+eventdistributor_time_in_buffer_seconds_bucket{name="test",le="+Inf"} 2
+eventdistributor_time_in_buffer_seconds_sum{name="test"} 2.5
+eventdistributor_time_in_buffer_seconds_count{name="test"} 2
+`
+	err := testutil.CollectAndCompare(
+		c,
+		strings.NewReader(expected),
+		"eventdistributor_time_in_buffer_seconds",
+	)
+	require.NoError(t, err)
+
+	t.Log("once closed, events are no longer observed")
+	c.Close()
+	d.Submit(2)
+	clock.advance(time.Second)
+	r.Consume()
+	err = testutil.CollectAndCompare(
+		c,
+		strings.NewReader(expected),
+		"eventdistributor_time_in_buffer_seconds",
+	)
+	require.NoError(t, err)
+}
+
+func TestCollectorOldestAge(t *testing.T) {
+	clock := &stepClock{now: time.Unix(1_700_000_000, 0)}
+	var options eventdistributor.Options[int]
+	options.WithClock(clock)
+	options.WithTimestamps()
+	d := eventdistributor.New(options)
+	c := promdist.NewCollector(d, prometheus.Labels{"name": "test"})
+	defer c.Close()
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(0)
+	clock.advance(time.Second)
+	d.Submit(1)
+	clock.advance(2 * time.Second)
+
+	t.Log("the age is measured with the Distributor's Clock")
+	expected := `
+# HELP eventdistributor_oldest_event_age_seconds Time since the oldest buffered event was submitted.
+# TYPE eventdistributor_oldest_event_age_seconds gauge
+eventdistributor_oldest_event_age_seconds{name="test"} 3
+`
+	err := testutil.CollectAndCompare(
+		c,
+		strings.NewReader(expected),
+		"eventdistributor_oldest_event_age_seconds",
+	)
+	require.NoError(t, err)
+}
+
+// stepClock is an eventdistributor.Clock that only moves forward when advanced. Its timers never
+// fire.
+type stepClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *stepClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *stepClock) AfterFunc(time.Duration, func()) eventdistributor.Timer {
+	return stoppedTimer{}
+}
+
+func (c *stepClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type stoppedTimer struct{}
+
+func (stoppedTimer) Stop() bool { return false }
@@ -0,0 +1,125 @@
+package eventdistributor
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrStopAndUnconsume can be returned by the function passed to (*Reader[T]).Range() to stop
+// without consuming the event it was called with, so that the Reader receives it again.
+var ErrStopAndUnconsume = errors.New("stop and unconsume the event")
+
+// Range calls fn with each event received by the Reader, in order, until ctx is canceled, the
+// Distributor is closed, or fn returns an error. It is the loop that would otherwise be written by
+// hand around WaitChan() and TryConsume(), called from the caller's own goroutine.
+//
+// If fn returns an error, Range returns it, and the event that fn was called with counts as
+// consumed. The exception is ErrStopAndUnconsume: the event is put back, so that it is received
+// again, and Range returns nil. The event is kept in the buffer while fn is called, so that it can
+// be put back. Readers created by SubscribeLIFO can't put events back, and neither can any Reader
+// for events it receives before the buffer - like those loaded from the Archiver by
+// SubscribeFrom(), or retained by WithRetainLast - so for them, the event always counts as
+// consumed.
+//
+// For Readers in ack mode, each event is acked once fn returns nil, and nacked if fn returns
+// ErrStopAndUnconsume. If fn returns any other error, the event remains in flight. See
+// (*Distributor[T]).SubscribeAcked().
+//
+// Range returns ctx.Err() if ctx is canceled, and nil if the Distributor is closed. If the Reader
+// expires, Range returns ErrExpired.
+//
+// Range never unsubscribes the Reader - that remains up to the caller - so it's safe to call Range
+// again after it returns. It must not be called on the same Reader from multiple goroutines at
+// once, or while another goroutine waits on the Reader's Signal, because it waits with the same
+// channel. Unlike a loop around WaitChan(), waiting doesn't allocate. See (*Reader[T]).Signal().
+func (r *Reader[T]) Range(ctx context.Context, fn func(T) error) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Range is the only one waiting on the Reader, so it can reuse the same channel for every
+		// wait.
+		wait := r.waitSignal()
+		select {
+		case <-ctx.Done():
+			r.disarmSignal()
+			return ctx.Err()
+		case <-wait:
+		}
+
+		value, seq, unpin, err := r.rangeConsume()
+		if err == ErrNoEvent {
+			continue
+		} else if err == ErrClosed {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		err = fn(value)
+		r.rangeFinish(seq, err, unpin != nil)
+		if unpin != nil {
+			unpin()
+		}
+
+		if err == ErrStopAndUnconsume {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// rangeConsume consumes the next event for Range, pinning it if it can be put back. If it can't,
+// unpin is nil.
+func (r *Reader[T]) rangeConsume() (_ T, seq int64, unpin func(), _ error) {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	var zero T
+	if err := r.prepare(); err != nil {
+		return zero, 0, nil, err
+	} else if !r.hasPending() && !r.hasQueued() {
+		return zero, 0, nil, ErrNoEvent
+	}
+
+	// In ack mode, the event is held while it's in flight. Otherwise, pinning it stops it from
+	// being removed when every Reader has consumed it. Events from the backfill aren't in the
+	// buffer, so they can't be pinned.
+	if r.acks == nil && r.lifo == nil && !r.hasBackfill() {
+		idx := int(r.position - r.d.basePosition)
+		unpin = r.d.pin(r.d.buf[idx].seq, idx)
+	}
+
+	value, seq := r.deliver()
+	return value, seq, unpin, nil
+}
+
+// rangeFinish acks, nacks, or puts back the event with the sequence number seq, as appropriate
+// after fn returned err. The event is only put back if it was pinned. See Range().
+func (r *Reader[T]) rangeFinish(seq int64, err error, pinned bool) {
+	if r.acks != nil {
+		if err == nil {
+			_ = r.Ack(seq)
+		} else if err == ErrStopAndUnconsume {
+			_ = r.Nack(seq)
+		}
+		return
+	} else if err != ErrStopAndUnconsume || !pinned {
+		return
+	}
+
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if r.d.closed || r.checkReleased() != nil {
+		return
+	}
+
+	// The event was pinned, so it's still buffered unless it was explicitly removed.
+	idx := int(r.position-r.d.basePosition) - 1
+	if idx >= 0 && r.d.buf[idx].seq == seq {
+		r.moveTo(r.position - 1)
+	}
+}
@@ -0,0 +1,147 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestRange(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	for i := 0; i < 4; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+
+	t.Log("errors from fn are returned verbatim, and the event counts as consumed")
+	errStop := errors.New("stop")
+	var ids []int
+	err := r.Range(context.Background(), func(e MyEvent) error {
+		ids = append(ids, e.id)
+		if e.id == 1 {
+			return errStop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, []int{0, 1}, ids)
+
+	t.Log("ErrStopAndUnconsume puts the event back, even if no other Reader holds it")
+	ids = nil
+	err = r.Range(context.Background(), func(e MyEvent) error {
+		ids = append(ids, e.id)
+		return eventdistributor.ErrStopAndUnconsume
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{2}, ids)
+	require.Equal(t, []int{2, 3}, drainIDs(&r))
+
+	t.Log("Range stops when the context is canceled")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = r.Range(ctx, func(MyEvent) error {
+		t.Fatal("unexpected event")
+		return nil
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	t.Log("Range returns nil when the Distributor is closed")
+	done := make(chan error)
+	go func() {
+		done <- r.Range(context.Background(), func(MyEvent) error { return nil })
+	}()
+	d.Close()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Range to return")
+	}
+}
+
+func TestRangeAcked(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	r := d.SubscribeAcked()
+	defer r.Unsubscribe()
+
+	allConsumed := d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 1})
+
+	t.Log("events are nacked by ErrStopAndUnconsume and acked by success")
+	var ids []int
+	err := r.Range(context.Background(), func(e MyEvent) error {
+		ids = append(ids, e.id)
+		return eventdistributor.ErrStopAndUnconsume
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{0}, ids)
+	require.Equal(t, 0, r.InFlight())
+	nowNotReady(t, allConsumed)
+
+	ids = nil
+	err = r.Range(context.Background(), func(e MyEvent) error {
+		ids = append(ids, e.id)
+		if len(ids) == 1 {
+			return nil
+		}
+		return eventdistributor.ErrStopAndUnconsume
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1}, ids)
+	nowReady(t, allConsumed)
+	require.Equal(t, 0, r.InFlight())
+}
+
+func TestRangeBackfill(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithRetainLast()
+	a := &memArchiver{}
+	options.WithArchiver(a)
+	d := eventdistributor.New(options)
+
+	other := d.Subscribe()
+	defer other.Unsubscribe()
+	d.Submit(MyEvent{id: 0})
+
+	t.Log("the retained event is received first, and can't be put back")
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(MyEvent{id: 1})
+
+	var ids []int
+	err := r.Range(context.Background(), func(e MyEvent) error {
+		ids = append(ids, e.id)
+		return eventdistributor.ErrStopAndUnconsume
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{0}, ids)
+	require.Equal(t, []int{1}, drainIDs(&r))
+	require.Equal(t, []int{0, 1}, drainIDs(&other))
+
+	t.Log("archived events are received before buffered ones")
+	d.Submit(MyEvent{id: 2})
+	from, err := d.SubscribeFrom(0)
+	require.NoError(t, err)
+	defer from.Unsubscribe()
+
+	errStop := errors.New("stop")
+	ids = nil
+	err = from.Range(context.Background(), func(e MyEvent) error {
+		ids = append(ids, e.id)
+		if e.id == 2 {
+			return errStop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, []int{0, 1, 2}, ids)
+}
@@ -0,0 +1,17 @@
+module github.com/sharnoff/eventdistributor/ratelimit
+
+go 1.20
+
+require (
+	github.com/sharnoff/eventdistributor v0.0.0
+	github.com/stretchr/testify v1.9.0
+	golang.org/x/time v0.5.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/sharnoff/eventdistributor => ../
@@ -0,0 +1,26 @@
+// Package ratelimit provides submit limiting for eventdistributor using golang.org/x/time/rate.
+//
+// It's a separate module so that the core package doesn't depend on golang.org/x/time.
+package ratelimit
+
+import (
+	"golang.org/x/time/rate"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+// *rate.Limiter already implements eventdistributor.Limiter; this is checked here so that any
+// change to either breaks the build.
+var _ eventdistributor.Limiter = (*rate.Limiter)(nil)
+
+// WithSubmitLimiter sets l as the submit limiter for the Distributor. It is equivalent to
+// o.WithSubmitLimiter(l, policy).
+//
+// The same *rate.Limiter may be shared between Distributors, to limit their combined rate.
+func WithSubmitLimiter[T any](
+	o *eventdistributor.Options[T],
+	l *rate.Limiter,
+	policy eventdistributor.ThrottlePolicy,
+) {
+	o.WithSubmitLimiter(l, policy)
+}
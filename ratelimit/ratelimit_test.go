@@ -0,0 +1,32 @@
+package ratelimit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/sharnoff/eventdistributor"
+	"github.com/sharnoff/eventdistributor/ratelimit"
+)
+
+func TestWithSubmitLimiter(t *testing.T) {
+	limiter := rate.NewLimiter(rate.Every(1<<62), 2)
+
+	var options eventdistributor.Options[int]
+	ratelimit.WithSubmitLimiter(&options, limiter, eventdistributor.ThrottleError)
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	for i := 0; i < 2; i++ {
+		_, err := d.SubmitChecked(i)
+		require.NoError(t, err)
+	}
+	_, err := d.SubmitChecked(2)
+	require.ErrorIs(t, err, eventdistributor.ErrThrottled)
+
+	require.Equal(t, 0, r.Consume())
+	require.Equal(t, 1, r.Consume())
+}
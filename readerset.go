@@ -0,0 +1,66 @@
+package eventdistributor
+
+// ReaderSet is a set of Readers of the same Distributor, created together by
+// (*Distributor[T]).SubscribeN().
+type ReaderSet[T any] []Reader[T]
+
+// SubscribeN creates n new Readers at once, all starting at the same position: each of them
+// receives exactly the same events, no matter what is submitted concurrently. Calling Subscribe() n
+// times doesn't guarantee that, because events may be submitted between the calls.
+//
+// Each Reader is otherwise the same as one created by Subscribe(). The Readers can be unsubscribed
+// separately, or together with (ReaderSet[T]).Unsubscribe().
+//
+// SubscribeN panics if n is not positive.
+//
+// SubscribeN is thread-safe.
+func (d *Distributor[T]) SubscribeN(n int) ReaderSet[T] {
+	if n <= 0 {
+		panic("eventdistributor: SubscribeN requires n > 0")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Only the first Reader takes over the seed of a fork, so the others start where it does. See
+	// ForkAt().
+	seeded := d.seed != nil
+	readers := make(ReaderSet[T], n)
+	for i := range readers {
+		r := d.subscribe()
+		if i != 0 && seeded {
+			r.firstSeq = readers[0].firstSeq
+			r.moveTo(readers[0].position)
+		} else if d.retained != nil && !d.closed && !seeded {
+			// The retained event was already submitted, so it's received like an archived one.
+			r.backfill = []ArchivedEvent[T]{*d.retained}
+		}
+		readers[i] = r
+	}
+	return readers
+}
+
+// Unsubscribe unsubscribes every Reader in the set at once, returning the number of them that were
+// unsubscribed by this call - i.e., that weren't already unsubscribed. For more information, see
+// (*Reader[T]).Unsubscribe().
+//
+// Every Reader in the set must belong to the same Distributor.
+//
+// Unsubscribe is thread-safe.
+func (s ReaderSet[T]) Unsubscribe() int {
+	if len(s) == 0 {
+		return 0
+	}
+
+	d := s[0].d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	unsubscribed := 0
+	for _, r := range s {
+		if r.unsubscribe() {
+			unsubscribed += 1
+		}
+	}
+	return unsubscribed
+}
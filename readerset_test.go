@@ -0,0 +1,77 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSubscribeN(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	stop := make(chan struct{})
+	last := make(chan int)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				last <- i - 1
+				return
+			default:
+				d.Submit(MyEvent{id: i})
+			}
+		}
+	}()
+
+	t.Log("every Reader receives the same events, even while events are being submitted")
+	readers := d.SubscribeN(3)
+	require.Len(t, readers, 3)
+	close(stop)
+	lastID := <-last
+
+	first := drainIDs(&readers[0])
+	if len(first) != 0 {
+		require.Equal(t, lastID, first[len(first)-1])
+	}
+	for i := range readers[1:] {
+		require.Equal(t, first, drainIDs(&readers[1+i]))
+	}
+
+	t.Log("unsubscribing the set releases the events held for every Reader")
+	done := d.Submit(MyEvent{id: -1})
+	require.Equal(t, -1, readers[0].Consume().id)
+	nowNotReady(t, done)
+	require.True(t, readers[1].Unsubscribe())
+	require.Equal(t, 2, readers.Unsubscribe())
+	require.Equal(t, 0, readers.Unsubscribe())
+	nowReady(t, done)
+}
+
+func TestSubscribeNFork(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 1})
+
+	fork, stop, err := d.ForkAt(0, false)
+	require.NoError(t, err)
+	defer stop()
+
+	t.Log("every Reader of a fork receives the copied events")
+	readers := fork.SubscribeN(2)
+	defer readers.Unsubscribe()
+	fork.Submit(MyEvent{id: 2})
+	require.Equal(t, []int{0, 1, 2}, drainIDs(&readers[0]))
+	require.Equal(t, []int{0, 1, 2}, drainIDs(&readers[1]))
+}
+
+func TestSubscribeNRequiresPositiveCount(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	require.PanicsWithValue(t, "eventdistributor: SubscribeN requires n > 0", func() {
+		d.SubscribeN(0)
+	})
+	require.Panics(t, func() { d.SubscribeN(-1) })
+}
@@ -0,0 +1,74 @@
+package eventdistributor
+
+// ReplayDone returns a channel that is closed once the Reader has consumed every event that it is
+// replaying - i.e., every event it is positioned before that was submitted before it was
+// positioned there. After that, the Reader is only seeing live events.
+//
+// Readers created by Subscribe() start at the live edge, so the channel is already closed.
+// Rewind() and SeekTo() can move a Reader back into events it has already seen; the replay then
+// ends when the Reader returns to the position it moved back from. Events submitted during a
+// replay are live, and are delivered after the replay ends.
+//
+// The channel is also closed if the Reader is unsubscribed or the Distributor is closed.
+//
+// ReplayDone is thread-safe.
+func (r *Reader[T]) ReplayDone() <-chan struct{} {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if r.replay == nil || r.d.closed {
+		return closedChannel
+	}
+
+	if r.replay.done == nil {
+		r.replay.done = make(chan struct{})
+	}
+	return r.replay.done
+}
+
+// readerReplay tracks the end of a Reader's replay. See ReplayDone().
+type readerReplay struct {
+	// end is the position at which the replay ends
+	end int64
+	// done, if not nil, is the channel returned by ReplayDone()
+	done chan struct{}
+}
+
+// startReplay marks the Reader as replaying events until it reaches the position end, extending
+// any current replay.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) startReplay(end int64) {
+	if r.position >= end {
+		return
+	}
+
+	if r.replay == nil {
+		r.replay = &readerReplay{end: end, done: nil}
+	} else if end > r.replay.end {
+		r.replay.end = end
+	}
+}
+
+// checkReplayDone ends the Reader's replay if it has reached the end.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) checkReplayDone() {
+	if r.replay != nil && r.position >= r.replay.end && !r.hasBackfill() {
+		r.finishReplay()
+	}
+}
+
+// finishReplay ends the Reader's replay, if there is one.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) finishReplay() {
+	if r.replay == nil {
+		return
+	}
+
+	if r.replay.done != nil {
+		close(r.replay.done)
+	}
+	r.replay = nil
+}
@@ -0,0 +1,81 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestReplayDone(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	slow := d.Subscribe()
+	defer slow.Unsubscribe()
+
+	t.Log("a new reader is live")
+	nowReady(t, r.ReplayDone())
+
+	d.Submit(MyEvent{id: 1})
+	d.Submit(MyEvent{id: 2})
+	require.Equal(t, []int{1, 2}, drainIDs(&r))
+
+	t.Log("after rewinding, the reader is replaying")
+	n, err := r.Rewind(2)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	done := r.ReplayDone()
+	nowNotReady(t, done)
+
+	t.Log("events submitted during the replay are live, delivered after the boundary")
+	d.Submit(MyEvent{id: 3})
+	require.Equal(t, 1, r.Consume().id)
+	nowNotReady(t, done)
+	require.Equal(t, 2, r.Consume().id)
+	nowReady(t, done)
+	nowReady(t, r.ReplayDone())
+	require.Equal(t, 3, r.Consume().id)
+}
+
+func TestReplayDoneFilterInPlace(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	slow := d.Subscribe()
+	defer slow.Unsubscribe()
+
+	for id := 1; id <= 3; id++ {
+		d.Submit(MyEvent{id: id})
+	}
+	require.Equal(t, []int{1, 2, 3}, drainIDs(&r))
+	_, err := r.Rewind(3)
+	require.NoError(t, err)
+	done := r.ReplayDone()
+
+	t.Log("removing the remaining replayed events ends the replay")
+	require.Equal(t, 1, r.Consume().id)
+	d.FilterInPlace(func(e MyEvent) bool { return e.id != 1 })
+	nowReady(t, done)
+}
+
+func TestReplayDoneUnsubscribe(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	r := d.Subscribe()
+	slow := d.Subscribe()
+	defer slow.Unsubscribe()
+
+	d.Submit(MyEvent{id: 1})
+	r.Consume()
+	_, err := r.Rewind(1)
+	require.NoError(t, err)
+	done := r.ReplayDone()
+	nowNotReady(t, done)
+
+	r.Unsubscribe()
+	nowReady(t, done)
+}
@@ -0,0 +1,43 @@
+package eventdistributor
+
+import (
+	"time"
+)
+
+// WithRetainLast makes the Distributor retain the most recently submitted event, even after it has
+// been fully consumed, so that every Reader created by Subscribe() first receives the retained
+// event, followed by any events submitted afterwards. This is useful when events describe the
+// current value of something, and new Readers need the current value immediately.
+//
+// The retained event is replaced whenever an event is added to the buffer, and can be removed with
+// ClearRetained(). Events that are dropped when submitted - for example, because the Distributor is
+// sealed - are not retained.
+//
+// Receiving the retained event doesn't affect when it's fully consumed, so OnFullyConsumed
+// callbacks are called for it only once, as usual.
+func (o *Options[T]) WithRetainLast() {
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.retainLast = true
+	})
+}
+
+// ClearRetained removes the event retained by WithRetainLast, if there is one, so that Readers
+// created afterwards only receive events submitted after they were created. Readers that were
+// created before ClearRetained still receive the retained event, if they haven't already.
+//
+// ClearRetained is thread-safe.
+func (d *Distributor[T]) ClearRetained() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.retained = nil
+}
+
+// retain records the event as the one retained for new Readers, if WithRetainLast is set.
+//
+// d.mu must be held.
+func (d *Distributor[T]) retain(seq int64, timestamp time.Time, value T) {
+	if d.retainLast {
+		d.retained = &ArchivedEvent[T]{Seq: seq, Timestamp: timestamp, Value: value}
+	}
+}
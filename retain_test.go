@@ -0,0 +1,49 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestWithRetainLast(t *testing.T) {
+	var fullyConsumed []int
+	var options eventdistributor.Options[MyEvent]
+	options.WithRetainLast()
+	options.OnFullyConsumed(func(e MyEvent) { fullyConsumed = append(fullyConsumed, e.id) })
+	d := eventdistributor.New(options)
+
+	t.Log("without a retained event, new Readers have nothing to receive")
+	r1 := d.Subscribe()
+	defer r1.Unsubscribe()
+	notReady(t, r1)
+
+	t.Log("new Readers receive the retained event, even after it's fully consumed")
+	d.Submit(MyEvent{id: 1})
+	d.Submit(MyEvent{id: 2})
+	require.Equal(t, []int{1, 2}, drainIDs(&r1))
+	require.Equal(t, []int{1, 2}, fullyConsumed)
+
+	r2 := d.Subscribe()
+	defer r2.Unsubscribe()
+	ready(t, r2)
+	require.Equal(t, []int{2}, drainIDs(&r2))
+
+	t.Log("the retained event is replaced by each submitted event")
+	d.Submit(MyEvent{id: 3})
+	r3 := d.Subscribe()
+	defer r3.Unsubscribe()
+	d.Submit(MyEvent{id: 4})
+	require.Equal(t, []int{3, 4}, drainIDs(&r3))
+	require.Equal(t, []int{3, 4}, drainIDs(&r2))
+	require.Equal(t, []int{3, 4}, drainIDs(&r1))
+	require.Equal(t, []int{1, 2, 3, 4}, fullyConsumed)
+
+	t.Log("ClearRetained removes the retained event")
+	d.ClearRetained()
+	r4 := d.Subscribe()
+	defer r4.Unsubscribe()
+	notReady(t, r4)
+}
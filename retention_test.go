@@ -0,0 +1,88 @@
+package eventdistributor_test
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+// payload is a large event value, for checking that values released from the buffer can be
+// garbage collected
+type payload struct {
+	id   int
+	data [1 << 16]byte
+}
+
+// submitTracked submits a new payload, arranging for collected to be incremented once it's garbage
+// collected
+//
+//go:noinline
+func submitTracked(d *eventdistributor.Distributor[*payload], id int, collected *atomic.Int32) {
+	p := &payload{id: id}
+	runtime.SetFinalizer(p, func(*payload) { collected.Add(1) })
+	d.SubmitQuiet(p)
+}
+
+//go:noinline
+func consumeIDs(r *eventdistributor.Reader[*payload], n int) []int {
+	var ids []int
+	for i := 0; i < n; i++ {
+		ids = append(ids, r.Consume().id)
+	}
+	return ids
+}
+
+func waitCollected(t *testing.T, collected *atomic.Int32, n int32) {
+	require.Eventually(t, func() bool {
+		runtime.GC()
+		return collected.Load() == n
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestReleasedValuesAreCollectible(t *testing.T) {
+	var collected atomic.Int32
+	d := eventdistributor.New[*payload]()
+	fast := d.Subscribe()
+	defer fast.Unsubscribe()
+	slow := d.Subscribe()
+	defer slow.Unsubscribe()
+
+	for i := 0; i < 4; i++ {
+		submitTracked(d, i, &collected)
+	}
+
+	t.Log("fully consumed events are collectible while later events keep the buffer alive")
+	require.Equal(t, []int{0, 1, 2}, consumeIDs(&fast, 3))
+	require.Equal(t, []int{0, 1}, consumeIDs(&slow, 2))
+	require.Equal(t, 2, d.Stats().Bufsize)
+	waitCollected(t, &collected, 2)
+
+	t.Log("removed events are collectible too")
+	d.FilterInPlace(func(p *payload) bool { return p.id == 2 })
+	require.Equal(t, 1, d.Stats().Bufsize)
+	waitCollected(t, &collected, 3)
+
+	require.Equal(t, []int{3}, consumeIDs(&fast, 1))
+	require.Equal(t, []int{3}, consumeIDs(&slow, 1))
+	waitCollected(t, &collected, 4)
+}
+
+func TestOverflowedValuesAreCollectible(t *testing.T) {
+	var collected atomic.Int32
+	d, err := eventdistributor.NewFixed[*payload](2, eventdistributor.OverflowDropOldest)
+	require.NoError(t, err)
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	for i := 0; i < 5; i++ {
+		submitTracked(d, i, &collected)
+	}
+	require.Equal(t, 2, d.Stats().Bufsize)
+	waitCollected(t, &collected, 3)
+	require.Equal(t, []int{3, 4}, consumeIDs(&r, 2))
+}
@@ -0,0 +1,94 @@
+package eventdistributor
+
+import (
+	"math"
+	"time"
+)
+
+// WithRetry sets how a handler retries events that fail - see AddHandlerFunc(). Each event is
+// attempted at most maxAttempts times, including the first attempt, waiting initial before the
+// first retry, and multiplying the wait by multiplier before each one after that. Waits use the
+// Distributor's Clock - see (*Options[T]).WithClock().
+//
+// By default, failed events are not retried. WithRetry panics if initial is negative, if
+// multiplier is less than 1, or if maxAttempts is not positive. If WithRetry is set more than once,
+// the last call takes precedence.
+func (o *HandlerOptions[T]) WithRetry(initial time.Duration, multiplier float64, maxAttempts int) {
+	if initial < 0 {
+		panic("eventdistributor: WithRetry requires initial >= 0")
+	} else if !(multiplier >= 1) {
+		panic("eventdistributor: WithRetry requires multiplier >= 1")
+	} else if maxAttempts <= 0 {
+		panic("eventdistributor: WithRetry requires maxAttempts > 0")
+	}
+
+	o.modify = append(o.modify, func(h *managedHandler[T]) {
+		h.retry = handlerRetry{initial: initial, multiplier: multiplier, maxAttempts: maxAttempts}
+	})
+}
+
+// OnFailed adds a callback to the options that will be called with each event that the handler
+// gives up on, once its last attempt has failed, along with the error from that attempt.
+//
+// The callback is called by the handler's goroutine, before it moves on to the next event. Events
+// that are abandoned because the handler was stopped are not reported.
+func (o *HandlerOptions[T]) OnFailed(callback func(item T, err error)) {
+	o.modify = append(o.modify, func(h *managedHandler[T]) {
+		h.onFailed = append(h.onFailed, callback)
+	})
+}
+
+// handlerRetry is the retry policy for a managed handler. See (*HandlerOptions[T]).WithRetry().
+type handlerRetry struct {
+	initial     time.Duration
+	multiplier  float64
+	maxAttempts int
+}
+
+// handle calls fn with value until it succeeds, the handler's retry policy gives up on it, or the
+// handler is stopped, reporting the event to onFailed if it was given up on
+func (h *managedHandler[T]) handle(value T) {
+	err := h.fn(h.ctx, value)
+	wait := h.retry.initial
+	for attempt := 1; err != nil && attempt < h.retry.maxAttempts; attempt++ {
+		if !h.sleep(wait) {
+			return
+		}
+		if next := float64(wait) * h.retry.multiplier; next < math.MaxInt64 {
+			wait = time.Duration(next)
+		}
+		err = h.fn(h.ctx, value)
+	}
+
+	if err != nil && h.ctx.Err() == nil {
+		for _, f := range h.onFailed {
+			f(value, err)
+		}
+	}
+}
+
+// sleep waits for the duration on the Distributor's Clock, returning false if the handler was
+// stopped or halted first
+func (h *managedHandler[T]) sleep(wait time.Duration) bool {
+	select {
+	case <-h.ctx.Done():
+		return false
+	case <-h.halt:
+		return false
+	default:
+		if wait == 0 {
+			return true
+		}
+	}
+
+	woken := make(chan struct{})
+	timer := h.r.d.getClock().AfterFunc(wait, func() { close(woken) })
+	select {
+	case <-woken:
+		return true
+	case <-h.ctx.Done():
+	case <-h.halt:
+	}
+	timer.Stop()
+	return false
+}
@@ -0,0 +1,131 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+// attempt is a call to a handler created by AddHandlerFunc
+type attempt struct {
+	id     int
+	result chan error
+}
+
+// addAttemptHandler adds a handler that sends each attempt to the returned channel, and returns the
+// error that's sent back for it
+func addAttemptHandler(
+	d *eventdistributor.Distributor[MyEvent],
+	options ...eventdistributor.HandlerOptions[MyEvent],
+) (<-chan attempt, func()) {
+	attempts := make(chan attempt)
+	stop := d.AddHandlerFunc(func(_ context.Context, e MyEvent) error {
+		a := attempt{id: e.id, result: make(chan error)}
+		attempts <- a
+		return <-a.result
+	}, options...)
+	return attempts, stop
+}
+
+func waitForTimer(t *testing.T, clock *fakeClock) {
+	require.Eventually(t, func() bool { return clock.Pending() == 1 }, time.Second, time.Millisecond)
+}
+
+func TestAddHandlerFuncRetry(t *testing.T) {
+	clock := newFakeClock()
+	d := eventdistributor.New(eventdistributor.WithClock[MyEvent](clock))
+
+	failed := make(chan error, 1)
+	attempts, stop := addAttemptHandler(d,
+		eventdistributor.WithRetry[MyEvent](time.Second, 2, 3),
+		eventdistributor.WithOnFailed(func(e MyEvent, err error) { failed <- err }),
+	)
+	defer stop()
+
+	d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 1})
+
+	t.Log("failed events are retried with backoff, before later events are handled")
+	a := <-attempts
+	require.Equal(t, 0, a.id)
+	a.result <- errors.New("first")
+	waitForTimer(t, clock)
+	clock.Advance(time.Second)
+	a = <-attempts
+	require.Equal(t, 0, a.id)
+	a.result <- errors.New("second")
+
+	waitForTimer(t, clock)
+	clock.Advance(time.Second)
+	select {
+	case a := <-attempts:
+		t.Fatalf("event %d was attempted before the backoff elapsed", a.id)
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.Advance(time.Second)
+	a = <-attempts
+	require.Equal(t, 0, a.id)
+
+	t.Log("once every attempt has failed, the event is reported with the last error")
+	a.result <- errors.New("third")
+	require.EqualError(t, <-failed, "third")
+
+	t.Log("events that succeed aren't retried")
+	a = <-attempts
+	require.Equal(t, 1, a.id)
+	a.result <- nil
+	d.Submit(MyEvent{id: 2})
+	a = <-attempts
+	require.Equal(t, 2, a.id)
+	a.result <- nil
+	require.Equal(t, 0, clock.Pending())
+	require.Empty(t, failed)
+}
+
+func TestAddHandlerFuncWithoutRetry(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	failed := make(chan string)
+	var options eventdistributor.HandlerOptions[MyEvent]
+	options.OnFailed(func(e MyEvent, err error) { failed <- fmt.Sprintf("%d: %v", e.id, err) })
+	attempts, stop := addAttemptHandler(d, options)
+	defer stop()
+
+	t.Log("by default, failed events are reported immediately")
+	d.Submit(MyEvent{id: 0})
+	a := <-attempts
+	a.result <- errors.New("failed")
+	require.Equal(t, "0: failed", <-failed)
+}
+
+func TestAddHandlerFuncStopWhileRetrying(t *testing.T) {
+	clock := newFakeClock()
+	d := eventdistributor.New(eventdistributor.WithClock[MyEvent](clock))
+
+	attempts, stop := addAttemptHandler(d,
+		eventdistributor.WithRetry[MyEvent](time.Second, 1, 2),
+		eventdistributor.WithOnFailed(func(MyEvent, error) { t.Error("abandoned event was reported") }),
+	)
+
+	t.Log("stopping the handler interrupts the wait between attempts")
+	d.Submit(MyEvent{id: 0})
+	a := <-attempts
+	a.result <- errors.New("failed")
+	waitForTimer(t, clock)
+	stop()
+	require.Equal(t, 0, clock.Pending())
+}
+
+func TestWithRetryInvalid(t *testing.T) {
+	require.PanicsWithValue(t, "eventdistributor: WithRetry requires maxAttempts > 0", func() {
+		eventdistributor.WithRetry[MyEvent](time.Second, 2, 0)
+	})
+	require.Panics(t, func() { eventdistributor.WithRetry[MyEvent](-time.Second, 2, 1) })
+	require.Panics(t, func() { eventdistributor.WithRetry[MyEvent](time.Second, 0.5, 1) })
+}
@@ -0,0 +1,69 @@
+package eventdistributor
+
+import (
+	"errors"
+	"sort"
+)
+
+// Rewind moves the Reader back by up to n events that it has already consumed, so that they will
+// be delivered again, returning the number of events it was actually moved back by.
+//
+// Only events from after the Reader subscribed can be rewound to, and only while they are still
+// available: either still buffered, because other Readers have not yet consumed them, or kept by
+// WithHistory. Once an event has been consumed by every Reader, it is dropped from the buffer, and
+// once it is no longer among the events kept by WithHistory, it can't be returned to. If fewer than
+// n events are available, Rewind moves back as far as it can and returns the smaller count; this is
+// not an error.
+//
+// The rewound events are treated as a replay, ending when the Reader returns to its current
+// position. See ReplayDone(). Rewound events that are no longer buffered are received from the
+// history, like those for SubscribeWithHistory().
+//
+// Rewind returns an error if n is negative, if the Reader has expired, or if the Distributor is
+// closed.
+//
+// Rewind is thread-safe.
+func (r *Reader[T]) Rewind(n int) (int, error) {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if n < 0 {
+		return 0, errors.New("cannot rewind by a negative number of events")
+	} else if r.d.closed {
+		return 0, ErrClosed
+	} else if err := r.checkReleased(); err != nil {
+		return 0, err
+	}
+
+	// Only events submitted after the Reader subscribed can be rewound to, even if earlier ones
+	// are still buffered for other Readers.
+	idx := int(r.position - r.d.basePosition)
+	available := 0
+	for available < idx && r.d.buf[idx-available-1].seq >= r.firstSeq {
+		available += 1
+	}
+	if n <= available {
+		if n != 0 {
+			r.moveTo(r.position - int64(n))
+		}
+		return n, nil
+	}
+
+	// Past the start of the buffer, the Reader can only be rewound to events kept by WithHistory,
+	// before any that it has yet to receive from its backfill.
+	var kept []ArchivedEvent[T]
+	if available == idx {
+		kept = r.d.keptBeforeBuffer(r.firstSeq)
+		if r.hasBackfill() {
+			next := r.backfill[0].Seq
+			kept = kept[:sort.Search(len(kept), func(i int) bool { return kept[i].Seq >= next })]
+		}
+		if len(kept) > n-available {
+			kept = kept[len(kept)-(n-available):]
+		}
+	}
+
+	r.moveTo(r.position - int64(available))
+	r.replayKept(kept)
+	return available + len(kept), nil
+}
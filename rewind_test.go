@@ -0,0 +1,130 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestRewind(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var consumed []int
+	options.OnFullyConsumed(func(e MyEvent) {
+		consumed = append(consumed, e.id)
+	})
+	d := eventdistributor.New(options)
+
+	fast := d.Subscribe()
+	defer fast.Unsubscribe()
+	slow := d.Subscribe()
+	defer slow.Unsubscribe()
+
+	for id := 1; id <= 4; id++ {
+		d.Submit(MyEvent{id: id})
+	}
+	require.Equal(t, []int{1, 2, 3, 4}, drainIDs(&fast))
+	require.Equal(t, 1, slow.Consume().id)
+	require.Equal(t, []int{1}, consumed)
+
+	t.Log("rewinding is limited to events that are still buffered")
+	n, err := fast.Rewind(10)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+
+	t.Log("rewound events are not cleaned up before they're read again")
+	require.Equal(t, []int{2, 3, 4}, drainIDs(&slow))
+	require.Equal(t, []int{1}, consumed)
+	require.Equal(t, []int{2, 3, 4}, drainIDs(&fast))
+	require.Equal(t, []int{1, 2, 3, 4}, consumed)
+
+	t.Log("nothing is left to rewind to")
+	n, err = fast.Rewind(1)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+
+	t.Log("partial rewind")
+	d.Submit(MyEvent{id: 5})
+	d.Submit(MyEvent{id: 6})
+	require.Equal(t, []int{5, 6}, drainIDs(&fast))
+	n, err = fast.Rewind(1)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []int{6}, drainIDs(&fast))
+
+	_, err = fast.Rewind(-1)
+	require.Error(t, err)
+}
+
+func TestRewindBeforeSubscribe(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	early := d.Subscribe()
+	defer early.Unsubscribe()
+
+	d.Submit(MyEvent{id: 1})
+	late := d.Subscribe()
+	defer late.Unsubscribe()
+	d.Submit(MyEvent{id: 2})
+
+	require.Equal(t, []int{2}, drainIDs(&late))
+
+	t.Log("events from before the Reader subscribed can't be rewound to, even though they're buffered")
+	n, err := late.Rewind(2)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []int{2}, drainIDs(&late))
+	require.Equal(t, []int{1, 2}, drainIDs(&early))
+}
+
+func TestRewindHistory(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithHistory(3)
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	for id := 1; id <= 5; id++ {
+		d.Submit(MyEvent{id: id})
+	}
+	require.Equal(t, []int{1, 2, 3, 4, 5}, drainIDs(&r))
+	require.Equal(t, 0, d.Stats().Bufsize)
+
+	t.Log("events that are no longer buffered can be rewound to while they're kept")
+	n, err := r.Rewind(10)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	nowNotReady(t, r.ReplayDone())
+	require.Equal(t, []int{3, 4, 5}, drainIDs(&r))
+	nowReady(t, r.ReplayDone())
+
+	t.Log("rewinding continues from the buffer into the history")
+	slow := d.Subscribe()
+	defer slow.Unsubscribe()
+	d.Submit(MyEvent{id: 6})
+	d.Submit(MyEvent{id: 7})
+	require.Equal(t, []int{6, 7}, drainIDs(&r))
+	n, err = r.Rewind(3)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, 5, r.Consume().id)
+
+	t.Log("rewinding stops at the oldest kept event")
+	n, err = r.Rewind(2)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []int{5, 6, 7}, drainIDs(&r))
+	require.Equal(t, []int{6, 7}, drainIDs(&slow))
+
+	t.Log("kept events from before the Reader subscribed can't be rewound to")
+	late := d.Subscribe()
+	defer late.Unsubscribe()
+	d.Submit(MyEvent{id: 8})
+	require.Equal(t, []int{8}, drainIDs(&late))
+	n, err = late.Rewind(3)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []int{8}, drainIDs(&late))
+}
@@ -0,0 +1,137 @@
+package eventdistributor
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrSeqEvicted is returned by (*Reader[T]).SeekTo() when the requested sequence number is no
+// longer available to the Reader
+var ErrSeqEvicted = errors.New("sequence number no longer available")
+
+// ErrSeqInFuture is returned by (*Reader[T]).SeekTo() when the requested sequence number has not
+// yet been assigned to an event
+var ErrSeqInFuture = errors.New("sequence number not yet submitted")
+
+// SeekTo moves the Reader so that the next event it receives is the first one with a sequence
+// number of at least seq. Seeking to the sequence number of the next event that will be submitted
+// moves the Reader to the live edge. Sequence numbers are available from TryConsumeSeq() and
+// (*Distributor[T]).NextSeq().
+//
+// Seeking backwards is like Rewind(): only events that were submitted after the Reader subscribed,
+// and that are still buffered or kept by WithHistory, are available. The events in between are
+// treated as a replay. See ReplayDone(). Seeking forwards skips over the events in between, as if
+// they had been consumed.
+//
+// SeekTo returns ErrSeqEvicted if seq is before the events that are available to the Reader, and
+// ErrSeqInFuture if it is after the live edge. It also returns an error if the Reader has expired
+// or the Distributor is closed.
+//
+// SeekTo is thread-safe.
+func (r *Reader[T]) SeekTo(seq int64) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if r.d.closed {
+		return ErrClosed
+	} else if err := r.checkReleased(); err != nil {
+		return err
+	}
+
+	buf := r.d.buf
+	if seq > r.d.nextSeq {
+		return ErrSeqInFuture
+	} else if seq < r.d.nextSeq && seq < r.firstSeq {
+		return ErrSeqEvicted
+	}
+
+	// Any events the Reader had yet to receive from its backfill are either skipped over, or
+	// replaced by the kept events from seq onwards.
+	if seq == r.d.nextSeq || (len(buf) != 0 && seq >= buf[0].seq) {
+		idx := sort.Search(len(buf), func(i int) bool { return buf[i].seq >= seq })
+		r.backfill = nil
+		r.moveTo(r.d.basePosition + int64(idx))
+		r.checkReplayDone()
+		return nil
+	}
+
+	// Events before the buffer are only available if they're kept by WithHistory.
+	kept := r.d.keptBeforeBuffer(r.firstSeq)
+	if len(kept) == 0 || seq < kept[0].Seq {
+		return ErrSeqEvicted
+	}
+	r.backfill = nil
+	r.moveTo(r.d.basePosition)
+	r.replayKept(kept[sort.Search(len(kept), func(i int) bool { return kept[i].Seq >= seq }):])
+	r.checkReplayDone()
+	return nil
+}
+
+// NextSeq returns the sequence number that will be assigned to the next submitted event.
+//
+// Every event accepted by Submit is assigned the next sequence number, starting from zero - even if
+// it is immediately discarded because there are no Readers.
+//
+// NextSeq is thread-safe.
+func (d *Distributor[T]) NextSeq() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.nextSeq
+}
+
+// TryConsumeSeq is like TryConsume, but additionally returns the sequence number of the event, for
+// use with SeekTo().
+//
+// TryConsumeSeq is thread-safe.
+func (r *Reader[T]) TryConsumeSeq() (T, int64, error) {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	return r.tryConsume()
+}
+
+// moveTo moves the Reader to the given position, which must be within the buffer or at its end,
+// transferring the Reader's reference to its new event.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) moveTo(position int64) {
+	oldPosition := r.position
+	if position == oldPosition {
+		return
+	}
+
+	oldIdx := int(oldPosition - r.d.basePosition)
+	if oldIdx < len(r.d.buf) {
+		r.d.buf[oldIdx].refcount -= 1
+	} else {
+		r.d.nextRefcount -= 1
+	}
+
+	newIdx := int(position - r.d.basePosition)
+	if newIdx < len(r.d.buf) {
+		r.d.buf[newIdx].refcount += 1
+	} else {
+		r.d.nextRefcount += 1
+	}
+
+	r.position = position
+	r.publishPosition()
+	r.checkLag()
+	if position < oldPosition {
+		r.startReplay(oldPosition)
+		// The Reader may have been waiting at the end of the buffer, but now has events to receive.
+		// If it's waiting on the shared channel, the other Readers there are woken too, and wait
+		// again once they find nothing to receive.
+		if r.available() {
+			r.wakeOwn()
+			r.d.wakeShared()
+			r.endWait()
+		}
+	} else {
+		r.checkReplayDone()
+		r.checkCaughtUp()
+		r.d.wakeProducers()
+		r.d.cleanupOldEvents()
+	}
+}
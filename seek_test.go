@@ -0,0 +1,101 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSeekTo(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var consumed []int
+	options.OnFullyConsumed(func(e MyEvent) {
+		consumed = append(consumed, e.id)
+	})
+	d := eventdistributor.New(options)
+
+	slow := d.Subscribe()
+	defer slow.Unsubscribe()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	require.Equal(t, int64(0), d.NextSeq())
+	for id := 0; id < 4; id++ {
+		d.Submit(MyEvent{id: id})
+	}
+	require.Equal(t, int64(4), d.NextSeq())
+
+	e, seq, err := r.TryConsumeSeq()
+	require.NoError(t, err)
+	require.Equal(t, 0, e.id)
+	require.Equal(t, int64(0), seq)
+
+	t.Log("seeking forward skips events")
+	require.NoError(t, r.SeekTo(3))
+	require.Equal(t, []int{3}, drainIDs(&r))
+
+	t.Log("seeking backward replays events")
+	require.NoError(t, r.SeekTo(1))
+	nowNotReady(t, r.ReplayDone())
+	require.Equal(t, []int{1, 2, 3}, drainIDs(&r))
+	nowReady(t, r.ReplayDone())
+
+	t.Log("seeking to the live edge")
+	require.NoError(t, r.SeekTo(0))
+	require.NoError(t, r.SeekTo(d.NextSeq()))
+	_, err = r.TryConsume()
+	require.Equal(t, eventdistributor.ErrNoEvent, err)
+
+	require.ErrorIs(t, r.SeekTo(5), eventdistributor.ErrSeqInFuture)
+
+	t.Log("seeking forward releases events")
+	require.NoError(t, slow.SeekTo(2))
+	require.Equal(t, []int{0, 1}, consumed)
+	require.ErrorIs(t, r.SeekTo(1), eventdistributor.ErrSeqEvicted)
+	require.Equal(t, []int{2, 3}, drainIDs(&slow))
+	require.Equal(t, []int{0, 1, 2, 3}, consumed)
+}
+
+func TestSeekToHistory(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	options.WithHistory(3)
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	for id := 0; id < 5; id++ {
+		d.Submit(MyEvent{id: id})
+	}
+	require.Equal(t, []int{0, 1, 2, 3, 4}, drainIDs(&r))
+	require.Equal(t, 0, d.Stats().Bufsize)
+
+	t.Log("seeking backward is limited to the events that are kept")
+	require.ErrorIs(t, r.SeekTo(1), eventdistributor.ErrSeqEvicted)
+	require.NoError(t, r.SeekTo(3))
+	nowNotReady(t, r.ReplayDone())
+	require.Equal(t, []int{3, 4}, drainIDs(&r))
+	nowReady(t, r.ReplayDone())
+
+	t.Log("seeking forward skips kept events that haven't been received again")
+	require.NoError(t, r.SeekTo(2))
+	require.NoError(t, r.SeekTo(4))
+	require.Equal(t, []int{4}, drainIDs(&r))
+	require.NoError(t, r.SeekTo(2))
+	require.NoError(t, r.SeekTo(d.NextSeq()))
+	nowReady(t, r.ReplayDone())
+	_, err := r.TryConsume()
+	require.Equal(t, eventdistributor.ErrNoEvent, err)
+
+	t.Log("kept events are followed by those that are still buffered")
+	slow := d.Subscribe()
+	defer slow.Unsubscribe()
+	d.Submit(MyEvent{id: 5})
+	d.Submit(MyEvent{id: 6})
+	require.Equal(t, []int{5, 6}, drainIDs(&r))
+	require.NoError(t, r.SeekTo(4))
+	require.Equal(t, []int{4, 5, 6}, drainIDs(&r))
+	require.Equal(t, []int{5, 6}, drainIDs(&slow))
+}
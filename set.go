@@ -0,0 +1,264 @@
+package eventdistributor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"reflect"
+	"sync"
+)
+
+// DistributorSet is like Multi, but only keeps a Distributor for the keys that are in use, so
+// that it can handle very many keys that are mostly idle. A key's Distributor is created when it
+// is first subscribed to, and removed as soon as it has no Readers and nothing buffered, so an
+// idle key costs at most a map entry instead of a Distributor.
+//
+// Readers returned by Subscribe() are ordinary Readers of the key's Distributor, and sequence
+// numbers continue from where they left off when a key's Distributor is created again. The
+// differences from Multi follow from removing idle Distributors:
+//
+//   - Events submitted to a key without any Readers are discarded without creating a
+//     Distributor, so callbacks like OnSubmit are not called for them, they aren't retained by
+//     WithRetainLast, and they don't use up sequence numbers.
+//   - Anything else kept by an idle key's Distributor, like its Stats, the event retained by
+//     WithRetainLast, or its history, is lost when it's removed.
+//   - The Distributors themselves are not exposed, because they may be replaced at any time.
+//
+// Keys are split between a fixed number of shards by their hash, each with its own lock, so that
+// producers and subscribers for different keys rarely contend with each other.
+//
+// The zero value of a DistributorSet is valid, and has no options. A DistributorSet must not be
+// copied after first use.
+type DistributorSet[K comparable, T any] struct {
+	// shards hold the keys' Distributors, split by the hash of each key, so that operations on
+	// keys in different shards don't contend for the same lock. See keyShard.
+	shards [setShards]setShard[K, T]
+
+	// mu protects options
+	mu      sync.Mutex
+	options []Options[T]
+}
+
+// setShards is the number of shards in a DistributorSet
+const setShards = 64
+
+// setShard is one part of a DistributorSet's lock table
+type setShard[K comparable, T any] struct {
+	mu     sync.Mutex
+	active map[K]*Distributor[T]
+	// nextSeqs is the next sequence number for each key whose Distributor was removed after
+	// events were submitted to it, so that its sequence numbers continue if it's created again
+	nextSeqs map[K]int64
+}
+
+// DefaultOptions sets the options for each Distributor created afterwards, replacing any
+// previously set options. Distributors that already exist are unaffected.
+//
+// DefaultOptions is thread-safe.
+func (s *DistributorSet[K, T]) DefaultOptions(options ...Options[T]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.options = options
+}
+
+// Submit submits the value to the key's Distributor, if it has one. See (*Distributor[T]).Submit().
+//
+// If the key has no Distributor, there are no Readers to receive the event, so it is discarded,
+// and the returned channel is already closed.
+//
+// Submit is thread-safe.
+func (s *DistributorSet[K, T]) Submit(key K, value T) <-chan struct{} {
+	shard := s.keyShard(key)
+	shard.mu.Lock()
+	d, ok := shard.active[key]
+	shard.mu.Unlock()
+
+	if !ok {
+		return closedChannel
+	}
+	// If d was removed in the meantime, it has no Readers, so the event is discarded as usual.
+	return d.Submit(value)
+}
+
+// Subscribe creates a new Reader for the key, creating its Distributor if it doesn't already
+// exist. See (*Distributor[T]).Subscribe().
+//
+// Once the key has no Readers and nothing buffered - for example, once the last Reader
+// unsubscribes, or once the last event pinned by a Reader's Range() is released - its Distributor
+// is removed by whichever call made it idle.
+//
+// Subscribe is thread-safe.
+func (s *DistributorSet[K, T]) Subscribe(key K) Reader[T] {
+	shard := s.keyShard(key)
+	shard.mu.Lock()
+
+	d, ok := shard.active[key]
+	if !ok {
+		s.mu.Lock()
+		options := s.options
+		s.mu.Unlock()
+
+		d = New(options...)
+		d.nextSeq = shard.nextSeqs[key]
+		delete(shard.nextSeqs, key)
+		d.setIdle = func() { shard.removeIfIdle(key, d) }
+		if shard.active == nil {
+			shard.active = make(map[K]*Distributor[T])
+		}
+		shard.active[key] = d
+	}
+
+	d.mu.Lock()
+	r := d.subscribe()
+	// Callbacks may use the DistributorSet, so they're only run once its lock is released.
+	d.mu.unlockQuietly()
+	shard.mu.Unlock()
+	d.mu.flush()
+	return r
+}
+
+// Len returns the number of keys that currently have a Distributor.
+//
+// Len is thread-safe.
+func (s *DistributorSet[K, T]) Len() int {
+	n := 0
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		n += len(shard.active)
+		shard.mu.Unlock()
+	}
+	return n
+}
+
+// keyShard returns the shard that holds the key's Distributor
+func (s *DistributorSet[K, T]) keyShard(key K) *setShard[K, T] {
+	return &s.shards[hashKey(key)%setShards]
+}
+
+// checkSetIdle queues d.setIdle if the Distributor belongs to a DistributorSet, and has no Readers
+// and nothing buffered.
+//
+// d.mu must be held.
+func (d *Distributor[T]) checkSetIdle() {
+	if d.setIdle != nil && len(d.readers) == 0 && d.isDrained() {
+		d.mu.queue(d.setIdle)
+	}
+}
+
+// removeIfIdle removes the key's Distributor d if it has no Readers and nothing buffered. It's
+// d.setIdle, so it's called after d.mu is released.
+func (s *setShard[K, T]) removeIfIdle(key K, d *Distributor[T]) {
+	s.mu.Lock()
+	if s.active[key] != d {
+		s.mu.Unlock()
+		return
+	}
+
+	d.mu.Lock()
+	idle := len(d.readers) == 0 && d.isDrained()
+	nextSeq := d.nextSeq
+	// Like Subscribe, callbacks are only run once the shard's lock is released.
+	d.mu.unlockQuietly()
+
+	if idle {
+		delete(s.active, key)
+		// Maps don't shrink, so release the memory once every key in the shard is idle.
+		if len(s.active) == 0 {
+			s.active = nil
+		}
+		if nextSeq != 0 {
+			if s.nextSeqs == nil {
+				s.nextSeqs = make(map[K]int64)
+			}
+			s.nextSeqs[key] = nextSeq
+		}
+	}
+	s.mu.Unlock()
+	d.mu.flush()
+}
+
+// keySeed is the seed for hashKey, shared by every DistributorSet so that their zero values are
+// valid
+var keySeed = maphash.MakeSeed()
+
+// hashKey returns the hash of a comparable key, so that equal keys - according to == - have the
+// same hash. Common key types are hashed directly, and others by reflection.
+func hashKey[K comparable](key K) uint64 {
+	var h maphash.Hash
+	h.SetSeed(keySeed)
+	switch k := any(key).(type) {
+	case string:
+		_, _ = h.WriteString(k)
+	case int:
+		writeUint64(&h, uint64(k))
+	case int64:
+		writeUint64(&h, uint64(k))
+	case uint64:
+		writeUint64(&h, k)
+	default:
+		hashValue(&h, reflect.ValueOf(any(key)))
+	}
+	return h.Sum64()
+}
+
+// hashValue writes the comparable value v to h, for hashKey. Values that are equal according to
+// == are always written the same way.
+func hashValue(h *maphash.Hash, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		// A nil interface
+	case reflect.Bool:
+		if v.Bool() {
+			_ = h.WriteByte(1)
+		} else {
+			_ = h.WriteByte(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint64(h, uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint64(h, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeFloat(h, v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		writeFloat(h, real(v.Complex()))
+		writeFloat(h, imag(v.Complex()))
+	case reflect.String:
+		_, _ = h.WriteString(v.String())
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		writeUint64(h, uint64(v.Pointer()))
+	case reflect.Interface:
+		hashValue(h, v.Elem())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			hashValue(h, v.Index(i))
+		}
+	case reflect.Struct:
+		// Blank fields are ignored by ==
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if t.Field(i).Name != "_" {
+				hashValue(h, v.Field(i))
+			}
+		}
+	default:
+		panic(fmt.Sprintf("eventdistributor: can't hash key of non-comparable kind %v", v.Kind()))
+	}
+}
+
+// writeFloat writes f to h, so that 0 and -0, which are equal, are written the same way
+func writeFloat(h *maphash.Hash, f float64) {
+	if f == 0 {
+		f = 0
+	}
+	writeUint64(h, math.Float64bits(f))
+}
+
+// writeUint64 writes the bytes of n to h
+func writeUint64(h *maphash.Hash, n uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], n)
+	_, _ = h.Write(b[:])
+}
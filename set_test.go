@@ -0,0 +1,177 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestDistributorSet(t *testing.T) {
+	var s eventdistributor.DistributorSet[string, MyEvent]
+
+	t.Log("events for keys without Readers are discarded")
+	nowReady(t, s.Submit("a", MyEvent{id: 0}))
+	require.Equal(t, 0, s.Len())
+
+	a1 := s.Subscribe("a")
+	a2 := s.Subscribe("a")
+	b := s.Subscribe("b")
+	require.Equal(t, 2, s.Len())
+
+	s.Submit("a", MyEvent{id: 1})
+	s.Submit("b", MyEvent{id: 2})
+	require.Equal(t, []int{1}, drainIDs(&a1))
+	require.Equal(t, []int{2}, drainIDs(&b))
+
+	t.Log("a key is removed once it has no Readers")
+	b.Unsubscribe()
+	require.Equal(t, 1, s.Len())
+
+	a1.Unsubscribe()
+	require.Equal(t, 1, s.Len())
+	require.Equal(t, []int{1}, drainIDs(&a2))
+	a2.Unsubscribe()
+	require.Equal(t, 0, s.Len())
+
+	t.Log("removed keys can be used again, continuing their sequence numbers")
+	b = s.Subscribe("b")
+	defer b.Unsubscribe()
+	s.Submit("b", MyEvent{id: 3})
+	value, seq, err := b.TryConsumeSeq()
+	require.NoError(t, err)
+	require.Equal(t, MyEvent{id: 3}, value)
+	require.Equal(t, int64(1), seq)
+}
+
+func TestDistributorSetPinned(t *testing.T) {
+	var s eventdistributor.DistributorSet[string, MyEvent]
+
+	r := s.Subscribe("a")
+	s.Submit("a", MyEvent{id: 1})
+
+	t.Log("a key isn't removed while its events are pinned, and is removed once they're released")
+	err := r.Range(context.Background(), func(MyEvent) error {
+		r.Unsubscribe()
+		require.Equal(t, 1, s.Len())
+		return errors.New("stop")
+	})
+	require.EqualError(t, err, "stop")
+	require.Equal(t, 0, s.Len())
+}
+
+func TestDistributorSetCallbacks(t *testing.T) {
+	var s eventdistributor.DistributorSet[string, MyEvent]
+	var options eventdistributor.Options[MyEvent]
+	var lens []int
+	options.OnFirstSubscriber(func() { lens = append(lens, s.Len()) })
+	options.OnLastUnsubscribed(func() { lens = append(lens, s.Len()) })
+	s.DefaultOptions(options)
+
+	t.Log("callbacks can use the DistributorSet")
+	r := s.Subscribe("a")
+	r.Unsubscribe()
+	require.Equal(t, []int{1, 1}, lens)
+	require.Equal(t, 0, s.Len())
+}
+
+func TestDistributorSetKeys(t *testing.T) {
+	type key struct {
+		name  string
+		value float64
+		extra any
+	}
+	var s eventdistributor.DistributorSet[key, MyEvent]
+
+	t.Log("equal keys of any comparable type find the same Distributor")
+	r := s.Subscribe(key{name: "a", value: 0, extra: 1})
+	defer r.Unsubscribe()
+	s.Submit(key{name: "a", value: math.Copysign(0, -1), extra: 1}, MyEvent{id: 0})
+	s.Submit(key{name: "a", value: 0, extra: int64(1)}, MyEvent{id: 1})
+	require.Equal(t, []int{0}, drainIDs(&r))
+
+	t.Log("keys are independent when used concurrently")
+	var ints eventdistributor.DistributorSet[int, MyEvent]
+	var wg sync.WaitGroup
+	for k := 0; k < 100; k++ {
+		k := k
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := ints.Subscribe(k)
+			defer r.Unsubscribe()
+			ints.Submit(k, MyEvent{id: k})
+			require.Equal(t, []int{k}, drainIDs(&r))
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 0, ints.Len())
+}
+
+// BenchmarkDistributorSetParallel measures producers submitting to different idle keys of the same
+// DistributorSet in parallel, which only looks up each key's Distributor
+func BenchmarkDistributorSetParallel(b *testing.B) {
+	const keys = 1024
+
+	var s eventdistributor.DistributorSet[int, MyEvent]
+	var next atomic.Int64
+	b.RunParallel(func(pb *testing.PB) {
+		k := int(next.Add(1)) % keys
+		for pb.Next() {
+			s.Submit(k, MyEvent{id: k})
+		}
+	})
+}
+
+// BenchmarkIdleKeys compares the memory used by many keys that have been used once and are now
+// idle, in a DistributorSet and in a Multi
+func BenchmarkIdleKeys(b *testing.B) {
+	const keys = 50_000
+
+	measure := func(b *testing.B, setup func() any) {
+		var before, after runtime.MemStats
+		var keep any
+		for i := 0; i < b.N; i++ {
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			keep = setup()
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+		}
+		runtime.KeepAlive(keep)
+		b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc)/keys, "bytes/key")
+	}
+
+	b.Run("DistributorSet", func(b *testing.B) {
+		measure(b, func() any {
+			s := &eventdistributor.DistributorSet[int, MyEvent]{}
+			for k := 0; k < keys; k++ {
+				r := s.Subscribe(k)
+				s.Submit(k, MyEvent{id: k})
+				r.Consume()
+				r.Unsubscribe()
+			}
+			return s
+		})
+	})
+
+	b.Run("Multi", func(b *testing.B) {
+		measure(b, func() any {
+			m := &eventdistributor.Multi[int, MyEvent]{}
+			for k := 0; k < keys; k++ {
+				r := m.Subscribe(k)
+				m.Submit(k, MyEvent{id: k})
+				r.Consume()
+				r.Unsubscribe()
+			}
+			return m
+		})
+	})
+}
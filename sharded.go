@@ -0,0 +1,253 @@
+package eventdistributor
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// ShardedDistributor spreads events over several Distributors - its shards - so that producers and
+// Readers of different shards don't contend for the same lock. Each event is submitted to the shard
+// chosen by the hash of its key, and each ShardedReader has a Reader on every shard.
+//
+// Events are only ordered within a shard: events with the same key are always received in the
+// order they were submitted, but events with different keys may be received in any order, even if
+// they were submitted by the same goroutine.
+//
+// Each shard is a Distributor created with the options passed to NewSharded(), so limits like
+// WithMaxReaderLag apply to each shard separately, and callbacks are called by each shard for its
+// own events and Readers.
+type ShardedDistributor[T any] struct {
+	shards []*Distributor[T]
+	key    func(T) uint64
+}
+
+// NewSharded creates a new ShardedDistributor with the given number of shards, each created with
+// the provided options. key returns the hash of an event's key, which selects its shard.
+//
+// NewSharded panics if shards is not positive.
+func NewSharded[T any](
+	shards int,
+	key func(T) uint64,
+	options ...Options[T],
+) *ShardedDistributor[T] {
+	if shards <= 0 {
+		panic("eventdistributor: NewSharded requires shards > 0")
+	}
+
+	s := &ShardedDistributor[T]{
+		shards: make([]*Distributor[T], shards),
+		key:    key,
+	}
+	for i := range s.shards {
+		s.shards[i] = New(options...)
+	}
+	return s
+}
+
+// Submit adds an event to the shard selected by its key, notifying any waiting ShardedReaders. For
+// more information, see (*Distributor[T]).Submit().
+//
+// Submit is thread-safe.
+func (s *ShardedDistributor[T]) Submit(value T) <-chan struct{} {
+	return s.shards[s.key(value)%uint64(len(s.shards))].Submit(value)
+}
+
+// Subscribe creates a new ShardedReader, which receives every event submitted afterwards.
+//
+// The ShardedReader subscribes to every shard at once, while nothing can be submitted to any of
+// them, so it never receives an event from one shard while missing an earlier one from another.
+//
+// Subscribe is thread-safe.
+func (s *ShardedDistributor[T]) Subscribe() ShardedReader[T] {
+	state := &shardedReaderState[T]{
+		s:       s,
+		readers: make([]Reader[T], len(s.shards)),
+		next:    atomic.Uint64{},
+		waitCh:  atomic.Pointer[chan struct{}]{},
+	}
+
+	s.lockAll()
+	defer s.unlockAll()
+
+	for i, d := range s.shards {
+		r := d.subscribe()
+		// The retained event was already submitted, so it's received like an archived one.
+		if d.retained != nil && !d.closed {
+			r.backfill = []ArchivedEvent[T]{*d.retained}
+		}
+		r.notify = state.wake
+		state.readers[i] = r
+	}
+	return ShardedReader[T]{shardedReaderState: state}
+}
+
+// Close closes every shard. For more information, see (*Distributor[T]).Close().
+//
+// Close is thread-safe.
+func (s *ShardedDistributor[T]) Close() {
+	for _, d := range s.shards {
+		d.Close()
+	}
+}
+
+// lockAll locks every shard, in order, so that they can be changed at once.
+func (s *ShardedDistributor[T]) lockAll() {
+	for _, d := range s.shards {
+		d.mu.Lock()
+	}
+}
+
+// unlockAll unlocks every shard locked by lockAll, and only then runs their queued callbacks, so
+// that callbacks can use any of the shards.
+func (s *ShardedDistributor[T]) unlockAll() {
+	for i := len(s.shards) - 1; i >= 0; i-- {
+		s.shards[i].mu.unlockQuietly()
+	}
+	for _, d := range s.shards {
+		d.mu.flush()
+	}
+}
+
+// ShardedReader receives events from every shard of a ShardedDistributor. It is created by
+// (*ShardedDistributor[T]).Subscribe().
+//
+// A ShardedReader is used like a Reader, with WaitChan() and Consume(). Copies of a ShardedReader
+// refer to the same subscription, but it should only wait or consume from one goroutine at a time.
+type ShardedReader[T any] struct {
+	*shardedReaderState[T]
+}
+
+// shardedReaderState is the state of a ShardedReader, shared between all copies of it
+type shardedReaderState[T any] struct {
+	s *ShardedDistributor[T]
+	// readers has the Reader for each shard, at the shard's index
+	readers []Reader[T]
+	// next is the index of the first shard to consume from, so that a busy shard can't starve the
+	// others
+	next atomic.Uint64
+	// waitCh, if not nil, is the channel returned by WaitChan() while waiting. The first shard to
+	// wake the ShardedReader closes it. See wake().
+	waitCh atomic.Pointer[chan struct{}]
+}
+
+// WaitChan returns a channel that will be closed once there is an event on any shard that this
+// ShardedReader has not yet seen, or once it can no longer be used.
+//
+// Like (*Reader[T]).WaitChan(), calls while the ShardedReader is waiting return the same channel,
+// and each wait that actually blocks allocates a new one.
+//
+// WaitChan is thread-safe.
+func (r *ShardedReader[T]) WaitChan() <-chan struct{} {
+	for _, sr := range r.readers {
+		if sr.availableFast() {
+			return closedChannel
+		}
+	}
+
+	// The channel must exist before the shards are checked, so that an event submitted to a shard
+	// after it's checked closes the channel that's returned.
+	ch := r.waitCh.Load()
+	for ch == nil {
+		newCh := make(chan struct{})
+		if r.waitCh.CompareAndSwap(nil, &newCh) {
+			ch = &newCh
+		} else {
+			ch = r.waitCh.Load()
+		}
+	}
+
+	for _, sr := range r.readers {
+		if !sr.waitShard() {
+			return closedChannel
+		}
+	}
+	return *ch
+}
+
+// waitShard registers the shard's Reader to wake its ShardedReader, returning false instead if it
+// has an event available or can no longer be used.
+func (r *Reader[T]) waitShard() bool {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if r.prepare() != nil || r.available() {
+		return false
+	}
+
+	r.startWait()
+	if !r.isOwnWaiter() {
+		r.d.ownWaiters = append(r.d.ownWaiters, r.readerState)
+	}
+	r.notifyWaiting = true
+	return true
+}
+
+// wake closes the channel returned by WaitChan(), if there is one. It is called by the shards'
+// Readers, with their shard's lock held.
+func (s *shardedReaderState[T]) wake() {
+	if ch := s.waitCh.Swap(nil); ch != nil {
+		close(*ch)
+	}
+}
+
+// Consume returns the first event from one of the shards that has not yet been seen by this
+// ShardedReader, marking it as "seen".
+//
+// Consume must only be called when there is an event available - i.e., after WaitChan() has been
+// closed - and panics otherwise.
+//
+// Consume is thread-safe.
+func (r *ShardedReader[T]) Consume() T {
+	value, err := r.TryConsume()
+	if err == ErrNoEvent {
+		panic("eventdistributor: Consume called with no event available for ShardedReader")
+	} else if err != nil {
+		panic(fmt.Errorf("eventdistributor: Consume called on unusable ShardedReader: %w", err))
+	}
+	return value
+}
+
+// TryConsume is like Consume, but returns ErrNoEvent if there is no event available, instead of
+// panicking. If no shard has an event available and one of them can no longer be used - for
+// example, because the ShardedDistributor was closed - TryConsume returns the reason, like
+// (*Reader[T]).TryConsume().
+//
+// TryConsume is thread-safe.
+func (r *ShardedReader[T]) TryConsume() (T, error) {
+	n := uint64(len(r.readers))
+	start := r.next.Load()
+	var unusable error
+	for i := uint64(0); i < n; i++ {
+		idx := (start + i) % n
+		value, err := r.readers[idx].TryConsume()
+		if err == nil {
+			r.next.Store(idx + 1)
+			return value, nil
+		} else if err != ErrNoEvent && unusable == nil {
+			unusable = err
+		}
+	}
+
+	var zero T
+	if unusable != nil {
+		return zero, unusable
+	}
+	return zero, ErrNoEvent
+}
+
+// Unsubscribe de-registers the ShardedReader from every shard at once, returning whether this call
+// was the one that unsubscribed it. For more information, see (*Reader[T]).Unsubscribe().
+//
+// Unsubscribe is thread-safe.
+func (r *ShardedReader[T]) Unsubscribe() bool {
+	r.s.lockAll()
+	defer r.s.unlockAll()
+
+	unsubscribed := false
+	for _, sr := range r.readers {
+		if sr.unsubscribe() {
+			unsubscribed = true
+		}
+	}
+	return unsubscribed
+}
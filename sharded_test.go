@@ -0,0 +1,168 @@
+package eventdistributor_test
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func keyByID(e MyEvent) uint64 {
+	return uint64(e.id % 4)
+}
+
+func TestShardedDistributor(t *testing.T) {
+	s := eventdistributor.NewSharded(3, keyByID)
+	r := s.Subscribe()
+	defer r.Unsubscribe()
+
+	nowNotReady(t, r.WaitChan())
+	for i := 0; i < 20; i++ {
+		s.Submit(MyEvent{id: i})
+	}
+
+	t.Log("every event is received, and events with the same key are in order")
+	byKey := make(map[uint64][]int)
+	for i := 0; i < 20; i++ {
+		nowReady(t, r.WaitChan())
+		e := r.Consume()
+		byKey[keyByID(e)] = append(byKey[keyByID(e)], e.id)
+	}
+	require.Equal(t, map[uint64][]int{
+		0: {0, 4, 8, 12, 16},
+		1: {1, 5, 9, 13, 17},
+		2: {2, 6, 10, 14, 18},
+		3: {3, 7, 11, 15, 19},
+	}, byKey)
+
+	nowNotReady(t, r.WaitChan())
+	_, err := r.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrNoEvent)
+	require.Panics(t, func() { r.Consume() })
+}
+
+func TestShardedWaitChan(t *testing.T) {
+	s := eventdistributor.NewSharded(4, keyByID)
+	r := s.Subscribe()
+	defer r.Unsubscribe()
+
+	t.Log("an event on any shard closes the channel, which is the same while waiting")
+	for i := 0; i < 4; i++ {
+		wait := r.WaitChan()
+		require.Equal(t, wait, r.WaitChan())
+		nowNotReady(t, wait)
+		s.Submit(MyEvent{id: i})
+		nowReady(t, wait)
+		require.Equal(t, i, r.Consume().id)
+	}
+
+	t.Log("closing the ShardedDistributor ends the wait")
+	wait := r.WaitChan()
+	s.Close()
+	nowReady(t, wait)
+	_, err := r.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrClosed)
+}
+
+func TestShardedUnsubscribe(t *testing.T) {
+	s := eventdistributor.NewSharded(2, keyByID)
+	r := s.Subscribe()
+	wait := r.WaitChan()
+
+	first := s.Submit(MyEvent{id: 0})
+	second := s.Submit(MyEvent{id: 1})
+	nowNotReady(t, first)
+
+	t.Log("unsubscribing releases the events on every shard")
+	require.True(t, r.Unsubscribe())
+	require.False(t, r.Unsubscribe())
+	nowReady(t, first)
+	nowReady(t, second)
+	nowReady(t, wait)
+	_, err := r.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrUnsubscribed)
+}
+
+// TestShardedSubscribeAtomic checks that a ShardedReader that subscribes while events are being
+// submitted to every shard receives every event after the first one it receives
+func TestShardedSubscribeAtomic(t *testing.T) {
+	s := eventdistributor.NewSharded(4, keyByID)
+	defer s.Close()
+
+	stop := make(chan struct{})
+	last := make(chan int)
+	go func() {
+		i := 0
+		for ; ; i++ {
+			select {
+			case <-stop:
+				// One more event for each shard, so that the ShardedReader knows when it's done
+				for j := i; j < i+4; j++ {
+					s.Submit(MyEvent{id: j})
+				}
+				last <- i - 1
+				return
+			default:
+				s.Submit(MyEvent{id: i})
+			}
+		}
+	}()
+
+	r := s.Subscribe()
+	defer r.Unsubscribe()
+	close(stop)
+	lastID := <-last
+
+	var ids []int
+	for done := 0; done < 4; {
+		<-r.WaitChan()
+		if e := r.Consume(); e.id > lastID {
+			done += 1
+		} else {
+			ids = append(ids, e.id)
+		}
+	}
+
+	sort.Ints(ids)
+	for i, id := range ids {
+		require.Equal(t, ids[0]+i, id, "missed an event")
+	}
+	if len(ids) != 0 {
+		require.Equal(t, lastID, ids[len(ids)-1])
+	}
+}
+
+// BenchmarkShardedSubmit measures producers submitting in parallel to a ShardedDistributor with a
+// single Reader consuming from every shard
+func BenchmarkShardedSubmit(b *testing.B) {
+	for _, shards := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			s := eventdistributor.NewSharded(shards, func(e MyEvent) uint64 { return uint64(e.id) })
+			r := s.Subscribe()
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for {
+					<-r.WaitChan()
+					if _, err := r.TryConsume(); err == eventdistributor.ErrClosed {
+						return
+					}
+				}
+			}()
+
+			var next atomic.Int64
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					s.Submit(MyEvent{id: int(next.Add(1))})
+				}
+			})
+			s.Close()
+			<-done
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package eventdistributor
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// Shutdownable is the interface required of members of a ShutdownGroup. It is implemented by
+// *Distributor[T].
+type Shutdownable interface {
+	Seal()
+	WaitForDrain(ctx context.Context) error
+	Close()
+}
+
+// handlerStopper is implemented by members of a ShutdownGroup that run managed handlers, like
+// *Distributor[T]. See (*Distributor[T]).StopHandlers().
+type handlerStopper interface {
+	StopHandlers(ctx context.Context) error
+}
+
+// ShutdownGroup coordinates shutting down a set of Distributors (or anything else implementing
+// Shutdownable).
+//
+// Members are shut down in tiers, from lowest to highest: every member of a tier is sealed, then
+// the group waits for all of them to drain, then they are all closed, before moving on to the next
+// tier. Typically, producers of events should be placed in lower tiers than their consumers, so
+// that events that are still being forwarded have a chance to be consumed.
+//
+// The zero value is ready to use.
+type ShutdownGroup struct {
+	mu      sync.Mutex
+	members []shutdownMember
+}
+
+type shutdownMember struct {
+	tier int
+	s    Shutdownable
+}
+
+// Add adds a member to the ShutdownGroup, in tier zero.
+//
+// Add is thread-safe.
+func (g *ShutdownGroup) Add(s Shutdownable) {
+	g.AddInTier(0, s)
+}
+
+// AddInTier adds a member to the ShutdownGroup, in the given tier. Lower tiers are shut down
+// before higher ones.
+//
+// AddInTier is thread-safe.
+func (g *ShutdownGroup) AddInTier(tier int, s Shutdownable) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.members = append(g.members, shutdownMember{tier: tier, s: s})
+}
+
+// Shutdown seals, drains, and closes every member of the group, tier by tier.
+//
+// Members that run managed handlers, like Distributors with handlers added by AddHandler() or
+// AddHandlerFunc(), have them stopped once they're sealed, before waiting for the rest of their
+// buffer to drain: each handler handles the events that were already submitted, and then exits.
+// See (*Distributor[T]).StopHandlers().
+//
+// ctx is the deadline for the whole shutdown. If it expires while waiting for members to drain,
+// those members are closed anyway (dropping any events remaining in their buffers), and all
+// remaining tiers are closed without waiting. Errors from waiting are combined with errors.Join.
+//
+// Shutdown is thread-safe, but members added during a call to Shutdown may not be shut down by it.
+func (g *ShutdownGroup) Shutdown(ctx context.Context) error {
+	g.mu.Lock()
+	members := make([]shutdownMember, len(g.members))
+	copy(members, g.members)
+	g.mu.Unlock()
+
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].tier < members[j].tier
+	})
+
+	var errs []error
+	for start := 0; start < len(members); {
+		end := start
+		for end < len(members) && members[end].tier == members[start].tier {
+			end += 1
+		}
+		tier := members[start:end]
+		start = end
+
+		for _, m := range tier {
+			m.s.Seal()
+		}
+
+		var wg sync.WaitGroup
+		tierErrs := make([]error, len(tier))
+		for i, m := range tier {
+			wg.Add(1)
+			go func(i int, m shutdownMember) {
+				defer wg.Done()
+				if h, ok := m.s.(handlerStopper); ok {
+					if err := h.StopHandlers(ctx); err != nil {
+						tierErrs[i] = err
+						return
+					}
+				}
+				tierErrs[i] = m.s.WaitForDrain(ctx)
+			}(i, m)
+		}
+		wg.Wait()
+
+		for _, m := range tier {
+			m.s.Close()
+		}
+		errs = append(errs, tierErrs...)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,140 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+// recordingShutdownable wraps a Distributor, recording the order of calls to it
+type recordingShutdownable struct {
+	name  string
+	d     *eventdistributor.Distributor[MyEvent]
+	calls chan<- string
+}
+
+func (s recordingShutdownable) Seal() {
+	s.calls <- s.name + ":seal"
+	s.d.Seal()
+}
+
+func (s recordingShutdownable) StopHandlers(ctx context.Context) error {
+	err := s.d.StopHandlers(ctx)
+	s.calls <- s.name + ":stopped"
+	return err
+}
+
+func (s recordingShutdownable) WaitForDrain(ctx context.Context) error {
+	err := s.d.WaitForDrain(ctx)
+	s.calls <- s.name + ":drained"
+	return err
+}
+
+func (s recordingShutdownable) Close() {
+	s.calls <- s.name + ":close"
+	s.d.Close()
+}
+
+func TestShutdownGroup(t *testing.T) {
+	calls := make(chan string, 100)
+	producer := recordingShutdownable{name: "producer", d: eventdistributor.New[MyEvent](), calls: calls}
+	consumer := recordingShutdownable{name: "consumer", d: eventdistributor.New[MyEvent](), calls: calls}
+
+	var group eventdistributor.ShutdownGroup
+	group.AddInTier(1, consumer)
+	group.Add(producer)
+
+	r := producer.d.Subscribe()
+	defer r.Unsubscribe()
+	producer.d.Submit(MyEvent{id: 1})
+
+	done := make(chan error)
+	go func() {
+		done <- group.Shutdown(context.Background())
+	}()
+
+	require.Equal(t, "producer:seal", <-calls)
+	require.Equal(t, "producer:stopped", <-calls)
+	select {
+	case c := <-calls:
+		require.FailNow(t, "unexpected call before drain", c)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	r.Consume()
+	require.NoError(t, <-done)
+	close(calls)
+
+	var rest []string
+	for c := range calls {
+		rest = append(rest, c)
+	}
+	require.Equal(t, []string{
+		"producer:drained", "producer:close",
+		"consumer:seal", "consumer:stopped", "consumer:drained", "consumer:close",
+	}, rest)
+}
+
+func TestShutdownGroupTimeout(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var dropped []eventdistributor.DropReason
+	options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+		dropped = append(dropped, reason)
+	})
+	stuck := eventdistributor.New(options)
+	other := eventdistributor.New[MyEvent]()
+
+	var group eventdistributor.ShutdownGroup
+	group.Add(stuck)
+	group.AddInTier(1, other)
+
+	r := stuck.Subscribe()
+	defer r.Unsubscribe()
+	stuck.Submit(MyEvent{id: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	t.Log("on timeout, remaining events are dropped and everything is still closed")
+	err := group.Shutdown(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, []eventdistributor.DropReason{eventdistributor.DropReasonClosed}, dropped)
+	otherReader := other.Subscribe()
+	_, err = otherReader.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrClosed)
+}
+
+func TestShutdownGroupHandlers(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	handled := make(chan int, 10)
+	release := make(chan struct{})
+	stop := d.AddHandler(func(e MyEvent) {
+		<-release
+		handled <- e.id
+	})
+	defer stop()
+
+	var group eventdistributor.ShutdownGroup
+	group.Add(d)
+
+	d.Submit(MyEvent{id: 1})
+	d.Submit(MyEvent{id: 2})
+	done := make(chan error)
+	go func() {
+		done <- group.Shutdown(context.Background())
+	}()
+
+	t.Log("managed handlers finish the events submitted before shutdown, then stop")
+	close(release)
+	require.NoError(t, <-done)
+	close(handled)
+	var ids []int
+	for id := range handled {
+		ids = append(ids, id)
+	}
+	require.Equal(t, []int{1, 2}, ids)
+}
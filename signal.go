@@ -0,0 +1,158 @@
+package eventdistributor
+
+import (
+	"context"
+)
+
+// Signal waits for events to be available to a Reader. It is an alternative to WaitChan() that is
+// obtained once and used for the Reader's whole life: instead of a new channel for every wait, it
+// re-arms itself, so waiting doesn't allocate, and there is no stale channel to accidentally wait
+// on after consuming.
+//
+// A Signal is created by (*Reader[T]).Signal(). It should only be used by one goroutine at a time,
+// typically the one consuming from the Reader.
+type Signal[T any] struct {
+	r Reader[T]
+}
+
+// Signal returns the Signal for the Reader. Every call returns an equivalent Signal.
+//
+// Signal is thread-safe.
+func (r *Reader[T]) Signal() Signal[T] {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if r.signal == nil {
+		r.signal = make(chan struct{}, 1)
+	}
+	return Signal[T]{r: *r}
+}
+
+// Wait blocks until there is an event available for the Reader, returning nil. Like WaitChan(), it
+// also returns if the Reader can no longer be used, with the reason - for example, ErrClosed if
+// the Distributor has been closed. If ctx is canceled first, Wait returns ctx.Err().
+//
+// Wait is thread-safe, but only one goroutine should wait on a Reader's Signal at a time.
+func (s Signal[T]) Wait(ctx context.Context) error {
+	r := s.r
+	for {
+		r.d.mu.Lock()
+		if err := r.prepare(); err != nil {
+			r.d.mu.Unlock()
+			return err
+		} else if r.available() {
+			r.d.mu.Unlock()
+			return nil
+		}
+
+		r.armSignal()
+		r.d.mu.Unlock()
+
+		select {
+		case <-r.signal:
+			// A wakeup may be left over from an earlier Wait that was canceled, so check again.
+		case <-ctx.Done():
+			r.disarmSignal()
+			return ctx.Err()
+		}
+	}
+}
+
+// Ready returns whether Wait would return immediately - i.e., whether there is an event available,
+// or the Reader can no longer be used.
+//
+// Ready is thread-safe.
+func (s Signal[T]) Ready() bool {
+	r := s.r
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if r.prepare() != nil {
+		return true
+	}
+	return r.available()
+}
+
+// waitSignal is like WaitChan(), but for loops inside this package that are the only ones waiting
+// on the Reader, like Range() and managed handlers: instead of allocating a new channel for every
+// wait that blocks, it returns the Reader's Signal channel, which is reused.
+//
+// Because the channel is reused, receiving from it only means that the Reader should be checked
+// again - the value may be left over from an earlier wait. If the caller stops waiting without
+// receiving from it, it must call disarmSignal().
+//
+// waitSignal is thread-safe.
+func (r *Reader[T]) waitSignal() <-chan struct{} {
+	if r.availableFast() {
+		return closedChannel
+	}
+
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if r.prepare() != nil || r.available() {
+		return closedChannel
+	}
+	if r.signal == nil {
+		r.signal = make(chan struct{}, 1)
+	}
+	r.armSignal()
+	return r.signal
+}
+
+// armSignal registers the Reader's Signal channel to be woken once an event is available for it.
+//
+// r.d.mu must be held, and r.signal must not be nil.
+func (r *Reader[T]) armSignal() {
+	if !r.isOwnWaiter() {
+		r.d.ownWaiters = append(r.d.ownWaiters, r.readerState)
+	}
+	r.signalWaiting = true
+	r.startWait()
+}
+
+// disarmSignal stops waking the Reader's Signal channel, once the caller armed it but stopped
+// waiting for some other reason.
+//
+// disarmSignal is thread-safe.
+func (r *Reader[T]) disarmSignal() {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if r.signalWaiting {
+		r.signalWaiting = false
+		if !r.isOwnWaiter() {
+			r.removeOwnWaiter()
+		}
+	}
+}
+
+// isOwnWaiter returns whether the Reader is in d.ownWaiters.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) isOwnWaiter() bool {
+	return r.waitCh != nil || r.signalWaiting || r.notifyWaiting
+}
+
+// wakeOwnWaiter wakes the Reader's WaitChan(), Signal, and ShardedReader or Group, if they're
+// waiting. The caller is responsible for removing it from d.ownWaiters, unless it's still waiting
+// afterwards - a Group may keep waiting for its other members.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) wakeOwnWaiter() {
+	if r.waitCh != nil {
+		close(r.waitCh)
+		r.waitCh = nil
+	}
+	if r.signalWaiting {
+		r.signalWaiting = false
+		select {
+		case r.signal <- struct{}{}:
+		default:
+		}
+	}
+	if r.notifyWaiting {
+		r.notifyWaiting = false
+		r.notify()
+	}
+}
@@ -0,0 +1,127 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSignal(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	sig := r.Signal()
+
+	require.False(t, sig.Ready())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, sig.Wait(ctx), context.DeadlineExceeded)
+
+	t.Log("the same Signal is used for every event")
+	for i := 0; i < 3; i++ {
+		waited := make(chan error)
+		go func() { waited <- sig.Wait(context.Background()) }()
+		time.Sleep(time.Millisecond)
+		d.Submit(MyEvent{id: i})
+		require.NoError(t, <-waited)
+		require.True(t, sig.Ready())
+		require.Equal(t, i, r.Consume().id)
+		require.False(t, sig.Ready())
+	}
+
+	t.Log("Readers that must be woken individually work too")
+	filtered := d.SubscribeFiltered(func(e MyEvent) bool { return e.id%2 == 0 })
+	defer filtered.Unsubscribe()
+	fsig := filtered.Signal()
+	waited := make(chan error)
+	go func() { waited <- fsig.Wait(context.Background()) }()
+	d.Submit(MyEvent{id: 1})
+	select {
+	case <-waited:
+		t.Fatal("woken by an event that doesn't match the filter")
+	case <-time.After(10 * time.Millisecond):
+	}
+	d.Submit(MyEvent{id: 2})
+	require.NoError(t, <-waited)
+
+	t.Log("closing the Distributor wakes waiting Signals")
+	drainIDs(&r)
+	go func() { waited <- sig.Wait(context.Background()) }()
+	time.Sleep(time.Millisecond)
+	d.Close()
+	require.ErrorIs(t, <-waited, eventdistributor.ErrClosed)
+	require.True(t, sig.Ready())
+}
+
+// BenchmarkWait measures waiting for each event in turn, with the producer waiting for the
+// consumer to receive each event before submitting the next - i.e., one producer and one consumer
+// playing ping-pong. Each WaitChan() wait allocates a channel, while Signal, Range(), and managed
+// handlers reuse the same one. Range() still allocates to pin each event while fn is called.
+func BenchmarkWait(b *testing.B) {
+	// run measures the consumer started by consume, which must send to received for each event
+	run := func(b *testing.B, consume func(r *eventdistributor.Reader[MyEvent], received chan<- struct{})) {
+		d := eventdistributor.New[MyEvent]()
+		r := d.Subscribe()
+		defer r.Unsubscribe()
+
+		received := make(chan struct{})
+		go consume(&r, received)
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			d.SubmitQuiet(MyEvent{id: i})
+			<-received
+		}
+	}
+	loop := func(wait func(r *eventdistributor.Reader[MyEvent]) func()) func(
+		*eventdistributor.Reader[MyEvent],
+		chan<- struct{},
+	) {
+		return func(r *eventdistributor.Reader[MyEvent], received chan<- struct{}) {
+			w := wait(r)
+			for {
+				w()
+				if _, err := r.TryConsume(); err != nil {
+					return
+				}
+				received <- struct{}{}
+			}
+		}
+	}
+
+	b.Run("WaitChan", func(b *testing.B) {
+		run(b, loop(func(r *eventdistributor.Reader[MyEvent]) func() {
+			return func() { <-r.WaitChan() }
+		}))
+	})
+	b.Run("Signal", func(b *testing.B) {
+		run(b, loop(func(r *eventdistributor.Reader[MyEvent]) func() {
+			sig := r.Signal()
+			return func() { _ = sig.Wait(context.Background()) }
+		}))
+	})
+	b.Run("Range", func(b *testing.B) {
+		run(b, func(r *eventdistributor.Reader[MyEvent], received chan<- struct{}) {
+			_ = r.Range(context.Background(), func(MyEvent) error {
+				received <- struct{}{}
+				return nil
+			})
+		})
+	})
+	b.Run("AddHandler", func(b *testing.B) {
+		d := eventdistributor.New[MyEvent]()
+		received := make(chan struct{})
+		stop := d.AddHandler(func(MyEvent) { received <- struct{}{} })
+		defer stop()
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			d.SubmitQuiet(MyEvent{id: i})
+			<-received
+		}
+	})
+}
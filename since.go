@@ -0,0 +1,96 @@
+package eventdistributor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTooOld is matched by the errors returned by SubscribeSince() when events submitted at or after
+// the requested time have already left the buffer.
+//
+// The returned error is a *TooOldError, which provides the oldest time that is still available.
+var ErrTooOld = errors.New("events since the requested time are no longer available")
+
+// TooOldError is the error returned by SubscribeSince() when events submitted at or after the
+// requested time have already left the buffer. It matches ErrTooOld with errors.Is.
+type TooOldError struct {
+	// Oldest is the timestamp of the oldest buffered event, or the zero value if there are none
+	Oldest time.Time
+}
+
+// Error implements the error interface
+func (e *TooOldError) Error() string {
+	if e.Oldest.IsZero() {
+		return fmt.Sprintf("%s (no events available)", ErrTooOld)
+	}
+	return fmt.Sprintf("%s (oldest available is %s)", ErrTooOld, e.Oldest)
+}
+
+// Is allows errors.Is(err, ErrTooOld) to match
+func (e *TooOldError) Is(target error) bool {
+	return target == ErrTooOld
+}
+
+// SubscribeSince creates a new Reader that starts at the first buffered event that was submitted
+// at or after t, receiving every event from there on. If there is no such event, the Reader starts
+// at the live edge.
+//
+// The Reader starts at the first event, in order of submission, whose timestamp is not before t.
+// Timestamps are normally in the same order as the events, but if the Distributor's Clock goes
+// backwards, some events after the starting point may have timestamps before t; they are still
+// delivered.
+//
+// The buffered events are treated as a replay. See ReplayDone().
+//
+// SubscribeSince returns a *TooOldError, matching ErrTooOld, if any event that has already left
+// the buffer was submitted at or after t. Events that leave the buffer are not retrieved from the
+// Distributor's Archiver; see SubscribeFrom() for that. SubscribeSince returns ErrClosed if the
+// Distributor is closed.
+//
+// SubscribeSince requires the Distributor to record timestamps, and panics otherwise. See
+// (*Options[T]).WithTimestamps().
+//
+// SubscribeSince is thread-safe.
+func (d *Distributor[T]) SubscribeSince(t time.Time) (Reader[T], error) {
+	if !d.timestamps {
+		panic("eventdistributor: SubscribeSince requires timestamps. See (*Options[T]).WithTimestamps()")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return Reader[T]{}, ErrClosed
+	} else if !d.lastReleased.IsZero() && !d.lastReleased.Before(t) {
+		err := &TooOldError{Oldest: time.Time{}}
+		if len(d.buf) != 0 {
+			err.Oldest = d.buf[0].timestamp
+		}
+		return Reader[T]{}, err
+	}
+
+	idx := len(d.buf)
+	for i, e := range d.buf {
+		if !e.timestamp.Before(t) {
+			idx = i
+			break
+		}
+	}
+
+	r := d.subscribe()
+	if idx < len(d.buf) {
+		r.firstSeq = d.buf[idx].seq
+	}
+	r.moveTo(d.basePosition + int64(idx))
+	return r, nil
+}
+
+// noteReleased records the timestamp of an event that has left the buffer, for SubscribeSince().
+//
+// d.mu must be held.
+func (d *Distributor[T]) noteReleased(timestamp time.Time) {
+	if timestamp.After(d.lastReleased) {
+		d.lastReleased = timestamp
+	}
+}
@@ -0,0 +1,59 @@
+package eventdistributor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSubscribeSince(t *testing.T) {
+	clock := newFakeClock()
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	options.WithTimestamps()
+	d := eventdistributor.New(options)
+
+	start := clock.Now()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	for i := 0; i < 5; i++ {
+		d.Submit(MyEvent{id: i})
+		clock.Skip(time.Second)
+	}
+	require.Equal(t, []int{0, 1}, drainN(t, &r, 2))
+
+	t.Log("the Reader starts at the first event not before the time")
+	since, err := d.SubscribeSince(start.Add(2500 * time.Millisecond))
+	require.NoError(t, err)
+	defer since.Unsubscribe()
+	replayDone := since.ReplayDone()
+	require.Equal(t, []int{3, 4}, drainIDs(&since))
+	nowReady(t, replayDone)
+
+	exact, err := d.SubscribeSince(start.Add(2 * time.Second))
+	require.NoError(t, err)
+	defer exact.Unsubscribe()
+	d.Submit(MyEvent{id: 5})
+	require.Equal(t, []int{2, 3, 4, 5}, drainIDs(&exact))
+
+	t.Log("a time after every event starts at the live edge")
+	live, err := d.SubscribeSince(clock.Now().Add(time.Hour))
+	require.NoError(t, err)
+	defer live.Unsubscribe()
+	nowReady(t, live.ReplayDone())
+	notReady(t, live)
+
+	t.Log("events at or after the time that have left the buffer are an error")
+	_, err = d.SubscribeSince(start.Add(time.Second))
+	require.ErrorIs(t, err, eventdistributor.ErrTooOld)
+	var tooOld *eventdistributor.TooOldError
+	require.ErrorAs(t, err, &tooOld)
+	require.Equal(t, start.Add(2*time.Second), tooOld.Oldest)
+
+	require.Panics(t, func() {
+		_, _ = eventdistributor.New[MyEvent]().SubscribeSince(start)
+	})
+}
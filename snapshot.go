@@ -0,0 +1,109 @@
+package eventdistributor
+
+import (
+	"time"
+)
+
+// Snapshot is a view of a Distributor's state at a single point in time, as returned by
+// (*Distributor[T]).Snapshot(). It is intended for debugging.
+type Snapshot[T any] struct {
+	// NextSeq is the sequence number that will be assigned to the next submitted event
+	NextSeq int64
+	// Buffered is the number of events in the buffer
+	Buffered int
+	// Sealed and Closed are true if the Distributor has been sealed or closed, respectively
+	Sealed bool
+	Closed bool
+	// Readers is every Reader of the Distributor, in no particular order
+	Readers []ReaderSnapshot
+	// Oldest is the oldest events in the buffer, from oldest to newest
+	Oldest []SnapshotEvent[T]
+	// Newest is the newest events in the buffer, from oldest to newest. It never includes any of
+	// the events in Oldest.
+	Newest []SnapshotEvent[T]
+}
+
+// ReaderSnapshot describes a single Reader in a Snapshot
+type ReaderSnapshot struct {
+	// ID is the Reader's ID, as returned by (*Reader[T]).ID()
+	ID uint64
+	// NextSeq is the sequence number of the next buffered event the Reader will receive, or the
+	// Distributor's NextSeq if it has received every buffered event
+	NextSeq int64
+	// Pending is the number of buffered events that the Reader has not yet seen
+	Pending int
+}
+
+// SnapshotEvent is a single buffered event in a Snapshot
+type SnapshotEvent[T any] struct {
+	// Seq is the sequence number of the event
+	Seq int64
+	// Timestamp is the time the event was submitted, if the Distributor records timestamps. See
+	// (*Options[T]).WithTimestamps().
+	Timestamp time.Time
+	// Value is the submitted value
+	Value T
+}
+
+// Snapshot returns a view of the Distributor's current state, including up to the n oldest and n
+// newest buffered events.
+//
+// The snapshot is taken while holding the Distributor's lock, but the lock is released before
+// Snapshot returns, so the snapshot may be inspected at leisure.
+//
+// Snapshot is thread-safe.
+func (d *Distributor[T]) Snapshot(n int) Snapshot[T] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := Snapshot[T]{
+		NextSeq:  d.nextSeq,
+		Buffered: len(d.buf),
+		Sealed:   d.sealed,
+		Closed:   d.closed,
+		Readers:  make([]ReaderSnapshot, 0, len(d.readers)),
+		Oldest:   nil,
+		Newest:   nil,
+	}
+
+	tail := d.basePosition + int64(len(d.buf))
+	for _, r := range d.readers {
+		nextSeq := d.nextSeq
+		if idx := int(r.position - d.basePosition); idx < len(d.buf) {
+			nextSeq = d.buf[idx].seq
+		}
+		s.Readers = append(s.Readers, ReaderSnapshot{
+			ID:      r.id,
+			NextSeq: nextSeq,
+			Pending: int(tail - r.position),
+		})
+	}
+
+	oldest := n
+	if oldest < 0 {
+		oldest = 0
+	} else if oldest > len(d.buf) {
+		oldest = len(d.buf)
+	}
+	newest := len(d.buf) - oldest
+	if newest < oldest {
+		newest = oldest
+	}
+	s.Oldest = snapshotEvents(d.buf[:oldest])
+	s.Newest = snapshotEvents(d.buf[newest:])
+
+	return s
+}
+
+// snapshotEvents copies the events for a Snapshot, returning nil if there are none
+func snapshotEvents[T any](buf []eventInfo[T]) []SnapshotEvent[T] {
+	if len(buf) == 0 {
+		return nil
+	}
+
+	events := make([]SnapshotEvent[T], 0, len(buf))
+	for _, e := range buf {
+		events = append(events, SnapshotEvent[T]{Seq: e.seq, Timestamp: e.timestamp, Value: e.value})
+	}
+	return events
+}
@@ -0,0 +1,40 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSnapshot(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	for i := 0; i < 5; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+	require.Equal(t, []int{0, 1}, drainN(t, &r, 2))
+	other := d.Subscribe()
+	defer other.Unsubscribe()
+
+	s := d.Snapshot(2)
+	require.Equal(t, int64(5), s.NextSeq)
+	require.Equal(t, 3, s.Buffered)
+	require.ElementsMatch(t, []eventdistributor.ReaderSnapshot{
+		{ID: r.ID(), NextSeq: 2, Pending: 3},
+		{ID: other.ID(), NextSeq: 5, Pending: 0},
+	}, s.Readers)
+
+	var oldest, newest []int64
+	for _, e := range s.Oldest {
+		oldest = append(oldest, e.Seq)
+	}
+	for _, e := range s.Newest {
+		newest = append(newest, e.Seq)
+	}
+	require.Equal(t, []int64{2, 3}, oldest)
+	require.Equal(t, []int64{4}, newest)
+}
@@ -0,0 +1,254 @@
+package eventdistributor
+
+import (
+	"container/heap"
+	"time"
+)
+
+// WithSortWindow holds each submitted event for up to window before adding it to the buffer, so
+// that events submitted slightly out of order are delivered to Readers in the order given by less.
+//
+// Held events are released in order once the event submitted earliest has been held for window,
+// according to the Distributor's Clock: that event is added to the buffer, along with every held
+// event that comes before it. Because the order is kept, an event may be held for longer than
+// window while it waits for an earlier event that was submitted after it.
+//
+// An event that is submitted after a later event has already been released - i.e., more than
+// window late - is added to the buffer immediately, out of order, and passed to any OnLate
+// callbacks.
+//
+// Held events are not yet part of the buffer: they don't have sequence numbers, are not visible to
+// Readers or handlers, and are not waited for by WaitForDrain(). OnSubmit callbacks are called when
+// each event is submitted, and other callbacks (like OnBufsizeChange) once it's released. Events
+// submitted together with SubmitAll() are held separately, so they may not be released together.
+//
+// Seal() releases all held events immediately, and Close() drops them, passing them to any OnDrop
+// callbacks with DropReasonClosed.
+//
+// WithSortWindow panics if window is not positive or less is nil.
+func (o *Options[T]) WithSortWindow(window time.Duration, less func(a, b T) bool) {
+	if window <= 0 {
+		panic("eventdistributor: WithSortWindow requires window > 0")
+	} else if less == nil {
+		panic("eventdistributor: WithSortWindow requires a less function")
+	}
+
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.sorter = &sortWindow[T]{
+			window:    window,
+			held:      sortHeap[T]{less: less, items: nil},
+			arrivals:  nil,
+			last:      *new(T),
+			hasLast:   false,
+			nextOrder: 0,
+			timer:     nil,
+		}
+	})
+}
+
+// OnLate adds a callback that is called with each event that is submitted too late to be sorted by
+// the Distributor's sort window, and was added to the buffer out of order. See WithSortWindow.
+func (o *Options[T]) OnLate(callback func(item T)) {
+	site := captureRegistrationSite()
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.onLate = append(d.onLate, watchCallback(d.watchdog, "OnLate", site, callback))
+	})
+}
+
+// sortWindow is the state of the sort window set by WithSortWindow
+type sortWindow[T any] struct {
+	window time.Duration
+	// held is the events that have not yet been released, in the order they'll be released
+	held sortHeap[T]
+	// arrivals is the held events in the order they were submitted. Events released early, because
+	// they came before an earlier submission, are only removed once they reach the front.
+	arrivals []*sortedEvent[T]
+	// last is the most recently released event, if hasLast is true
+	last    T
+	hasLast bool
+	// nextOrder is the submission order of the next held event
+	nextOrder uint64
+	// timer, if not nil, releases the first event in arrivals once it has been held for window
+	timer Timer
+}
+
+// sortedEvent is an event held by the sort window
+type sortedEvent[T any] struct {
+	value  T
+	caller []uintptr
+	// allConsumed is the channel returned when the event was submitted, if it was tracked
+	allConsumed chan struct{}
+	// onDone is the callback passed to SubmitWithCallback(), if any
+	onDone func(struct{})
+	// token is the event's identity, if any. See eventToken.
+	token   *eventToken
+	arrival time.Time
+	// order is the order in which events were submitted, used to break ties between equal events
+	order    uint64
+	released bool
+}
+
+// isLate returns whether the value comes before an event that has already been released
+func (s *sortWindow[T]) isLate(value T) bool {
+	return s.hasLast && s.held.less(value, s.last)
+}
+
+// stage holds the submitted event in the sort window, releasing any events that are ready. It
+// returns the channel that will be closed once the event is fully consumed - allConsumed, if it's
+// not nil - or nil if track is false.
+//
+// d.mu must be held.
+func (d *Distributor[T]) stage(
+	value T,
+	caller []uintptr,
+	allConsumed chan struct{},
+	onDone func(struct{}),
+	token *eventToken,
+	track bool,
+) <-chan struct{} {
+	s := d.sorter
+	now := d.getClock().Now()
+
+	if allConsumed == nil && track {
+		allConsumed = make(chan struct{})
+	}
+
+	e := &sortedEvent[T]{
+		value:       value,
+		caller:      caller,
+		allConsumed: allConsumed,
+		onDone:      onDone,
+		token:       token,
+		arrival:     now,
+		order:       s.nextOrder,
+		released:    false,
+	}
+	s.nextOrder += 1
+	heap.Push(&s.held, e)
+	s.arrivals = append(s.arrivals, e)
+
+	d.releaseSorted(now)
+	return allConsumed
+}
+
+// releaseSorted releases every held event that is ready at the time now, and arranges for the
+// next ones to be released when they're ready.
+//
+// d.mu must be held.
+func (d *Distributor[T]) releaseSorted(now time.Time) {
+	s := d.sorter
+	for len(s.arrivals) != 0 {
+		first := s.arrivals[0]
+		if !first.released {
+			if now.Before(first.arrival.Add(s.window)) {
+				break
+			}
+			// Everything that comes before the first event must be released before it.
+			for !first.released {
+				d.releaseSortedEvent(heap.Pop(&s.held).(*sortedEvent[T]))
+			}
+		}
+
+		s.arrivals[0] = nil
+		s.arrivals = s.arrivals[1:]
+	}
+
+	if s.timer != nil || len(s.arrivals) == 0 {
+		return
+	}
+
+	wait := s.arrivals[0].arrival.Add(s.window).Sub(now)
+	s.timer = d.getClock().AfterFunc(wait, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		s.timer = nil
+		d.releaseSorted(d.getClock().Now())
+	})
+}
+
+// releaseSortedEvent adds the held event to the buffer.
+//
+// d.mu must be held.
+func (d *Distributor[T]) releaseSortedEvent(e *sortedEvent[T]) {
+	s := d.sorter
+	e.released = true
+	s.last = e.value
+	s.hasLast = true
+	d.admit(e.value, e.caller, e.allConsumed, e.onDone, e.token, false, false)
+}
+
+// flushSorted releases every held event immediately, in order. See Seal().
+//
+// d.mu must be held.
+func (d *Distributor[T]) flushSorted() {
+	s := d.sorter
+	if s == nil {
+		return
+	}
+
+	d.stopSortTimer()
+	for s.held.Len() != 0 {
+		d.releaseSortedEvent(heap.Pop(&s.held).(*sortedEvent[T]))
+	}
+	s.arrivals = nil
+}
+
+// dropSorted drops every held event, because the Distributor was closed.
+//
+// d.mu must be held.
+func (d *Distributor[T]) dropSorted() {
+	s := d.sorter
+	if s == nil {
+		return
+	}
+
+	d.stopSortTimer()
+	for s.held.Len() != 0 {
+		e := heap.Pop(&s.held).(*sortedEvent[T])
+		d.dropped(e.value, -1, DropReasonClosed)
+		d.finish(e.allConsumed, e.onDone)
+	}
+	s.arrivals = nil
+}
+
+// stopSortTimer stops the timer that releases held events, if there is one.
+//
+// d.mu must be held.
+func (d *Distributor[T]) stopSortTimer() {
+	if d.sorter.timer != nil {
+		d.sorter.timer.Stop()
+		d.sorter.timer = nil
+	}
+}
+
+// sortHeap is a heap of held events, implementing heap.Interface. Ties are broken by the order in
+// which the events were submitted.
+type sortHeap[T any] struct {
+	less  func(a, b T) bool
+	items []*sortedEvent[T]
+}
+
+func (h *sortHeap[T]) Len() int { return len(h.items) }
+
+func (h *sortHeap[T]) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if h.less(a.value, b.value) {
+		return true
+	} else if h.less(b.value, a.value) {
+		return false
+	}
+	return a.order < b.order
+}
+
+func (h *sortHeap[T]) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *sortHeap[T]) Push(x any) { h.items = append(h.items, x.(*sortedEvent[T])) }
+
+func (h *sortHeap[T]) Pop() any {
+	last := len(h.items) - 1
+	item := h.items[last]
+	h.items[last] = nil
+	h.items = h.items[:last]
+	return item
+}
@@ -0,0 +1,80 @@
+package eventdistributor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestWithSortWindow(t *testing.T) {
+	clock := newFakeClock()
+	var late []int
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	options.WithSortWindow(100*time.Millisecond, func(a, b MyEvent) bool { return a.id < b.id })
+	options.OnLate(func(e MyEvent) { late = append(late, e.id) })
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	d.Submit(MyEvent{id: 3})
+	clock.Advance(40 * time.Millisecond)
+	allConsumed := d.Submit(MyEvent{id: 1})
+	d.Submit(MyEvent{id: 2})
+	notReady(t, r)
+
+	t.Log("once the first event has been held for the window, it's released with the ones before it")
+	clock.Advance(60 * time.Millisecond)
+	require.Equal(t, []int{1, 2, 3}, drainIDs(&r))
+	nowReady(t, allConsumed)
+
+	t.Log("events released early are not released again")
+	d.Submit(MyEvent{id: 5})
+	clock.Advance(50 * time.Millisecond)
+	d.Submit(MyEvent{id: 6})
+	clock.Advance(50 * time.Millisecond)
+	require.Equal(t, []int{5}, drainIDs(&r))
+	clock.Advance(50 * time.Millisecond)
+	require.Equal(t, []int{6}, drainIDs(&r))
+
+	t.Log("events that come before a released event are late")
+	d.Submit(MyEvent{id: 4})
+	require.Equal(t, []int{4}, drainIDs(&r))
+	require.Equal(t, []int{4}, late)
+
+	t.Log("Seal releases the held events")
+	d.Submit(MyEvent{id: 8})
+	d.Submit(MyEvent{id: 7})
+	notReady(t, r)
+	d.Seal()
+	require.Equal(t, []int{7, 8}, drainIDs(&r))
+}
+
+func TestWithSortWindowClose(t *testing.T) {
+	clock := newFakeClock()
+	var dropped []int
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	options.WithSortWindow(time.Second, func(a, b MyEvent) bool { return a.id < b.id })
+	options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+		require.Equal(t, eventdistributor.DropReasonClosed, reason)
+		dropped = append(dropped, e.id)
+	})
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	allConsumed := d.Submit(MyEvent{id: 1})
+	d.Close()
+	nowReady(t, allConsumed)
+	require.Equal(t, []int{1}, dropped)
+
+	// The timer no longer releases anything.
+	clock.Advance(time.Second)
+	require.Equal(t, []int{1}, dropped)
+}
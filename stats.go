@@ -0,0 +1,93 @@
+package eventdistributor
+
+import (
+	"time"
+)
+
+// ReaderStats is a snapshot of a Reader's counters, as returned by (*Reader[T]).Stats()
+type ReaderStats struct {
+	// Consumed is the total number of events delivered to the Reader
+	Consumed int64
+	// Skipped is the total number of events skipped by the Reader. See Skipped().
+	Skipped int64
+	// Pending is the number of events waiting for the Reader: buffered events after its position,
+	// along with any nacked or archived events that have yet to be delivered. Events that the
+	// Reader will skip, for example because they don't match its filter, are included.
+	Pending int
+	// LastConsumed is the time at which an event was last delivered to the Reader, according to
+	// the Distributor's Clock, or the zero value if none have been
+	LastConsumed time.Time
+}
+
+// Stats returns the Reader's counters. The returned ReaderStats is a copy, so it can be used
+// without any synchronization.
+//
+// Stats is thread-safe.
+func (r *Reader[T]) Stats() ReaderStats {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	pending := int(r.d.basePosition + int64(len(r.d.buf)) - r.position)
+	pending += len(r.backfill)
+	if r.acks != nil {
+		pending += len(r.acks.redeliver)
+	}
+
+	return ReaderStats{
+		Consumed:     r.consumed,
+		Skipped:      r.skipped,
+		Pending:      pending,
+		LastConsumed: r.lastConsumed,
+	}
+}
+
+// Stats is a snapshot of a Distributor's state and counters, as returned by
+// (*Distributor[T]).Stats()
+//
+// Every submitted event is eventually either fully consumed, dropped, or deduplicated, so at any
+// point, Submitted = FullyConsumed + Dropped + Deduped + Bufsize, plus any events held by the sort
+// window or gates. See WithSortWindow and SubscribeGate().
+type Stats struct {
+	// Bufsize is the number of events in the buffer
+	Bufsize int
+	// Readers is the number of active Readers
+	Readers int
+	// Submitted is the total number of events submitted, including those that were dropped
+	Submitted int64
+	// FullyConsumed is the total number of events that were fully consumed, including those that
+	// were discarded immediately. See OnFullyConsumed.
+	FullyConsumed int64
+	// Discarded is the total number of events that were discarded immediately when submitted,
+	// because no Reader would receive them
+	Discarded int64
+	// Dropped is the total number of events that were dropped. See OnDrop.
+	Dropped int64
+	// Deduped is the total number of events that weren't added to the buffer because an equal event
+	// was already buffered. See WithDedupPending.
+	Deduped int64
+	// MaxBufsize is the largest number of events that have been in the buffer at once
+	MaxBufsize int
+}
+
+// Stats returns the Distributor's counters, along with the current number of buffered events and
+// Readers. The returned Stats is a copy, so it can be used without any synchronization, and its
+// values are consistent with each other.
+//
+// Stats is thread-safe.
+func (d *Distributor[T]) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := d.stats
+	stats.Bufsize = len(d.buf)
+	stats.Readers = d.numReaders()
+	return stats
+}
+
+// noteSubmitted records that an event was submitted, calling OnSubmit callbacks.
+//
+// d.mu must be held.
+func (d *Distributor[T]) noteSubmitted(value T) {
+	d.stats.Submitted += 1
+	runCallbacks(&d.mu, d.onSubmit.fs, value)
+}
@@ -0,0 +1,81 @@
+package eventdistributor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestReaderStats(t *testing.T) {
+	clock := newFakeClock()
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	require.Equal(t, eventdistributor.ReaderStats{}, r.Stats())
+
+	d.Submit(MyEvent{id: 1})
+	d.Submit(MyEvent{id: 2})
+	d.Submit(MyEvent{id: 3})
+	clock.Skip(time.Second)
+	r.Consume()
+
+	require.Equal(t, eventdistributor.ReaderStats{
+		Consumed:     1,
+		Skipped:      0,
+		Pending:      2,
+		LastConsumed: clock.Now(),
+	}, r.Stats())
+
+	t.Log("nacked events are pending again")
+	acked := d.SubscribeAcked()
+	defer acked.Unsubscribe()
+	d.Submit(MyEvent{id: 4})
+	_, seq, err := acked.TryConsumeSeq()
+	require.NoError(t, err)
+	require.Equal(t, 0, acked.Stats().Pending)
+	require.NoError(t, acked.Nack(seq))
+	require.Equal(t, 1, acked.Stats().Pending)
+	require.EqualValues(t, 1, acked.Stats().Consumed)
+}
+
+func TestDistributorStats(t *testing.T) {
+	var d eventdistributor.Distributor[MyEvent]
+	require.Equal(t, eventdistributor.Stats{}, d.Stats())
+
+	d.Submit(MyEvent{id: 0})
+	r1 := d.Subscribe()
+	defer r1.Unsubscribe()
+	r2 := d.Subscribe()
+	d.Submit(MyEvent{id: 1})
+	d.Submit(MyEvent{id: 2})
+	d.Submit(MyEvent{id: 3})
+	r1.Consume()
+	r2.Consume()
+	require.Equal(t, eventdistributor.Stats{
+		Bufsize:       2,
+		Readers:       2,
+		Submitted:     4,
+		FullyConsumed: 2,
+		Discarded:     1,
+		Dropped:       0,
+		MaxBufsize:    3,
+	}, d.Stats())
+
+	r2.Unsubscribe()
+	require.Equal(t, 1, d.FilterInPlace(func(e MyEvent) bool { return e.id == 3 }))
+	require.Equal(t, eventdistributor.Stats{
+		Bufsize:       1,
+		Readers:       1,
+		Submitted:     4,
+		FullyConsumed: 2,
+		Discarded:     1,
+		Dropped:       1,
+		MaxBufsize:    3,
+	}, d.Stats())
+}
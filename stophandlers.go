@@ -0,0 +1,131 @@
+package eventdistributor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrHandlersNotDrained is matched by the error returned by StopHandlers() when some handlers
+// didn't finish handling their events in time.
+//
+// The returned error is a *HandlersNotDrainedError, which identifies the handlers.
+var ErrHandlersNotDrained = errors.New("handlers did not finish draining")
+
+// HandlersNotDrainedError is the error returned by StopHandlers() when its context expires before
+// every handler has finished handling its events. It matches both ErrHandlersNotDrained and the
+// context's error with errors.Is.
+type HandlersNotDrainedError struct {
+	// Handlers has an entry for each handler that hadn't finished, in the order they were added
+	Handlers []HandlerBacklog
+	// Err is the context's error
+	Err error
+}
+
+// HandlerBacklog describes a handler that hadn't finished draining when StopHandlers() gave up
+type HandlerBacklog struct {
+	// Name is the handler's name - see (*HandlerOptions[T]).WithName()
+	Name string
+	// Remaining is the number of events that the handler hadn't started handling
+	Remaining int
+}
+
+// Error implements the error interface
+func (e *HandlersNotDrainedError) Error() string {
+	return fmt.Sprintf("%d handlers did not finish draining: %v", len(e.Handlers), e.Err)
+}
+
+// Is allows errors.Is(err, ErrHandlersNotDrained) to match
+func (e *HandlersNotDrainedError) Is(target error) bool {
+	return target == ErrHandlersNotDrained
+}
+
+// Unwrap returns the context's error
+func (e *HandlersNotDrainedError) Unwrap() error {
+	return e.Err
+}
+
+// WithName sets the name that identifies the handler in errors from StopHandlers(). By default,
+// handlers are named "handler N", where N is the ID of the handler's Reader.
+func (o *HandlerOptions[T]) WithName(name string) {
+	o.modify = append(o.modify, func(h *managedHandler[T]) {
+		h.name = name
+	})
+}
+
+// StopHandlers stops every running handler created by AddHandler() or AddHandlerFunc() once it
+// has handled the events that were submitted before StopHandlers was called, and waits for them
+// to exit. Events submitted afterwards are not handled, and handlers added afterwards are
+// unaffected.
+//
+// If ctx expires first, StopHandlers stops waiting, and returns a *HandlersNotDrainedError with the
+// number of events left for each handler that hadn't finished. Those handlers exit once any event
+// they're handling is done - its context isn't canceled - without handling any more events, so
+// they may still be running when StopHandlers returns.
+//
+// The stop functions returned for each handler should still be called afterwards: they return
+// once the handler has exited, and re-raise any panic that stopped it, as usual. If StopHandlers
+// is called more than once, events are only handled up to the first call.
+//
+// StopHandlers is thread-safe.
+func (d *Distributor[T]) StopHandlers(ctx context.Context) error {
+	d.mu.Lock()
+	handlers := make([]*managedHandler[T], len(d.managed))
+	copy(handlers, d.managed)
+	tail := d.basePosition + int64(len(d.buf))
+	for _, h := range handlers {
+		if h.drainTo < 0 {
+			h.drainTo = tail
+			close(h.stopping)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, h := range handlers {
+		select {
+		case <-h.done:
+		case <-ctx.Done():
+			return d.haltHandlers(handlers, ctx.Err())
+		}
+	}
+	return nil
+}
+
+// haltHandlers makes any of the handlers that haven't exited exit as soon as possible, once
+// StopHandlers() gives up on waiting for them, returning the error describing them
+func (d *Distributor[T]) haltHandlers(handlers []*managedHandler[T], err error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var backlogs []HandlerBacklog
+	for _, h := range handlers {
+		// Handlers that are exiting have already unsubscribed.
+		if h.r.unsubscribed {
+			continue
+		}
+
+		if !h.halted {
+			h.halted = true
+			close(h.halt)
+		}
+		n := 0
+		if h.r.prepare() == nil && h.r.position < h.drainTo {
+			n = int(h.drainTo - h.r.position)
+		}
+		backlogs = append(backlogs, HandlerBacklog{Name: h.name, Remaining: n})
+	}
+	if len(backlogs) == 0 {
+		return nil
+	}
+	return &HandlersNotDrainedError{Handlers: backlogs, Err: err}
+}
+
+// drained returns whether the handler has handled every event it should before exiting, once
+// StopHandlers() has been called
+func (h *managedHandler[T]) drained() bool {
+	d := h.r.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return h.r.prepare() != nil || h.r.position >= h.drainTo || !h.r.available()
+}
@@ -0,0 +1,184 @@
+package eventdistributor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestStopHandlers(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	started := make(chan int)
+	release := make(chan struct{})
+	var handled []int
+	stop := d.AddHandler(func(e MyEvent) {
+		started <- e.id
+		<-release
+		handled = append(handled, e.id)
+	})
+
+	for i := 0; i < 3; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+	require.Equal(t, 0, <-started)
+
+	t.Log("handlers finish the events that were already submitted before exiting")
+	result := make(chan error)
+	go func() { result <- d.StopHandlers(context.Background()) }()
+	close(release)
+	require.Equal(t, 1, <-started)
+	require.Equal(t, 2, <-started)
+	require.NoError(t, <-result)
+
+	t.Log("once they've exited, they no longer receive events")
+	done := d.Submit(MyEvent{id: 3})
+	nowReady(t, done)
+	stop()
+	require.Equal(t, []int{0, 1, 2}, handled)
+
+	t.Log("without any handlers, there's nothing to wait for")
+	require.NoError(t, d.StopHandlers(context.Background()))
+}
+
+func TestStopHandlersDeadline(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	started := make(chan int)
+	release := make(chan struct{})
+	ctxErr := make(chan error, 1)
+	stopSlow := d.AddHandlerFunc(func(ctx context.Context, e MyEvent) error {
+		started <- e.id
+		<-release
+		ctxErr <- ctx.Err()
+		return nil
+	}, eventdistributor.WithName[MyEvent]("slow"))
+
+	// A second handler with the same name, which is stuck on its first event
+	stuckStarted := make(chan struct{}, 1)
+	stopStuck := d.AddHandler(func(e MyEvent) {
+		if e.id == 0 {
+			stuckStarted <- struct{}{}
+			<-release
+		}
+	}, eventdistributor.WithName[MyEvent]("slow"))
+
+	fast := make(chan int, 3)
+	stopFast := d.AddHandler(func(e MyEvent) { fast <- e.id })
+	defer stopFast()
+
+	for i := 0; i < 3; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+	require.Equal(t, 0, <-started)
+	<-stuckStarted
+	for i := 0; i < 3; i++ {
+		require.Equal(t, i, <-fast)
+	}
+
+	t.Log("handlers that don't finish in time are each reported, with the number of events left")
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() { result <- d.StopHandlers(ctx) }()
+	require.Eventually(t, func() bool { return d.Stats().Readers == 2 }, time.Second, time.Millisecond)
+	cancel()
+	err := <-result
+	require.ErrorIs(t, err, eventdistributor.ErrHandlersNotDrained)
+	require.ErrorIs(t, err, context.Canceled)
+	var notDrained *eventdistributor.HandlersNotDrainedError
+	require.True(t, errors.As(err, &notDrained))
+	expected := []eventdistributor.HandlerBacklog{{Name: "slow", Remaining: 2}, {Name: "slow", Remaining: 2}}
+	require.Equal(t, expected, notDrained.Handlers)
+	require.Equal(t, "2 handlers did not finish draining: context canceled", err.Error())
+
+	t.Log("the event being handled is allowed to finish, but no more are handled")
+	close(release)
+	require.NoError(t, <-ctxErr)
+	stopSlow()
+	stopStuck()
+	select {
+	case id := <-started:
+		t.Fatalf("halted handler started handling event %d", id)
+	default:
+	}
+}
+
+func TestStopHandlersLateSubmit(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	started := make(chan int, 2)
+	release := make(chan struct{})
+	var handled []int
+	stop := d.AddHandler(func(e MyEvent) {
+		started <- e.id
+		<-release
+		handled = append(handled, e.id)
+	})
+
+	fast := make(chan int, 1)
+	stopFast := d.AddHandler(func(e MyEvent) { fast <- e.id })
+	defer stopFast()
+
+	d.Submit(MyEvent{id: 0})
+	require.Equal(t, 0, <-started)
+	require.Equal(t, 0, <-fast)
+
+	result := make(chan error)
+	go func() { result <- d.StopHandlers(context.Background()) }()
+	// The fast handler has nothing left to drain, so it exits as soon as StopHandlers is called.
+	require.Eventually(t, func() bool {
+		return d.Stats().Readers == 1
+	}, time.Second, time.Millisecond)
+
+	t.Log("events submitted while a handler is still draining aren't handled")
+	d.Submit(MyEvent{id: 1})
+	close(release)
+	require.NoError(t, <-result)
+	stop()
+	require.Equal(t, []int{0}, handled)
+}
+
+func TestStopHandlersAfterClear(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+
+	started := make(chan int, 2)
+	release := make(chan struct{})
+	var handled []int
+	stop := d.AddHandler(func(e MyEvent) {
+		started <- e.id
+		<-release
+		handled = append(handled, e.id)
+	})
+
+	fast := make(chan int, 3)
+	stopFast := d.AddHandler(func(e MyEvent) { fast <- e.id })
+	defer stopFast()
+
+	for i := 0; i < 3; i++ {
+		d.Submit(MyEvent{id: i})
+	}
+	require.Equal(t, 0, <-started)
+	for i := 0; i < 3; i++ {
+		require.Equal(t, i, <-fast)
+	}
+
+	result := make(chan error)
+	go func() { result <- d.StopHandlers(context.Background()) }()
+	// The fast handler has nothing left to drain, so it exits as soon as StopHandlers is called.
+	require.Eventually(t, func() bool {
+		return d.Stats().Readers == 1
+	}, time.Second, time.Millisecond)
+
+	t.Log("events removed while draining no longer count towards the handler's backlog")
+	d.Clear()
+	d.Submit(MyEvent{id: 3})
+	close(release)
+	require.NoError(t, <-result)
+	stop()
+	require.Equal(t, []int{0}, handled)
+}
@@ -0,0 +1,93 @@
+package eventdistributor
+
+import (
+	"unsafe"
+)
+
+// SubmitAll submits v1 to d1 and v2 to d2 as a single atomic step, returning the channels that
+// Submit would have returned for each.
+//
+// Both Distributors' locks are held while the events are added, so no Reader or other producer can
+// observe one event without the other having already been added. If either Distributor is sealed,
+// neither event is added; both are passed to their Distributor's OnDrop callbacks with
+// DropReasonSealed. Likewise, if either was created by NewFixed() and has no room for its event -
+// following its OverflowPolicy, where OverflowBlock drops the new event - neither event is added,
+// and both are passed to OnDrop callbacks with DropReasonOverflow.
+//
+// Deadlocks between concurrent calls are avoided by always locking the Distributors in the same
+// order. d1 and d2 may be the same Distributor, in which case v1 is added immediately before v2.
+//
+// Callbacks (OnSubmit, OnBufsizeChange, etc.) still run separately for each Distributor - first
+// d1's, then d2's - once both locks are released.
+//
+// SubmitAll does not consult submit limiters or wait for lagging Readers, because holding back
+// only one of the events would break atomicity. See (*Options[T]).WithSubmitLimiter() and
+// (*Options[T]).WithMaxReaderLag().
+//
+// SubmitAll is thread-safe.
+func SubmitAll[T1, T2 any](
+	d1 *Distributor[T1],
+	v1 T1,
+	d2 *Distributor[T2],
+	v2 T2,
+) (<-chan struct{}, <-chan struct{}) {
+	caller1, caller2 := d1.captureCaller(), d2.captureCaller()
+
+	unlock := lockInOrder(&d1.mu, &d2.mu)
+	defer unlock()
+	d1.absorbPublished()
+	d2.absorbPublished()
+
+	if d1.sealed || d2.sealed {
+		d1.noteSubmitted(v1)
+		d1.dropped(v1, -1, DropReasonSealed)
+		d2.noteSubmitted(v2)
+		d2.dropped(v2, -1, DropReasonSealed)
+		return closedChannel, closedChannel
+	}
+
+	// Neither event is added unless both can be, so if either Distributor is full, both events are
+	// dropped, as if both were full. If d1 and d2 are the same, there must be room for both events.
+	room1, room2 := d1.hasRoom(), d2.hasRoom()
+	if &d1.mu == &d2.mu {
+		room1 = d1.hasRoomFor(2)
+		room2 = room1
+	}
+	if !room1 || !room2 {
+		d1.noteSubmitted(v1)
+		d1.dropped(v1, -1, DropReasonOverflow)
+		d2.noteSubmitted(v2)
+		d2.dropped(v2, -1, DropReasonOverflow)
+		return closedChannel, closedChannel
+	}
+
+	allConsumed1, _ := d1.submit(v1, caller1, nil, nil, nil, true, false)
+	allConsumed2, _ := d2.submit(v2, caller2, nil, nil, nil, true, false)
+	return allConsumed1, allConsumed2
+}
+
+// lockInOrder locks both mutexes in a canonical order - by address - returning a function that
+// unlocks them. If a and b are the same mutex, it is only locked once.
+//
+// Queued callbacks are only run once both mutexes are unlocked, first a's and then b's, so that
+// callbacks for one Distributor can call methods on the other.
+func lockInOrder(a, b *callbackMutex) (unlock func()) {
+	if a == b {
+		a.Lock()
+		return a.Unlock
+	}
+
+	// The garbage collector doesn't move heap objects, so the addresses give a consistent order.
+	first, second := a, b
+	if uintptr(unsafe.Pointer(first)) > uintptr(unsafe.Pointer(second)) {
+		first, second = second, first
+	}
+	first.Lock()
+	second.Lock()
+	return func() {
+		second.unlockQuietly()
+		first.unlockQuietly()
+		a.flush()
+		b.flush()
+	}
+}
@@ -0,0 +1,140 @@
+package eventdistributor_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestSubmitAll(t *testing.T) {
+	commands := eventdistributor.New[MyEvent]()
+	audit := eventdistributor.New[string]()
+
+	cr := commands.Subscribe()
+	defer cr.Unsubscribe()
+	ar := audit.Subscribe()
+	defer ar.Unsubscribe()
+
+	t.Log("concurrent readers never see one event without the other")
+	const count = 1000
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < count; i++ {
+			<-ar.WaitChan()
+			ar.Consume()
+			_, err := cr.TryConsume()
+			if err != nil {
+				t.Errorf("audit event %d visible before its command: %v", i, err)
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < count; i++ {
+		eventdistributor.SubmitAll(commands, MyEvent{id: i}, audit, "command")
+	}
+	<-done
+}
+
+func TestSubmitAllLockOrder(t *testing.T) {
+	a := eventdistributor.New[MyEvent]()
+	b := eventdistributor.New[MyEvent]()
+
+	t.Log("opposite argument orders don't deadlock")
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			eventdistributor.SubmitAll(a, MyEvent{id: i}, b, MyEvent{id: i})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			eventdistributor.SubmitAll(b, MyEvent{id: i}, a, MyEvent{id: i})
+		}
+	}()
+	wg.Wait()
+
+	t.Log("the same Distributor twice")
+	r := a.Subscribe()
+	defer r.Unsubscribe()
+	eventdistributor.SubmitAll(a, MyEvent{id: 1}, a, MyEvent{id: 2})
+	require.Equal(t, []int{1, 2}, drainIDs(&r))
+}
+
+func TestSubmitAllSealed(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var dropped []int
+	options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+		require.Equal(t, eventdistributor.DropReasonSealed, reason)
+		dropped = append(dropped, e.id)
+	})
+	a := eventdistributor.New(options)
+	b := eventdistributor.New(options)
+
+	ra := a.Subscribe()
+	defer ra.Unsubscribe()
+	b.Seal()
+
+	t.Log("if either is sealed, neither event is added")
+	c1, c2 := eventdistributor.SubmitAll(a, MyEvent{id: 1}, b, MyEvent{id: 2})
+	nowReady(t, c1)
+	nowReady(t, c2)
+	require.Equal(t, []int{1, 2}, dropped)
+	notReady(t, ra)
+}
+
+func TestSubmitAllFull(t *testing.T) {
+	var options eventdistributor.Options[MyEvent]
+	var dropped []int
+	options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+		require.Equal(t, eventdistributor.DropReasonOverflow, reason)
+		dropped = append(dropped, e.id)
+	})
+	a := eventdistributor.New(options)
+	full, err := eventdistributor.NewFixed(1, eventdistributor.OverflowDropNewest, options)
+	require.NoError(t, err)
+
+	ra := a.Subscribe()
+	defer ra.Unsubscribe()
+	rf := full.Subscribe()
+	defer rf.Unsubscribe()
+	full.Submit(MyEvent{id: 0})
+
+	t.Log("if either has no room, neither event is added")
+	c1, c2 := eventdistributor.SubmitAll(a, MyEvent{id: 1}, full, MyEvent{id: 2})
+	nowReady(t, c1)
+	nowReady(t, c2)
+	require.Equal(t, []int{1, 2}, dropped)
+	notReady(t, ra)
+	require.Equal(t, []int{0}, drainIDs(&rf))
+
+	t.Log("once there's room, both are added")
+	eventdistributor.SubmitAll(a, MyEvent{id: 3}, full, MyEvent{id: 4})
+	require.Equal(t, []int{3}, drainIDs(&ra))
+	require.Equal(t, []int{4}, drainIDs(&rf))
+
+	t.Log("OverflowDropOldest makes room on both sides")
+	oldest, err := eventdistributor.NewFixed(1, eventdistributor.OverflowDropOldest, options)
+	require.NoError(t, err)
+	ro := oldest.Subscribe()
+	defer ro.Unsubscribe()
+	oldest.Submit(MyEvent{id: 5})
+	dropped = nil
+	eventdistributor.SubmitAll(a, MyEvent{id: 6}, oldest, MyEvent{id: 7})
+	require.Equal(t, []int{5}, dropped)
+	require.Equal(t, []int{6}, drainIDs(&ra))
+	require.Equal(t, []int{7}, drainIDs(&ro))
+
+	t.Log("the same Distributor needs room for both events")
+	dropped = nil
+	eventdistributor.SubmitAll(full, MyEvent{id: 8}, full, MyEvent{id: 9})
+	require.Equal(t, []int{8, 9}, dropped)
+	notReady(t, rf)
+}
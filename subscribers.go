@@ -0,0 +1,38 @@
+package eventdistributor
+
+// OnFirstSubscriber adds a callback that is called whenever the number of Readers goes from zero to
+// one - for example, to start a producer that should only run while there's something to receive
+// its events.
+//
+// Calls to OnFirstSubscriber and OnLastUnsubscribed callbacks always alternate, starting with
+// OnFirstSubscriber, because callbacks are called in the order of the changes they report. See
+// Options.
+func (o *Options[T]) OnFirstSubscriber(callback func()) {
+	site := captureRegistrationSite()
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		watched := watchCallback(d.watchdog, "OnFirstSubscriber", site, func(struct{}) { callback() })
+		d.onFirstSub = append(d.onFirstSub, watched)
+	})
+}
+
+// OnLastUnsubscribed adds a callback that is called whenever the number of Readers goes from one to
+// zero, whether because the last Reader was unsubscribed, expired, or released by Close(). See
+// OnFirstSubscriber.
+func (o *Options[T]) OnLastUnsubscribed(callback func()) {
+	site := captureRegistrationSite()
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		watched := watchCallback(d.watchdog, "OnLastUnsubscribed", site, func(struct{}) { callback() })
+		d.onLastUnsub = append(d.onLastUnsub, watched)
+	})
+}
+
+// numReaders returns the number of active Readers. The seed of a fork isn't counted, because it
+// isn't visible outside the Distributor until it's taken by Subscribe(). See ForkAt().
+//
+// d.mu must be held.
+func (d *Distributor[T]) numReaders() int {
+	if d.seed != nil {
+		return len(d.readers) - 1
+	}
+	return len(d.readers)
+}
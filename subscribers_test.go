@@ -0,0 +1,39 @@
+package eventdistributor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestOnFirstSubscriber(t *testing.T) {
+	var calls []string
+	var options eventdistributor.Options[MyEvent]
+	options.OnFirstSubscriber(func() { calls = append(calls, "first") })
+	options.OnLastUnsubscribed(func() { calls = append(calls, "last") })
+	d := eventdistributor.New(options)
+
+	r1 := d.Subscribe()
+	r1.Unsubscribe()
+	require.Equal(t, []string{"first", "last"}, calls)
+
+	t.Log("only the transitions to and from zero Readers are reported")
+	calls = nil
+	r1 = d.Subscribe()
+	r2 := d.Subscribe()
+	r1.Unsubscribe()
+	r3 := d.Subscribe()
+	r2.Unsubscribe()
+	require.Equal(t, []string{"first"}, calls)
+	r3.Unsubscribe()
+	require.Equal(t, []string{"first", "last"}, calls)
+
+	t.Log("closing the Distributor releases the remaining Readers")
+	calls = nil
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Close()
+	require.Equal(t, []string{"first", "last"}, calls)
+}
@@ -0,0 +1,95 @@
+package eventdistributor
+
+import (
+	"time"
+)
+
+// WithEventTTL limits how long events can stay in the buffer: once an event has been buffered for
+// ttl, according to the Distributor's Clock, it is dropped with DropReasonExpired, even if some
+// Readers haven't consumed it yet. Those Readers skip it, as counted by (*Reader[T]).Skipped().
+// Like other dropped events, expired events are reported to OnDrop callbacks instead of
+// OnFullyConsumed.
+//
+// Events are expired by a timer, so that they're dropped even if the Distributor is otherwise idle,
+// and also whenever an event is submitted or a Reader is used, so that no Reader receives an event
+// that has expired.
+//
+// WithEventTTL enables timestamps, as if by WithTimestamps(), because they're required to know how
+// long events have been buffered.
+//
+// WithEventTTL panics if ttl is not positive.
+func (o *Options[T]) WithEventTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		panic("eventdistributor: WithEventTTL requires ttl > 0")
+	}
+
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.timestamps = true
+		d.ttl = &eventTTL{ttl: ttl, timer: nil}
+	})
+}
+
+// eventTTL is the state of a Distributor with WithEventTTL
+type eventTTL struct {
+	ttl time.Duration
+	// timer, if not nil, expires the oldest buffered event once it's due
+	timer Timer
+}
+
+// expireEvents drops every buffered event that has been buffered for at least the TTL, if there is
+// one, and arranges for the next one to be dropped when it's due. See WithEventTTL.
+//
+// d.mu must be held.
+func (d *Distributor[T]) expireEvents() {
+	t := d.ttl
+	if t == nil || d.closed {
+		return
+	}
+
+	now := d.getClock().Now()
+	expired := false
+	// Events buffered before timestamps were enabled never expire.
+	for len(d.buf) != 0 && !d.buf[0].timestamp.IsZero() {
+		if now.Sub(d.buf[0].timestamp) < t.ttl {
+			break
+		}
+		d.dropFront(DropReasonExpired)
+		expired = true
+	}
+	if expired {
+		runCallbacks(&d.mu, d.onBufsizeChange.fs, len(d.buf))
+	}
+
+	d.armTTLTimer(now)
+}
+
+// armTTLTimer starts the timer that expires the oldest buffered event, if it isn't already running.
+//
+// d.mu must be held.
+func (d *Distributor[T]) armTTLTimer(now time.Time) {
+	t := d.ttl
+	if t == nil || t.timer != nil || len(d.buf) == 0 || d.buf[0].timestamp.IsZero() {
+		return
+	}
+
+	// The oldest event may be consumed before the timer fires, in which case it just starts again
+	// for the next one.
+	wait := d.buf[0].timestamp.Add(t.ttl).Sub(now)
+	t.timer = d.getClock().AfterFunc(wait, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		t.timer = nil
+		d.expireEvents()
+	})
+}
+
+// stopTTLTimer stops the timer that expires buffered events, if there is one.
+//
+// d.mu must be held.
+func (d *Distributor[T]) stopTTLTimer() {
+	if d.ttl != nil && d.ttl.timer != nil {
+		d.ttl.timer.Stop()
+		d.ttl.timer = nil
+	}
+}
@@ -0,0 +1,73 @@
+package eventdistributor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestWithEventTTL(t *testing.T) {
+	clock := newFakeClock()
+	var expired, consumed []int
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	options.WithEventTTL(time.Second)
+	options.OnDrop(func(e MyEvent, reason eventdistributor.DropReason) {
+		require.Equal(t, eventdistributor.DropReasonExpired, reason)
+		expired = append(expired, e.id)
+	})
+	options.OnFullyConsumed(func(e MyEvent) { consumed = append(consumed, e.id) })
+	d := eventdistributor.New(options)
+
+	fast := d.Subscribe()
+	defer fast.Unsubscribe()
+	slow := d.Subscribe()
+	defer slow.Unsubscribe()
+
+	allConsumed := d.Submit(MyEvent{id: 0})
+	clock.Advance(500 * time.Millisecond)
+	d.Submit(MyEvent{id: 1})
+	require.Equal(t, []int{0, 1}, drainIDs(&fast))
+
+	t.Log("the timer expires events even while the Distributor is idle")
+	clock.Advance(500 * time.Millisecond)
+	require.Equal(t, []int{0}, expired)
+	require.Empty(t, consumed)
+	nowReady(t, allConsumed)
+	require.EqualValues(t, 1, slow.Skipped())
+
+	clock.Advance(500 * time.Millisecond)
+	require.Equal(t, []int{0, 1}, expired)
+	notReady(t, slow)
+	require.Equal(t, 0, d.Stats().Bufsize)
+
+	t.Log("events that are consumed in time are not expired")
+	d.Submit(MyEvent{id: 2})
+	require.Equal(t, []int{2}, drainIDs(&fast))
+	require.Equal(t, []int{2}, drainIDs(&slow))
+	require.Equal(t, []int{2}, consumed)
+	clock.Advance(time.Second)
+	require.Equal(t, []int{0, 1}, expired)
+}
+
+func TestWithEventTTLLazy(t *testing.T) {
+	clock := newFakeClock()
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	options.WithEventTTL(time.Second)
+	d := eventdistributor.New(options)
+
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	t.Log("expired events are never delivered, even before the timer fires")
+	d.Submit(MyEvent{id: 0})
+	clock.Skip(time.Second)
+	notReady(t, r)
+	_, err := r.TryConsume()
+	require.ErrorIs(t, err, eventdistributor.ErrNoEvent)
+	require.EqualValues(t, 1, r.Skipped())
+}
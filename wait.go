@@ -0,0 +1,119 @@
+package eventdistributor
+
+import (
+	"math"
+	"time"
+)
+
+// OnWaitStart adds a callback that is called with a Reader's ID when it starts waiting for an event
+// - i.e., when its WaitChan() returns a channel that isn't closed yet, or its Signal starts
+// waiting. Calls to WaitChan() while the Reader is already waiting don't start a new wait.
+//
+// Together with OnWaitEnd, it can be used to measure how long Readers spend idle.
+func (o *Options[T]) OnWaitStart(callback func(readerID uint64)) {
+	site := captureRegistrationSite()
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.onWaitStart = append(d.onWaitStart, watchCallback(d.watchdog, "OnWaitStart", site, callback))
+	})
+}
+
+// OnWaitEnd adds a callback that is called with a Reader's ID and how long it waited, when a wait
+// started by OnWaitStart ends. A wait ends once there is an event available for the Reader, or the
+// Reader can no longer be used - for example, because it was unsubscribed or the Distributor was
+// closed. Each wait ends exactly once, and Readers that never waited don't have waits that end.
+//
+// Waits are tracked per Reader, so a wait ends when an event becomes available even if the Reader
+// has stopped waiting on its channel.
+func (o *Options[T]) OnWaitEnd(callback func(readerID uint64, waited time.Duration)) {
+	site := captureRegistrationSite()
+	o.modify = append(o.modify, func(d *Distributor[T]) {
+		d.onWaitEnd = append(d.onWaitEnd, watchCallback2(d.watchdog, "OnWaitEnd", site, callback))
+	})
+}
+
+// tracksWaits returns whether the Distributor has any OnWaitStart or OnWaitEnd callbacks
+func (d *Distributor[T]) tracksWaits() bool {
+	return len(d.onWaitStart) != 0 || len(d.onWaitEnd) != 0
+}
+
+// startWait records that the Reader has started waiting, if it isn't already. See OnWaitStart.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) startWait() {
+	if !r.d.tracksWaits() || !r.waitStart.IsZero() {
+		return
+	}
+
+	r.waitStart = r.d.getClock().Now()
+	runCallbacks(&r.d.mu, r.d.onWaitStart, r.id)
+}
+
+// endWait records that the Reader's wait has ended, if it was waiting. See OnWaitEnd.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) endWait() {
+	if r.waitStart.IsZero() {
+		return
+	}
+
+	waited := r.d.getClock().Now().Sub(r.waitStart)
+	r.waitStart = time.Time{}
+	runCallbacks2(&r.d.mu, r.d.onWaitEnd, r.id, waited)
+}
+
+// endWaits ends the wait of every waiting Reader that now has an event available.
+//
+// d.mu must be held.
+func (d *Distributor[T]) endWaits() {
+	if !d.tracksWaits() {
+		return
+	}
+
+	for _, r := range d.readers {
+		if !r.waitStart.IsZero() && r.reader().available() {
+			r.reader().endWait()
+		}
+	}
+}
+
+// availableFast returns whether the Reader has an event available, without d.mu. It's used by
+// WaitChan() to avoid the lock when the Reader is keeping up with a busy producer.
+//
+// It only reports events at the Reader's position, and only for Readers that can't skip or defer
+// them, so a false result doesn't mean there's nothing available - the caller must check again
+// with the lock held.
+//
+// The result was correct at some point during the call: a Reader's position is published as soon
+// as it changes, without releasing d.mu in between, and the published end of the buffer only moves
+// back while fastFilter is odd, so the two values that are read are never from incompatible states.
+func (r *Reader[T]) availableFast() bool {
+	gen := r.d.fastFilter.Load()
+	if gen%2 != 0 {
+		return false
+	}
+
+	tail := r.d.fastTail.Load()
+	position := r.fastPos.Load()
+	return position < tail && r.d.fastFilter.Load() == gen
+}
+
+// publishPosition updates the Reader's position as seen by availableFast, or hides it if the Reader
+// may skip or defer the events at its position. It must be called whenever the Reader's position
+// changes, or it starts or stops skipping or deferring events.
+//
+// r.d.mu must be held.
+func (r *Reader[T]) publishPosition() {
+	if r.filter != nil || r.maxAge != 0 || r.lifo != nil || r.deferral != nil || r.d.ttl != nil {
+		r.fastPos.Store(math.MaxInt64)
+	} else {
+		r.fastPos.Store(r.position)
+	}
+}
+
+// publishTail updates the end of the buffer as seen by availableFast. It must be called whenever
+// events are added to the end of the buffer, or removed from the middle of it.
+//
+// d.mu must be held.
+func (d *Distributor[T]) publishTail() {
+	d.fastTail.Store(d.basePosition + int64(len(d.buf)))
+}
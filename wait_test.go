@@ -0,0 +1,334 @@
+package eventdistributor_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestOnWaitStartEnd(t *testing.T) {
+	clock := newFakeClock()
+	var starts []uint64
+	waits := make(map[uint64][]time.Duration)
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	options.OnWaitStart(func(id uint64) { starts = append(starts, id) })
+	options.OnWaitEnd(func(id uint64, waited time.Duration) { waits[id] = append(waits[id], waited) })
+	d := eventdistributor.New(options)
+
+	r1 := d.Subscribe()
+	defer r1.Unsubscribe()
+	r2 := d.Subscribe()
+	r3 := d.Subscribe()
+	defer r3.Unsubscribe()
+
+	t.Log("each Reader has its own wait")
+	r1.WaitChan()
+	clock.Skip(time.Second)
+	r2.WaitChan()
+	r2.WaitChan()
+	require.Equal(t, []uint64{r1.ID(), r2.ID()}, starts)
+
+	clock.Skip(time.Second)
+	d.Submit(MyEvent{id: 1})
+	require.Equal(t, map[uint64][]time.Duration{
+		r1.ID(): {2 * time.Second},
+		r2.ID(): {time.Second},
+	}, waits)
+
+	t.Log("Readers that never waited don't end a wait")
+	require.Equal(t, []int{1}, drainIDs(&r3))
+	require.NotContains(t, waits, r3.ID())
+
+	t.Log("unsubscribing ends a wait")
+	drainIDs(&r2)
+	r2.WaitChan()
+	clock.Skip(time.Second)
+	r2.Unsubscribe()
+	require.Equal(t, []time.Duration{time.Second, time.Second}, waits[r2.ID()])
+
+	t.Log("filtered Readers only stop waiting for events they receive")
+	drainIDs(&r1)
+	starts = nil
+	filtered := d.SubscribeFiltered(func(e MyEvent) bool { return e.id%2 == 0 })
+	defer filtered.Unsubscribe()
+	filtered.WaitChan()
+	d.Submit(MyEvent{id: 3})
+	require.Equal(t, []uint64{filtered.ID()}, starts)
+	require.NotContains(t, waits, filtered.ID())
+	d.Submit(MyEvent{id: 4})
+	require.Len(t, waits[filtered.ID()], 1)
+}
+
+func TestWaitChanWakesOnlyReceivers(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r1 := d.Subscribe()
+	defer r1.Unsubscribe()
+	r2 := d.Subscribe()
+	defer r2.Unsubscribe()
+	even := d.SubscribeFiltered(func(e MyEvent) bool { return e.id%2 == 0 })
+	defer even.Unsubscribe()
+	// slow keeps the events buffered, so that the other Readers can be rewound.
+	slow := d.Subscribe()
+	defer slow.Unsubscribe()
+
+	d.Submit(MyEvent{id: 0})
+	d.Submit(MyEvent{id: 1})
+	drainIDs(&r1)
+	drainIDs(&r2)
+	drainIDs(&even)
+	wait1, wait2, waitEven := r1.WaitChan(), r2.WaitChan(), even.WaitChan()
+
+	t.Log("rewinding a waiting Reader wakes it, but not Readers with their own channel")
+	n, err := r1.Rewind(1)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	nowReady(t, wait1)
+	nowNotReady(t, waitEven)
+	require.Equal(t, []int{1}, drainIDs(&r1))
+	// r2 shares r1's channel, so it was woken too, but has nothing to receive.
+	notReady(t, r2)
+	wait2 = r2.WaitChan()
+
+	t.Log("seeking backwards wakes the Reader too")
+	wait1 = r1.WaitChan()
+	require.NoError(t, r1.SeekTo(0))
+	nowReady(t, wait1)
+	require.Equal(t, []int{0, 1}, drainIDs(&r1))
+
+	t.Log("a new event only wakes the Readers that receive it")
+	wait1 = r1.WaitChan()
+	d.Submit(MyEvent{id: 3})
+	nowReady(t, wait1)
+	nowReady(t, wait2)
+	nowNotReady(t, waitEven)
+	d.Submit(MyEvent{id: 4})
+	nowReady(t, waitEven)
+	require.Equal(t, []int{4}, drainIDs(&even))
+}
+
+func TestWaitChanAllocations(t *testing.T) {
+	d := eventdistributor.New[MyEvent]()
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+
+	// Checks are made without require, which may allocate.
+	d.Submit(MyEvent{id: 0})
+	allocs := testing.AllocsPerRun(100, func() {
+		select {
+		case <-r.WaitChan():
+		default:
+			t.Fatal("WaitChan not ready")
+		}
+	})
+	require.Zero(t, allocs, "an available event allocated")
+
+	drainIDs(&r)
+	wait := r.WaitChan()
+	allocs = testing.AllocsPerRun(100, func() {
+		if r.WaitChan() != wait {
+			t.Fatal("waiting Reader got a new channel")
+		}
+	})
+	require.Zero(t, allocs, "waiting again allocated")
+}
+
+// TestWaitChanSkippedEvents checks that WaitChan() isn't closed by events that the Reader would
+// skip instead of receiving, even though they're at its position in the buffer
+func TestWaitChanSkippedEvents(t *testing.T) {
+	clock := newFakeClock()
+	var options eventdistributor.Options[MyEvent]
+	options.WithClock(clock)
+	options.WithTimestamps()
+	d := eventdistributor.New(options)
+	hold := d.Subscribe()
+	defer hold.Unsubscribe()
+
+	t.Log("events that are too old for a lossy Reader")
+	lossy := d.SubscribeLossyByAge(time.Second)
+	defer lossy.Unsubscribe()
+	d.Submit(MyEvent{id: 0})
+	clock.Skip(2 * time.Second)
+	nowNotReady(t, lossy.WaitChan())
+
+	t.Log("events that don't match a filter set after they were submitted")
+	filtered := d.Subscribe()
+	defer filtered.Unsubscribe()
+	d.Submit(MyEvent{id: 1})
+	filtered.SetFilter(func(e MyEvent) bool { return e.id != 1 })
+	nowNotReady(t, filtered.WaitChan())
+
+	t.Log("events already received by a LIFO Reader")
+	lifo := d.SubscribeLIFO()
+	defer lifo.Unsubscribe()
+	d.Submit(MyEvent{id: 2})
+	d.Submit(MyEvent{id: 3})
+	require.Equal(t, 3, lifo.Consume().id)
+	require.Equal(t, 2, lifo.Consume().id)
+	nowNotReady(t, lifo.WaitChan())
+
+	t.Log("events that have expired")
+	options.WithEventTTL(time.Second)
+	d = eventdistributor.New(options)
+	r := d.Subscribe()
+	defer r.Unsubscribe()
+	d.Submit(MyEvent{id: 4})
+	clock.Skip(2 * time.Second)
+	nowNotReady(t, r.WaitChan())
+}
+
+// TestWaitChanConcurrentFilterInPlace checks that a Reader woken by WaitChan() always has an event
+// to receive while FilterInPlace() is concurrently removing events that it has already received,
+// which moves both the Reader and the end of the buffer back
+func TestWaitChanConcurrentFilterInPlace(t *testing.T) {
+	const events = 10000
+	d := eventdistributor.New[MyEvent]()
+	hold := d.Subscribe()
+	defer hold.Unsubscribe()
+	r := d.Subscribe()
+
+	// received is the ID of the last event received by r, so every event up to it is behind r.
+	var received atomic.Int64
+	received.Store(-1)
+	var failures atomic.Int64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer r.Unsubscribe()
+		for {
+			<-r.WaitChan()
+			e, err := r.TryConsume()
+			if err == eventdistributor.ErrClosed {
+				return
+			} else if err != nil {
+				failures.Add(1)
+				continue
+			}
+			received.Store(int64(e.id))
+		}
+	}()
+
+	for i := 0; i < events; i++ {
+		d.SubmitQuiet(MyEvent{id: i})
+		if i%4 == 0 {
+			seen := received.Load()
+			d.FilterInPlace(func(e MyEvent) bool { return int64(e.id) <= seen })
+		}
+	}
+	d.Close()
+	<-done
+	require.Zero(t, failures.Load(), "WaitChan was closed without an event to receive")
+}
+
+// BenchmarkManyWaiters measures a single producer submitting to many Readers, each waiting with
+// WaitChan() in its own goroutine. Filtered Readers each receive one in every 16 events.
+func BenchmarkManyWaiters(b *testing.B) {
+	for _, filtered := range []bool{false, true} {
+		for _, readers := range []int{100, 2000} {
+			b.Run(fmt.Sprintf("filtered=%v/readers=%d", filtered, readers), func(b *testing.B) {
+				d := eventdistributor.New[MyEvent]()
+
+				var wg sync.WaitGroup
+				for i := 0; i < readers; i++ {
+					var r eventdistributor.Reader[MyEvent]
+					if filtered {
+						i := i
+						r = d.SubscribeFiltered(func(e MyEvent) bool { return e.id%16 == i%16 })
+					} else {
+						r = d.Subscribe()
+					}
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						defer r.Unsubscribe()
+						for {
+							<-r.WaitChan()
+							if _, err := r.TryConsume(); err == eventdistributor.ErrClosed {
+								return
+							}
+						}
+					}()
+				}
+
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					d.SubmitQuiet(MyEvent{id: i})
+				}
+				d.Close()
+				wg.Wait()
+			})
+		}
+	}
+}
+
+// BenchmarkBroadcastWake measures waking many waiting Readers, with the producer waiting for every
+// Reader to receive each event before submitting the next
+func BenchmarkBroadcastWake(b *testing.B) {
+	for _, readers := range []int{100, 2000} {
+		b.Run(fmt.Sprintf("readers=%d", readers), func(b *testing.B) {
+			d := eventdistributor.New[MyEvent]()
+
+			var received, done sync.WaitGroup
+			for i := 0; i < readers; i++ {
+				r := d.Subscribe()
+				done.Add(1)
+				go func() {
+					defer done.Done()
+					defer r.Unsubscribe()
+					for {
+						<-r.WaitChan()
+						if _, err := r.TryConsume(); err != nil {
+							return
+						}
+						received.Done()
+					}
+				}()
+			}
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				received.Add(readers)
+				d.SubmitQuiet(MyEvent{id: i})
+				received.Wait()
+			}
+			d.Close()
+			done.Wait()
+		})
+	}
+}
+
+// BenchmarkWaitChanContended measures a single producer submitting to 8 Readers, each consuming in
+// its own goroutine as fast as it can, so that WaitChan() usually finds an event already available.
+func BenchmarkWaitChanContended(b *testing.B) {
+	const readers = 8
+	d := eventdistributor.New[MyEvent]()
+
+	var wg sync.WaitGroup
+	for i := 0; i < readers; i++ {
+		r := d.Subscribe()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer r.Unsubscribe()
+			for {
+				<-r.WaitChan()
+				if _, err := r.TryConsume(); err == eventdistributor.ErrClosed {
+					return
+				}
+			}
+		}()
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d.SubmitQuiet(MyEvent{id: i})
+	}
+	d.Close()
+	wg.Wait()
+}
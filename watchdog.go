@@ -0,0 +1,107 @@
+package eventdistributor
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// WithCallbackWatchdog sets a threshold for the time taken by each invocation of a user callback
+// (OnSubmit, OnFullyConsumed, etc.). Any invocation that takes longer than the threshold is
+// reported by calling report with the kind of callback (e.g. "OnSubmit"), the time it took, and the
+// stack at the point where the callback was registered.
+//
+// Callbacks run one at a time, so a slow callback delays every callback after it, and slows down
+// the Readers and producers that end up calling them. This option is intended to find such
+// callbacks.
+//
+// When this option is not set, callbacks are not timed at all. If it is set more than once, the
+// last call takes precedence.
+func (o *Options[T]) WithCallbackWatchdog(
+	threshold time.Duration,
+	report func(kind string, took time.Duration, stack []byte),
+) {
+	o.watchdog = &callbackWatchdog{threshold: threshold, report: report}
+}
+
+type callbackWatchdog struct {
+	threshold time.Duration
+	report    func(kind string, took time.Duration, stack []byte)
+}
+
+// maxRegistrationDepth is the maximum number of stack frames recorded when a callback is
+// registered
+const maxRegistrationDepth = 8
+
+// registrationSite is the stack at the point where a callback was registered, recorded so that the
+// watchdog can report where a slow callback came from.
+type registrationSite struct {
+	pcs [maxRegistrationDepth]uintptr
+	n   int
+}
+
+// captureRegistrationSite records the stack of the caller's caller - i.e. the user code that called
+// one of the Options methods.
+func captureRegistrationSite() registrationSite {
+	var site registrationSite
+	// skip runtime.Callers, captureRegistrationSite, and the Options method
+	site.n = runtime.Callers(3, site.pcs[:])
+	return site
+}
+
+func (s registrationSite) format() []byte {
+	return formatStack(s.pcs[:s.n])
+}
+
+// formatStack formats the stack recorded by runtime.Callers, one frame per function
+func formatStack(pcs []uintptr) []byte {
+	var buf bytes.Buffer
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			fmt.Fprintf(&buf, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return buf.Bytes()
+}
+
+// watchCallback wraps the callback so that it's timed by the watchdog, if there is one. If w is
+// nil, the callback is returned unchanged.
+func watchCallback[A any](w *callbackWatchdog, kind string, site registrationSite, f func(A)) func(A) {
+	if w == nil {
+		return f
+	}
+
+	return func(arg A) {
+		start := time.Now()
+		f(arg)
+		if took := time.Since(start); took > w.threshold {
+			w.report(kind, took, site.format())
+		}
+	}
+}
+
+// watchCallback2 is like watchCallback, but for callbacks with two arguments
+func watchCallback2[A, B any](
+	w *callbackWatchdog,
+	kind string,
+	site registrationSite,
+	f func(A, B),
+) func(A, B) {
+	if w == nil {
+		return f
+	}
+
+	return func(a A, b B) {
+		start := time.Now()
+		f(a, b)
+		if took := time.Since(start); took > w.threshold {
+			w.report(kind, took, site.format())
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package eventdistributor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sharnoff/eventdistributor"
+)
+
+func TestCallbackWatchdog(t *testing.T) {
+	type report struct {
+		kind  string
+		took  time.Duration
+		stack string
+	}
+	var reports []report
+
+	var options eventdistributor.Options[MyEvent]
+	options.OnSubmit(func(e MyEvent) {
+		if e.id == 2 {
+			time.Sleep(20 * time.Millisecond)
+		}
+	})
+	options.OnFullyConsumed(func(e MyEvent) {})
+	options.WithCallbackWatchdog(10*time.Millisecond, func(kind string, took time.Duration, stack []byte) {
+		reports = append(reports, report{kind: kind, took: took, stack: string(stack)})
+	})
+
+	d := eventdistributor.New(options)
+
+	t.Log("fast callbacks are not reported")
+	d.Submit(MyEvent{id: 1})
+	require.Empty(t, reports)
+
+	t.Log("slow callbacks are reported, with where they were registered")
+	d.Submit(MyEvent{id: 2})
+	require.Len(t, reports, 1)
+	require.Equal(t, "OnSubmit", reports[0].kind)
+	require.GreaterOrEqual(t, reports[0].took, 20*time.Millisecond)
+	require.Contains(t, reports[0].stack, "TestCallbackWatchdog")
+	require.Contains(t, reports[0].stack, "watchdog_test.go")
+}